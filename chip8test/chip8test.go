@@ -0,0 +1,137 @@
+// Package chip8test provides helpers for regression testing ROMs against
+// their rendered output.
+//
+// Golden frames are PNG images of the display, one image pixel per display
+// pixel. Run the tests with -chip8test.update to write the current frame to
+// the golden file instead of comparing against it.
+package chip8test
+
+import (
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+var update = flag.Bool("chip8test.update", false, "Update chip8test golden frames")
+
+// RunROM loads the ROM at path into a new CHIP-8 VM and executes cycles
+// instructions, failing the test on any error.
+func RunROM(t testing.TB, path string, cycles int) *chip8.VM {
+	t.Helper()
+
+	return run(t, path, chip8.New(), cycles)
+}
+
+// RunROMVariant is RunROM for another variant, named as for the -variant flag,
+// one of chip8.Variants.
+func RunROMVariant(t testing.TB, path, variant string, cycles int) *chip8.VM {
+	t.Helper()
+
+	vr, err := chip8.ParseVariant(variant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return run(t, path, chip8.NewVariant(vr), cycles)
+}
+
+// run loads the ROM at path into vm and executes cycles instructions.
+func run(t testing.TB, path string, vm *chip8.VM, cycles int) *chip8.VM {
+	t.Helper()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("could not open ROM: %s", err)
+	}
+	defer f.Close()
+
+	if err = vm.Load(f); err != nil {
+		t.Fatalf("could not load ROM: %s", err)
+	}
+
+	for c := 0; c < cycles; c++ {
+		if err = vm.Cycle(); err != nil {
+			t.Fatalf("cycle %d: %s", c, err)
+		}
+	}
+
+	return vm
+}
+
+// AssertFrame compares the display of vm against the golden PNG at path,
+// failing the test if any pixel differs.
+func AssertFrame(t testing.TB, vm *chip8.VM, golden string) {
+	t.Helper()
+
+	got := Frame(vm)
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		f, err := os.Create(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if err = png.Encode(f, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	f, err := os.Open(golden)
+	if err != nil {
+		t.Fatalf("could not open golden frame: %s", err)
+	}
+	defer f.Close()
+
+	exp, err := png.Decode(f)
+	if err != nil {
+		t.Fatalf("could not decode golden frame: %s", err)
+	}
+
+	if !exp.Bounds().Eq(got.Bounds()) {
+		t.Fatalf("frame size: expected %v, got %v", exp.Bounds().Size(), got.Bounds().Size())
+	}
+
+	diff := 0
+	b := got.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if lit(exp.At(x, y)) == lit(got.At(x, y)) {
+				continue
+			}
+			if diff < 10 {
+				t.Errorf("pixel (%d, %d): expected %t, got %t", x, y, lit(exp.At(x, y)), lit(got.At(x, y)))
+			}
+			diff++
+		}
+	}
+	if diff > 0 {
+		t.Errorf("%d pixels differ from %s", diff, golden)
+	}
+}
+
+// Frame returns the display of vm as a greyscale image, lit pixels are white.
+func Frame(vm *chip8.VM) *image.Gray {
+	disp := vm.Display()
+	img := image.NewGray(image.Rect(0, 0, disp.Width(), disp.Height()))
+	for i := range img.Pix {
+		if vm.PixelSet(i) {
+			img.Pix[i] = 0xFF
+		}
+	}
+	return img
+}
+
+// lit returns true if c is closer to white than black.
+func lit(c color.Color) bool {
+	return color.GrayModel.Convert(c).(color.Gray).Y >= 0x80
+}
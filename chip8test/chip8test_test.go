@@ -0,0 +1,17 @@
+package chip8test
+
+import "testing"
+
+func TestAssertFrame(t *testing.T) {
+	// Draws the font sprite for C at (5, 3).
+	vm := RunROM(t, "testdata/font.ch8", 10)
+	AssertFrame(t, vm, "testdata/font.png")
+}
+
+func TestRunROMVariant(t *testing.T) {
+	vm := RunROMVariant(t, "testdata/font.ch8", "megachip", 10)
+	if vm.Variant().String() != "megachip" {
+		t.Fatalf("expected megachip, got %s", vm.Variant())
+	}
+	AssertFrame(t, vm, "testdata/font.png")
+}
@@ -0,0 +1,52 @@
+package chip8test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/chip8/reference"
+)
+
+// Diff runs rom on both the VM and the reference interpreter for up to cycles
+// instructions, comparing their state after every instruction. The test fails
+// at the first divergence, reporting the instruction responsible.
+//
+// Both machines are expected to agree on errors too; the run stops once both
+// have failed.
+func Diff(t testing.TB, rom []byte, cycles int) {
+	t.Helper()
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatalf("could not load ROM: %s", err)
+	}
+	ref, err := reference.New(rom)
+	if err != nil {
+		t.Fatalf("could not load ROM into reference: %s", err)
+	}
+
+	for c := 0; c < cycles; c++ {
+		pc := vm.PC()
+		opc := uint16(vm.Peek(pc))<<8 | uint16(vm.Peek(pc+1))
+
+		vmErr, refErr := vm.Cycle(), ref.Step()
+		switch {
+		case vmErr != nil && refErr != nil:
+			return
+		case vmErr != nil || refErr != nil:
+			t.Fatalf("cycle %d pc 0x%03X opcode 0x%04X: VM error %v, reference error %v", c, pc, opc, vmErr, refErr)
+		}
+
+		// Random numbers can't be compared, so take the VM's result.
+		if opc&0xF000 == 0xC000 {
+			x := byte(opc >> 8 & 0xF)
+			ref.V[x] = vm.V(x)
+		}
+
+		if d := reference.Diff(vm.State(), ref); len(d) > 0 {
+			t.Fatalf("cycle %d pc 0x%03X opcode 0x%04X diverged:\n\t%s", c, pc, opc, strings.Join(d, "\n\t"))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package chip8test
+
+import "testing"
+
+func TestDiffALU(t *testing.T) {
+	var rom []byte
+
+	// Exercise every 8XYN instruction across a spread of operands, including
+	// the carry and borrow edge cases, writing each result to memory.
+	vals := []byte{0x00, 0x01, 0x7F, 0x80, 0xFE, 0xFF}
+	for _, a := range vals {
+		for _, b := range vals {
+			for _, n := range []byte{0x0, 0x1, 0x2, 0x3, 0x4, 0x5, 0x6, 0x7, 0xE} {
+				rom = append(rom,
+					0x61, a, // V1 = a
+					0x62, b, // V2 = b
+					0x81, 0x20|n, // V1 = V1 op V2
+				)
+			}
+		}
+	}
+	rom = append(rom, 0x12, 0x00) // Jump back to the start.
+
+	Diff(t, rom, len(rom))
+}
+
+func TestDiffDraw(t *testing.T) {
+	rom := []byte{
+		0x00, 0xE0, // Clear the display.
+		0x60, 0x08, // V0 = 8
+		0xF0, 0x29, // I = font(V0)
+		0x61, 0x10, // V1 = 16
+		0x62, 0x05, // V2 = 5
+		0xD1, 0x25, // Draw.
+		0xD1, 0x25, // Draw again, colliding.
+		0x71, 0x04, // V1 += 4
+		0xD1, 0x25, // Draw, partially overlapping.
+		0x60, 0x63, // V0 = 99
+		0xA3, 0x00, // I = 0x300
+		0xF0, 0x33, // BCD of V0.
+		0xF2, 0x65, // Load V0-V2.
+		0x12, 0x1A, // Jump to self.
+	}
+
+	Diff(t, rom, 50)
+}
+
+func TestDiffSubroutines(t *testing.T) {
+	rom := []byte{
+		0x22, 0x06, // 0x200: Call 0x206.
+		0x22, 0x06, // 0x202: Call 0x206 again.
+		0x12, 0x04, // 0x204: Jump to self.
+		0x73, 0x01, // 0x206: V3 += 1
+		0x33, 0x02, // 0x208: Skip if V3 == 2.
+		0x22, 0x06, // 0x20A: Recurse.
+		0x00, 0xEE, // 0x20C: Return.
+	}
+
+	Diff(t, rom, 40)
+}
+
+func TestDiffDrawEdges(t *testing.T) {
+	var rom []byte
+
+	// Draw the font sprite for 8 at positions either side of every edge.
+	rom = append(rom, 0x60, 0x08, 0xF0, 0x29) // I = font(8)
+	for _, x := range []byte{0, 1, 57, 60, 63, 64, 100} {
+		for _, y := range []byte{0, 27, 29, 31, 32, 40} {
+			rom = append(rom,
+				0x61, x, // V1 = x
+				0x62, y, // V2 = y
+				0xD1, 0x25, // Draw.
+			)
+		}
+	}
+	rom = append(rom, 0x12, 0x00)
+
+	Diff(t, rom, len(rom))
+}
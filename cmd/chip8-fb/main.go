@@ -0,0 +1,289 @@
+// Command chip8-fb runs the emulator on a Linux framebuffer, with no windowing
+// system or OpenGL, for dedicated consoles such as a bare Raspberry Pi.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/evdev"
+	"github.com/danmrichards/chip8/internal/fb"
+	"github.com/danmrichards/chip8/internal/gpio"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/romfmt"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+func main() {
+	var (
+		device   string
+		variant  string
+		ips      int
+		audio    string
+		input    string
+		grab     bool
+		keypad   string
+		buzzer   int
+		freq     int
+		flicker  int
+		logLevel string
+	)
+	flag.StringVar(&device, "device", "/dev/fb0", "Path to the framebuffer device to draw to")
+	flag.StringVar(&variant, "variant", "auto", fmt.Sprintf("Instruction set variant, one of %q, or auto to detect it from the ROM", chip8.Variants))
+	flag.IntVar(&ips, "ips", chip8.ClockSpeed, "Instructions executed per second")
+	flag.StringVar(&audio, "audio", "beep", fmt.Sprintf("Audio backend, one of %q", sound.Backends()))
+	flag.StringVar(&input, "input", "", "Comma separated paths of the input devices to read the keypad from, all of /dev/input/event* by default")
+	flag.BoolVar(&grab, "grab", true, "Take the input devices from the console, so keys typed aren't also read by it")
+	flag.StringVar(&keypad, "keypad", "", "GPIO pins of a 4x4 matrix keypad to read, the rows then the columns, such as 5,6,13,19:12,16,20,21")
+	flag.IntVar(&buzzer, "buzzer", -1, "GPIO pin of a piezo buzzer to sound the tone on, in place of -audio")
+	flag.IntVar(&freq, "buzzer-freq", gpio.DefaultBuzzerFreq, "Frequency to drive a passive buzzer at, or 0 for an active buzzer")
+	flag.IntVar(&flicker, "flicker", 0, "Frames pixels stay lit after they're turned off, to reduce flicker: 1 blends the last two frames, 0 turns it off")
+	flag.StringVar(&logLevel, "log-level", "info", fmt.Sprintf("Minimum level of the messages logged, one of %q", logging.Levels))
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: chip8-fb [flags] rom")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		return
+	}
+
+	level, err := logging.ParseLevel(logLevel)
+	if err != nil {
+		fatalf("%s", err)
+	}
+	logging.SetLevel(level)
+
+	rom, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		fatalf("%s", err)
+	}
+	if rom, _, err = romfmt.Decode(flag.Arg(0), rom); err != nil {
+		fatalf("%s", err)
+	}
+	vr, _ := chip8.Detect(rom)
+	if variant != "auto" {
+		if vr, err = chip8.ParseVariant(variant); err != nil {
+			fatalf("%s", err)
+		}
+	}
+
+	vm := chip8.NewVariant(vr)
+	if err = vm.Load(bytes.NewReader(rom)); err != nil {
+		fatalf("%s", err)
+	}
+	vm.SetFlicker(flicker)
+
+	f, err := fb.Open(device)
+	if err != nil {
+		fatalf("Could not open the framebuffer: %s", err)
+	}
+	defer f.Close()
+
+	var au sound.Audio
+	if buzzer >= 0 {
+		au, err = gpio.OpenBuzzer(buzzer, freq)
+	} else {
+		au, err = sound.New(audio, sound.DefaultBuffer)
+	}
+	if err != nil {
+		fatalf("Could not open audio: %s", err)
+	}
+	defer au.Close()
+
+	// A keypad may be the only input of a physical console.
+	quit, err := openInput(vm, input, grab)
+	if err != nil && (keypad == "" || input != "") {
+		fatalf("Could not open input: %s", err)
+	}
+
+	if keypad != "" {
+		pins, err := gpio.ParseKeypadPins(keypad)
+		if err != nil {
+			fatalf("%s", err)
+		}
+		k, err := gpio.OpenKeypad(pins, gpio.COSMACLayout)
+		if err != nil {
+			fatalf("Could not open the keypad: %s", err)
+		}
+		defer k.Close()
+		go scanKeypad(vm, k)
+	}
+
+	if err = run(vm, f, au, ips, quit); err != nil {
+		fatalf("%s", err)
+	}
+}
+
+// openInput opens the comma separated input devices in paths, or all of them
+// if there are none, pressing the keys read on vm. Devices that can't be
+// opened are skipped when finding all of them. The channel returned is closed
+// when Escape is pressed.
+func openInput(vm *chip8.VM, paths string, grab bool) (<-chan struct{}, error) {
+	var devs []string
+	if paths != "" {
+		devs = strings.Split(paths, ",")
+	} else {
+		devs, _ = filepath.Glob("/dev/input/event*")
+	}
+
+	quit := make(chan struct{})
+	var (
+		quitOnce sync.Once
+		opened   int
+	)
+	for _, path := range devs {
+		d, err := evdev.Open(path, evdev.DefaultKeymap)
+		if err != nil {
+			if paths != "" {
+				return nil, err
+			}
+			continue
+		}
+		if grab {
+			if err = d.Grab(); err != nil {
+				logging.Warnf("Could not grab %s: %s", path, err)
+			}
+		}
+		opened++
+
+		go func(path string, d *evdev.Device) {
+			defer d.Close()
+			for {
+				e, err := d.Next()
+				switch {
+				case err == evdev.ErrEscape:
+					quitOnce.Do(func() { close(quit) })
+					return
+				case err != nil:
+					logging.Warnf("Stopped reading %s: %s", path, err)
+					return
+				case e.Down:
+					vm.Press(e.Key)
+				default:
+					vm.Release(e.Key)
+				}
+			}
+		}(path, d)
+	}
+
+	if opened == 0 {
+		return nil, fmt.Errorf("no devices could be opened in /dev/input")
+	}
+	return quit, nil
+}
+
+// scanKeypad scans k at the frame rate, pressing the keys read on vm.
+func scanKeypad(vm *chip8.VM, k *gpio.Keypad) {
+	tick := time.NewTicker(time.Second / chip8.FrameRate)
+	defer tick.Stop()
+
+	for range tick.C {
+		err := k.Scan(func(key byte, down bool) {
+			if down {
+				vm.Press(key)
+			} else {
+				vm.Release(key)
+			}
+		})
+		if err != nil {
+			logging.Warnf("Stopped scanning the keypad: %s", err)
+			return
+		}
+	}
+}
+
+// run runs vm a frame at a time at ips instructions a second, drawing the
+// display to f whenever it changes and sounding the tone with au, until quit
+// is closed.
+func run(vm *chip8.VM, f *fb.Framebuffer, au sound.Audio, ips int, quit <-chan struct{}) error {
+	tick := time.NewTicker(time.Second / chip8.FrameRate)
+	defer tick.Stop()
+
+	perFrame := ips / chip8.FrameRate
+	if perFrame < 1 {
+		perFrame = 1
+	}
+
+	events := vm.Subscribe(4)
+	defer events.Close()
+
+	var drawn *chip8.Display
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-tick.C:
+		}
+
+		for i := 0; i < perFrame; i++ {
+			if err := vm.Cycle(); err != nil {
+				return err
+			}
+		}
+
+		// The display is drawn below if it's dirty, only the tone needs
+		// acting on.
+	drain:
+		for {
+			select {
+			case e := <-events.Events():
+				switch e.(type) {
+				case chip8.BeepStartEvent:
+					tone(vm, au, true)
+				case chip8.BeepStopEvent:
+					tone(vm, au, false)
+				}
+			default:
+				break drain
+			}
+		}
+
+		// The display is redrawn in full at first and each time the VM
+		// replaces it, as the resolution changes.
+		if disp := vm.Display(); disp != drawn || disp.Dirty() {
+			var rects []image.Rectangle
+			if disp == drawn {
+				rects = disp.DirtyRects()
+			}
+			if err := f.Draw(vm.Image(), rects); err != nil {
+				return err
+			}
+			disp.MarkClean()
+			drawn = disp
+		}
+	}
+}
+
+// tone starts or stops the tone, playing the program's waveform if it has set
+// one.
+func tone(vm *chip8.VM, au sound.Audio, on bool) {
+	var err error
+	if pattern, ok := vm.Pattern(); on && ok {
+		err = au.PlayPattern(pattern)
+	} else if on {
+		err = au.StartTone()
+	} else {
+		err = au.StopTone()
+	}
+	if err != nil {
+		logging.Warnf("Error playing tone: %s", err)
+	}
+}
+
+// fatalf logs the message at the error level and exits.
+func fatalf(format string, v ...interface{}) {
+	logging.Errorf(format, v...)
+	os.Exit(1)
+}
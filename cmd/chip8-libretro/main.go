@@ -0,0 +1,400 @@
+// Command chip8-libretro is a libretro core backed by the VM, so ROMs can be
+// run in RetroArch with its save states, shaders and input handling. Build it
+// as a shared library:
+//
+//	go build -buildmode=c-shared -o chip8_libretro.so ./cmd/chip8-libretro
+package main
+
+/*
+#include <stdlib.h>
+
+#include "libretro.h"
+
+static bool call_environment(retro_environment_t cb, unsigned cmd, void *data) {
+	return cb(cmd, data);
+}
+
+static void call_video_refresh(retro_video_refresh_t cb, const void *data, unsigned width, unsigned height, size_t pitch) {
+	cb(data, width, height, pitch);
+}
+
+static size_t call_audio_sample_batch(retro_audio_sample_batch_t cb, const int16_t *data, size_t frames) {
+	return cb(data, frames);
+}
+
+static void call_input_poll(retro_input_poll_t cb) {
+	cb();
+}
+
+static int16_t call_input_state(retro_input_state_t cb, unsigned port, unsigned device, unsigned index, unsigned id) {
+	return cb(port, device, index, id);
+}
+
+static void call_log(retro_log_printf_t cb, enum retro_log_level level, const char *msg) {
+	cb(level, "%s\n", msg);
+}
+*/
+import "C"
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"unsafe"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// sampleRate is the rate, in Hz, of the audio given to the frontend.
+const sampleRate = 44100
+
+// toneFreq is the frequency, in Hz, of the square wave played for the tone.
+const toneFreq = 500
+
+// The callbacks set by the frontend.
+var (
+	environment  C.retro_environment_t
+	videoRefresh C.retro_video_refresh_t
+	audioBatch   C.retro_audio_sample_batch_t
+	inputPoll    C.retro_input_poll_t
+	inputState   C.retro_input_state_t
+	logPrintf    C.retro_log_printf_t
+)
+
+// The strings of the system info, which must outlive the call.
+var (
+	libraryName     = C.CString("chip8")
+	libraryVersion  = C.CString("1.0")
+	validExtensions = C.CString("ch8|c8|sc8|xo8")
+)
+
+// The core's state: the VM running the game, the frame given to the frontend
+// as XRGB8888 and the audio of a frame as interleaved stereo samples.
+var (
+	vm        *chip8.VM
+	frame     []uint32
+	samples   []int16
+	phase     int
+	stateSize int
+
+	// stopped is set once the program stops with an error, after which the
+	// last frame is shown until the game is reset or a state loaded.
+	stopped bool
+)
+
+// keyboard maps keys on the keyboard, the libretro key codes of which are
+// ASCII, to the keypad as in the window.
+var keyboard = map[byte]C.unsigned{
+	0x1: '1', 0x2: '2', 0x3: '3', 0xC: '4',
+	0x4: 'q', 0x5: 'w', 0x6: 'e', 0xD: 'r',
+	0x7: 'a', 0x8: 's', 0x9: 'd', 0xE: 'f',
+	0xA: 'z', 0x0: 'x', 0xB: 'c', 0xF: 'v',
+}
+
+// joypad maps the RetroPad to the keypad: the D-pad to 2, 4, 6 and 8, which
+// most games use to move, and A and B to 5 and 0.
+var joypad = map[byte]C.unsigned{
+	0x2: C.RETRO_DEVICE_ID_JOYPAD_UP,
+	0x8: C.RETRO_DEVICE_ID_JOYPAD_DOWN,
+	0x4: C.RETRO_DEVICE_ID_JOYPAD_LEFT,
+	0x6: C.RETRO_DEVICE_ID_JOYPAD_RIGHT,
+	0x5: C.RETRO_DEVICE_ID_JOYPAD_A,
+	0x0: C.RETRO_DEVICE_ID_JOYPAD_B,
+}
+
+//export retro_api_version
+func retro_api_version() C.unsigned {
+	return C.RETRO_API_VERSION
+}
+
+//export retro_set_environment
+func retro_set_environment(cb C.retro_environment_t) {
+	environment = cb
+
+	var logging C.struct_retro_log_callback
+	if C.call_environment(cb, C.RETRO_ENVIRONMENT_GET_LOG_INTERFACE, unsafe.Pointer(&logging)) {
+		logPrintf = logging.log
+	}
+}
+
+// errorf logs an error to the frontend's log, or to stderr if the frontend
+// has none.
+func errorf(format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if logPrintf == nil {
+		log.Print(msg)
+		return
+	}
+
+	cmsg := C.CString(msg)
+	defer C.free(unsafe.Pointer(cmsg))
+	C.call_log(logPrintf, C.RETRO_LOG_ERROR, cmsg)
+}
+
+//export retro_set_video_refresh
+func retro_set_video_refresh(cb C.retro_video_refresh_t) {
+	videoRefresh = cb
+}
+
+//export retro_set_audio_sample
+func retro_set_audio_sample(C.retro_audio_sample_t) {}
+
+//export retro_set_audio_sample_batch
+func retro_set_audio_sample_batch(cb C.retro_audio_sample_batch_t) {
+	audioBatch = cb
+}
+
+//export retro_set_input_poll
+func retro_set_input_poll(cb C.retro_input_poll_t) {
+	inputPoll = cb
+}
+
+//export retro_set_input_state
+func retro_set_input_state(cb C.retro_input_state_t) {
+	inputState = cb
+}
+
+//export retro_init
+func retro_init() {
+	samples = make([]int16, 2*sampleRate/chip8.FrameRate)
+}
+
+//export retro_deinit
+func retro_deinit() {
+	vm = nil
+}
+
+//export retro_get_system_info
+func retro_get_system_info(info *C.struct_retro_system_info) {
+	info.library_name = libraryName
+	info.library_version = libraryVersion
+	info.valid_extensions = validExtensions
+	info.need_fullpath = false
+	info.block_extract = false
+}
+
+//export retro_get_system_av_info
+func retro_get_system_av_info(info *C.struct_retro_system_av_info) {
+	w, h := chip8.DisplayWidth, chip8.DisplayHeight
+	maxW, maxH := chip8.MegaDisplayWidth, chip8.MegaDisplayHeight
+	if vm != nil {
+		w, h = vm.Display().Width(), vm.Display().Height()
+	}
+
+	info.geometry.base_width = C.unsigned(w)
+	info.geometry.base_height = C.unsigned(h)
+	info.geometry.max_width = C.unsigned(maxW)
+	info.geometry.max_height = C.unsigned(maxH)
+	info.geometry.aspect_ratio = C.float(float64(w) / float64(h))
+	info.timing.fps = chip8.FrameRate
+	info.timing.sample_rate = sampleRate
+}
+
+//export retro_set_controller_port_device
+func retro_set_controller_port_device(port, device C.unsigned) {}
+
+//export retro_reset
+func retro_reset() {
+	if vm != nil {
+		vm.Reset()
+		stopped = false
+	}
+}
+
+//export retro_load_game
+func retro_load_game(game *C.struct_retro_game_info) C.bool {
+	if game == nil || game.data == nil {
+		return false
+	}
+	rom := C.GoBytes(game.data, C.int(game.size))
+
+	format := C.unsigned(C.RETRO_PIXEL_FORMAT_XRGB8888)
+	if !C.call_environment(environment, C.RETRO_ENVIRONMENT_SET_PIXEL_FORMAT, unsafe.Pointer(&format)) {
+		return false
+	}
+
+	vr, _ := chip8.Detect(rom)
+	vm = chip8.NewVariant(vr)
+	stopped = false
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		vm = nil
+		return false
+	}
+
+	// Save states are JSON, which grows as the program runs, so leave room
+	// for the largest it can be. Loading may have switched the variant, to
+	// Hi-Res for one.
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		errorf("Could not save the state: %s", err)
+		vm = nil
+		return false
+	}
+	stateSize = buf.Len() + stateMargin(vm.Variant())
+
+	return true
+}
+
+// stateMargin returns the most a JSON save state of a freshly loaded VM of the
+// variant can grow by. Memory is a fixed size, so only the display, the
+// stack and the numbers held grow.
+func stateMargin(vr chip8.Variant) int {
+	// The pixels of the variant's largest display, base64 encoded.
+	w, h := vr.DisplaySize()
+	pixels := 4 * ((w*h + 2) / 3)
+
+	// A full stack, at most 16 addresses each indented on its own line, and the
+	// registers, I, PC and timers growing from a digit to their largest.
+	const (
+		stack   = 16 * len("\t\t65535,\n")
+		regs    = 16 * len("255")
+		numbers = len("4294967295") + len("65535") + 2*len("255")
+	)
+
+	return pixels + stack + regs + numbers
+}
+
+//export retro_load_game_special
+func retro_load_game_special(C.unsigned, *C.struct_retro_game_info, C.size_t) C.bool {
+	return false
+}
+
+//export retro_unload_game
+func retro_unload_game() {
+	vm = nil
+}
+
+//export retro_get_region
+func retro_get_region() C.unsigned {
+	return C.RETRO_REGION_NTSC
+}
+
+//export retro_run
+func retro_run() {
+	if vm == nil {
+		return
+	}
+
+	C.call_input_poll(inputPoll)
+	var keys [16]bool
+	for k, id := range keyboard {
+		keys[k] = C.call_input_state(inputState, 0, C.RETRO_DEVICE_KEYBOARD, 0, id) != 0
+	}
+	for k, id := range joypad {
+		keys[k] = keys[k] || C.call_input_state(inputState, 0, C.RETRO_DEVICE_JOYPAD, 0, id) != 0
+	}
+	vm.SetKeys(keys)
+
+	// Frontends expect a frame every run, so the last is shown again once
+	// the program has stopped.
+	if !stopped {
+		if err := vm.StepFrame(); err != nil {
+			errorf("The program stopped: %s", err)
+			stopped = true
+		}
+	}
+
+	video()
+	audio()
+}
+
+// video gives the display to the frontend.
+func video() {
+	img := vm.Image()
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	if len(frame) != w*h {
+		frame = make([]uint32, w*h)
+	}
+
+	palette := make([]uint32, len(img.Palette))
+	for i, c := range img.Palette {
+		r, g, b, _ := c.RGBA()
+		palette[i] = (r>>8)<<16 | (g>>8)<<8 | b>>8
+	}
+	for i, p := range img.Pix {
+		frame[i] = palette[p]
+	}
+
+	C.call_video_refresh(videoRefresh, unsafe.Pointer(&frame[0]), C.unsigned(w), C.unsigned(h), C.size_t(4*w))
+}
+
+// audio gives a frame of audio to the frontend, a square wave while the sound
+// timer is running and silence otherwise, or once the program has stopped.
+func audio() {
+	_, sound := vm.Timers()
+	for i := 0; i < len(samples); i += 2 {
+		var s int16
+		if sound > 0 && !stopped {
+			s = 4000
+			if phase < sampleRate/toneFreq/2 {
+				s = -s
+			}
+			phase = (phase + 1) % (sampleRate / toneFreq)
+		}
+		samples[i], samples[i+1] = s, s
+	}
+
+	C.call_audio_sample_batch(audioBatch, (*C.int16_t)(unsafe.Pointer(&samples[0])), C.size_t(len(samples)/2))
+}
+
+//export retro_serialize_size
+func retro_serialize_size() C.size_t {
+	return C.size_t(stateSize)
+}
+
+//export retro_serialize
+func retro_serialize(data unsafe.Pointer, size C.size_t) C.bool {
+	if vm == nil {
+		return false
+	}
+
+	var buf bytes.Buffer
+	if err := vm.SaveState(&buf); err != nil {
+		errorf("Could not save the state: %s", err)
+		return false
+	}
+	if buf.Len() > int(size) {
+		errorf("Could not save the state: %d bytes is more than the %d allowed", buf.Len(), size)
+		return false
+	}
+
+	// Pad the state with spaces, which the JSON decoder skips, to fill the
+	// fixed size frontends expect.
+	dst := (*[1 << 30]byte)(data)[:size:size]
+	n := copy(dst, buf.Bytes())
+	for i := n; i < len(dst); i++ {
+		dst[i] = ' '
+	}
+	return true
+}
+
+//export retro_unserialize
+func retro_unserialize(data unsafe.Pointer, size C.size_t) C.bool {
+	if vm == nil {
+		return false
+	}
+	if err := vm.LoadState(bytes.NewReader(C.GoBytes(data, C.int(size)))); err != nil {
+		errorf("Could not load the state: %s", err)
+		return false
+	}
+	stopped = false
+	return true
+}
+
+//export retro_cheat_reset
+func retro_cheat_reset() {}
+
+//export retro_cheat_set
+func retro_cheat_set(C.unsigned, C.bool, *C.char) {}
+
+//export retro_get_memory_data
+func retro_get_memory_data(C.unsigned) unsafe.Pointer {
+	return nil
+}
+
+//export retro_get_memory_size
+func retro_get_memory_size(C.unsigned) C.size_t {
+	return 0
+}
+
+func main() {}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/octo"
+	"github.com/danmrichards/chip8/internal/romfmt"
+)
+
+// runAsm runs the asm subcommand, assembling an Octo source file into a ROM
+// and returning the process exit code.
+func runAsm(args []string) int {
+	fs := flag.NewFlagSet("asm", flag.ExitOnError)
+	out := fs.String("o", "", "Path to write the ROM to, the source path with a .ch8 extension by default")
+	syms := fs.String("symbols", "", "Path to write a symbol file of the labels to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 asm [flags] program.8o")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	src := fs.Arg(0)
+
+	p, err := assemble(src)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(src, ".8o") + ".ch8"
+	}
+	if err = ioutil.WriteFile(*out, p.ROM, 0644); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *syms != "" {
+		f, err := os.Create(*syms)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer f.Close()
+
+		if err = p.Symbols.Write(f); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// assemble assembles the Octo source file at path.
+func assemble(path string) (*octo.Program, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := octo.Assemble(src)
+	if e, ok := err.(*octo.Error); ok {
+		return nil, fmt.Errorf("%s:%d: %s", path, e.Line, e.Msg)
+	}
+	return p, err
+}
+
+// readROM reads the ROM at path, assembling it first if it's an Octo source
+// file and decoding it if it's Intel HEX or base64.
+func readROM(path string) ([]byte, error) {
+	if filepath.Ext(path) != ".8o" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		rom, _, err := romfmt.Decode(path, data)
+		return rom, err
+	}
+
+	p, err := assemble(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.ROM, nil
+}
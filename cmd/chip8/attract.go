@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/browse"
+	"github.com/danmrichards/chip8/internal/event"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/movie"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// demo is a movie the attract mode can play, with the ROM it was recorded
+// with.
+type demo struct {
+	path  string
+	rom   string
+	movie *movie.Movie
+}
+
+// demosDir returns the directory of the movies bundled for the attract mode,
+// in the user's config directory.
+func demosDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "demos"), nil
+}
+
+// findDemos returns the movies under the demos directory and dir that were
+// recorded with one of roms, matched by hash so they play wherever the ROMs
+// have moved to.
+func findDemos(dir string, roms []string, b *browse.Browser) []demo {
+	byHash := make(map[string]string)
+	for _, rom := range roms {
+		if id, ok := b.ID(rom); ok {
+			byHash[id.SHA1] = rom
+		}
+	}
+	if len(byHash) == 0 {
+		return nil
+	}
+
+	dirs := []string{dir}
+	if d, err := demosDir(); err == nil {
+		dirs = append(dirs, d)
+	}
+
+	var demos []demo
+	for _, d := range dirs {
+		filepath.Walk(d, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.EqualFold(filepath.Ext(path), movie.Ext) {
+				return nil
+			}
+			m, err := movie.Load(path)
+			if err != nil {
+				logging.Warnf("Could not load the demo %s: %s", path, err)
+				return nil
+			}
+			if rom, ok := byHash[m.SHA1]; ok && m.Frames > 0 {
+				demos = append(demos, demo{path: path, rom: rom, movie: m})
+			}
+			return nil
+		})
+	}
+	return demos
+}
+
+// attract plays a random one of demos in window until a key or mouse button
+// is pressed, returning false if the window was closed.
+func attract(window *pixelgl.Window, demos []demo, b *browse.Browser) bool {
+	d := demos[rand.New(rand.NewSource(time.Now().UnixNano())).Intn(len(demos))]
+	rom, err := readROM(d.rom)
+	if err == nil {
+		err = d.movie.Check(rom)
+	}
+	if err != nil {
+		logging.Warnf("Could not play the demo %s: %s", d.path, err)
+		return true
+	}
+	vm, err := play(d.movie, rom)
+	if err != nil {
+		logging.Warnf("Could not play the demo %s: %s", d.path, err)
+		return true
+	}
+
+	title := filepath.Base(d.rom)
+	if info := b.Info(d.rom); info.Known {
+		title = info.Entry.String()
+	}
+	return event.Demo(window, vm, d.movie.Frames, []string{"DEMO: " + title, "Press any key"})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/danmrichards/chip8/internal/browse"
+	"github.com/danmrichards/chip8/internal/event"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/thumbs"
+)
+
+// newBrowser returns the lookup of ROMs for the ROM browser, with the
+// thumbnails rendered by the thumbs subcommand and the hashes cached in the
+// config directory.
+func newBrowser() *browse.Browser {
+	store, err := thumbs.DefaultStore()
+	if err != nil {
+		logging.Warnf("Could not find the thumbnails: %s", err)
+	}
+	cache, err := browse.CachePath()
+	if err != nil {
+		logging.Warnf("Could not find the ROM browser's cache: %s", err)
+	}
+	return browse.New(knownROMs(), store, readROM, cache)
+}
+
+// browserPreview returns the preview of the ROM selected in the ROM browser:
+// its cover art or thumbnail, and its title and description if it's known.
+func browserPreview(b *browse.Browser) func(rom string) event.Preview {
+	return func(rom string) event.Preview {
+		info := b.Info(rom)
+		p := event.Preview{Picture: info.Picture}
+		if info.Known {
+			p.Text = info.Entry.String()
+			if info.Entry.Description != "" {
+				p.Text += "\n\n" + info.Entry.Description
+			}
+		}
+		return p
+	}
+}
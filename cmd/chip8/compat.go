@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/compat"
+)
+
+// runCompat runs the compat subcommand, running ROMs headlessly and writing a
+// table of how each fared, and returning the process exit code.
+func runCompat(args []string) int {
+	fs := flag.NewFlagSet("compat", flag.ExitOnError)
+	seconds := fs.Int("seconds", 10, "Seconds of emulated time to run each ROM for")
+	format := fs.String("format", "markdown", "Format of the report, markdown or html")
+	out := fs.String("o", "", "Path to write the report to, standard output by default")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 compat [flags] rom|dir...")
+		fmt.Fprintln(fs.Output(), "\nRuns each ROM, and those under each directory, and reports whether it drew, halted, hit an unsupported opcode or crashed. dir/... is read as dir.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	var write func(io.Writer, []compat.Result) error
+	switch *format {
+	case "markdown":
+		write = compat.WriteMarkdown
+	case "html":
+		write = compat.WriteHTML
+	default:
+		fmt.Printf("unknown format %q, expected markdown or html\n", *format)
+		return 2
+	}
+
+	// Sources aren't assembled, only ROMs are run.
+	var roms []string
+	for _, arg := range fs.Args() {
+		arg = strings.TrimSuffix(arg, "/...")
+		found, err := findROMs(arg)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		for _, r := range found {
+			if filepath.Ext(r) != ".8o" {
+				roms = append(roms, r)
+			}
+		}
+	}
+
+	var results []compat.Result
+	for _, path := range roms {
+		rom, err := readROM(path)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		results = append(results, compat.Run(path, rom, *seconds))
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w, results); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
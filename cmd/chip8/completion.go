@@ -0,0 +1,354 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/event"
+	"github.com/danmrichards/chip8/internal/fonts"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+// compFlag is a flag as completed by the shells.
+type compFlag struct {
+	name  string
+	usage string
+
+	// Set if the flag takes a value, completed from values if set or as a
+	// file name if file is set.
+	hasArg bool
+	values []string
+	file   bool
+}
+
+// compCommand is a subcommand as completed by the shells.
+type compCommand struct {
+	name    string
+	summary string
+	flags   []compFlag
+
+	// The words taken as arguments or, if there are none, the extensions of
+	// the files taken, or nil for directories.
+	words []string
+	exts  []string
+}
+
+// flagValues are the values of the flags taking one of a set of names.
+func flagValues() map[string][]string {
+	return map[string][]string{
+		"variant":     append([]string{"auto"}, chip8.Variants...),
+		"frontend":    frontends(),
+		"audio":       sound.Backends(),
+		"visual-beep": event.VisualBeeps,
+		"rotate":      {"0", "90", "180", "270"},
+		"mirror":      event.Mirrors,
+		"pacing":      pacings,
+		"background":  backgrounds,
+		"font":        fonts.Styles,
+		"log-level":   logging.Levels,
+		"vf-reset":    toggles,
+		"ext":         extensions,
+	}
+}
+
+// compCommands returns the subcommands and their flags.
+func compCommands() []compCommand {
+	values := flagValues()
+
+	// String flags other than these with no set of values take paths.
+	notFiles := map[string]bool{"monitor": true, "serial": true}
+
+	// The run and debug flags are read from the flag set, so they can't
+	// drift from the completions.
+	windowFlags := func(openDebugger bool) []compFlag {
+		fs := flag.NewFlagSet("", flag.ContinueOnError)
+		new(config).register(fs, openDebugger)
+
+		var flags []compFlag
+		fs.VisitAll(func(f *flag.Flag) {
+			b, isBool := f.Value.(interface{ IsBoolFlag() bool })
+			_, isString := f.Value.(flag.Getter).Get().(string)
+			flags = append(flags, compFlag{
+				name:   f.Name,
+				usage:  f.Usage,
+				hasArg: !isBool || !b.IsBoolFlag(),
+				values: values[f.Name],
+				file:   isString && values[f.Name] == nil && !notFiles[f.Name],
+			})
+		})
+		return flags
+	}
+
+	var cmds []compCommand
+	for _, c := range commands() {
+		cc := compCommand{name: c.name, summary: c.summary, exts: romExts}
+
+		switch c.name {
+		case "run":
+			cc.flags = windowFlags(false)
+		case "debug":
+			cc.flags = windowFlags(true)
+		case "dev":
+			cc.exts = []string{".8o"}
+			cc.flags = windowFlags(true)
+		case "disasm":
+			cc.flags = []compFlag{{name: "symbols", usage: "Path to a symbol file", hasArg: true, file: true}}
+		case "asm":
+			cc.exts = []string{".8o"}
+			cc.flags = []compFlag{
+				{name: "o", usage: "Path to write the ROM to", hasArg: true, file: true},
+				{name: "symbols", usage: "Path to write a symbol file to", hasArg: true, file: true},
+			}
+		case "verify":
+			cc.exts = []string{".yaml", ".yml", ".json"}
+			cc.flags = []compFlag{
+				{name: "rom", usage: "Path to the ROM file to load", hasArg: true, file: true},
+				{name: "coverage", usage: "Print how much of the ROM each spec executed"},
+			}
+		case "compat":
+			cc.flags = []compFlag{
+				{name: "seconds", usage: "Seconds of emulated time to run each ROM for", hasArg: true},
+				{name: "format", usage: "Format of the report", hasArg: true, values: []string{"markdown", "html"}},
+				{name: "o", usage: "Path to write the report to", hasArg: true, file: true},
+			}
+		case "selftest":
+			cc.exts = nil
+			cc.flags = []compFlag{
+				{name: "show", usage: "Print the final display of the runs that failed or have nothing expected"},
+				{name: "update", usage: "Record the final displays as those expected"},
+			}
+		case "sprites":
+			cc.flags = []compFlag{
+				{name: "seconds", usage: "Seconds of emulated time to run the ROM for", hasArg: true},
+				{name: "o", usage: "Path to write the PNG sprite sheet to", hasArg: true, file: true},
+				{name: "scale", usage: "Size in pixels of each sprite pixel on the sheet", hasArg: true},
+				{name: "cols", usage: "Sprites per row of the sheet", hasArg: true},
+			}
+		case "thumbs":
+			cc.exts = nil
+			cc.flags = []compFlag{
+				{name: "seconds", usage: "Seconds of emulated time to run each ROM for", hasArg: true},
+				{name: "workers", usage: "ROMs rendered at once", hasArg: true},
+				{name: "o", usage: "Directory to write the thumbnails to", hasArg: true, file: true},
+				{name: "force", usage: "Render ROMs that already have a thumbnail again"},
+			}
+		case "serve":
+			cc.words = []string{}
+			cc.flags = []compFlag{
+				{name: "listen", usage: "Address to serve on", hasArg: true},
+				{name: "max-rom", usage: "Largest ROM accepted, in bytes", hasArg: true},
+				{name: "frames", usage: "Frames run when a request doesn't say", hasArg: true},
+				{name: "max-frames", usage: "Most frames a request may ask for", hasArg: true},
+				{name: "scale", usage: "Size in pixels of each display pixel when a request doesn't say", hasArg: true},
+				{name: "max-scale", usage: "Largest scale a request may ask for", hasArg: true},
+				{name: "max-pixels", usage: "Most pixels a GIF may have, summed over its frames", hasArg: true},
+				{name: "max-cycles", usage: "Most instructions a ROM may run", hasArg: true},
+				{name: "budget", usage: "Longest a frame may take to run", hasArg: true},
+				{name: "timeout", usage: "Longest a run may take", hasArg: true},
+				{name: "runs", usage: "Most ROMs run at once", hasArg: true},
+				{name: "megachip", usage: "Allow MegaChip ROMs"},
+			}
+		case "render":
+			cc.exts = []string{".c8m"}
+			cc.flags = []compFlag{
+				{name: "o", usage: "Path to write the video to", hasArg: true, file: true},
+				{name: "rom", usage: "Path to the ROM the movie was recorded with", hasArg: true, file: true},
+				{name: "scale", usage: "Size in pixels of each pixel of the display in the video", hasArg: true},
+				{name: "ffmpeg", usage: "Path to the ffmpeg executable", hasArg: true, file: true},
+				{name: "flicker", usage: "Frames pixels stay lit after they're turned off", hasArg: true},
+			}
+		case "list-roms":
+			cc.exts = nil
+		case "romtool":
+			cc.flags = []compFlag{
+				{name: "o", usage: "Path to write the ROM to", hasArg: true, file: true},
+				{name: "trim", usage: "Strip the zeros the ROM ends with"},
+				{name: "pad", usage: "Pad the ROM with zeros to at least this many bytes", hasArg: true},
+				{name: "align", usage: "Pad the ROM with zeros to a multiple of this many bytes", hasArg: true},
+			}
+		case "completion":
+			cc.words = []string{"bash", "zsh", "fish"}
+		}
+
+		cmds = append(cmds, cc)
+	}
+
+	return cmds
+}
+
+// runCompletion runs the completion subcommand, printing the completion
+// script for a shell and returning the process exit code.
+func runCompletion(args []string) int {
+	fs := flag.NewFlagSet("completion", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 completion bash|zsh|fish")
+		fmt.Fprintln(fs.Output(), "\nPrints the completion script for the shell, e.g.:")
+		fmt.Fprintln(fs.Output(), "\n\tsource <(chip8 completion bash)")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	switch fs.Arg(0) {
+	case "bash":
+		writeBash(os.Stdout, compCommands())
+	case "zsh":
+		// zsh runs bash completion functions through bashcompinit.
+		fmt.Fprintln(os.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+		writeBash(os.Stdout, compCommands())
+	case "fish":
+		writeFish(os.Stdout, compCommands())
+	default:
+		fmt.Printf("unknown shell %q, expected bash, zsh or fish\n", fs.Arg(0))
+		return 2
+	}
+
+	return 0
+}
+
+// bashPatterns returns the quoted bash patterns matching files with exts.
+func bashPatterns(exts []string) string {
+	var pats []string
+	for _, e := range exts {
+		pats = append(pats, "'*"+e+"'")
+	}
+	return strings.Join(pats, " ")
+}
+
+// bashFiles is the bash function completing directories and the files
+// matching any of the patterns following the word being completed.
+const bashFiles = `_chip8_files() {
+	local cur=$1 f p
+	shift
+	COMPREPLY+=($(compgen -d -- "$cur"))
+	while IFS= read -r f; do
+		[[ -f $f ]] || continue
+		for p in "$@"; do
+			if [[ $f == $p ]]; then
+				COMPREPLY+=("$f")
+				break
+			fi
+		done
+	done < <(compgen -f -- "$cur")
+}
+`
+
+// writeBash writes the bash completion script for cmds.
+func writeBash(w io.Writer, cmds []compCommand) {
+	var names []string
+	for _, c := range cmds {
+		names = append(names, c.name)
+	}
+
+	fmt.Fprintln(w, "# bash completion for chip8, generated by chip8 completion bash.")
+	io.WriteString(w, bashFiles+"\n")
+	fmt.Fprintln(w, "_chip8() {")
+	fmt.Fprintln(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}")
+	fmt.Fprintln(w, "\tlocal cmd=${COMP_WORDS[1]}")
+	fmt.Fprintln(w, "\tCOMPREPLY=()")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tif [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintf(w, "\t\t_chip8_files \"$cur\" %s\n", bashPatterns(romExts))
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\t# chip8 rom is short for chip8 run rom.")
+	fmt.Fprintln(w, "\tcase $cmd in")
+	fmt.Fprintf(w, "\t%s) ;;\n", strings.Join(names, "|"))
+	fmt.Fprintln(w, "\t*) cmd=run ;;")
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "\tcase $cmd in")
+	for _, c := range cmds {
+		var flags, argFlags, fileFlags []string
+		for _, f := range c.flags {
+			flags = append(flags, "-"+f.name)
+			if f.file {
+				fileFlags = append(fileFlags, "-"+f.name)
+			}
+			if f.values != nil {
+				argFlags = append(argFlags, fmt.Sprintf("\t\t-%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;", f.name, strings.Join(f.values, " ")))
+			}
+		}
+
+		fmt.Fprintf(w, "\t%s)\n", c.name)
+		fmt.Fprintln(w, "\t\tcase $prev in")
+		for _, a := range argFlags {
+			fmt.Fprintln(w, "\t"+a)
+		}
+		if len(fileFlags) > 0 {
+			fmt.Fprintf(w, "\t\t%s) _chip8_files \"$cur\" '*'; return ;;\n", strings.Join(fileFlags, "|"))
+		}
+		fmt.Fprintln(w, "\t\tesac")
+		fmt.Fprintln(w, "\t\tif [[ $cur == -* ]]; then")
+		fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(flags, " "))
+		fmt.Fprintln(w, "\t\telse")
+		if c.words != nil {
+			fmt.Fprintf(w, "\t\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(c.words, " "))
+		} else {
+			fmt.Fprintf(w, "\t\t\t_chip8_files \"$cur\" %s\n", bashPatterns(c.exts))
+		}
+		fmt.Fprintln(w, "\t\tfi")
+		fmt.Fprintln(w, "\t\t;;")
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "complete -o filenames -F _chip8 chip8")
+}
+
+// writeFish writes the fish completion script for cmds.
+func writeFish(w io.Writer, cmds []compCommand) {
+	fmt.Fprintln(w, "# fish completion for chip8, generated by chip8 completion fish.")
+	fmt.Fprintln(w, "complete -c chip8 -f")
+
+	for _, c := range cmds {
+		fmt.Fprintf(w, "complete -c chip8 -n __fish_use_subcommand -a %s -d %s\n", c.name, fishQuote(c.summary))
+	}
+	for _, e := range romExts {
+		fmt.Fprintf(w, "complete -c chip8 -n __fish_use_subcommand -k -a '(__fish_complete_suffix %s)'\n", e)
+	}
+
+	for _, c := range cmds {
+		cond := fmt.Sprintf("-n '__fish_seen_subcommand_from %s'", c.name)
+		for _, f := range c.flags {
+			line := fmt.Sprintf("complete -c chip8 %s -o %s -d %s", cond, f.name, fishQuote(f.usage))
+			switch {
+			case f.values != nil:
+				line += fmt.Sprintf(" -x -a %s", fishQuote(strings.Join(f.values, " ")))
+			case f.file:
+				line += " -r -F"
+			case f.hasArg:
+				line += " -x"
+			}
+			fmt.Fprintln(w, line)
+		}
+
+		if c.words != nil {
+			fmt.Fprintf(w, "complete -c chip8 %s -x -a %s\n", cond, fishQuote(strings.Join(c.words, " ")))
+			continue
+		}
+		if c.exts == nil {
+			fmt.Fprintf(w, "complete -c chip8 %s -x -a '(__fish_complete_directories)'\n", cond)
+			continue
+		}
+		for _, e := range c.exts {
+			fmt.Fprintf(w, "complete -c chip8 %s -k -a '(__fish_complete_suffix %s)'\n", cond, e)
+		}
+	}
+}
+
+// fishQuote quotes s for fish.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,7 @@
+//go:build !gui
+// +build !gui
+
+package main
+
+// guiMode is set when built as a GUI app, see gui.go.
+const guiMode = false
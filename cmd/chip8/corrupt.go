@@ -0,0 +1,24 @@
+package main
+
+import (
+	"time"
+
+	"github.com/danmrichards/chip8/internal/corrupt"
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// corrupt flips -corrupt bits of the ROM loaded, of size bytes. Without a
+// -corrupt-seed one is picked on the first load and logged, so a corruption
+// worth keeping can be played again; restarts reuse it.
+func (a *app) corrupt(size int) {
+	if a.cfg.corruptSeed == 0 {
+		a.cfg.corruptSeed = time.Now().UnixNano()
+	}
+	a.romSize = size
+
+	addrs := corrupt.Flip(a.vm, size, a.cfg.corrupt, a.cfg.corruptSeed)
+	logging.Infof("Corrupted %d bytes of the ROM with -corrupt-seed %d", len(addrs), a.cfg.corruptSeed)
+	for _, addr := range addrs {
+		logging.Debugf("Corrupted 0x%03X", addr)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// writeCoverage writes the coverage of the ROM running to the -coverage file,
+// a summary followed by a listing marking the bytes executed and read. A path
+// of - writes it to the console, coloured.
+func (a *app) writeCoverage() {
+	if a.cov == nil {
+		return
+	}
+
+	f, colour := os.Stdout, true
+	if a.cfg.coverage != "-" {
+		var err error
+		if f, err = os.Create(a.cfg.coverage); err != nil {
+			logging.Warnf("Could not write the coverage: %s", err)
+			return
+		}
+		defer f.Close()
+		colour = false
+	}
+
+	fmt.Fprintf(f, "%s: %s\n\n", a.cfg.rom, a.cov.Summary())
+	if err := a.cov.WriteListing(f, a.vm.Symbols, colour); err != nil {
+		logging.Warnf("Could not write the coverage: %s", err)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// runDev runs the dev subcommand, a development loop for Octo programs: the
+// source is assembled and run with the debugger open, its labels naming
+// addresses, and rebuilt whenever it's saved. It returns the process exit
+// code.
+func runDev(args []string) int {
+	fs := flag.NewFlagSet("dev", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 dev [flags] program.8o")
+		fmt.Fprintln(fs.Output(), "\nRuns the program with the debugger open, reassembling it whenever it changes. The flags are those of run.")
+		fs.PrintDefaults()
+	}
+
+	var cfg config
+	cfg.register(fs, true)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || filepath.Ext(fs.Arg(0)) != ".8o" {
+		fs.Usage()
+		return 2
+	}
+	cfg.rom, cfg.debugger, cfg.watch = fs.Arg(0), true, true
+
+	if err := cfg.setupLog(); err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	// Errors in the source are reported here, before a window opens. Once
+	// running, they're logged and the last good build carries on.
+	if _, err := assemble(cfg.rom); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	a := newApp(cfg)
+	pixelgl.Run(a.run)
+
+	return a.exit
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/disasm"
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+// runDisasm runs the disasm subcommand, printing a listing of a ROM and
+// returning the process exit code.
+func runDisasm(args []string) int {
+	fs := flag.NewFlagSet("disasm", flag.ExitOnError)
+	symbols := fs.String("symbols", "", "Path to a symbol file used to name addresses and mark data")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 disasm [flags] rom")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	rom, err := readROM(fs.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	var syms *symbol.Table
+	if *symbols != "" {
+		if syms, err = symbol.Load(*symbols); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	// Lay the ROM out in memory as it's loaded, so addresses match.
+	mem := append(make([]byte, 0x200), rom...)
+	for _, l := range disasm.Range(mem, 0x200, uint16(len(mem)), syms) {
+		fmt.Println(l)
+	}
+
+	return 0
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// The exit codes of the run and debug commands, so scripts running the
+// emulator can tell why it stopped.
+const (
+	// exitOK is returned once the program has halted, or was quit after
+	// it had.
+	exitOK = 0
+
+	// exitError is returned when anything else goes wrong, and exitUsage
+	// for bad flags.
+	exitError = 1
+	exitUsage = 2
+
+	// exitNoROM is returned when the ROM doesn't exist.
+	exitNoROM = 3
+
+	// exitOpcode is returned when the program executes an opcode the
+	// variant doesn't implement, and exitCrash when it stops on any other
+	// error, such as a stack overflow.
+	exitOpcode = 4
+	exitCrash  = 5
+
+	// exitQuit is returned when the player quit before the program halted.
+	exitQuit = 6
+)
+
+// The reasons the run stopped given in the summary.
+const (
+	reasonHalted = "halted"
+	reasonQuit   = "quit"
+	reasonError  = "error"
+)
+
+// summary is what -summary-json writes once the run has stopped.
+type summary struct {
+	ROM     string `json:"rom"`
+	Variant string `json:"variant"`
+
+	// Cycles is the instructions executed and Draws the changes made to
+	// the display. Frames is the frames the window presented.
+	Cycles uint64 `json:"cycles"`
+	Draws  uint64 `json:"draws"`
+	Frames uint64 `json:"frames,omitempty"`
+
+	// Reason is why the run stopped, one of halted, quit or error, with
+	// the error if there was one.
+	Reason   string `json:"reason"`
+	Error    string `json:"error,omitempty"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// loadExit returns the exit code for err, returned loading the ROM.
+func loadExit(err error) int {
+	if errors.Is(err, os.ErrNotExist) {
+		return exitNoROM
+	}
+	return exitError
+}
+
+// crashes are the errors the VM stops a program on, other than unknown
+// opcodes.
+var crashes = []error{
+	chip8.ErrMemOutOfRange,
+	chip8.ErrStackOverflow,
+	chip8.ErrStackUnderflow,
+	chip8.ErrFlagRange,
+	chip8.ErrCycleLimit,
+	chip8.ErrWatchdog,
+}
+
+// runExit returns the exit code for err, returned running the program.
+func runExit(err error) int {
+	if errors.Is(err, chip8.ErrUnknownOpcode) {
+		return exitOpcode
+	}
+	for _, c := range crashes {
+		if errors.Is(err, c) {
+			return exitCrash
+		}
+	}
+	return exitError
+}
+
+// stopped returns the summary of the run once it's stopped, having executed
+// cycles and presented frames, with err if the program stopped on one.
+func (a *app) stopped(cycles, frames uint64, err error) summary {
+	s := summary{
+		ROM:     a.cfg.rom,
+		Variant: a.vm.Variant().String(),
+		Cycles:  cycles,
+		Draws:   a.vm.Draws(),
+		Frames:  frames,
+	}
+	switch {
+	case err != nil:
+		s.Reason, s.Error, s.ExitCode = reasonError, err.Error(), runExit(err)
+	case a.vm.Halted():
+		s.Reason, s.ExitCode = reasonHalted, exitOK
+	default:
+		s.Reason, s.ExitCode = reasonQuit, exitQuit
+	}
+	return s
+}
+
+// writeSummary writes s to the -summary-json file, or stdout for -.
+func (a *app) writeSummary(s summary) {
+	if a.cfg.summary == "" {
+		return
+	}
+
+	b, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		logging.Warnf("Could not write the summary: %s", err)
+		return
+	}
+	b = append(b, '\n')
+	if a.cfg.summary == "-" {
+		_, err = os.Stdout.Write(b)
+	} else {
+		err = ioutil.WriteFile(a.cfg.summary, b, 0644)
+	}
+	if err != nil {
+		logging.Warnf("Could not write the summary: %s", err)
+	}
+}
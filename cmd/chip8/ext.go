@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/mmio"
+)
+
+// extensions are the names of the experimental extensions -ext enables.
+var extensions = []string{"serial"}
+
+// attachExtensions maps the extensions enabled with -ext into the memory of
+// the VM.
+func (a *app) attachExtensions() error {
+	if a.cfg.ext == "" {
+		return nil
+	}
+
+	for _, name := range strings.Split(a.cfg.ext, ",") {
+		switch name {
+		case "serial":
+			w, err := openSerial(a.cfg.serial)
+			if err != nil {
+				return fmt.Errorf("could not open the serial port: %s", err)
+			}
+			a.serialOut = w
+			a.serial = mmio.NewSerial(w)
+			a.serial.Attach(a.vm)
+			logging.Infof("Bytes written to 0x%03X-0x%03X are sent to %s", mmio.SerialStart, mmio.SerialEnd-1, a.cfg.serial)
+		default:
+			return fmt.Errorf("unknown extension %q, expected one of %q", name, extensions)
+		}
+	}
+	return nil
+}
+
+// openSerial returns where the serial port writes to: standard output for -,
+// or a TCP connection to addr.
+func openSerial(addr string) (io.WriteCloser, error) {
+	if addr == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return net.Dial("tcp", addr)
+}
+
+// closeSerial closes the serial port, if it's open, logging the error it
+// stopped on.
+func (a *app) closeSerial() {
+	if a.serial == nil {
+		return
+	}
+	if err := a.serial.Err(); err != nil {
+		logging.Warnf("The serial port stopped: %s", err)
+	}
+	a.serialOut.Close()
+}
+
+// nopCloser is a writer that isn't closed.
+type nopCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer.
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// flagsPath returns the path the user flags of rom are kept at, in the user's
+// config directory. Files are named by the hash of the ROM, so renamed copies
+// share their flags.
+func flagsPath(rom []byte) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum(rom)
+	return filepath.Join(dir, "chip8", "flags", hex.EncodeToString(sum[:])), nil
+}
+
+// restoreFlags sets the user flags of the VM to those kept for rom, and has
+// them kept whenever the program saves them.
+func (a *app) restoreFlags(rom []byte) {
+	path, err := flagsPath(rom)
+	if err != nil {
+		logging.Warnf("Could not find where to keep the flags: %s", err)
+		return
+	}
+	a.flagsFile = path
+
+	flags, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		logging.Warnf("Could not restore the flags: %s", err)
+		return
+	}
+	a.vm.SetFlags(flags)
+	logging.Debugf("Restored the flags from %s", path)
+}
+
+// saveFlags keeps the flags saved by the program, as a flags hook.
+func (a *app) saveFlags(flags []byte) {
+	if a.flagsFile == "" {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(a.flagsFile), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(a.flagsFile, flags, 0644)
+	}
+	if err != nil {
+		logging.Warnf("Could not keep the flags: %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/frontend"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+// windowFrontend is the name of the default frontend, the window, which
+// isn't registered with the frontend package as it runs on pixel's main
+// thread and does much more.
+const windowFrontend = "pixel"
+
+// frontends returns the names of the frontends -frontend takes.
+func frontends() []string {
+	return append([]string{windowFrontend}, frontend.Names()...)
+}
+
+// runFrontend runs the ROM behind the frontend picked with -frontend in place
+// of the window. The flags of the window, such as -scale and -debugger, don't
+// apply.
+func (a *app) runFrontend() int {
+	if a.cfg.ips <= 0 {
+		fmt.Println("-ips must be positive")
+		return exitUsage
+	}
+	fe, err := frontend.New(a.cfg.frontend)
+	if err != nil {
+		fmt.Println(err)
+		return exitUsage
+	}
+
+	if err = a.load(); err != nil {
+		fe.Close()
+		fmt.Println(err)
+		return loadExit(err)
+	}
+
+	au, err := a.newAudio(a.cfg.audio, a.cfg.buffer)
+	if err != nil {
+		logging.Warnf("Could not initialise %s audio: %s", a.cfg.audio, err)
+		au = sound.Null{}
+	}
+	defer au.Close()
+
+	err = frontend.Run(a.vm, signalFrontend{fe, a, notifySignals()}, au, a.cfg.ips)
+	if cerr := fe.Close(); err == nil {
+		err = cerr
+	}
+
+	a.recordScore()
+	a.writeCoverage()
+	a.writeTrace()
+	a.writeMovie()
+	a.closeSerial()
+
+	sum := a.stopped(a.vm.Cycles(), 0, err)
+	a.writeSummary(sum)
+	if err != nil {
+		fmt.Println(err)
+	}
+	return sum.ExitCode
+}
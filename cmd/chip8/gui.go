@@ -0,0 +1,8 @@
+//go:build gui
+// +build gui
+
+package main
+
+// guiMode is set when built as a GUI app, with the gui tag, which has no
+// console for the log or errors to be seen on.
+const guiMode = true
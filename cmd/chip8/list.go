@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/romdb"
+	"github.com/danmrichards/chip8/internal/romfmt"
+)
+
+// romExts are the file extensions of ROMs.
+var romExts = append([]string{".ch8", ".c8", ".sc8", ".xo8", ".8o"}, romfmt.Exts...)
+
+// isROM returns true if path has the extension of a ROM.
+func isROM(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range romExts {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// findROMs returns the paths of the ROMs under dir.
+func findROMs(dir string) ([]string, error) {
+	var roms []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && isROM(path) {
+			roms = append(roms, path)
+		}
+		return err
+	})
+	return roms, err
+}
+
+// runListROMs runs the list-roms subcommand, listing the ROMs under a
+// directory with the variant each is detected as, and returning the process
+// exit code.
+func runListROMs(args []string) int {
+	fs := flag.NewFlagSet("list-roms", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: chip8 list-roms [dir]\n\nLists the files with the extensions %q, searching dir, the working directory by default.\n", romExts)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+
+	roms, err := findROMs(dir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintln(tw, "ROM\tSIZE\tVARIANT\tTITLE")
+	known := knownROMs()
+
+	for _, path := range roms {
+		info, err := os.Stat(path)
+		if err != nil {
+			fmt.Println(err)
+			return 1
+		}
+
+		// Sources aren't assembled to keep listing quick, their variant is
+		// left blank.
+		variant, title := "", ""
+		if filepath.Ext(path) != ".8o" {
+			rom, err := readROM(path)
+			if err != nil {
+				// Files named like HEX or base64 ROMs may be neither.
+				fmt.Fprintln(os.Stderr, err)
+				continue
+			}
+			vr, _ := chip8.Detect(rom)
+			variant = vr.String()
+			if e, ok := known.Lookup(romdb.Identify(rom)); ok {
+				title = e.String()
+				if e.Variant != "" {
+					variant = e.Variant
+				}
+			}
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", path, info.Size(), variant, title)
+	}
+
+	return 0
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/danmrichards/chip8/internal/dialog"
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// logPath returns the path of the log file written by GUI builds, in the
+// user's config directory.
+func logPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "chip8.log"), nil
+}
+
+// logToFile appends the log to the file at path rather than writing it to the
+// console.
+func logToFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	log.SetOutput(f)
+
+	return nil
+}
+
+// fatal logs v at the error level, formatted as log.Print does, and exits. GUI builds also show it in an
+// error dialog, as there's no console to read the log on.
+func fatal(v ...interface{}) {
+	fatalCode(exitError, v...)
+}
+
+// fatalCode is fatal exiting with code.
+func fatalCode(code int, v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	logging.Errorf("%s", msg)
+
+	if guiMode {
+		if err := dialog.Error("chip8", msg); err != nil {
+			logging.Errorf("Could not show the error: %s", err)
+		}
+	}
+	os.Exit(code)
+}
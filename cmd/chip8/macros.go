@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/event"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/macro"
+)
+
+// loadMacros binds the macros of the keymap, -keymap or else the user's if
+// they keep one, to their keys in the window.
+func (a *app) loadMacros(eh *event.Handler) error {
+	path := a.cfg.keymap
+	if path == "" {
+		var err error
+		if path, err = macro.Path(); err != nil {
+			logging.Warnf("Could not find the keymap: %s", err)
+			return nil
+		}
+	}
+
+	km, err := macro.Load(path)
+	if err != nil {
+		return err
+	}
+	if km == nil {
+		if a.cfg.keymap != "" {
+			return fmt.Errorf("no keymap at %s", path)
+		}
+		return nil
+	}
+
+	p := macro.NewPlayer(km)
+	if err = eh.SetMacros(p); err != nil {
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	logging.Infof("Bound macros to %q", p.Names())
+	return nil
+}
@@ -1,98 +1,859 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/coverage"
+	"github.com/danmrichards/chip8/internal/debugger"
+	"github.com/danmrichards/chip8/internal/dialog"
 	"github.com/danmrichards/chip8/internal/event"
+	"github.com/danmrichards/chip8/internal/fonts"
+	"github.com/danmrichards/chip8/internal/heatmap"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/mmio"
+	"github.com/danmrichards/chip8/internal/movie"
+	"github.com/danmrichards/chip8/internal/octo"
+	"github.com/danmrichards/chip8/internal/romdb"
+	"github.com/danmrichards/chip8/internal/romfmt"
+	"github.com/danmrichards/chip8/internal/script"
+	"github.com/danmrichards/chip8/internal/slots"
+	"github.com/danmrichards/chip8/internal/sound"
+	"github.com/danmrichards/chip8/internal/symbol"
+	"github.com/danmrichards/chip8/internal/trace"
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/pixelgl"
 )
 
-var (
+// toggles are the values of flags overriding a quirk of the variant: auto
+// leaves it to the variant.
+var toggles = []string{"auto", "on", "off"}
+
+// config holds the command line flags.
+type config struct {
+	rom        string
+	variant    string
+	symbols    string
+	script     string
+	debug      bool
+	debugger   bool
+	vsync      bool
+	fps        int
+	frontend   string
+	audio      string
+	visualBeep string
+	scale      int
+	monitor    string
+	fullscreen bool
+	borderless bool
+	title      string
+	rotate     int
+	mirror     string
+	flicker    int
+	shader     string
+	buffer     time.Duration
+	pacing     string
+	ips        int
+	batch      int
+	latency    bool
+	logLevel   string
+	logFile    string
+	noPersist  bool
+	noStats    bool
+	background string
+	keyRelease bool
+	vipRandom  bool
+	vfReset    string
+	fineDelay  bool
+	ext        string
+	serial     string
+	font       string
+	entry      int
+	image      bool
+	coverage   string
+	heatmap    bool
+	keypad     bool
+	keymap     string
+	resume     bool
+	summary    string
+	attract    time.Duration
+	trace      string
+	record     string
+	watch      bool
+
+	corrupt     int
+	corruptSeed int64
+}
+
+// register registers the flags with fs. The debugger flag is left out of
+// commands that always open it.
+func (c *config) register(fs *flag.FlagSet, openDebugger bool) {
+	fs.StringVar(&c.rom, "rom", "", "Path to the ROM file to load, or an Octo source file to assemble and run. The ROM may also be given as an argument")
+	fs.StringVar(&c.variant, "variant", "auto", fmt.Sprintf("Instruction set variant, one of %q, or auto to detect it from the ROM", chip8.Variants))
+	fs.StringVar(&c.symbols, "symbols", "", "Path to a symbol file used to name addresses in debug output")
+	fs.StringVar(&c.font, "font", "octo", fmt.Sprintf("Font set programs draw digits with, one of %q", fonts.Styles))
+	fs.IntVar(&c.entry, "entry", 0, "Address to start execution at, such as 0x300, 0 for where the variant starts")
+	fs.BoolVar(&c.image, "image", false, "Load the ROM as a dump of the whole of memory from address 0, font included, as saved by other emulators")
+	fs.BoolVar(&c.keyRelease, "key-release", false, "Make FX0A wait for the key to be released, as the COSMAC VIP did, for games that take a held key twice")
+	fs.StringVar(&c.vfReset, "vf-reset", "auto", fmt.Sprintf("Whether 8XY1, 8XY2 and 8XY3 reset VF to 0 as the COSMAC VIP did, one of %q. auto leaves it to the variant, which only chip8 resets", toggles))
+	fs.BoolVar(&c.fineDelay, "fine-delay", false, "Count the delay timer down from when FX15 sets it rather than at frame boundaries, so delays last exactly as long as set, for music ROMs")
+	fs.BoolVar(&c.vipRandom, "vip-random", false, "Make CXNN generate the COSMAC VIP's sequence of random numbers, which needs the VIP interpreter in memory from -image")
+	fs.StringVar(&c.ext, "ext", "", fmt.Sprintf("Comma separated experimental extensions to enable, of %q. serial sends the bytes stored at 0xF00-0xF0F to -serial", extensions))
+	fs.StringVar(&c.serial, "serial", "-", "Where -ext serial sends bytes: - for standard output, or host:port to connect to over TCP")
+	fs.IntVar(&c.corrupt, "corrupt", 0, "Flip this many random bits of the ROM as it's loaded, for glitched games. Errors stop the game rather than the emulator")
+	fs.Int64Var(&c.corruptSeed, "corrupt-seed", 0, "Seed picking the bits -corrupt flips, 0 for a random seed, which is logged")
+	fs.BoolVar(&c.noPersist, "no-persist", false, "Don't keep the SUPER-CHIP user flags, where games save high scores, between runs")
+	fs.BoolVar(&c.noStats, "no-stats", false, "Don't keep the time each ROM is played for, shown in the ROM browser. The statistics never leave the machine")
+	fs.StringVar(&c.coverage, "coverage", "", "Path to write a report of the ROM bytes executed and read as data to on exit, - to print it coloured")
+	fs.StringVar(&c.trace, "trace", "", "Path to write a Chrome trace of the instructions, frames, draws and timers to on exit, for Perfetto")
+	fs.StringVar(&c.record, "record", "", "Path to write a movie of the keys pressed to on exit, for chip8 render to turn into a video")
+	fs.BoolVar(&c.watch, "watch", false, "Reload the ROM, or reassemble the source, whenever the file changes on disk")
+	fs.StringVar(&c.script, "script", "", "Path to a Lua script to run alongside the ROM, hooking into frames and instructions")
+	fs.BoolVar(&c.debug, "debug", false, "Log every instruction executed, implies -log-level debug")
+	fs.StringVar(&c.logLevel, "log-level", "info", fmt.Sprintf("Minimum level of the messages logged, one of %q", logging.Levels))
+	fs.StringVar(&c.logFile, "log-file", "", "Path to append the log to rather than writing it to the console")
+	fs.StringVar(&c.frontend, "frontend", windowFrontend, fmt.Sprintf("Display and keypad to run the ROM behind, one of %q. Frontends other than the window quit once the program halts", frontends()))
+	fs.StringVar(&c.audio, "audio", "beep", fmt.Sprintf("Audio backend, one of %q", sound.Backends()))
+	fs.DurationVar(&c.buffer, "audio-buffer", sound.DefaultBuffer, "Length of audio queued ahead of the device, shorter brings the tone closer to the display")
+	fs.StringVar(&c.visualBeep, "visual-beep", "none", fmt.Sprintf("Show the tone on screen, one of %q", event.VisualBeeps))
+	if !openDebugger {
+		fs.BoolVar(&c.debugger, "debugger", false, "Open a debugger window alongside the game")
+	}
+	fs.BoolVar(&c.vsync, "vsync", true, "Synchronise drawing with the monitor refresh rate")
+	fs.IntVar(&c.scale, "scale", 0, "Draw each pixel as an exact NxN block, 0 to stretch the display to fill the window")
+	fs.StringVar(&c.monitor, "monitor", "", "Monitor to open the window on, by name or index from 0, the primary by default. Without it the window opens where it last was")
+	fs.BoolVar(&c.fullscreen, "fullscreen", false, "Fill the monitor with a borderless window")
+	fs.BoolVar(&c.borderless, "borderless", false, "Open the window without a border or title bar, at its usual size")
+	fs.StringVar(&c.title, "title-format", defaultTitleFormat, "Window title, with %rom replaced by the ROM's title or file name, %variant by its variant, and %fps and %ips by the frames drawn and instructions executed a second")
+	fs.IntVar(&c.rotate, "rotate", 0, fmt.Sprintf("Degrees to rotate the display clockwise by, one of %v, for screens mounted on their side", event.Rotations))
+	fs.StringVar(&c.mirror, "mirror", "none", fmt.Sprintf("Flip the display, one of %q", event.Mirrors))
+	fs.IntVar(&c.flicker, "flicker", 0, "Frames pixels stay lit after they're turned off, to reduce flicker: 1 blends the last two frames, 0 turns it off")
+	fs.StringVar(&c.shader, "shader", "", "Path to a GLSL fragment shader to post-process the display with")
+	fs.IntVar(&c.fps, "fps", event.DefaultFrameRate, "Maximum frames drawn per second, 0 for no limit")
+	fs.StringVar(&c.pacing, "pacing", "sleep", fmt.Sprintf("How the emulator waits between batches of cycles, one of %q. busy is steadier but keeps a CPU core busy", pacings))
+	fs.IntVar(&c.ips, "ips", chip8.ClockSpeed, "Instructions executed per second, the timers count down in step")
+	fs.StringVar(&c.background, "background", "pause", fmt.Sprintf("What to do while the window is out of focus, one of %q", backgrounds))
+	fs.IntVar(&c.batch, "batch", chip8.ClockSpeed/chip8.FrameRate, "Instructions executed per batch, 1 to wait between every instruction")
+	fs.BoolVar(&c.latency, "latency", false, "Show the frame statistics, draws dropped and the frame and audio latency in the window")
+	fs.BoolVar(&c.heatmap, "heatmap", false, "Show how often each pixel is drawn and a bar of the memory executed over the game")
+	fs.BoolVar(&c.resume, "resume", false, "Carry on from the state saved when the emulator was last stopped with SIGTERM")
+	fs.StringVar(&c.summary, "summary-json", "", "Path to write a JSON summary of the run to on exit, with the cycles executed, the draws and frames, why it stopped and the exit code, - for stdout")
+	fs.StringVar(&c.keymap, "keymap", "", "Path to a keymap binding turbo buttons and sequences of key presses to keys, chip8/keymap.json in the config directory by default")
+	fs.DurationVar(&c.attract, "attract", time.Minute, "How long the ROM browser waits idle before playing a demo movie of one of the ROMs, 0 to never")
+	fs.BoolVar(&c.keypad, "keypad", false, "Show a keypad that can be clicked or touched to play with the mouse or on a touchscreen. F3 shows and hides it")
+}
+
+// setupLog sets the log level and output from the flags.
+func (c *config) setupLog() error {
+	level, err := logging.ParseLevel(c.logLevel)
+	if err != nil {
+		return err
+	}
+	if c.debug {
+		level = logging.Debug
+	}
+	logging.SetLevel(level)
+
+	if c.logFile != "" {
+		return logToFile(c.logFile)
+	}
+	return nil
+}
+
+// app is the windowed emulator.
+type app struct {
+	cfg config
+
+	// Dependencies, replaceable to run without the filesystem or an audio
+	// device.
+	readFile func(path string) ([]byte, error)
+	newAudio func(name string, buffer time.Duration) (sound.Audio, error)
+
 	vm *chip8.VM
 
-	rom   string
-	debug bool
-)
+	// Where the user flags of the ROM running are kept, empty if they
+	// aren't.
+	flagsFile string
+
+	// The coverage of the ROM running, if it's being recorded.
+	cov *coverage.Map
+
+	// The trace of the emulator, if it's being recorded.
+	tr *trace.Recorder
+
+	// The movie of the keys pressed, if it's being recorded.
+	mov *movie.Recorder
+
+	// The serial port of -ext serial and where it writes to, if it's open.
+	serial    *mmio.Serial
+	serialOut io.WriteCloser
+
+	// The size of the ROM corrupted, and whether it has stopped on an
+	// error, with -corrupt.
+	romSize int
+	crashed bool
+
+	// Polls the ROM for changes with -watch.
+	watcher romWatcher
 
-const cycleRate = 300
+	// The debugger window, if it's open.
+	dw *debugger.Window
+
+	// The monitor the window opens on.
+	monitor *pixelgl.Monitor
+
+	// The name of the ROM in the window title, its title if it's a known
+	// one, and the cycles executed, counted for the title's rates.
+	name     string
+	executed uint64
+
+	// The exit code of the run, set once the window closes.
+	exit int
+
+	// The time the ROM running has been played for, unpaused, this session.
+	played time.Duration
+
+	// Where the save states of the ROM running are kept, empty if they
+	// can't be, and the menu browsing them.
+	states slots.Store
+	menu   *event.SlotMenu
+
+	// The paddle profile of the ROM running, if it has one in the ROM
+	// index, and the key it's holding.
+	paddle     *romdb.Paddle
+	paddleKey  byte
+	paddleHeld bool
+}
+
+// newApp returns an app configured by cfg.
+func newApp(cfg config) *app {
+	return &app{
+		cfg:      cfg,
+		readFile: ioutil.ReadFile,
+		newAudio: sound.New,
+	}
+}
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	summary string
+
+	// run runs the command with its arguments, returning the process exit
+	// code.
+	run func(args []string) int
+}
+
+// commands returns the subcommands of the CLI.
+func commands() []command {
+	return []command{
+		{"run", "Run a ROM in a window", runRun},
+		{"debug", "Run a ROM with the debugger window open", runDebug},
+		{"dev", "Assemble and run an Octo source file, rebuilding it on save", runDev},
+		{"disasm", "Disassemble a ROM", runDisasm},
+		{"asm", "Assemble an Octo source file into a ROM", runAsm},
+		{"verify", "Run ROMs headlessly and check them against specs", runVerify},
+		{"compat", "Run ROMs headlessly and report which work", runCompat},
+		{"selftest", "Run Timendus' test suite headlessly and score the emulator", runSelfTest},
+		{"sprites", "Run a ROM headlessly and write a sheet of the sprites it draws", runSprites},
+		{"thumbs", "Render thumbnails of the ROMs in a directory for the ROM browser", runThumbs},
+		{"serve", "Serve GIFs of uploaded ROMs, with limits for running them publicly", runServe},
+		{"render", "Render a movie recorded with run -record to a video", runRender},
+		{"list-roms", "List the ROMs in a directory and their variants", runListROMs},
+		{"romtool", "Describe a ROM, or trim, pad or convert it", runROMTool},
+		{"completion", "Print the shell completion script for bash, zsh or fish", runCompletion},
+	}
+}
+
+// usage prints the commands.
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: chip8 <command> [flags] [args]")
+	fmt.Fprintln(os.Stderr, "       chip8 [flags] rom (shorthand for chip8 run)")
+	fmt.Fprintln(os.Stderr, "       chip8 (opens a ROM browser)")
+	fmt.Fprintln(os.Stderr, "\nCommands:")
+	for _, c := range commands() {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun chip8 <command> -h for the flags of a command.")
+}
 
 func main() {
 	log.SetFlags(log.LstdFlags)
+	if guiMode {
+		path, err := logPath()
+		if err == nil {
+			err = logToFile(path)
+		}
+		if err != nil {
+			fatal("Could not open the log file: ", err)
+		}
+	}
+
+	// Without arguments a window opens on the splash screen to pick a ROM.
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "help", "-h", "-help", "--help":
+			usage()
+			os.Exit(0)
+		}
+		for _, c := range commands() {
+			if c.name == args[0] {
+				os.Exit(c.run(args[1:]))
+			}
+		}
+	}
+
+	// Anything else is a ROM, or flags, to run.
+	os.Exit(runRun(args))
+}
+
+// runRun runs the run subcommand, opening a window running the ROM.
+func runRun(args []string) int {
+	return runWindow("run", args, false)
+}
+
+// runDebug runs the debug subcommand, which runs the ROM with the debugger
+// window open beside it.
+func runDebug(args []string) int {
+	return runWindow("debug", args, true)
+}
 
-	flag.StringVar(&rom, "rom", "", "Path to the ROM file to load")
-	flag.BoolVar(&debug, "debug", false, "Run the emulator in debug mode")
-	flag.Parse()
+// runWindow parses the flags of the run or debug subcommand called name and
+// runs the ROM in a window.
+func runWindow(name string, args []string, openDebugger bool) int {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: chip8 %s [flags] [rom]\n\nWithout a ROM the window opens on a browser of the ROMs in the working directory.\n", name)
+		fs.PrintDefaults()
+	}
+
+	var cfg config
+	cfg.register(fs, openDebugger)
+	fs.Parse(args)
+	cfg.debugger = cfg.debugger || openDebugger
 
-	// Validate the ROM flag.
-	if rom == "" {
-		fmt.Println("ROM flag is required")
-		os.Exit(1)
+	if err := cfg.setupLog(); err != nil {
+		fmt.Println(err)
+		return exitUsage
 	}
-	if _, err := os.Stat(rom); err != nil {
-		if os.IsNotExist(err) {
-			fmt.Printf("ROM %q does not exist", rom)
-			os.Exit(1)
-		} else {
-			log.Fatal(err)
+
+	// The ROM may be given as an argument or, as it once was, with -rom.
+	if fs.NArg() > 0 {
+		cfg.rom = fs.Arg(0)
+	}
+	if cfg.rom == "" && cfg.frontend != windowFrontend {
+		fmt.Printf("The %s frontend needs a ROM to run\n", cfg.frontend)
+		return exitUsage
+	}
+	if cfg.rom != "" {
+		if _, err := os.Stat(cfg.rom); os.IsNotExist(err) {
+			fmt.Printf("ROM %q does not exist, run chip8 help for the commands\n", cfg.rom)
+			return exitNoROM
+		} else if err != nil {
+			fmt.Println(err)
+			return exitError
 		}
 	}
+	if cfg.frontend != windowFrontend {
+		return newApp(cfg).runFrontend()
+	}
 
-	pixelgl.Run(run)
+	a := newApp(cfg)
+	pixelgl.Run(a.run)
+
+	return a.exit
 }
 
-func run() {
-	tick := time.NewTicker(time.Second / cycleRate)
-	defer tick.Stop()
+// load loads the ROM into the VM, creating the VM the first time. Octo source
+// files, with the .8o extension, are assembled first.
+func (a *app) load() error {
+	data, err := a.readFile(a.cfg.rom)
+	if err != nil {
+		return fmt.Errorf("could not open ROM: %s", err)
+	}
 
-	cfg := pixelgl.WindowConfig{
-		Title:  "chip8",
-		Bounds: pixel.R(0, 0, 1024, 768),
-		VSync:  true,
+	var syms *symbol.Table
+	if strings.HasSuffix(a.cfg.rom, ".8o") {
+		p, err := octo.Assemble(data)
+		if err != nil {
+			return fmt.Errorf("could not assemble %s: %s", a.cfg.rom, err)
+		}
+		data, syms = p.ROM, p.Symbols
+	} else {
+		var f romfmt.Format
+		if data, f, err = romfmt.Decode(a.cfg.rom, data); err != nil {
+			return err
+		}
+		if f != romfmt.Raw {
+			logging.Infof("Decoded the ROM from %s", f)
+		}
+	}
+
+	if a.cfg.entry < 0 || a.cfg.entry > 0xFFFF {
+		return fmt.Errorf("invalid entry point %d", a.cfg.entry)
+	}
+	opts := chip8.LoadOptions{Entry: uint16(a.cfg.entry), Image: a.cfg.image}
+
+	// Known ROMs are named in the title and may say what they need to run.
+	entry, known := knownROMs().Lookup(romdb.Identify(data))
+	a.name = filepath.Base(a.cfg.rom)
+	if known {
+		logging.Infof("Identified the ROM as %s", entry)
+		a.name = entry.Title
+	}
+
+	// Unless told otherwise, pick the variant from the instructions the ROM
+	// uses, which start after the interpreter in a memory image.
+	prog := data
+	if opts.Image && len(prog) > chip8.ProgramAddr {
+		prog = prog[chip8.ProgramAddr:]
+	}
+	vr, reason := chip8.Detect(prog)
+	if known && entry.Variant != "" {
+		if vr, err = chip8.ParseVariant(entry.Variant); err != nil {
+			return fmt.Errorf("ROM index entry for %s: %s", entry, err)
+		}
+		reason = "is listed in the ROM index as one"
+	}
+	if a.cfg.variant == "auto" {
+		logging.Infof("Running as %s, the ROM %s", vr, reason)
+	} else if vr, err = chip8.ParseVariant(a.cfg.variant); err != nil {
+		return err
+	}
+
+	if a.vm == nil {
+		a.vm = chip8.NewVariant(vr)
+		a.vm.Debug = a.cfg.debug
+		a.vm.OnFlags(a.saveFlags)
+		a.vm.EnableDebug(logging.Infof)
+		a.vm.SetFlicker(a.cfg.flicker)
+		a.vm.SetLoadOptions(opts)
+		if err = a.attachExtensions(); err != nil {
+			return err
+		}
+		err = a.vm.Load(bytes.NewReader(data))
+	} else {
+		// Swap the ROM into the running VM, which the window, debugger and
+		// script hold on to.
+		err = a.vm.Reload(vr, bytes.NewReader(data))
+	}
+	if err != nil {
+		return fmt.Errorf("could not load ROM: %s", err)
+	}
+	if a.vm.Variant() != vr {
+		logging.Infof("Detected a %s ROM", a.vm.Variant())
+	}
+	font, err := fonts.Parse(a.cfg.font)
+	if err != nil {
+		return err
+	}
+
+	// A memory image brings its own font.
+	if !opts.Image {
+		a.vm.SetFont(font)
+	}
+	a.releasePaddle()
+	a.paddle = nil
+	if known && entry.Paddle != nil {
+		a.paddle = entry.Paddle
+		logging.Infof("The mouse moves the paddle, up and down")
+	}
+	if known && entry.Quirks != nil && a.cfg.variant == "auto" {
+		a.vm.SetQuirks(*entry.Quirks)
+	}
+	if a.cfg.keyRelease || a.cfg.vipRandom || a.cfg.fineDelay {
+		q := a.vm.Quirks()
+		q.KeyRelease = q.KeyRelease || a.cfg.keyRelease
+		q.VIPRandom = q.VIPRandom || a.cfg.vipRandom
+		q.FineDelay = q.FineDelay || a.cfg.fineDelay
+		a.vm.SetQuirks(q)
+	}
+	switch a.cfg.vfReset {
+	case "auto":
+	case "on", "off":
+		q := a.vm.Quirks()
+		q.Logic = a.cfg.vfReset == "on"
+		a.vm.SetQuirks(q)
+	default:
+		return fmt.Errorf("unknown -vf-reset %q, expected one of %q", a.cfg.vfReset, toggles)
+	}
+	if a.vm.Quirks().VIPRandom && !opts.Image {
+		logging.Warnf("The VIP's random numbers are drawn from its interpreter at 0x100, load a memory image holding it with -image")
 	}
 
-	window, err := pixelgl.NewWindow(cfg)
+	if a.cfg.coverage != "" && a.cov == nil {
+		a.cov = coverage.New(a.vm, len(data))
+	} else if a.cov != nil {
+		a.cov.Reset(len(data))
+	}
+
+	if a.cfg.trace != "" && a.tr == nil {
+		a.tr = trace.New(a.vm)
+	}
+
+	if a.cfg.record != "" {
+		a.record(data, vr)
+	}
+
+	a.crashed = false
+	if a.cfg.corrupt > 0 {
+		a.corrupt(len(data))
+	}
+
+	if a.states, err = statesDir(data); err != nil {
+		logging.Warnf("Could not find where to keep save states: %s", err)
+	}
+	if a.cfg.resume && a.states != "" {
+		a.resume()
+	}
+	a.cfg.resume = false
+
+	a.flagsFile = ""
+	if !a.cfg.noPersist && a.vm.Flags() != nil {
+		a.restoreFlags(data)
+	}
+
+	a.vm.Symbols = syms
+	if a.cfg.symbols != "" {
+		if a.vm.Symbols, err = symbol.Load(a.cfg.symbols); err != nil {
+			return fmt.Errorf("could not load symbols: %s", err)
+		}
+	}
+	if a.dw != nil {
+		a.dw.SetSymbols(a.vm.Symbols)
+	}
+
+	return nil
+}
+
+// open shows a file dialog and swaps the ROM picked into the running VM. The
+// program carries on if the dialog is cancelled or the ROM can't be read.
+func (a *app) open() {
+	path, ok, err := dialog.OpenFile("Open ROM", romExts)
+	if err != nil {
+		logging.Warnf("Could not open a file dialog: %s", err)
+		return
+	}
+	if !ok {
+		return
+	}
+
+	a.recordScore()
+	a.recordSession()
+
+	// Symbols given on the command line were for the previous ROM.
+	prev := a.cfg
+	a.cfg.rom, a.cfg.symbols = path, ""
+	if err = a.load(); err != nil {
+		logging.Errorf("Could not open %s: %s", path, err)
+		a.cfg = prev
+	}
+}
+
+func (a *app) run() {
+	if a.cfg.ips <= 0 || a.cfg.batch <= 0 {
+		fatal("-ips and -batch must be positive")
+	}
+	wait, stop, err := newPacer(a.cfg.pacing, time.Duration(a.cfg.batch)*time.Second/time.Duration(a.cfg.ips))
+	if err != nil {
+		fatal(err)
+	}
+	defer stop()
+	batch := newBatcher(a.cfg.ips, a.cfg.batch)
+
+	// Throttled, a tenth of the cycles are run, a batch a frame.
+	slow := newBatcher(a.cfg.ips/throttle, a.cfg.batch)
+	switch a.cfg.background {
+	case "pause", "throttle", "run":
+	default:
+		fatal(fmt.Errorf("unknown background %q, expected one of %q", a.cfg.background, backgrounds))
+	}
+	if err = event.ValidRotation(a.cfg.rotate); err != nil {
+		fatal(err)
+	}
+	mirror, err := event.ParseMirror(a.cfg.mirror)
 	if err != nil {
-		log.Fatal("Could not create event:", err)
+		fatal(err)
+	}
+
+	if a.monitor, err = findMonitor(a.cfg.monitor); err != nil {
+		fatal(err)
+	}
+	saved, restore := loadWindowState()
+
+	cfg := pixelgl.WindowConfig{
+		Title:       "chip8",
+		Bounds:      pixel.R(0, 0, 1024, 768),
+		VSync:       a.cfg.vsync,
+		Resizable:   !a.cfg.fullscreen,
+		Undecorated: a.cfg.fullscreen || a.cfg.borderless,
+	}
+
+	// On its side the display is shown in a portrait window as tall as the
+	// usual one. The size the window was last run at wins over either.
+	if w, h := event.RotatedSize(4, 3, a.cfg.rotate); w < h {
+		cfg.Bounds = pixel.R(0, 0, 576, 768)
+	}
+	if restore {
+		cfg.Bounds = pixel.R(0, 0, saved.Width, saved.Height)
+	}
+
+	// Without a ROM the window opens on the splash screen to pick one.
+	var window *pixelgl.Window
+	if a.cfg.rom == "" {
+		if window, err = pixelgl.NewWindow(cfg); err != nil {
+			fatal("Could not create event:", err)
+		}
+		a.placeWindow(window, saved, restore)
+
+		roms, err := findROMs(".")
+		if err != nil {
+			logging.Warnf("Could not list ROMs: %s", err)
+		}
+		st := loadStats()
+		st.SortByLastPlayed(roms)
+		b := newBrowser()
+
+		// Left idle, the browser plays a demo and comes back to it.
+		idle := a.cfg.attract
+		var demos []demo
+		if idle > 0 {
+			if demos = findDemos(".", roms, b); len(demos) == 0 {
+				idle = 0
+			}
+		}
+		rom, pick := event.Splash(window, roms, browserLabel(st), browserPreview(b), idle)
+		for pick == event.Idle && attract(window, demos, b) {
+			rom, pick = event.Splash(window, roms, browserLabel(st), browserPreview(b), idle)
+		}
+		if err = b.Save(); err != nil {
+			logging.Warnf("Could not save the ROM browser's cache: %s", err)
+		}
+		if pick != event.Picked {
+			a.exit = exitQuit
+			return
+		}
+		a.cfg.rom = rom
+	}
+
+	if err = a.load(); err != nil {
+		fatalCode(loadExit(err), err)
+	}
+	vm := a.vm
+
+	if a.cfg.scale > 0 {
+		w, h := vm.Variant().DisplaySize()
+		w, h = event.RotatedSize(w, h, a.cfg.rotate)
+		s := fitScale(a.monitor, w, h, a.cfg.scale)
+		cfg.Bounds = pixel.R(0, 0, float64(w*s), float64(h*s))
 	}
 
-	vm = chip8.New()
-	vm.Debug = debug
+	if window == nil {
+		if window, err = pixelgl.NewWindow(cfg); err != nil {
+			fatal("Could not create event:", err)
+		}
+		a.placeWindow(window, saved, restore)
+	} else if a.cfg.scale > 0 {
+		window.SetBounds(cfg.Bounds)
+		a.placeWindow(window, saved, false)
+	}
+
+	// The debugger runs the cycles, so it can pause and step the game.
+	cycle := vm.Cycle
+	if a.cfg.debugger {
+		if a.dw, err = debugger.New(vm, vm.Symbols); err != nil {
+			fatal("Could not create debugger window:", err)
+		}
+		go a.dw.Run()
+		cycle = a.dw.Cycle
+	}
 
 	eh := event.NewHandler(window, vm)
+	eh.SetFrameRate(a.cfg.fps)
+	eh.SetIntegerScale(a.cfg.scale > 0)
+	eh.SetOrientation(a.cfg.rotate, mirror)
+
+	vb, err := event.ParseVisualBeep(a.cfg.visualBeep)
+	if err != nil {
+		fatal(err)
+	}
+	eh.SetVisualBeep(vb)
 
-	rom, err := os.Open(rom)
+	// Without a working audio device fall back to a visual beep rather than
+	// erroring on every tone.
+	au, err := a.newAudio(a.cfg.audio, a.cfg.buffer)
 	if err != nil {
-		log.Fatalln("Could not open ROM:", err)
+		logging.Warnf("Could not initialise %s audio, using a visual beep instead: %s", a.cfg.audio, err)
+		au = sound.Null{}
+		if vb == event.NoVisualBeep {
+			eh.SetVisualBeep(event.BorderBeep)
+		}
+	}
+	defer au.Close()
+	eh.SetAudio(au)
+	eh.SetHUD(a.cfg.latency)
+	eh.SetKeypad(a.cfg.keypad)
+	if err = a.loadMacros(&eh); err != nil {
+		fatal("Could not load the keymap:", err)
+	}
+	a.menu = &event.SlotMenu{}
+	eh.SetSlotMenu(a.menu)
+	if a.cfg.heatmap {
+		eh.SetHeatmap(heatmap.New(vm))
+	}
+
+	if a.cfg.shader != "" {
+		src, err := a.readFile(a.cfg.shader)
+		if err != nil {
+			fatal("Could not load shader:", err)
+		}
+		eh.SetShader(string(src))
 	}
 
-	if err := vm.Load(rom); err != nil {
-		log.Fatal("Could not load ROM:", err)
+	if a.cfg.script != "" {
+		e, err := script.Load(vm, a.cfg.script)
+		if err != nil {
+			fatal("Could not load script:", err)
+		}
+		eh.SetOverlay(e)
 	}
 
 	// Handle input, screen and sound events.
 	go eh.Handle()
 
 	// Emulation loop.
-	for !window.Closed() {
+	title, last := newTitleBar(a.cfg.title), time.Now()
+	var stopErr error
+	sigs := notifySignals()
+	for !window.Closed() && !a.handleSignals(sigs) {
 		window.UpdateInput()
 
 		if window.Pressed(pixelgl.KeyEscape) {
 			break
 		}
 
-		// Emulate a cycle.
-		if err = vm.Cycle(); err != nil {
-			log.Fatal(err)
+		// Ctrl+O opens another ROM in place of this one.
+		ctrl := window.Pressed(pixelgl.KeyLeftControl) || window.Pressed(pixelgl.KeyRightControl)
+		if ctrl && window.JustPressed(pixelgl.KeyO) {
+			a.open()
+			last = time.Now()
+		}
+		if a.cfg.watch {
+			a.watch()
+		}
+		title.update(window, time.Now(), a.name, a.vm.Variant().String(), eh.Frames(), a.executed)
+
+		// Out of focus the game pauses, or runs slowly, rather than playing
+		// itself and using the CPU in the background. It's paused while the
+		// save state menu is open too.
+		background := ""
+		if !window.Focused() {
+			background = a.cfg.background
+		}
+		menuOpen := a.slotMenu(window)
+		eh.Pause(background == "pause" || menuOpen)
+		a.movePaddle(window, &eh)
+
+		// Only time spent unpaused counts as played.
+		now := time.Now()
+		if background != "pause" && !menuOpen {
+			a.played += now.Sub(last)
 		}
+		last = now
+		b := batch
+		switch {
+		case background == "pause" || menuOpen:
+			time.Sleep(time.Second / chip8.FrameRate)
+			continue
+		case background == "throttle":
+			b = slow
+		}
+
+		// Once the program halts, or a corrupted ROM stops on an error, R
+		// restarts it. Until then there's nothing to emulate once the tone
+		// has finished, so just wait for input.
+		if vm.Halted() || a.crashed {
+			if window.JustPressed(pixelgl.KeyR) {
+				if err = vm.Reset(); err != nil {
+					fatal(err)
+				}
+				if a.mov != nil {
+					a.mov.Restart()
+				}
+				if a.cfg.corrupt > 0 {
+					a.crashed = false
+					a.corrupt(a.romSize)
+				}
+			} else if _, st := vm.Timers(); st == 0 || a.crashed {
+				time.Sleep(time.Second / chip8.FrameRate)
+				continue
+			}
+		}
+
+		// Emulate the cycles due since the last batch.
+		for n := b.due(time.Now()); n > 0; n-- {
+			a.executed++
+			if err = cycle(); err != nil && a.cfg.corrupt > 0 {
+				logging.Errorf("The corrupted ROM stopped, press R to restart it: %s", err)
+				a.crashed = true
+				break
+			} else if err != nil {
+				stopErr = err
+				break
+			}
+		}
+		if stopErr != nil {
+			break
+		}
+
+		// Block the next batch until it's due. This prevents the emulator
+		// from running too quickly. Throttled, batches run once a frame.
+		if b == slow {
+			time.Sleep(time.Second / chip8.FrameRate)
+			continue
+		}
+		wait()
+	}
+
+	a.recordScore()
+	a.recordSession()
+	a.writeCoverage()
+	a.writeTrace()
+	a.writeMovie()
+	a.closeSerial()
+	if !a.cfg.fullscreen {
+		saveWindowState(window)
+	}
+
+	// An error the program stopped on is shown once everything is saved.
+	sum := a.stopped(a.executed, eh.Frames(), stopErr)
+	a.writeSummary(sum)
+	if stopErr != nil {
+		fatalCode(sum.ExitCode, stopErr)
+	}
+	a.exit = sum.ExitCode
+}
+
+// fitScale returns the largest scale, up to s, at which a w by h display fits
+// on monitor m's current video mode.
+func fitScale(m *pixelgl.Monitor, w, h, s int) int {
+	if m == nil {
+		return s
+	}
 
-		// A bit dirty, but block the next cycle until a tick. This prevents
-		// the emulator from running too quickly.
-		<-tick.C
+	mw, mh := m.Size()
+	for s > 1 && (float64(w*s) > mw || float64(h*s) > mh) {
+		s--
 	}
+	return s
 }
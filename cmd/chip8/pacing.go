@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// pacings are the names of the ways the emulation loop waits between batches.
+var pacings = []string{"sleep", "busy"}
+
+// backgrounds are the names of what the emulator does while its window is out
+// of focus: pause, throttle to a tenth of the speed, or run as normal.
+var backgrounds = []string{"pause", "throttle", "run"}
+
+// throttle divides the speed of the emulator while throttled in the
+// background.
+const throttle = 10
+
+// newPacer returns a func blocking until the next batch is due, every period,
+// waiting the way called name: sleep waits on a ticker and busy spins, trading
+// a CPU core for steadier timing. stop releases the pacer.
+func newPacer(name string, period time.Duration) (wait, stop func(), err error) {
+	switch name {
+	case "sleep":
+		tick := time.NewTicker(period)
+		return func() { <-tick.C }, tick.Stop, nil
+	case "busy":
+		next := time.Now()
+		wait = func() {
+			// Don't try to catch up after a pause, e.g. while halted.
+			if now := time.Now(); now.Sub(next) > time.Second/chip8.FrameRate {
+				next = now
+			}
+			next = next.Add(period)
+			for time.Now().Before(next) {
+				runtime.Gosched()
+			}
+		}
+		return wait, func() {}, nil
+	}
+
+	return nil, nil, fmt.Errorf("unknown pacing %q, expected one of %q", name, pacings)
+}
+
+// batcher sizes batches of cycles from the time elapsed since the last, so
+// the average rate holds at ips however late the timer wakes the loop.
+type batcher struct {
+	ips float64
+
+	// Batches are capped at max cycles. Time beyond that, e.g. after the
+	// window was dragged, is dropped rather than run in a burst.
+	max int
+
+	last time.Time
+
+	// Fractions of a cycle carried over to the next batch.
+	owed float64
+}
+
+// newBatcher returns a batcher running ips cycles per second in batches of
+// around size cycles.
+func newBatcher(ips, size int) *batcher {
+	return &batcher{ips: float64(ips), max: 2 * size}
+}
+
+// due returns the number of cycles to run at now.
+func (b *batcher) due(now time.Time) int {
+	if b.last.IsZero() {
+		b.last = now
+		return 1
+	}
+	b.owed += now.Sub(b.last).Seconds() * b.ips
+	b.last = now
+
+	n := int(b.owed)
+	if n > b.max {
+		b.owed = 0
+		return b.max
+	}
+	b.owed -= float64(n)
+	return n
+}
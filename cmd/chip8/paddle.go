@@ -0,0 +1,39 @@
+package main
+
+import (
+	"github.com/danmrichards/chip8/internal/event"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// movePaddle holds the key moving the paddle toward the mouse, for ROMs with
+// a paddle profile in the ROM index.
+func (a *app) movePaddle(win *pixelgl.Window, eh *event.Handler) {
+	if a.paddle == nil {
+		return
+	}
+
+	var key byte
+	hold := false
+	pos := win.MousePosition()
+	if _, y, ok := eh.DisplayPoint(pos); ok && win.Bounds().Contains(pos) {
+		at := a.paddle.Middle(a.vm.V, a.vm.Display().Height())
+		key, hold = a.paddle.Key(y, at)
+	}
+	if hold == a.paddleHeld && key == a.paddleKey {
+		return
+	}
+
+	a.releasePaddle()
+	if hold {
+		a.vm.Press(key)
+		a.paddleKey, a.paddleHeld = key, true
+	}
+}
+
+// releasePaddle releases the key held by the paddle profile, if any.
+func (a *app) releasePaddle() {
+	if a.paddleHeld {
+		a.vm.Release(a.paddleKey)
+		a.paddleHeld = false
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/movie"
+)
+
+// record starts recording a movie of the ROM just loaded from data as vr,
+// replacing the movie of any ROM loaded before. The seed of the random
+// numbers is picked afresh and logged.
+func (a *app) record(data []byte, vr chip8.Variant) {
+	q := a.vm.Quirks()
+	m := &movie.Movie{
+		ROM:        a.cfg.rom,
+		Variant:    vr.String(),
+		Font:       a.cfg.font,
+		KeyRelease: q.KeyRelease,
+		VIPRandom:  q.VIPRandom,
+		Logic:      q.Logic,
+		FineDelay:  q.FineDelay,
+		Seed:       time.Now().UnixNano(),
+		Entry:      uint16(a.cfg.entry),
+		Image:      a.cfg.image,
+	}
+	logging.Infof("Recording a movie to %s with seed %d", a.cfg.record, m.Seed)
+
+	if a.mov == nil {
+		a.mov = movie.Record(a.vm, m, data)
+	} else {
+		a.mov.Reset(m, data)
+	}
+}
+
+// writeMovie writes the movie recorded to the -record file.
+func (a *app) writeMovie() {
+	if a.mov == nil {
+		return
+	}
+	if err := a.mov.Movie().Save(a.cfg.record); err != nil {
+		logging.Warnf("Could not write the movie: %s", err)
+	}
+}
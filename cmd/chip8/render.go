@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/fonts"
+	"github.com/danmrichards/chip8/internal/movie"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+// runRender runs the render subcommand, playing a movie back offscreen and
+// encoding it to a video with ffmpeg, and returning the process exit code.
+func runRender(args []string) int {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	out := fs.String("o", "", "Path to write the video to, its format picked by ffmpeg from the extension, the movie path with a .mp4 extension by default")
+	rom := fs.String("rom", "", "Path to the ROM the movie was recorded with, if it has moved since")
+	scale := fs.Int("scale", 8, "Size in pixels of each pixel of the display in the video")
+	ffmpeg := fs.String("ffmpeg", "ffmpeg", "Path to the ffmpeg executable")
+	flicker := fs.Int("flicker", 0, "Frames pixels stay lit after they're turned off, to reduce flicker: 1 blends the last two frames, 0 turns it off")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 render [flags] movie.c8m")
+		fmt.Fprintln(fs.Output(), "\nPlays a movie recorded with chip8 run -record back offscreen, faster than real time, and encodes the display and tone to a video.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *scale < 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+
+	m, err := movie.Load(path)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if *rom == "" {
+		*rom = m.ROM
+	}
+	data, err := readROM(*rom)
+	if err == nil {
+		err = m.Check(data)
+	}
+	if err != nil {
+		fmt.Printf("Could not load the ROM of the movie: %s\n", err)
+		return 1
+	}
+
+	if *out == "" {
+		*out = strings.TrimSuffix(path, filepath.Ext(path)) + ".mp4"
+	}
+	if err = render(m, data, *out, *scale, *flicker, *ffmpeg); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	fmt.Printf("Rendered %d frames to %s\n", m.Frames, *out)
+
+	return 0
+}
+
+// render plays m back twice, once writing the tone to a temporary file, then
+// piping the display, with flicker reduction, to ffmpeg, which encodes both to
+// out.
+func render(m *movie.Movie, rom []byte, out string, scale, flicker int, ffmpeg string) error {
+	audio, err := ioutil.TempFile("", "chip8-render-*.pcm")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(audio.Name())
+
+	vm, err := play(m, rom)
+	if err == nil {
+		err = renderAudio(vm, m.Frames, audio)
+	}
+	if cerr := audio.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("could not render the audio: %s", err)
+	}
+
+	// Chip8 ROMs may have switched to the HiRes variant as they loaded.
+	if vm, err = play(m, rom); err != nil {
+		return err
+	}
+	vm.SetFlicker(flicker)
+	w, h := vm.Variant().DisplaySize()
+	w, h = w*scale, h*scale
+
+	cmd := exec.Command(ffmpeg,
+		"-loglevel", "error", "-y",
+		"-f", "rawvideo", "-pix_fmt", "rgb24", "-s", fmt.Sprintf("%dx%d", w, h), "-r", strconv.Itoa(chip8.FrameRate), "-i", "-",
+		"-f", "s16le", "-ar", strconv.Itoa(sound.SampleRate), "-ac", "1", "-i", audio.Name(),
+		"-pix_fmt", "yuv420p", "-shortest", out,
+	)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return fmt.Errorf("could not run ffmpeg: %s", err)
+	}
+
+	err = renderVideo(vm, m.Frames, stdin, w, h)
+	if cerr := stdin.Close(); err == nil {
+		err = cerr
+	}
+	if werr := cmd.Wait(); err == nil && werr != nil {
+		err = fmt.Errorf("ffmpeg failed: %s", werr)
+	}
+	return err
+}
+
+// play returns a VM set up as m was recorded, with rom loaded and the movie
+// queued to play.
+func play(m *movie.Movie, rom []byte) (*chip8.VM, error) {
+	vr, err := chip8.ParseVariant(m.Variant)
+	if err != nil {
+		return nil, err
+	}
+	font, err := fonts.Parse(m.Font)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := chip8.NewVariant(vr)
+	vm.SetLoadOptions(chip8.LoadOptions{Entry: m.Entry, Image: m.Image})
+	if err = vm.Load(bytes.NewReader(rom)); err != nil {
+		return nil, err
+	}
+	if !m.Image {
+		vm.SetFont(font)
+	}
+	q := vm.Quirks()
+	q.KeyRelease = m.KeyRelease
+	q.VIPRandom = m.VIPRandom
+	q.Logic = m.Logic
+	q.FineDelay = m.FineDelay
+	vm.SetQuirks(q)
+	movie.Play(vm, m)
+	return vm, nil
+}
+
+// renderAudio runs vm for the given number of frames, writing each frame of
+// the tone to w.
+func renderAudio(vm *chip8.VM, frames int, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	pcm := &sound.PCM{}
+	for f := 0; f < frames; f++ {
+		err := vm.StepFrame()
+		if err != nil {
+			return fmt.Errorf("frame %d: %s", f+1, err)
+		}
+
+		if _, st := vm.Timers(); st == 0 {
+			err = pcm.StopTone()
+		} else if pattern, ok := vm.Pattern(); ok {
+			err = pcm.PlayPattern(pattern)
+		} else {
+			err = pcm.StartTone()
+		}
+		if err == nil {
+			err = pcm.Write(bw, sound.SampleRate/chip8.FrameRate)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// renderVideo runs vm for the given number of frames, writing each frame of
+// the display to w as width by height rgb24 pixels.
+func renderVideo(vm *chip8.VM, frames int, w io.Writer, width, height int) error {
+	bw := bufio.NewWriter(w)
+	buf := make([]byte, 3*width*height)
+	for f := 0; f < frames; f++ {
+		if err := vm.StepFrame(); err != nil {
+			return fmt.Errorf("frame %d: %s", f+1, err)
+		}
+		rgb(buf, vm.Image(), width, height)
+		if _, err := bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// rgb scales img to fill width by height rgb24 pixels in buf, nearest
+// neighbour, so low resolution modes fill the frame too.
+func rgb(buf []byte, img *image.Paletted, width, height int) {
+	var pal [256][3]byte
+	for i, c := range img.Palette {
+		r, g, b, _ := c.RGBA()
+		pal[i] = [3]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8)}
+	}
+
+	iw, ih := img.Rect.Dx(), img.Rect.Dy()
+	i := 0
+	for y := 0; y < height; y++ {
+		row := img.Pix[y*ih/height*img.Stride:]
+		for x := 0; x < width; x++ {
+			c := pal[row[x*iw/width]]
+			buf[i], buf[i+1], buf[i+2] = c[0], c[1], c[2]
+			i += 3
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/disasm"
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// reportPath returns the path of the report of rom written at t, in the
+// user's config directory.
+func reportPath(rom string, t time.Time) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s.txt", filepath.Base(rom), t.Format("20060102-150405"))
+	return filepath.Join(dir, "chip8", "reports", name), nil
+}
+
+// writeReport writes a report of the state of the VM, for the report signal.
+func (a *app) writeReport() {
+	path, err := reportPath(a.cfg.rom, time.Now())
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	var f *os.File
+	if err == nil {
+		f, err = os.Create(path)
+	}
+	if err == nil {
+		a.report(f)
+		err = f.Close()
+	}
+	if err != nil {
+		logging.Warnf("Could not write the report: %s", err)
+		return
+	}
+	logging.Infof("Wrote a report of the state to %s", path)
+}
+
+// report writes the ROM, the registers, the code around the program counter,
+// the display and memory to w.
+func (a *app) report(w io.Writer) {
+	vm := a.vm
+	st := vm.State()
+
+	fmt.Fprintf(w, "ROM:     %s\n", a.cfg.rom)
+	fmt.Fprintf(w, "Variant: %s\n", vm.Variant())
+	fmt.Fprintf(w, "Cycles:  %d\n", vm.Cycles())
+	fmt.Fprintf(w, "Halted:  %t\n\n", vm.Halted())
+
+	fmt.Fprintf(w, "PC=0x%03X I=0x%03X SP=%d DT=%d ST=%d\n", st.PC, st.I, st.SP, st.DelayTimer, st.SoundTimer)
+	regs := make([]string, len(st.V))
+	for x, r := range st.V {
+		regs[x] = fmt.Sprintf("V%X=%02X", x, r)
+	}
+	fmt.Fprintln(w, strings.Join(regs, " "))
+	fmt.Fprint(w, "Stack:")
+	for _, addr := range st.Stack[1 : st.SP+1] {
+		fmt.Fprintf(w, " 0x%03X", addr)
+	}
+	fmt.Fprint(w, "\nKeys:")
+	for k, down := range st.Keys {
+		if down == 1 {
+			fmt.Fprintf(w, " %X", k)
+		}
+	}
+
+	fmt.Fprint(w, "\n\nCode:\n")
+	for addr := int(st.PC) - 8; addr <= int(st.PC)+8; addr += 2 {
+		if addr < 0 {
+			continue
+		}
+		opc := uint16(vm.Peek(uint16(addr)))<<8 | uint16(vm.Peek(uint16(addr+1)))
+		mark := " "
+		if addr == int(st.PC) {
+			mark = ">"
+		}
+		fmt.Fprintf(w, "%s 0x%03X  %04X  %s\n", mark, addr, opc, disasm.Instruction(opc, vm.Symbols))
+	}
+
+	fmt.Fprint(w, "\nDisplay:\n")
+	img := vm.Image()
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		var line strings.Builder
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.ColorIndexAt(x, y) != 0 {
+				line.WriteByte('#')
+			} else {
+				line.WriteByte('.')
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+
+	fmt.Fprint(w, "\nMemory:\n")
+	for addr := 0; addr < len(st.Mem); addr += 16 {
+		fmt.Fprintf(w, "0x%03X ", addr)
+		for _, v := range st.Mem[addr : addr+16] {
+			fmt.Fprintf(w, " %02X", v)
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/romdb"
+)
+
+// knownROMs returns the index of known ROMs: the one built in with the
+// user's metadata packs added, then their own index.
+func knownROMs() romdb.Index {
+	ix := romdb.Builtin()
+	dir, err := romdb.PacksDir()
+	if err == nil {
+		var packs romdb.Index
+		if packs, err = romdb.LoadPacks(dir); err == nil {
+			ix.Add(packs)
+		}
+	}
+	if err != nil {
+		logging.Warnf("Could not load the metadata packs: %s", err)
+	}
+
+	path, err := romdb.Path()
+	if err == nil {
+		var user romdb.Index
+		if user, err = romdb.Load(path); err == nil {
+			ix.Add(user)
+		}
+	}
+	if err != nil {
+		logging.Warnf("Could not load the ROM index: %s", err)
+	}
+	return ix
+}
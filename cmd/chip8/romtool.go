@@ -0,0 +1,104 @@
+package main
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/romdb"
+	"github.com/danmrichards/chip8/internal/romfmt"
+)
+
+// runROMTool runs the romtool subcommand, describing a ROM or writing it
+// trimmed, padded or in another format, and returning the process exit code.
+func runROMTool(args []string) int {
+	fs := flag.NewFlagSet("romtool", flag.ExitOnError)
+	out := fs.String("o", "", "Path to write the ROM to, as Intel HEX or base64 if it has the .hex or .b64 extension. Without it the ROM is described")
+	trim := fs.Bool("trim", false, "Strip the zeros the ROM ends with")
+	pad := fs.Int("pad", 0, "Pad the ROM with zeros to at least this many bytes")
+	align := fs.Int("align", 0, "Pad the ROM with zeros to a multiple of this many bytes")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 romtool [flags] rom")
+		fmt.Fprintln(fs.Output(), "\nDescribes the ROM: its size, the zeros padding it, its hashes and variant. With -o the ROM is written, trimmed and padded as asked.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *pad < 0 || *align < 0 {
+		fs.Usage()
+		return 2
+	}
+	if *out == "" && (*trim || *pad > 0 || *align > 0) {
+		fmt.Println("-trim, -pad and -align need -o to write the ROM to")
+		return 2
+	}
+	path := fs.Arg(0)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	rom, format, err := romfmt.Decode(path, data)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	if *out == "" {
+		if err = describeROM(rom, format); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		return 0
+	}
+
+	if *trim {
+		rom = romfmt.Trim(rom)
+	}
+	rom = romfmt.Pad(rom, *pad, *align)
+	if max := 0x1000 - chip8.ProgramAddr; len(rom) > max {
+		fmt.Printf("Warning: the ROM is %d bytes, more than the %d that fit in 4K of memory\n", len(rom), max)
+	}
+	if err = ioutil.WriteFile(*out, romfmt.Encode(*out, rom), 0644); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+// describeROM prints the size, padding, hashes and variant of rom, which was
+// stored in format.
+func describeROM(rom []byte, format romfmt.Format) error {
+	vr, reason := chip8.Detect(rom)
+	e, known := knownROMs().Lookup(romdb.Identify(rom))
+	if known && e.Variant != "" {
+		var err error
+		if vr, err = chip8.ParseVariant(e.Variant); err != nil {
+			return fmt.Errorf("ROM index entry for %s: %s", e, err)
+		}
+		reason = "is listed in the ROM index as one"
+	}
+	padding := romfmt.Padding(rom)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "Format\t%s\n", format)
+	fmt.Fprintf(tw, "Size\t%d bytes, filling 0x%03X up to 0x%03X\n", len(rom), chip8.ProgramAddr, chip8.ProgramAddr+len(rom))
+	fmt.Fprintf(tw, "Padding\t%d trailing zeros, %d bytes once trimmed\n", padding, len(rom)-padding)
+	fmt.Fprintf(tw, "SHA1\t%x\n", sha1.Sum(rom))
+	fmt.Fprintf(tw, "MD5\t%x\n", md5.Sum(rom))
+	fmt.Fprintf(tw, "CRC32\t%08x\n", crc32.ChecksumIEEE(rom))
+	fmt.Fprintf(tw, "Variant\t%s, the ROM %s\n", vr, reason)
+	if known {
+		fmt.Fprintf(tw, "Title\t%s\n", e)
+	}
+
+	return nil
+}
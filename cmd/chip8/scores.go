@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/scores"
+)
+
+// recordScore records the score of the game running if it's a high score, for
+// ROMs with a scores sidecar.
+func (a *app) recordScore() {
+	path := scores.Path(a.cfg.rom)
+	s, err := scores.Load(path)
+	if err != nil {
+		logging.Warnf("Could not load the high scores: %s", err)
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	score := s.Read(a.vm)
+	if !s.Record(score, time.Now()) {
+		return
+	}
+	if err = s.Save(path); err != nil {
+		logging.Warnf("Could not save the high scores: %s", err)
+		return
+	}
+	logging.Infof("Recorded a high score of %d", score)
+}
+
+// bestScore returns the best score recorded for the ROM at rom, formatted for
+// the ROM browser, or an empty string if there isn't one.
+func bestScore(rom string) string {
+	s, err := scores.Load(scores.Path(rom))
+	if err != nil || s == nil {
+		return ""
+	}
+	if best, ok := s.Best(); ok {
+		return fmt.Sprintf("best %d", best.Score)
+	}
+	return ""
+}
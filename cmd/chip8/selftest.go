@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/selftest"
+)
+
+// runSelfTest runs the selftest subcommand, running Timendus' test suite from
+// a directory and printing a scorecard, and returning the process exit code.
+func runSelfTest(args []string) int {
+	fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+	show := fs.Bool("show", false, "Print the final display of the runs that failed or have nothing expected")
+	update := fs.Bool("update", false, "Record the final displays as those expected, once checked with -show")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 selftest [flags] dir")
+		fmt.Fprintln(fs.Output(), "\nRuns the ROMs of Timendus' CHIP-8 test suite in dir headlessly, on each variant they test, and scores each against the display expected in dir/"+selftest.ExpectedFile+".")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	dir := fs.Arg(0)
+
+	exp, err := selftest.Load(dir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	code, passed, missing := 0, 0, 0
+	for _, r := range selftest.Run(dir, exp) {
+		fmt.Printf("%-9s %s\n", r.Status, r.Test.Name)
+		switch r.Status {
+		case selftest.Pass:
+			passed++
+		case selftest.Fail:
+			code = 1
+		case selftest.Error:
+			fmt.Printf("\t%s\n", r.Err)
+			code = 1
+		case selftest.Missing:
+			missing++
+		}
+		if *show && (r.Status == selftest.Fail || r.Status == selftest.Unchecked) {
+			fmt.Print(r.Screen)
+		}
+		if *update && r.Status != selftest.Missing && r.Status != selftest.Error {
+			exp[r.Test.Name] = r.Display
+		}
+	}
+	fmt.Printf("\n%d of %d runs passed, %d missing their ROM\n", passed, len(selftest.Suite), missing)
+
+	if *update {
+		if err = exp.Save(dir); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+		fmt.Printf("Recorded the displays in %s\n", selftest.ExpectedFile)
+		return 0
+	}
+	return code
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/showcase"
+)
+
+// runServe runs the serve subcommand, serving GIFs of uploaded ROMs under
+// strict limits, and returning the process exit code.
+func runServe(args []string) int {
+	cfg := showcase.DefaultConfig()
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("listen", "localhost:8089", "Address to serve on")
+	fs.Int64Var(&cfg.MaxROM, "max-rom", cfg.MaxROM, "Largest ROM accepted, in bytes")
+	fs.IntVar(&cfg.Frames, "frames", cfg.Frames, "Frames run when a request doesn't say")
+	fs.IntVar(&cfg.MaxFrames, "max-frames", cfg.MaxFrames, "Most frames a request may ask for")
+	fs.IntVar(&cfg.Scale, "scale", cfg.Scale, "Size in pixels of each display pixel when a request doesn't say")
+	fs.IntVar(&cfg.MaxScale, "max-scale", cfg.MaxScale, "Largest scale a request may ask for")
+	fs.IntVar(&cfg.MaxPixels, "max-pixels", cfg.MaxPixels, "Most pixels a GIF may have, summed over its frames")
+	fs.Uint64Var(&cfg.Limits.MaxCycles, "max-cycles", cfg.Limits.MaxCycles, "Most instructions a ROM may run, 0 for no limit")
+	fs.DurationVar(&cfg.Limits.Budget, "budget", cfg.Limits.Budget, "Longest a frame may take to run, 0 for no limit")
+	fs.DurationVar(&cfg.Timeout, "timeout", cfg.Timeout, "Longest a run may take, 0 for no limit")
+	fs.IntVar(&cfg.Runs, "runs", cfg.Runs, "Most ROMs run at once, those beyond are refused")
+	fs.BoolVar(&cfg.MegaChip, "megachip", false, "Allow MegaChip ROMs, which take 16MB of memory each")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 serve [flags]")
+		fmt.Fprintln(fs.Output(), "\nServes POST /gif, running the ROM in the request body headlessly and returning an animated GIF of it, with limits making it safe to expose publicly.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 || cfg.Frames < 1 || cfg.Frames > cfg.MaxFrames || cfg.Scale < 1 || cfg.Scale > cfg.MaxScale {
+		fs.Usage()
+		return 2
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           showcase.NewServer(cfg),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		IdleTimeout:       time.Minute,
+		MaxHeaderBytes:    8 << 10,
+	}
+	if cfg.Timeout > 0 {
+		// Leave time to encode and send the GIF once the run is over.
+		srv.WriteTimeout = srv.ReadTimeout + 2*cfg.Timeout
+	}
+
+	fmt.Printf("Serving on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+
+	"github.com/danmrichards/chip8/internal/frontend"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/slots"
+)
+
+// notifySignals returns a channel of the report and stop signals the process
+// receives, which never receives on platforms without them.
+func notifySignals() chan os.Signal {
+	c := make(chan os.Signal, 2)
+	if reportSignal != nil {
+		signal.Notify(c, reportSignal, stopSignal)
+	}
+	return c
+}
+
+// handleSignals acts on the signals received on c since it was last called,
+// between cycles: writing a report of the state for the report signal, and
+// saving the state to the automatic slot for the stop signal. It returns true
+// once the run should stop.
+func (a *app) handleSignals(c chan os.Signal) bool {
+	for {
+		select {
+		case s := <-c:
+			if s == reportSignal {
+				a.writeReport()
+				continue
+			}
+			logging.Infof("Stopping on %s", s)
+			a.autoSave()
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+// autoSave saves the state of the VM to the automatic slot, for -resume.
+func (a *app) autoSave() {
+	if a.states == "" {
+		return
+	}
+	if err := a.states.Save(slots.Auto, a.vm); err != nil {
+		logging.Errorf("Could not save the state: %s", err)
+		return
+	}
+	logging.Infof("Saved the state, run with -resume to carry on")
+}
+
+// resume restores the state saved to the automatic slot, if there is one.
+func (a *app) resume() {
+	if err := a.states.Load(slots.Auto, a.vm); err != nil {
+		logging.Warnf("Could not resume: %s", err)
+		return
+	}
+	logging.Infof("Resumed from the saved state")
+	if a.mov != nil {
+		logging.Infof("Stopped recording the movie, it can't play back a save state")
+		a.mov.Stop()
+	}
+}
+
+// signalFrontend is a frontend that quits once a signal stops the run, so
+// frontend.Run checks for signals between frames.
+type signalFrontend struct {
+	frontend.Frontend
+	a       *app
+	signals chan os.Signal
+}
+
+// Poll implements frontend.Frontend.
+func (f signalFrontend) Poll() ([16]bool, bool) {
+	keys, quit := f.Frontend.Poll()
+	return keys, quit || f.a.handleSignals(f.signals)
+}
@@ -0,0 +1,9 @@
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package main
+
+import "os"
+
+// There are no signals to report on or stop the run with outside Unix.
+var reportSignal, stopSignal os.Signal
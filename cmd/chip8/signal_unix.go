@@ -0,0 +1,16 @@
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// reportSignal asks for a report of the state of the run, and stopSignal
+// for it to save its state and exit.
+var (
+	reportSignal os.Signal = syscall.SIGUSR1
+	stopSignal   os.Signal = syscall.SIGTERM
+)
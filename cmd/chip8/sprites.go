@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/sprites"
+)
+
+// runSprites runs the sprites subcommand, running a ROM headlessly and
+// writing a sheet of the sprites it drew, and returning the process exit
+// code.
+func runSprites(args []string) int {
+	fs := flag.NewFlagSet("sprites", flag.ExitOnError)
+	seconds := fs.Int("seconds", 10, "Seconds of emulated time to run the ROM for")
+	out := fs.String("o", "", "Path to write the PNG sprite sheet to, the ROM path with a .png extension by default")
+	scale := fs.Int("scale", 4, "Size in pixels of each sprite pixel on the sheet")
+	cols := fs.Int("cols", 16, "Sprites per row of the sheet")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 sprites [flags] rom")
+		fmt.Fprintln(fs.Output(), "\nRuns the ROM without input and lists the sprites it draws, writing them to a sheet in the same order.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 || *scale < 1 || *cols < 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+
+	rom, err := readROM(path)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	vr, _ := chip8.Detect(rom)
+	vm := chip8.NewVariant(vr)
+	if err = vm.Load(bytes.NewReader(rom)); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	rec := sprites.Record(vm)
+
+	// The sprites drawn before an error are still worth seeing.
+	for c := 0; c < *seconds*chip8.ClockSpeed && !vm.Halted(); c++ {
+		if err = vm.Cycle(); err != nil {
+			fmt.Printf("Stopped after %d cycles: %s\n", c, err)
+			break
+		}
+	}
+
+	found := rec.Sprites()
+	if len(found) == 0 {
+		fmt.Println("No sprites were drawn")
+		return 1
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDR\tSIZE\tDRAWS")
+	for _, s := range found {
+		fmt.Fprintf(tw, "0x%03X\t%dx%d\t%d\n", s.Addr, s.Width, s.Height, s.Draws)
+	}
+	tw.Flush()
+
+	if *out == "" {
+		*out = strings.TrimSuffix(path, filepath.Ext(path)) + ".png"
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	defer f.Close()
+
+	green := color.RGBA{0x24, 0xCC, 0x42, 0xFF}
+	if err = png.Encode(f, sprites.Sheet(found, *cols, *scale, green, color.Black)); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	return 0
+}
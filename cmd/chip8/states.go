@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/slots"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// statesDir returns the directory the save state slots of rom are kept in,
+// found by its hash like the user flags.
+func statesDir(rom []byte) (slots.Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum(rom)
+	return slots.Store(filepath.Join(dir, "chip8", "states", hex.EncodeToString(sum[:]))), nil
+}
+
+// slotMenu handles the keys of the save state menu, F2 opening and closing
+// it. It returns true while the menu is open, when the game is paused.
+func (a *app) slotMenu(win *pixelgl.Window) bool {
+	m := a.menu
+	if win.JustPressed(pixelgl.KeyF2) {
+		switch {
+		case m.Open():
+			m.Hide()
+		case a.states == "":
+			logging.Warnf("Save states aren't available for this ROM")
+		default:
+			m.Show(a.states.List())
+		}
+	}
+	if !m.Open() {
+		return false
+	}
+
+	n := m.Selected()
+	switch {
+	case win.JustPressed(pixelgl.KeyLeft):
+		m.Move(-1)
+	case win.JustPressed(pixelgl.KeyRight):
+		m.Move(1)
+	case win.JustPressed(pixelgl.KeyUp):
+		m.MoveRow(-1)
+	case win.JustPressed(pixelgl.KeyDown):
+		m.MoveRow(1)
+	case win.JustPressed(pixelgl.KeySpace):
+		if err := a.states.Save(n, a.vm); err != nil {
+			logging.Errorf("Could not save slot %d: %s", n, err)
+			break
+		}
+		logging.Infof("Saved slot %d", n)
+		m.Show(a.states.List())
+	case win.JustPressed(pixelgl.KeyEnter):
+		if err := a.states.Load(n, a.vm); err != nil {
+			logging.Errorf("Could not load slot %d: %s", n, err)
+			break
+		}
+		logging.Infof("Loaded slot %d", n)
+		if a.mov != nil {
+			logging.Infof("Stopped recording the movie, it can't play back a save state")
+			a.mov.Stop()
+		}
+		m.Hide()
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/stats"
+)
+
+// loadStats returns the play statistics kept, empty if they can't be read.
+func loadStats() *stats.Stats {
+	path, err := stats.Path()
+	var st *stats.Stats
+	if err == nil {
+		st, err = stats.Load(path)
+	}
+	if err != nil {
+		logging.Warnf("Could not load the play statistics: %s", err)
+		return &stats.Stats{}
+	}
+	return st
+}
+
+// recordSession records the time the ROM running has been played for in the
+// play statistics, unless -no-stats is set or they're disabled.
+func (a *app) recordSession() {
+	played := a.played
+	a.played = 0
+	if a.cfg.noStats || a.cfg.rom == "" {
+		return
+	}
+
+	path, err := stats.Path()
+	var st *stats.Stats
+	if err == nil {
+		st, err = stats.Load(path)
+	}
+	if err == nil && !st.Disabled {
+		st.Record(a.cfg.rom, played, time.Now())
+		err = st.Save(path)
+	}
+	if err != nil {
+		logging.Warnf("Could not keep the play statistics: %s", err)
+	}
+}
+
+// browserLabel returns the label shown beside each ROM in the ROM browser:
+// its best score and how long and when it was last played.
+func browserLabel(st *stats.Stats) func(rom string) string {
+	most := st.MostPlayed()
+	return func(rom string) string {
+		var parts []string
+		if best := bestScore(rom); best != "" {
+			parts = append(parts, best)
+		}
+		if r := st.ROM(rom); r != nil {
+			parts = append(parts, "played "+playTime(r.Played), "last "+r.LastPlayed.Format("2006-01-02"))
+			if r == most {
+				parts = append(parts, "most played")
+			}
+		}
+		return strings.Join(parts, ", ")
+	}
+}
+
+// playTime formats secs seconds of play in hours and minutes.
+func playTime(secs int64) string {
+	h, m := secs/3600, secs/60%60
+	switch {
+	case h > 0:
+		return fmt.Sprintf("%dh%02dm", h, m)
+	case m > 0:
+		return fmt.Sprintf("%dm", m)
+	}
+	return fmt.Sprintf("%ds", secs)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/thumbs"
+)
+
+// runThumbs runs the thumbs subcommand, rendering thumbnails of the ROMs
+// under a directory for the ROM browser in parallel, and returning the
+// process exit code.
+func runThumbs(args []string) int {
+	fs := flag.NewFlagSet("thumbs", flag.ExitOnError)
+	seconds := fs.Int("seconds", 5, "Seconds of emulated time to run each ROM for before taking its thumbnail")
+	workers := fs.Int("workers", runtime.NumCPU(), "ROMs rendered at once")
+	out := fs.String("o", "", "Directory to write the thumbnails to, the one the ROM browser reads by default")
+	force := fs.Bool("force", false, "Render ROMs that already have a thumbnail again")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 thumbs [flags] [dir]")
+		fmt.Fprintln(fs.Output(), "\nRuns each ROM under dir, the working directory by default, without input and saves its display as a thumbnail for the ROM browser.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 || *seconds < 1 || *workers < 1 {
+		fs.Usage()
+		return 2
+	}
+	dir := "."
+	if fs.NArg() == 1 {
+		dir = fs.Arg(0)
+	}
+
+	store := thumbs.Store(*out)
+	if *out == "" {
+		var err error
+		if store, err = thumbs.DefaultStore(); err != nil {
+			fmt.Println(err)
+			return 1
+		}
+	}
+
+	paths, err := findROMs(dir)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	code, skipped := 0, 0
+	var jobs []thumbs.Job
+	for _, p := range paths {
+		rom, err := readROM(p)
+		if err != nil {
+			fmt.Printf("%s: %s\n", p, err)
+			code = 1
+			continue
+		}
+		if !*force && store.Has(rom) {
+			skipped++
+			continue
+		}
+		jobs = append(jobs, thumbs.Job{Name: p, ROM: rom})
+	}
+
+	// Results are saved and printed as they're rendered, one at a time.
+	var mu sync.Mutex
+	saved := 0
+	thumbs.RenderAll(jobs, *seconds*chip8.FrameRate, *workers, func(r thumbs.Result) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// A ROM stopping with an error still has a display worth showing.
+		if r.Err != nil {
+			fmt.Printf("%s: %s\n", r.Job.Name, r.Err)
+			if r.Image == nil {
+				code = 1
+				return
+			}
+		}
+		if err := store.Save(r.Job.ROM, r.Image); err != nil {
+			fmt.Printf("%s: %s\n", r.Job.Name, err)
+			code = 1
+			return
+		}
+		saved++
+	})
+
+	fmt.Printf("Rendered %d thumbnails to %s, %d ROMs already had one\n", saved, store, skipped)
+	return code
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// defaultTitleFormat is the window title unless -title-format is given.
+const defaultTitleFormat = "chip8 - %rom"
+
+// titleBar keeps the window title up to date with -title-format, whose
+// placeholders are replaced with the name of the ROM, its variant and the
+// frames drawn and instructions executed a second.
+type titleBar struct {
+	format string
+
+	// live is set if the format shows the rates, which are measured over
+	// a second from when at was, and frames and cycles the counts then.
+	live           bool
+	at             time.Time
+	frames, cycles uint64
+	fps, ips       float64
+
+	shown string
+}
+
+// newTitleBar returns the title bar for format.
+func newTitleBar(format string) *titleBar {
+	return &titleBar{
+		format: format,
+		live:   strings.Contains(format, "%fps") || strings.Contains(format, "%ips"),
+		at:     time.Now(),
+	}
+}
+
+// update sets the title of win, if it's changed, from the ROM's name and
+// variant, and the counts of frames drawn and cycles executed so far.
+func (t *titleBar) update(win *pixelgl.Window, now time.Time, name, variant string, frames, cycles uint64) {
+	if d := now.Sub(t.at); t.live && d >= time.Second {
+		t.fps = float64(frames-t.frames) / d.Seconds()
+		t.ips = float64(cycles-t.cycles) / d.Seconds()
+		t.at, t.frames, t.cycles = now, frames, cycles
+	}
+
+	title := strings.NewReplacer(
+		"%rom", name,
+		"%variant", variant,
+		"%fps", strconv.FormatFloat(t.fps, 'f', 0, 64),
+		"%ips", strconv.FormatFloat(t.ips, 'f', 0, 64),
+	).Replace(t.format)
+	if title != t.shown {
+		win.SetTitle(title)
+		t.shown = title
+	}
+}
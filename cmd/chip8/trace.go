@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// writeTrace writes the trace recorded to the -trace file.
+func (a *app) writeTrace() {
+	if a.tr == nil {
+		return
+	}
+
+	f, err := os.Create(a.cfg.trace)
+	if err == nil {
+		err = a.tr.Write(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		logging.Warnf("Could not write the trace: %s", err)
+	}
+}
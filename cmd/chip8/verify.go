@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/verify"
+)
+
+// runVerify runs the verify subcommand, returning the process exit code. Each
+// argument is a spec file, see the verify package for the format.
+func runVerify(args []string) int {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	romPath := fs.String("rom", "", "Path to the ROM file to load, overrides the ROM in each spec")
+	cov := fs.Bool("coverage", false, "Print how much of the ROM each spec executed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: chip8 verify [flags] spec.yaml...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	code := 0
+	for _, path := range fs.Args() {
+		spec, err := verify.LoadSpec(path)
+		if err != nil {
+			fmt.Printf("ERROR %s\n\t%s\n", path, err)
+			code = 1
+			continue
+		}
+
+		rom := spec.ROMPath()
+		if *romPath != "" {
+			rom = *romPath
+		}
+		if rom == "" {
+			fmt.Printf("ERROR %s\n\tno ROM given\n", path)
+			code = 1
+			continue
+		}
+
+		res, err := spec.Run(rom)
+		if err != nil {
+			fmt.Printf("ERROR %s\n\t%s\n", path, err)
+			code = 1
+			continue
+		}
+
+		switch {
+		case res.Passed() && res.Cycles < spec.Cycles:
+			fmt.Printf("ok    %s (halted after %d cycles)\n", path, res.Cycles)
+		case res.Passed():
+			fmt.Printf("ok    %s\n", path)
+		default:
+			code = 1
+			fmt.Printf("FAIL  %s\n", path)
+			for _, f := range res.Failures {
+				fmt.Printf("\t%s\n", f)
+			}
+		}
+
+		for _, l := range res.Output {
+			fmt.Printf("\t%s\n", l)
+		}
+		if *cov {
+			fmt.Printf("\tcoverage: %s\n", res.Coverage.Summary())
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// watchInterval is how often -watch checks the ROM for changes.
+const watchInterval = 250 * time.Millisecond
+
+// romWatcher polls the ROM for changes with -watch. The standard library has
+// no portable file notifications, and a stat of one file a few times a second
+// costs nothing.
+type romWatcher struct {
+	path    string
+	modTime time.Time
+	size    int64
+	next    time.Time
+}
+
+// changed returns true if the file at path has been written since the last
+// call, checking at most every watchInterval. The first call for a path only
+// notes its state, so opening another ROM isn't taken as a change.
+func (w *romWatcher) changed(path string, now time.Time) bool {
+	if now.Before(w.next) && path == w.path {
+		return false
+	}
+	w.next = now.Add(watchInterval)
+
+	// A file missing while an editor replaces it is checked again later.
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+
+	changed := path == w.path && (!info.ModTime().Equal(w.modTime) || info.Size() != w.size)
+	w.path, w.modTime, w.size = path, info.ModTime(), info.Size()
+	return changed
+}
+
+// watch reloads the ROM, reassembling a source file, if it has changed on
+// disk. The settings from the flags are applied again, and the program
+// carries on if the new ROM can't be loaded.
+func (a *app) watch() {
+	if !a.watcher.changed(a.cfg.rom, time.Now()) {
+		return
+	}
+
+	logging.Infof("Reloading %s", a.cfg.rom)
+	if err := a.load(); err != nil {
+		logging.Errorf("Could not reload %s: %s", a.cfg.rom, err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// windowState is where the window was and its size when the emulator last
+// exited, to open it there again.
+type windowState struct {
+	// Monitor is the name of the monitor the window was on, and X and Y the
+	// position of its top left corner on the desktop.
+	Monitor string  `json:"monitor"`
+	X       float64 `json:"x"`
+	Y       float64 `json:"y"`
+
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// windowStatePath returns the path the window state is kept at, in the
+// user's config directory.
+func windowStatePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "window.json"), nil
+}
+
+// loadWindowState returns the window state kept, false if there isn't one.
+func loadWindowState() (windowState, bool) {
+	var ws windowState
+	path, err := windowStatePath()
+	if err != nil {
+		return ws, false
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ws, false
+	}
+	if err = json.Unmarshal(b, &ws); err != nil {
+		logging.Warnf("Could not read the window position: %s", err)
+		return ws, false
+	}
+	return ws, ws.Width > 0 && ws.Height > 0
+}
+
+// saveWindowState keeps the position and size of win for the next run.
+func saveWindowState(win *pixelgl.Window) {
+	pos, b := win.GetPos(), win.Bounds()
+	ws := windowState{X: pos.X, Y: pos.Y, Width: b.W(), Height: b.H()}
+	if m := monitorAt(pos); m != nil {
+		ws.Monitor = m.Name()
+	}
+
+	path, err := windowStatePath()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(ws)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		logging.Warnf("Could not keep the window position: %s", err)
+	}
+}
+
+// findMonitor returns the monitor called name, or at that index of those
+// connected, counting from 0. An empty name is the primary monitor.
+func findMonitor(name string) (*pixelgl.Monitor, error) {
+	if name == "" {
+		return pixelgl.PrimaryMonitor(), nil
+	}
+
+	monitors := pixelgl.Monitors()
+	if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < len(monitors) {
+		return monitors[i], nil
+	}
+
+	names := make([]string, len(monitors))
+	for i, m := range monitors {
+		if m.Name() == name {
+			return m, nil
+		}
+		names[i] = m.Name()
+	}
+	return nil, fmt.Errorf("unknown monitor %q, expected an index or one of %q", name, names)
+}
+
+// monitorAt returns the monitor showing the point pos of the desktop, nil if
+// none does.
+func monitorAt(pos pixel.Vec) *pixelgl.Monitor {
+	for _, m := range pixelgl.Monitors() {
+		x, y := m.Position()
+		w, h := m.Size()
+		if pixel.R(x, y, x+w, y+h).Contains(pos) {
+			return m
+		}
+	}
+	return nil
+}
+
+// placeWindow moves win to the monitor picked with -monitor, filling it
+// with -fullscreen and centring it otherwise. Without -monitor the window
+// goes back where it was last run, if that monitor is still connected.
+func (a *app) placeWindow(win *pixelgl.Window, saved windowState, restore bool) {
+	if a.cfg.monitor == "" && restore && !a.cfg.fullscreen {
+		if m := monitorAt(pixel.V(saved.X, saved.Y)); m != nil && m.Name() == saved.Monitor {
+			win.SetPos(pixel.V(saved.X, saved.Y))
+			return
+		}
+	}
+
+	m := a.monitor
+	if m == nil {
+		return
+	}
+	x, y := m.Position()
+	w, h := m.Size()
+	if a.cfg.fullscreen {
+		win.SetBounds(pixel.R(0, 0, w, h))
+		win.SetPos(pixel.V(x, y))
+		return
+	}
+	b := win.Bounds()
+	win.SetPos(pixel.V(x+(w-b.W())/2, y+(h-b.H())/2))
+}
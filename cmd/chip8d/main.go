@@ -0,0 +1,29 @@
+// Command chip8d serves the remote control API, running the emulator
+// headless for bots and test farms.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/remote"
+)
+
+func main() {
+	var (
+		addr   string
+		limits chip8.Limits
+	)
+	flag.StringVar(&addr, "listen", "localhost:8088", "Address to serve the API on")
+	flag.Uint64Var(&limits.MaxCycles, "max-cycles", 0, "Most instructions a ROM may run before steps fail, 0 for no limit")
+	flag.DurationVar(&limits.Budget, "budget", 0, "Longest a frame may take to run before the step fails, 0 for no limit")
+	flag.Parse()
+
+	s := remote.NewServer()
+	s.SetLimits(limits)
+
+	log.Printf("Serving on %s\n", addr)
+	log.Fatal(http.ListenAndServe(addr, s))
+}
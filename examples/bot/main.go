@@ -0,0 +1,146 @@
+// Command bot plays PONG by reading the display and moving the left paddle
+// towards the ball, showing how to embed the VM and drive it from code.
+//
+// PONG isn't included, run it with the ROM from any of the public CHIP-8 game
+// packs:
+//
+//	go run ./examples/bot -rom PONG -frames 3600 -out pong.png
+package main
+
+import (
+	"flag"
+	"image/png"
+	"log"
+	"os"
+
+	"github.com/danmrichards/chip8/internal/bot"
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+const (
+	// PONG moves the left paddle up with 1 and down with 4.
+	keyUp   = 0x1
+	keyDown = 0x4
+
+	// The left paddle is drawn within the columns left of paddleCols, the
+	// right paddle within those right of the width less paddleCols.
+	paddleCols = 8
+)
+
+// pong is a bot that keeps the left paddle level with the ball.
+type pong struct {
+	// The display at the end of the last frame, used to tell the ball, which
+	// moves every frame, from the score and paddles, which mostly don't.
+	last []bool
+
+	// Where the ball was last seen.
+	ballY int
+}
+
+// Keys implements bot.Bot.
+func (p *pong) Keys(d *chip8.Display) [16]bool {
+	w, h := d.Width(), d.Height()
+
+	frame := make([]bool, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			frame[y*w+x] = d.Pixel(x, y)
+		}
+	}
+	if p.last != nil {
+		p.findBall(frame, w, h)
+	}
+	p.last = frame
+
+	// Aim the middle of the paddle at the ball, with some slack so the
+	// paddle doesn't jitter.
+	var keys [16]bool
+	top, bottom, ok := paddle(d)
+	if !ok {
+		return keys
+	}
+	switch mid := (top + bottom) / 2; {
+	case p.ballY < mid-1:
+		keys[keyUp] = true
+	case p.ballY > mid+1:
+		keys[keyDown] = true
+	}
+
+	return keys
+}
+
+// findBall updates ballY with the newly lit pixel between the paddles, if
+// there is one.
+func (p *pong) findBall(frame []bool, w, h int) {
+	for y := 0; y < h; y++ {
+		for x := paddleCols; x < w-paddleCols; x++ {
+			i := y*w + x
+			if frame[i] && !p.last[i] {
+				p.ballY = y
+				return
+			}
+		}
+	}
+}
+
+// paddle returns the rows spanned by the left paddle.
+func paddle(d *chip8.Display) (top, bottom int, ok bool) {
+	for y := 0; y < d.Height(); y++ {
+		for x := 0; x < paddleCols; x++ {
+			if !d.Pixel(x, y) {
+				continue
+			}
+			if !ok {
+				top, ok = y, true
+			}
+			bottom = y
+		}
+	}
+	return top, bottom, ok
+}
+
+func main() {
+	var (
+		rom    string
+		frames int
+		out    string
+	)
+	flag.StringVar(&rom, "rom", "", "Path to the PONG ROM")
+	flag.IntVar(&frames, "frames", 60*60, "Number of frames to play")
+	flag.StringVar(&out, "out", "", "Path to write a PNG of the last frame to")
+	flag.Parse()
+
+	if rom == "" {
+		log.Fatal("ROM flag is required")
+	}
+
+	f, err := os.Open(rom)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	vm := chip8.New()
+	if err = vm.Load(f); err != nil {
+		log.Fatal(err)
+	}
+
+	if err = bot.Run(vm, &pong{}, frames); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Played %d frames\n", frames)
+
+	if out == "" {
+		return
+	}
+
+	o, err := os.Create(out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer o.Close()
+
+	if err = png.Encode(o, vm.Image()); err != nil {
+		log.Fatal(err)
+	}
+}
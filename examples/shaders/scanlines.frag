@@ -0,0 +1,24 @@
+#version 330 core
+
+// Darkens every other line of the window, like the scanlines of a CRT, and
+// slowly pulses the brightness.
+
+in vec2 vTexCoords;
+
+out vec4 fragColor;
+
+uniform vec4 uTexBounds;
+uniform sampler2D uTexture;
+uniform float uTime;
+
+void main() {
+	vec2 t = (vTexCoords - uTexBounds.xy) / uTexBounds.zw;
+	vec4 c = texture(uTexture, t);
+
+	if (mod(gl_FragCoord.y, 2.0) < 1.0) {
+		c.rgb *= 0.6;
+	}
+	c.rgb *= 0.95 + 0.05 * sin(uTime * 2.0);
+
+	fragColor = c;
+}
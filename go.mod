@@ -8,8 +8,9 @@ require (
 	github.com/go-gl/gl v0.0.0-20181026044259-55b76b7df9d2 // indirect
 	github.com/go-gl/glfw v0.0.0-20181014061658-691ee1b84c51 // indirect
 	github.com/go-gl/mathgl v0.0.0-20180804195959-cdf14b6b8f8a // indirect
-	github.com/gobuffalo/packr v1.19.0
-	github.com/hajimehoshi/oto v0.2.1 // indirect
+	github.com/hajimehoshi/oto v0.2.1
+	github.com/yuin/gopher-lua v1.1.1
 	golang.org/x/image v0.0.0-20181109232246-249dc8530c0e
 	golang.org/x/tools v0.0.0-20181204185109-3832e276fb48 // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
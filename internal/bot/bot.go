@@ -0,0 +1,39 @@
+// Package bot runs programs that play ROMs, reading the display and choosing
+// which keys to hold each frame. The VM is stepped synchronously, so bots run
+// as fast as the host allows and games play out the same way every time for
+// a given bot.
+package bot
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Bot decides which keys to hold.
+type Bot interface {
+	// Keys returns the keys to hold for the next frame, given the display
+	// at the end of the last.
+	Keys(d *chip8.Display) [16]bool
+}
+
+// Func is a function implementing Bot.
+type Func func(d *chip8.Display) [16]bool
+
+// Keys calls f.
+func (f Func) Keys(d *chip8.Display) [16]bool {
+	return f(d)
+}
+
+// Run plays vm with b for the given number of frames.
+func Run(vm *chip8.VM, b Bot, frames int) error {
+	for f := 0; f < frames; f++ {
+		vm.SetKeys(b.Keys(vm.Display()))
+
+		if err := vm.StepFrame(); err != nil {
+			return fmt.Errorf("frame %d: %s", f, err)
+		}
+	}
+
+	return nil
+}
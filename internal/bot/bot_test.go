@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestRun(t *testing.T) {
+	// Draw a pixel at (0, 0) each time key 5 is released.
+	rom := []byte{
+		0xA2, 0x0A, // I = sprite.
+		0xF0, 0x0A, // Wait for a key.
+		0xD1, 0x11, // Draw at (0, 0).
+		0x12, 0x02, // Loop.
+		0x00, 0x00,
+		0x80, // Sprite.
+	}
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	frames := 0
+	b := Func(func(d *chip8.Display) [16]bool {
+		frames++
+
+		var keys [16]bool
+		keys[5] = !d.Pixel(0, 0)
+		return keys
+	})
+
+	if err := Run(vm, b, 10); err != nil {
+		t.Fatal(err)
+	}
+	if frames != 10 {
+		t.Fatalf("expected the bot to be asked 10 times, got %d", frames)
+	}
+	if !vm.Display().Pixel(0, 0) {
+		t.Fatal("expected the bot to have pressed key 5")
+	}
+}
@@ -0,0 +1,187 @@
+// Package browse finds what the ROM browser shows of each ROM: its entry in
+// the ROM index, which metadata packs add a description to, and a picture,
+// the cover art from a pack or else the thumbnail rendered by chip8 thumbs.
+//
+// ROMs are looked up lazily, as the browser shows them, and kept once looked
+// up. Hashing, the slow part with a directory of many ROMs, is cached in the
+// chip8 config directory by the path, size and modification time of each ROM,
+// so only new or changed ROMs are read again.
+package browse
+
+import (
+	"encoding/json"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/romdb"
+	"github.com/danmrichards/chip8/internal/thumbs"
+)
+
+// Info is what's shown of a ROM.
+type Info struct {
+	// Entry is the ROM's entry in the index, if Known.
+	Entry romdb.Entry
+	Known bool
+
+	// Picture is the cover art or thumbnail of the ROM, nil if it has
+	// neither.
+	Picture image.Image
+}
+
+// cached is the hash of a ROM as it was when hashed.
+type cached struct {
+	Size    int64    `json:"size"`
+	ModTime int64    `json:"modTime"`
+	ID      romdb.ID `json:"id"`
+}
+
+// Browser looks up ROMs for the ROM browser. It's safe for concurrent use.
+type Browser struct {
+	ix     romdb.Index
+	thumbs thumbs.Store
+	read   func(path string) ([]byte, error)
+
+	// cache is the path of the cache of hashes, empty if they aren't kept.
+	cache string
+
+	mu     sync.Mutex
+	hashes map[string]cached
+	dirty  bool
+	infos  map[string]Info
+}
+
+// CachePath returns the path of the cache of hashes in the user's config
+// directory.
+func CachePath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "browse.json"), nil
+}
+
+// New returns a browser looking ROMs up in ix and their thumbnails in store,
+// reading them with read, which decodes them as they're run. Hashes are
+// cached in the file at cache, if set. A cache that can't be read is started
+// again.
+func New(ix romdb.Index, store thumbs.Store, read func(path string) ([]byte, error), cache string) *Browser {
+	b := &Browser{
+		ix:     ix,
+		thumbs: store,
+		read:   read,
+		cache:  cache,
+		hashes: map[string]cached{},
+		infos:  map[string]Info{},
+	}
+	if cache != "" {
+		if data, err := ioutil.ReadFile(cache); err == nil {
+			json.Unmarshal(data, &b.hashes)
+		}
+	}
+	return b
+}
+
+// Info returns what's shown of the ROM at path, looking it up the first time.
+// ROMs that can't be read have nothing shown.
+func (b *Browser) Info(path string) Info {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if info, ok := b.infos[path]; ok {
+		return info
+	}
+
+	var info Info
+	if id, ok := b.identify(path); ok {
+		info.Entry, info.Known = b.ix.Lookup(id)
+
+		// A cover that can't be read falls back to the thumbnail.
+		if info.Entry.Cover != "" {
+			info.Picture, _ = loadPNG(info.Entry.Cover)
+		}
+		if info.Picture == nil && b.thumbs != "" {
+			info.Picture, _ = loadPNG(b.thumbs.PathOf(id.SHA1))
+		}
+	}
+	b.infos[path] = info
+	return info
+}
+
+// ID returns the ID of the ROM at path, false if it can't be read.
+func (b *Browser) ID(path string) (romdb.ID, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.identify(path)
+}
+
+// identify returns the ID of the ROM at path, from the cache if it hasn't
+// changed since it was hashed.
+func (b *Browser) identify(path string) (romdb.ID, bool) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return romdb.ID{}, false
+	}
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = path
+	}
+
+	if c, ok := b.hashes[key]; ok && c.Size == fi.Size() && c.ModTime == fi.ModTime().UnixNano() {
+		return c.ID, true
+	}
+
+	rom, err := b.read(path)
+	if err != nil {
+		return romdb.ID{}, false
+	}
+	id := romdb.Identify(rom)
+	b.hashes[key] = cached{Size: fi.Size(), ModTime: fi.ModTime().UnixNano(), ID: id}
+	b.dirty = true
+	return id, true
+}
+
+// Save writes the cache of hashes, if any were added.
+func (b *Browser) Save() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cache == "" || !b.dirty {
+		return nil
+	}
+
+	// ROMs that have gone are dropped, so the cache doesn't grow forever.
+	for k := range b.hashes {
+		if _, err := os.Stat(k); err != nil {
+			delete(b.hashes, k)
+		}
+	}
+
+	data, err := json.Marshal(b.hashes)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(b.cache), 0755); err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(b.cache, data, 0644); err != nil {
+		return err
+	}
+	b.dirty = false
+	return nil
+}
+
+// loadPNG reads the PNG image at path.
+func loadPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
@@ -0,0 +1,96 @@
+package browse
+
+import (
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/romdb"
+	"github.com/danmrichards/chip8/internal/thumbs"
+)
+
+// writePNG writes a w by h image to path.
+func writePNG(t *testing.T, path string, w, h int) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err = png.Encode(f, image.NewGray(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "browse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	roms := map[string][]byte{"a.ch8": {0x12, 0x00}, "b.ch8": {0x12, 0x02}, "c.ch8": {0x12, 0x04}}
+	for name, rom := range roms {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), rom, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// a has a cover, b a thumbnail and c neither.
+	cover := filepath.Join(dir, "a.png")
+	writePNG(t, cover, 8, 8)
+	store := thumbs.Store(filepath.Join(dir, "thumbs"))
+	if err = os.Mkdir(string(store), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writePNG(t, store.Path(roms["b.ch8"]), 64, 32)
+
+	ix := romdb.Index{
+		romdb.Identify(roms["a.ch8"]).SHA1: {Title: "A", Description: "The first.", Cover: cover},
+	}
+	reads := 0
+	read := func(path string) ([]byte, error) {
+		reads++
+		return ioutil.ReadFile(path)
+	}
+	cache := filepath.Join(dir, "cache", "browse.json")
+
+	b := New(ix, store, read, cache)
+	a := b.Info(filepath.Join(dir, "a.ch8"))
+	if !a.Known || a.Entry.Description != "The first." || a.Picture == nil || a.Picture.Bounds().Dx() != 8 {
+		t.Fatalf("expected a's entry and cover, got %+v", a)
+	}
+	if info := b.Info(filepath.Join(dir, "b.ch8")); info.Known || info.Picture == nil || info.Picture.Bounds().Dx() != 64 {
+		t.Fatalf("expected b's thumbnail, got %+v", info)
+	}
+	if info := b.Info(filepath.Join(dir, "c.ch8")); info.Known || info.Picture != nil {
+		t.Fatalf("expected nothing for c, got %+v", info)
+	}
+	if info := b.Info(filepath.Join(dir, "none.ch8")); info.Known || info.Picture != nil {
+		t.Fatalf("expected nothing for a missing ROM, got %+v", info)
+	}
+
+	b.Info(filepath.Join(dir, "a.ch8"))
+	if reads != 3 {
+		t.Fatalf("expected each ROM to be read once, got %d reads", reads)
+	}
+	if err = b.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new browser hashes from the cache rather than reading the ROMs.
+	reads = 0
+	b = New(ix, store, read, cache)
+	if info := b.Info(filepath.Join(dir, "a.ch8")); !info.Known {
+		t.Fatalf("expected a to be known from the cache, got %+v", info)
+	}
+	if id, ok := b.ID(filepath.Join(dir, "b.ch8")); !ok || id != romdb.Identify(roms["b.ch8"]) {
+		t.Fatalf("expected b's ID from the cache, got %v", id)
+	}
+	if reads != 0 {
+		t.Fatalf("expected the cached hashes to be used, got %d reads", reads)
+	}
+}
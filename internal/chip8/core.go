@@ -0,0 +1,123 @@
+package chip8
+
+import "io"
+
+// Core is the interface frontends drive the emulator through, whatever the
+// variant being emulated. VM implements it for every variant, delegating the
+// instructions a variant adds or changes to that variant's core.
+type Core interface {
+	// Cycle executes a single instruction.
+	Cycle() error
+
+	// Load loads a ROM into memory.
+	Load(rom io.Reader) error
+
+	// Reset restarts the program.
+	Reset() error
+
+	// Halted returns true once the program has stopped.
+	Halted() bool
+
+	// Subscribe subscribes to the VM's events: draws, the tone starting
+	// and stopping, halts, errors and states loaded.
+	Subscribe(n int) *Subscription
+
+	// Variant returns the variant being emulated.
+	Variant() Variant
+
+	// Display returns the display, which may be replaced when a program
+	// changes resolution.
+	Display() *Display
+
+	// Draws counts the times the display has changed.
+	Draws() uint64
+
+	// KeyDown and KeyUp press and release keys on the keypad.
+	KeyDown(key byte)
+	KeyUp(key byte)
+
+	// Press and Release queue presses and releases of keys, applied at the
+	// next frame, and Held returns the frames a key has been held for.
+	Press(key byte)
+	Release(key byte)
+	Held(key byte) int
+
+	// Timers returns the delay and sound timers.
+	Timers() (delay, sound byte)
+
+	// Pattern returns the waveform a program has set for the tone, if any.
+	Pattern() (pattern [16]byte, ok bool)
+}
+
+var _ Core = (*VM)(nil)
+
+// core is the variant specific part of a VM: the instructions a variant adds
+// to or changes from Chip8, and the state they need. The VM itself holds the
+// components shared by every variant, such as the registers, memory, timers
+// and keypad.
+type core interface {
+	// reset sets up memory and the display for the variant.
+	reset()
+
+	// load is called once a ROM has been loaded into memory.
+	load(rom []byte)
+
+	// handler returns the handler for the current opcode, or nil if it's
+	// handled the same as Chip8.
+	handler() opcodeHandlerFunc
+
+	// instrLen returns the length in bytes of the instruction at addr.
+	instrLen(addr uint16) uint16
+}
+
+// newCore returns the core of vr for v.
+func newCore(vr Variant, v *VM) core {
+	switch vr {
+	case MegaChip:
+		return &megaChipCore{v: v}
+	case HiRes:
+		return &hiResCore{v: v}
+	case SChip:
+		return &schipCore{v: v, clip: true}
+	case XOChip:
+		return &xoChipCore{schipCore: schipCore{v: v, palette: xoPalette}}
+	}
+	return chip8Core{}
+}
+
+// chip8Core is the original Chip8, which the VM implements without help.
+type chip8Core struct{}
+
+func (chip8Core) reset()                      {}
+func (chip8Core) load([]byte)                 {}
+func (chip8Core) handler() opcodeHandlerFunc  { return nil }
+func (chip8Core) instrLen(addr uint16) uint16 { return 2 }
+
+// hiResCore is the two page, 64x64, hi-res Chip8.
+type hiResCore struct {
+	v *VM
+}
+
+// reset switches to the 64x64 display.
+func (c *hiResCore) reset() {
+	c.v.disp = NewDisplay(DisplayWidth, HiResDisplayHeight)
+}
+
+// load starts execution at the Hi-Res entry point, skipping the interpreter
+// patch at the start of the ROM.
+func (c *hiResCore) load([]byte) {
+	c.v.pc = hiResEntry
+}
+
+// handler handles 0230, which clears the screen.
+func (c *hiResCore) handler() opcodeHandlerFunc {
+	if c.v.opc == 0x0230 {
+		return c.v.clrDisp
+	}
+	return nil
+}
+
+// instrLen returns 2, every instruction is 2 bytes.
+func (c *hiResCore) instrLen(addr uint16) uint16 {
+	return 2
+}
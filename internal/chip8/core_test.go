@@ -0,0 +1,173 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+// runVariant loads rom into a VM of variant vr and executes n instructions.
+func runVariant(t *testing.T, vr Variant, rom []byte, n int) *VM {
+	t.Helper()
+
+	v := NewVariant(vr)
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return v
+}
+
+func TestSChipResolution(t *testing.T) {
+	v := runVariant(t, SChip, []byte{0x00, 0xFF}, 1)
+	if w, h := v.Display().Width(), v.Display().Height(); w != 128 || h != 64 {
+		t.Fatalf("expected a 128x64 display, got %dx%d", w, h)
+	}
+
+	v = runVariant(t, SChip, []byte{0x00, 0xFF, 0x00, 0xFE}, 2)
+	if w, h := v.Display().Width(), v.Display().Height(); w != 64 || h != 32 {
+		t.Fatalf("expected a 64x32 display, got %dx%d", w, h)
+	}
+}
+
+func TestSChipDraw16(t *testing.T) {
+	rom := []byte{
+		0x00, 0xFF, // High resolution.
+		0xA2, 0x0A, // I = sprite.
+		0xD0, 0x00, // Draw 16x16 at (0, 0).
+		0xD0, 0x00, // Draw again, every row collides.
+		0x12, 0x08, // Loop.
+	}
+	sprite := make([]byte, 32)
+	for i := range sprite {
+		sprite[i] = 0xFF
+	}
+
+	v := runVariant(t, SChip, append(rom, sprite...), 3)
+	if !v.Display().Pixel(15, 15) || v.Display().Pixel(16, 0) {
+		t.Fatal("expected a 16x16 sprite")
+	}
+
+	v = runVariant(t, SChip, append(rom, sprite...), 4)
+	if v.V(0xF) != 16 {
+		t.Fatalf("expected VF to be 16, got %d", v.V(0xF))
+	}
+}
+
+func TestSChipScroll(t *testing.T) {
+	rom := []byte{
+		0xA0, 0x50, // I = the font.
+		0xD0, 0x01, // Draw the top row of "0" at (0, 0).
+		0x00, 0xC2, // Scroll down 2.
+		0x00, 0xFB, // Scroll right 4.
+	}
+
+	v := runVariant(t, SChip, rom, 4)
+	assertLit(t, v.Display(), []point{{4, 2}, {5, 2}, {6, 2}, {7, 2}})
+}
+
+func TestSChipFlags(t *testing.T) {
+	rom := []byte{
+		0x60, 0x12, // V0 = 0x12.
+		0x61, 0x34, // V1 = 0x34.
+		0xF1, 0x75, // Save V0-V1.
+		0x60, 0x00, // V0 = 0.
+		0x61, 0x00, // V1 = 0.
+		0xF1, 0x85, // Load V0-V1.
+	}
+
+	v := runVariant(t, SChip, rom, 6)
+	if v.V(0) != 0x12 || v.V(1) != 0x34 {
+		t.Fatalf("expected V0 0x12 and V1 0x34, got 0x%02X and 0x%02X", v.V(0), v.V(1))
+	}
+
+	// Flags are reported when saved, and can be restored.
+	v = NewVariant(SChip)
+	var saved []byte
+	v.OnFlags(func(flags []byte) { saved = flags })
+	v.SetFlags([]byte{0xAB})
+	if err := v.Load(bytes.NewReader([]byte{0xF0, 0x85, 0x70, 0x01, 0xF0, 0x75})); err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 3; n++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(saved) != 8 || saved[0] != 0xAC {
+		t.Fatalf("expected the saved flags to start 0xAC, got % X", saved)
+	}
+	if f := v.Flags(); !bytes.Equal(f, saved) {
+		t.Fatalf("expected flags % X, got % X", saved, f)
+	}
+	if New().Flags() != nil {
+		t.Fatal("expected Chip8 to have no flags")
+	}
+
+	v = NewVariant(SChip)
+	if err := v.Load(bytes.NewReader([]byte{0xF8, 0x75})); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err == nil {
+		t.Fatal("expected saving 9 flags to fail")
+	}
+}
+
+func TestXOChipPlanes(t *testing.T) {
+	rom := []byte{
+		0xF3, 0x01, // Select both planes.
+		0xA2, 0x08, // I = sprite.
+		0xD0, 0x01, // Draw 1 row to each plane at (0, 0).
+		0x12, 0x06, // Loop.
+		0xA0, // Plane 1.
+		0xC0, // Plane 2.
+	}
+
+	v := runVariant(t, XOChip, rom, 3)
+	d := v.Display()
+	for x, exp := range []byte{3, 2, 1} {
+		if got := d.Index(x, 0); got != exp {
+			t.Errorf("expected pixel (%d, 0) to be %d, got %d", x, exp, got)
+		}
+	}
+}
+
+func TestXOChipLongI(t *testing.T) {
+	// F000 NNNN sets I, and skips step over all 4 bytes of it.
+	rom := []byte{
+		0xF0, 0x00, 0x12, 0x34,
+		0x30, 0x00,
+		0xF0, 0x00, 0x56, 0x78,
+	}
+
+	v := runVariant(t, XOChip, rom, 2)
+	if v.I() != 0x1234 {
+		t.Fatalf("expected I to be 0x1234, got 0x%X", v.I())
+	}
+	if v.PC() != 0x20A {
+		t.Fatalf("expected PC to be 0x20A, got 0x%X", v.PC())
+	}
+}
+
+func TestXOChipSaveRange(t *testing.T) {
+	rom := []byte{
+		0x61, 0x01, // V1 = 1.
+		0x62, 0x02, // V2 = 2.
+		0x63, 0x03, // V3 = 3.
+		0xA3, 0x00, // I = 0x300.
+		0x53, 0x12, // Save V3-V1.
+	}
+
+	v := runVariant(t, XOChip, rom, 5)
+	for n, exp := range []byte{3, 2, 1} {
+		if got := v.Peek(0x300 + uint16(n)); got != exp {
+			t.Errorf("expected 0x%X to be %d, got %d", 0x300+n, exp, got)
+		}
+	}
+	if v.I() != 0x300 {
+		t.Fatalf("expected I to be unchanged, got 0x%X", v.I())
+	}
+}
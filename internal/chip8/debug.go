@@ -0,0 +1,55 @@
+package chip8
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Debug routines are machine code routines a ROM under development can call
+// with 0NNN to print its registers on the host or fail a test run, once
+// enabled with EnableDebug. The addresses are at the top of the interpreter's
+// memory, where no original machine code routine lived, and avoid the 0NE0 and
+// 0NEE that are decoded as 00E0 and 00EE. The Octo assembler emits calls to
+// them with :print, :dump and :fail.
+const (
+	// DebugPrint is called as 0FFX to print VX.
+	DebugPrint = 0xFF0
+
+	// DebugDump is called to print V0-VF and I.
+	DebugDump = 0xFD0
+
+	// DebugFail is called to fail an assertion, stopping the program with an
+	// error.
+	DebugFail = 0xFD1
+)
+
+// EnableDebug registers the debug routines with the VM, printing with printf.
+// The messages are prefixed with the address of the call.
+func (v *VM) EnableDebug(printf func(format string, args ...interface{})) {
+	for x := uint16(0); x < 0x10; x++ {
+		x := byte(x)
+		v.OnSys(DebugPrint|uint16(x), func() error {
+			printf("0x%03X: v%X = 0x%02X (%d)", v.pc-2, x, v.v[x], v.v[x])
+			return nil
+		})
+	}
+
+	v.OnSys(DebugDump, func() error {
+		printf("0x%03X: %s", v.pc-2, v.dump())
+		return nil
+	})
+
+	v.OnSys(DebugFail, func() error {
+		return fmt.Errorf("assertion failed at 0x%03X", v.pc-2)
+	})
+}
+
+// dump formats the registers for DebugDump.
+func (v *VM) dump() string {
+	var b strings.Builder
+	for x, r := range v.v {
+		fmt.Fprintf(&b, "v%X=%02X ", x, r)
+	}
+	fmt.Fprintf(&b, "i=0x%03X", v.i)
+	return b.String()
+}
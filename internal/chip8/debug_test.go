@@ -0,0 +1,47 @@
+package chip8
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEnableDebug(t *testing.T) {
+	rom := []byte{
+		0x63, 0x2A, // V3 = 0x2A.
+		0x0F, 0xF3, // Print V3.
+		0x0F, 0xD0, // Dump the registers.
+		0x0F, 0xD1, // Fail.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	var out []string
+	v.EnableDebug(func(format string, args ...interface{}) {
+		out = append(out, fmt.Sprintf(format, args...))
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(out) != 2 {
+		t.Fatalf("expected 2 lines printed, got %q", out)
+	}
+	if exp := "0x202: v3 = 0x2A (42)"; out[0] != exp {
+		t.Fatalf("expected %q, got %q", exp, out[0])
+	}
+	if !strings.HasPrefix(out[1], "0x204: v0=00 v1=00 v2=00 v3=2A") || !strings.HasSuffix(out[1], "i=0x000") {
+		t.Fatalf("unexpected dump %q", out[1])
+	}
+
+	err := v.Cycle()
+	if err == nil || !strings.Contains(err.Error(), "assertion failed at 0x206") {
+		t.Fatalf("expected the assertion to fail, got %v", err)
+	}
+}
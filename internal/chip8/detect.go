@@ -0,0 +1,69 @@
+package chip8
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Detect guesses the variant rom was written for from the instructions it
+// uses, returning the variant and why it was chosen. ROMs using no
+// instructions specific to a variant are Chip8.
+//
+// ROMs mix code and data, so any instruction found may really be data. To
+// keep mistakes rare only aligned instructions are considered, and the
+// extensions with the most distinctive instructions are checked first.
+func Detect(rom []byte) (vr Variant, reason string) {
+	if bytes.HasPrefix(rom, hiResStart) {
+		return HiRes, "starts with the Hi-Res startup jump 1260"
+	}
+	if bytes.HasPrefix(rom, []byte{0x00, 0x11}) {
+		return MegaChip, "starts by switching MegaChip mode on with 0011"
+	}
+
+	vr = Chip8
+	for addr := 0; addr+1 < len(rom); addr += 2 {
+		opc := uint16(rom[addr])<<8 | uint16(rom[addr+1])
+
+		if isXOChip(opc) {
+			return XOChip, fmt.Sprintf("uses %04X at 0x%03X", opc, addr+0x200)
+		}
+		if vr == Chip8 && isSChip(opc) {
+			vr, reason = SChip, fmt.Sprintf("uses %04X at 0x%03X", opc, addr+0x200)
+		}
+	}
+	if vr == Chip8 {
+		reason = "uses no instructions from other variants"
+	}
+
+	return vr, reason
+}
+
+// isSChip returns true if opc is only used by SUPER-CHIP and its extensions.
+func isSChip(opc uint16) bool {
+	switch {
+	case opc&0xFFF0 == 0x00C0 && opc != 0x00C0:
+		return true
+	case opc >= 0x00FB && opc <= 0x00FF:
+		return true
+	case opc&0xF0FF == 0xF030, opc&0xF0FF == 0xF075, opc&0xF0FF == 0xF085:
+		return true
+	}
+	return false
+}
+
+// isXOChip returns true if opc is only used by XO-CHIP.
+func isXOChip(opc uint16) bool {
+	switch {
+	case opc&0xFFF0 == 0x00D0 && opc != 0x00D0:
+		return true
+	case opc&0xF00F == 0x5002, opc&0xF00F == 0x5003:
+		return true
+	case opc == 0xF000, opc == 0xF002:
+		return true
+	case opc&0xF0FF == 0xF001 && opc != 0xF001:
+		return true
+	case opc&0xF0FF == 0xF03A:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package chip8
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  []byte
+		exp  Variant
+	}{
+		{
+			name: "chip8",
+			rom:  []byte{0x60, 0x01, 0xD0, 0x15, 0x12, 0x00},
+			exp:  Chip8,
+		},
+		{
+			name: "hires",
+			rom:  []byte{0x12, 0x60, 0x00, 0xFF},
+			exp:  HiRes,
+		},
+		{
+			name: "megachip",
+			rom:  []byte{0x00, 0x11, 0x00, 0xFF},
+			exp:  MegaChip,
+		},
+		{
+			name: "schip high resolution",
+			rom:  []byte{0x00, 0xE0, 0x00, 0xFF},
+			exp:  SChip,
+		},
+		{
+			name: "schip flags",
+			rom:  []byte{0x60, 0x01, 0xF3, 0x75},
+			exp:  SChip,
+		},
+		{
+			name: "xochip after schip",
+			rom:  []byte{0x00, 0xFF, 0xF0, 0x00, 0x12, 0x34},
+			exp:  XOChip,
+		},
+		{
+			name: "xochip planes",
+			rom:  []byte{0xF3, 0x01},
+			exp:  XOChip,
+		},
+		{
+			name: "unaligned data ignored",
+			rom:  []byte{0x60, 0x00, 0xFF, 0x00},
+			exp:  Chip8,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, reason := Detect(tc.rom)
+			if got != tc.exp {
+				t.Fatalf("expected %s, got %s: %s", tc.exp, got, reason)
+			}
+		})
+	}
+}
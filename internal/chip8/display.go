@@ -0,0 +1,433 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"sync"
+)
+
+// Display is a bitmap display. Pixels are stored one byte per pixel in row
+// order as colour indices, 0 being off. Monochrome displays only use 1.
+//
+// With flicker reduction on, see VM.SetFlicker, the display is double
+// buffered: programs draw to the pixels while Pixel, Index and Image return
+// those shown, updated at each frame.
+type Display struct {
+	w, h int
+	px   []byte
+
+	// With flicker reduction, the pixels shown, in which pixels turned off
+	// stay lit for a few frames, and the frames each is still held for. Both
+	// are nil without, when the pixels drawn are shown.
+	shown, held []byte
+
+	// The span of columns changed in each row since the display was last
+	// marked clean. A row is clean when its span is empty (min > max).
+	// Renderers may take them from another goroutine than the VM's, so they
+	// are guarded by dirtyMu.
+	dirtyMu            sync.Mutex
+	dirtyMin, dirtyMax []int
+
+	// Clip stops sprites wrapping around the edges of the display, pixels
+	// drawn past an edge are discarded instead. The sprite origin always
+	// wraps.
+	Clip bool
+
+	// Palette, if set, is the colour of each pixel value. It is nil for
+	// monochrome displays, which are drawn in the renderer's own colours.
+	Palette color.Palette
+}
+
+// NewDisplay returns a blank display of w by h pixels.
+func NewDisplay(w, h int) *Display {
+	d := &Display{
+		w:        w,
+		h:        h,
+		px:       make([]byte, w*h),
+		dirtyMin: make([]int, h),
+		dirtyMax: make([]int, h),
+	}
+	d.MarkClean()
+
+	return d
+}
+
+// Width returns the width of the display in pixels.
+func (d *Display) Width() int {
+	return d.w
+}
+
+// Height returns the height of the display in pixels.
+func (d *Display) Height() int {
+	return d.h
+}
+
+// Pixel returns true if the pixel at (x, y) is lit.
+func (d *Display) Pixel(x, y int) bool {
+	if x < 0 || y < 0 || x >= d.w || y >= d.h {
+		return false
+	}
+	return d.front()[y*d.w+x] != 0
+}
+
+// front returns the pixels shown.
+func (d *Display) front() []byte {
+	if d.shown != nil {
+		return d.shown
+	}
+	return d.px
+}
+
+// present updates the pixels shown at a frame boundary, keeping pixels lit
+// for frames more frames after they're turned off, at most 255. A frame
+// blends the last two frames drawn, more hold pixels on for longer, and zero
+// shows the pixels drawn. It returns true if the pixels shown changed.
+func (d *Display) present(frames int) bool {
+	if frames > 0xFF {
+		frames = 0xFF
+	}
+
+	switch {
+	case frames <= 0 && d.shown == nil:
+		return false
+	case frames <= 0:
+		changed := false
+		for i, p := range d.px {
+			if d.shown[i] != p {
+				d.markDirty(i%d.w, i/d.w)
+				changed = true
+			}
+		}
+		d.shown, d.held = nil, nil
+		return changed
+	case d.shown == nil:
+		d.shown = append([]byte(nil), d.px...)
+		d.held = make([]byte, len(d.px))
+	}
+
+	changed := false
+	for i, p := range d.px {
+		s := p
+		if p != 0 {
+			d.held[i] = byte(frames)
+		} else if d.held[i] > 0 {
+			d.held[i]--
+			s = d.shown[i]
+		}
+		if d.shown[i] != s {
+			d.shown[i] = s
+			d.markDirty(i%d.w, i/d.w)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// monoPalette is the palette of monochrome displays in images.
+var monoPalette = color.Palette{color.Black, color.White}
+
+// Image returns an image of the pixels shown, for use with image encoders or
+// custom renderers. It is a view of the pixels rather than a copy, so changes
+// as the display is drawn to. Monochrome displays are black and white.
+func (d *Display) Image() *image.Paletted {
+	pal := d.Palette
+	if pal == nil {
+		pal = monoPalette
+	}
+
+	return &image.Paletted{
+		Pix:     d.front(),
+		Stride:  d.w,
+		Rect:    image.Rect(0, 0, d.w, d.h),
+		Palette: pal,
+	}
+}
+
+// Index returns the colour index of the pixel at (x, y).
+func (d *Display) Index(x, y int) byte {
+	if x < 0 || y < 0 || x >= d.w || y >= d.h {
+		return 0
+	}
+	return d.front()[y*d.w+x]
+}
+
+// Clear turns off every pixel.
+func (d *Display) Clear() {
+	d.clearPlanes(0xFF)
+}
+
+// clearPlanes clears the bits of mask in every pixel. Each bit of a pixel is
+// a separate bit plane.
+func (d *Display) clearPlanes(mask byte) {
+	for i := range d.px {
+		if d.px[i]&mask == 0 {
+			continue
+		}
+		d.px[i] &^= mask
+		d.markDirty(i%d.w, i/d.w)
+	}
+}
+
+// DirtyRects returns the regions of the display that have changed since it was
+// last marked clean. Consecutive changed rows are merged into a single
+// rectangle spanning the changed columns of all of them.
+func (d *Display) DirtyRects() []image.Rectangle {
+	d.dirtyMu.Lock()
+	defer d.dirtyMu.Unlock()
+
+	return d.dirtyRects()
+}
+
+// TakeDirtyRects returns the regions of the display that have changed and marks
+// it clean, in one step so that no change made in between is lost. Renderers
+// running apart from the VM should use it over DirtyRects and MarkClean.
+func (d *Display) TakeDirtyRects() []image.Rectangle {
+	d.dirtyMu.Lock()
+	defer d.dirtyMu.Unlock()
+
+	rects := d.dirtyRects()
+	d.markClean()
+	return rects
+}
+
+// dirtyRects is DirtyRects, with dirtyMu held.
+func (d *Display) dirtyRects() []image.Rectangle {
+	var (
+		rects []image.Rectangle
+		cur   image.Rectangle
+		open  bool
+	)
+
+	for y := 0; y < d.h; y++ {
+		if d.dirtyMin[y] > d.dirtyMax[y] {
+			if open {
+				rects = append(rects, cur)
+				open = false
+			}
+			continue
+		}
+
+		row := image.Rect(d.dirtyMin[y], y, d.dirtyMax[y]+1, y+1)
+		if open {
+			cur = cur.Union(row)
+		} else {
+			cur, open = row, true
+		}
+	}
+	if open {
+		rects = append(rects, cur)
+	}
+
+	return rects
+}
+
+// Dirty returns true if any pixel has changed since the display was last
+// marked clean.
+func (d *Display) Dirty() bool {
+	d.dirtyMu.Lock()
+	defer d.dirtyMu.Unlock()
+
+	for y := 0; y < d.h; y++ {
+		if d.dirtyMin[y] <= d.dirtyMax[y] {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkClean resets change tracking. Renderers should call it once they have
+// drawn the dirty regions.
+func (d *Display) MarkClean() {
+	d.dirtyMu.Lock()
+	defer d.dirtyMu.Unlock()
+
+	d.markClean()
+}
+
+// markClean is MarkClean, with dirtyMu held.
+func (d *Display) markClean() {
+	for y := 0; y < d.h; y++ {
+		d.dirtyMin[y], d.dirtyMax[y] = d.w, -1
+	}
+}
+
+// markAll marks the whole display as changed.
+func (d *Display) markAll() {
+	d.dirtyMu.Lock()
+	defer d.dirtyMu.Unlock()
+
+	for y := 0; y < d.h; y++ {
+		d.dirtyMin[y], d.dirtyMax[y] = 0, d.w-1
+	}
+}
+
+// markDirty records a change to the pixel at (x, y).
+func (d *Display) markDirty(x, y int) {
+	d.dirtyMu.Lock()
+	if x < d.dirtyMin[y] {
+		d.dirtyMin[y] = x
+	}
+	if x > d.dirtyMax[y] {
+		d.dirtyMax[y] = x
+	}
+	d.dirtyMu.Unlock()
+}
+
+// DrawSprite XORs an 8 pixel wide sprite onto the display with its top left
+// corner at (x, y). Each byte of sprite is a row, most significant bit on the
+// left. It returns the number of rows in which a lit pixel was turned off.
+func (d *Display) DrawSprite(x, y int, sprite []byte) int {
+	return d.blit(x, y, spriteRows(sprite, false), 1)
+}
+
+// DrawSprite16 XORs a 16x16 sprite onto the display with its top left corner
+// at (x, y). Each pair of bytes in sprite is a row. It returns the number of
+// rows in which a lit pixel was turned off.
+func (d *Display) DrawSprite16(x, y int, sprite []byte) int {
+	return d.blit(x, y, spriteRows(sprite, true), 1)
+}
+
+// spriteRows returns the rows of an 8 pixel wide sprite, or a 16 pixel wide
+// one if wide is set, left aligned in 16 bits.
+func spriteRows(sprite []byte, wide bool) []uint16 {
+	if !wide {
+		rows := make([]uint16, len(sprite))
+		for i, b := range sprite {
+			rows[i] = uint16(b) << 8
+		}
+		return rows
+	}
+
+	rows := make([]uint16, len(sprite)/2)
+	for i := range rows {
+		rows[i] = uint16(sprite[i*2])<<8 | uint16(sprite[i*2+1])
+	}
+	return rows
+}
+
+// blit XORs up to 16 pixel wide rows onto the bit planes of the display in
+// mask, most significant bit on the left.
+func (d *Display) blit(x, y int, rows []uint16, mask byte) int {
+	// The origin always wraps, so a sprite drawn at (70, 40) on a 64x32
+	// display starts at (6, 8).
+	x, y = x%d.w, y%d.h
+
+	collisions := 0
+	for r, row := range rows {
+		py := y + r
+		if py >= d.h {
+			if d.Clip {
+				break
+			}
+			py %= d.h
+		}
+
+		collided := false
+		for c := 0; c < 16; c++ {
+			if row&(0x8000>>uint(c)) == 0 {
+				continue
+			}
+
+			px := x + c
+			if px >= d.w {
+				if d.Clip {
+					break
+				}
+				px %= d.w
+			}
+
+			i := py*d.w + px
+			if d.px[i]&mask != 0 {
+				collided = true
+			}
+			d.px[i] ^= mask
+			d.markDirty(px, py)
+		}
+		if collided {
+			collisions++
+		}
+	}
+
+	return collisions
+}
+
+// DrawIndexed draws a w by h sprite of colour indices onto the display with
+// its top left corner at (x, y), one byte per pixel in row order. Zero pixels
+// are transparent, others replace the pixel beneath. Pixels drawn past an edge
+// are discarded. It returns true if a pixel of colour collide was drawn over.
+func (d *Display) DrawIndexed(x, y, w, h int, sprite []byte, collide byte) bool {
+	collided := false
+	for r := 0; r < h; r++ {
+		py := y + r
+		if py < 0 || py >= d.h {
+			continue
+		}
+
+		for c := 0; c < w; c++ {
+			px := x + c
+			if px < 0 || px >= d.w {
+				continue
+			}
+
+			idx := sprite[r*w+c]
+			if idx == 0 {
+				continue
+			}
+
+			i := py*d.w + px
+			if d.px[i] == collide {
+				collided = true
+			}
+			if d.px[i] != idx {
+				d.px[i] = idx
+				d.markDirty(px, py)
+			}
+		}
+	}
+
+	return collided
+}
+
+// Scroll moves the contents of the display dx pixels right and dy pixels
+// down. Negative values scroll left and up. Pixels scrolled off an edge are
+// lost, those scrolled on are off.
+func (d *Display) Scroll(dx, dy int) {
+	d.scrollPlanes(dx, dy, 0xFF)
+}
+
+// scrollPlanes scrolls the bit planes of the display in mask, leaving the
+// others in place.
+func (d *Display) scrollPlanes(dx, dy int, mask byte) {
+	px := make([]byte, len(d.px))
+	for y := 0; y < d.h; y++ {
+		sy := y - dy
+		if sy < 0 || sy >= d.h {
+			continue
+		}
+		for x := 0; x < d.w; x++ {
+			sx := x - dx
+			if sx < 0 || sx >= d.w {
+				continue
+			}
+			px[y*d.w+x] = d.px[sy*d.w+sx]
+		}
+	}
+
+	for i := range px {
+		px[i] = d.px[i]&^mask | px[i]&mask
+		if px[i] != d.px[i] {
+			d.markDirty(i%d.w, i/d.w)
+		}
+	}
+	copy(d.px, px)
+}
+
+// copyFrom copies the pixels of src, which must be the same size.
+func (d *Display) copyFrom(src *Display) {
+	for i := range d.px {
+		if d.px[i] != src.px[i] {
+			d.px[i] = src.px[i]
+			d.markDirty(i%d.w, i/d.w)
+		}
+	}
+}
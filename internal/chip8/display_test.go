@@ -0,0 +1,347 @@
+package chip8
+
+import (
+	"image"
+	"image/color"
+	"sort"
+	"testing"
+)
+
+type point struct{ x, y int }
+
+func TestDisplayDrawSprite(t *testing.T) {
+	tests := []struct {
+		name       string
+		clip       bool
+		pre        []point
+		x, y       int
+		sprite     []byte
+		wide       bool
+		lit        []point
+		collisions int
+	}{
+		{
+			name:   "origin",
+			sprite: []byte{0xC0, 0x01},
+			lit:    []point{{0, 0}, {1, 0}, {7, 1}},
+		},
+		{
+			name:   "offset",
+			x:      10,
+			y:      5,
+			sprite: []byte{0x81},
+			lit:    []point{{10, 5}, {17, 5}},
+		},
+		{
+			name:   "empty sprite",
+			x:      3,
+			y:      3,
+			sprite: []byte{},
+		},
+		{
+			name:       "collision erases pixel",
+			pre:        []point{{0, 0}, {5, 5}},
+			sprite:     []byte{0xC0},
+			lit:        []point{{1, 0}, {5, 5}},
+			collisions: 1,
+		},
+		{
+			name:       "collisions counted per row",
+			pre:        []point{{0, 0}, {1, 0}, {0, 2}},
+			sprite:     []byte{0xC0, 0x80, 0x80},
+			lit:        []point{{0, 1}},
+			collisions: 2,
+		},
+		{
+			name:   "no collision on unlit pixels",
+			pre:    []point{{1, 0}},
+			sprite: []byte{0x80},
+			lit:    []point{{0, 0}, {1, 0}},
+		},
+		{
+			name:   "right edge wraps within the row",
+			x:      62,
+			y:      4,
+			sprite: []byte{0xF0},
+			lit:    []point{{62, 4}, {63, 4}, {0, 4}, {1, 4}},
+		},
+		{
+			name:   "bottom edge wraps to the top",
+			x:      2,
+			y:      31,
+			sprite: []byte{0x80, 0x80},
+			lit:    []point{{2, 31}, {2, 0}},
+		},
+		{
+			name:   "corner wraps both ways",
+			x:      63,
+			y:      31,
+			sprite: []byte{0xC0, 0xC0},
+			lit:    []point{{63, 31}, {0, 31}, {63, 0}, {0, 0}},
+		},
+		{
+			name:   "origin wraps",
+			x:      70,
+			y:      40,
+			sprite: []byte{0x80},
+			lit:    []point{{6, 8}},
+		},
+		{
+			name:   "clip right edge",
+			clip:   true,
+			x:      62,
+			y:      4,
+			sprite: []byte{0xF0},
+			lit:    []point{{62, 4}, {63, 4}},
+		},
+		{
+			name:   "clip bottom edge",
+			clip:   true,
+			x:      2,
+			y:      31,
+			sprite: []byte{0x80, 0x80},
+			lit:    []point{{2, 31}},
+		},
+		{
+			name:   "clip still wraps origin",
+			clip:   true,
+			x:      64,
+			y:      32,
+			sprite: []byte{0x80},
+			lit:    []point{{0, 0}},
+		},
+		{
+			name:   "16x16 sprite",
+			x:      8,
+			y:      8,
+			sprite: []byte{0x80, 0x01, 0x00, 0x00, 0xFF, 0xFF},
+			wide:   true,
+			lit: []point{
+				{8, 8}, {23, 8},
+				{8, 10}, {9, 10}, {10, 10}, {11, 10}, {12, 10}, {13, 10}, {14, 10}, {15, 10},
+				{16, 10}, {17, 10}, {18, 10}, {19, 10}, {20, 10}, {21, 10}, {22, 10}, {23, 10},
+			},
+		},
+		{
+			name:   "16x16 sprite wraps",
+			x:      56,
+			sprite: []byte{0x00, 0x81},
+			wide:   true,
+			lit:    []point{{0, 0}, {7, 0}},
+		},
+		{
+			name:       "16x16 collisions counted per row",
+			pre:        []point{{15, 0}, {0, 1}},
+			sprite:     []byte{0x00, 0x01, 0x80, 0x00, 0x80, 0x00},
+			wide:       true,
+			lit:        []point{{0, 2}},
+			collisions: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDisplay(64, 32)
+			d.Clip = tc.clip
+			for _, p := range tc.pre {
+				d.px[p.y*d.w+p.x] = 1
+			}
+
+			var collisions int
+			if tc.wide {
+				collisions = d.DrawSprite16(tc.x, tc.y, tc.sprite)
+			} else {
+				collisions = d.DrawSprite(tc.x, tc.y, tc.sprite)
+			}
+
+			if collisions != tc.collisions {
+				t.Errorf("expected %d collisions, got %d", tc.collisions, collisions)
+			}
+			assertLit(t, d, tc.lit)
+		})
+	}
+}
+
+func TestDisplayClear(t *testing.T) {
+	d := NewDisplay(64, 32)
+	d.DrawSprite(0, 0, []byte{0xFF, 0xFF})
+	d.Clear()
+	assertLit(t, d, nil)
+}
+
+func TestDrawOpcodeSetsVF(t *testing.T) {
+	v := New()
+	v.i = 0x300
+	v.mem[0x300] = 0x80
+	v.v[1], v.v[2] = 63, 31
+
+	for _, exp := range []byte{0, 1, 0} {
+		v.opc = 0xD121
+		if _, err := v.draw(); err != nil {
+			t.Fatal(err)
+		}
+		if v.v[0xF] != exp {
+			t.Fatalf("expected VF to be %d, got %d", exp, v.v[0xF])
+		}
+	}
+	assertLit(t, v.disp, []point{{63, 31}})
+}
+
+// assertLit fails the test if the lit pixels of d are not exactly exp.
+func assertLit(t *testing.T, d *Display, exp []point) {
+	t.Helper()
+
+	var got []point
+	for y := 0; y < d.Height(); y++ {
+		for x := 0; x < d.Width(); x++ {
+			if d.Pixel(x, y) {
+				got = append(got, point{x, y})
+			}
+		}
+	}
+
+	sortPoints(exp)
+	if len(got) != len(exp) {
+		t.Fatalf("expected lit pixels %v, got %v", exp, got)
+	}
+	for i := range got {
+		if got[i] != exp[i] {
+			t.Fatalf("expected lit pixels %v, got %v", exp, got)
+		}
+	}
+}
+
+func sortPoints(p []point) {
+	sort.Slice(p, func(i, j int) bool {
+		if p[i].y != p[j].y {
+			return p[i].y < p[j].y
+		}
+		return p[i].x < p[j].x
+	})
+}
+
+func TestDisplayDirtyRects(t *testing.T) {
+	d := NewDisplay(64, 32)
+	if d.Dirty() || len(d.DirtyRects()) != 0 {
+		t.Fatal("expected new display to be clean")
+	}
+
+	d.DrawSprite(4, 2, []byte{0x80, 0x01})
+	d.DrawSprite(20, 10, []byte{0x00, 0xC0})
+	d.DrawSprite(62, 20, []byte{0xF0})
+
+	exp := []image.Rectangle{
+		image.Rect(4, 2, 12, 4),
+		image.Rect(20, 11, 22, 12),
+		image.Rect(0, 20, 64, 21),
+	}
+	got := d.DirtyRects()
+	if len(got) != len(exp) {
+		t.Fatalf("expected %v, got %v", exp, got)
+	}
+	for i := range exp {
+		if !got[i].Eq(exp[i]) {
+			t.Fatalf("expected %v, got %v", exp, got)
+		}
+	}
+
+	d.MarkClean()
+	if d.Dirty() {
+		t.Fatal("expected display to be clean")
+	}
+
+	d.Clear()
+	got = d.DirtyRects()
+	if len(got) != 3 || !got[0].Eq(image.Rect(4, 2, 12, 4)) {
+		t.Fatalf("expected clear to dirty only lit pixels, got %v", got)
+	}
+}
+
+func TestDisplayTakeDirtyRects(t *testing.T) {
+	d := NewDisplay(64, 32)
+	d.DrawSprite(4, 2, []byte{0x80})
+
+	got := d.TakeDirtyRects()
+	if len(got) != 1 || !got[0].Eq(image.Rect(4, 2, 5, 3)) {
+		t.Fatalf("expected the sprite's rect, got %v", got)
+	}
+	if d.Dirty() || len(d.TakeDirtyRects()) != 0 {
+		t.Fatal("expected display to be clean")
+	}
+
+	// Taken while another goroutine draws, no change is lost. Run with -race
+	// to check the marks.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for x := 0; x < 64; x++ {
+			d.DrawSprite(x, 0, []byte{0x80})
+		}
+	}()
+
+	var seen image.Rectangle
+	for taking := true; taking; {
+		select {
+		case <-done:
+			taking = false
+		default:
+		}
+		for _, r := range d.TakeDirtyRects() {
+			seen = seen.Union(r)
+		}
+	}
+	if !seen.Eq(image.Rect(0, 0, 64, 1)) {
+		t.Fatalf("expected the whole top row, got %v", seen)
+	}
+}
+
+func TestDisplayImage(t *testing.T) {
+	d := NewDisplay(64, 32)
+	img := d.Image()
+	if img.Bounds() != image.Rect(0, 0, 64, 32) {
+		t.Fatalf("unexpected bounds %v", img.Bounds())
+	}
+
+	// The image is a view, so sees pixels drawn after it was made.
+	d.DrawSprite(3, 4, []byte{0x80})
+	if img.At(3, 4) != color.White {
+		t.Fatalf("expected (3, 4) to be white, got %v", img.At(3, 4))
+	}
+	if img.At(4, 4) != color.Black {
+		t.Fatalf("expected (4, 4) to be black, got %v", img.At(4, 4))
+	}
+}
+
+func TestDisplayFlicker(t *testing.T) {
+	d := NewDisplay(DisplayWidth, DisplayHeight)
+	d.DrawSprite(0, 0, []byte{0x80})
+	if d.present(2) || !d.Pixel(0, 0) {
+		t.Fatal("expected the pixel drawn to be shown")
+	}
+
+	// Erased, the pixel is held on for two frames, then turned off.
+	d.DrawSprite(0, 0, []byte{0x80})
+	for f, lit := range []bool{true, true, false} {
+		d.present(2)
+		if d.Pixel(0, 0) != lit {
+			t.Fatalf("frame %d: expected lit %v", f, lit)
+		}
+	}
+
+	// Redrawn between frames, it stays lit throughout.
+	d.DrawSprite(0, 0, []byte{0x80})
+	d.present(2)
+	d.DrawSprite(0, 0, []byte{0x80})
+	d.present(2)
+	d.DrawSprite(0, 0, []byte{0x80})
+	if d.present(2) || !d.Pixel(0, 0) || d.Image().Pix[0] != 1 {
+		t.Fatal("expected the flickering pixel to stay lit")
+	}
+
+	// Turned off, the pixels drawn are shown again.
+	d.DrawSprite(0, 0, []byte{0x80})
+	d.MarkClean()
+	if !d.present(0) || d.Pixel(0, 0) || !d.Dirty() {
+		t.Fatal("expected the erased pixel to be shown")
+	}
+}
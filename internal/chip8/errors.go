@@ -0,0 +1,74 @@
+package chip8
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// The errors Cycle returns when a program goes wrong, wrapped with where it
+// went wrong, so frontends can tell them apart with errors.Is.
+var (
+	// ErrUnknownOpcode is matched by every UnknownOpcodeError.
+	ErrUnknownOpcode = errors.New("unknown opcode")
+
+	// ErrMemOutOfRange is returned when an instruction reads or writes past
+	// the end of memory.
+	ErrMemOutOfRange = errors.New("memory access out of range")
+
+	// ErrStackOverflow and ErrStackUnderflow are returned when a program
+	// calls too many nested subroutines or returns without calling one.
+	ErrStackOverflow  = errors.New("stack overflow")
+	ErrStackUnderflow = errors.New("stack underflow")
+
+	// ErrFlagRange is returned when FX75 or FX85 use more flag registers than
+	// the variant has.
+	ErrFlagRange = errors.New("flag register out of range")
+
+	// ErrCycleLimit is returned once a VM has run the most instructions its
+	// Limits allow.
+	ErrCycleLimit = errors.New("cycle limit reached")
+
+	// ErrWatchdog is matched by every WatchdogError.
+	ErrWatchdog = errors.New("watchdog expired")
+)
+
+// UnknownOpcodeError is returned when a program executes an opcode the
+// variant doesn't implement.
+type UnknownOpcodeError struct {
+	Opcode uint16
+
+	// PC is the address of the opcode.
+	PC uint16
+}
+
+// Error implements error.
+func (e *UnknownOpcodeError) Error() string {
+	return fmt.Sprintf("unknown opcode 0x%04X at 0x%03X", e.Opcode, e.PC)
+}
+
+// Is returns true for ErrUnknownOpcode.
+func (e *UnknownOpcodeError) Is(target error) bool {
+	return target == ErrUnknownOpcode
+}
+
+// WatchdogError is returned when a Cycle or StepFrame takes longer than the
+// Budget of the VM's Limits.
+type WatchdogError struct {
+	Budget, Elapsed time.Duration
+}
+
+// Error implements error.
+func (e *WatchdogError) Error() string {
+	return fmt.Sprintf("watchdog expired: took %s, the budget is %s", e.Elapsed, e.Budget)
+}
+
+// Is returns true for ErrWatchdog.
+func (e *WatchdogError) Is(target error) bool {
+	return target == ErrWatchdog
+}
+
+// unknownOpcode returns the error for the opcode being executed.
+func (v *VM) unknownOpcode() error {
+	return &UnknownOpcodeError{Opcode: v.opc, PC: v.pc}
+}
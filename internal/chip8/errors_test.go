@@ -0,0 +1,55 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  []byte
+		err  error
+	}{
+		{name: "unknown opcode", rom: []byte{0x60, 0x01, 0x80, 0x1F}, err: ErrUnknownOpcode},
+		{name: "stack underflow", rom: []byte{0x00, 0xEE}, err: ErrStackUnderflow},
+		{name: "stack overflow", rom: []byte{0x22, 0x00}, err: ErrStackOverflow},
+		{name: "memory out of range", rom: []byte{0xAF, 0xFF, 0xF1, 0x55}, err: ErrMemOutOfRange},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := New()
+			if err := v.Load(bytes.NewReader(tc.rom)); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			for i := 0; i < 20 && err == nil; i++ {
+				err = v.Cycle()
+			}
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestUnknownOpcodeError(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x60, 0x01, 0x80, 0x1F})); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+
+	var uerr *UnknownOpcodeError
+	if err := v.Cycle(); !errors.As(err, &uerr) {
+		t.Fatalf("expected an UnknownOpcodeError, got %v", err)
+	}
+	if uerr.Opcode != 0x801F || uerr.PC != 0x202 {
+		t.Fatalf("expected opcode 0x801F at 0x202, got 0x%04X at 0x%03X", uerr.Opcode, uerr.PC)
+	}
+}
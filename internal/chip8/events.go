@@ -0,0 +1,120 @@
+package chip8
+
+import "sync/atomic"
+
+// Event is something a VM did that frontends, recorders and the like may want
+// to know about. It's one of DrawEvent, BeepStartEvent, BeepStopEvent,
+// HaltEvent, ErrorEvent and StateLoadedEvent.
+type Event interface {
+	isEvent()
+}
+
+// DrawEvent is published when the display changes and should be drawn.
+type DrawEvent struct{}
+
+// BeepStartEvent is published when the sound timer is set and the tone should
+// start sounding, and BeepStopEvent when it runs out and the tone should stop.
+type (
+	BeepStartEvent struct{}
+	BeepStopEvent  struct{}
+)
+
+// HaltEvent is published when the program halts, see Halted. It's published
+// once, not for every cycle spent halted.
+type HaltEvent struct{}
+
+// ErrorEvent is published when Cycle returns an error.
+type ErrorEvent struct {
+	Err error
+}
+
+// StateLoadedEvent is published when LoadState replaces the state of the VM.
+type StateLoadedEvent struct{}
+
+func (DrawEvent) isEvent()        {}
+func (BeepStartEvent) isEvent()   {}
+func (BeepStopEvent) isEvent()    {}
+func (HaltEvent) isEvent()        {}
+func (ErrorEvent) isEvent()       {}
+func (StateLoadedEvent) isEvent() {}
+
+// Subscription delivers the events of a VM to a subscriber.
+type Subscription struct {
+	vm *VM
+	c  chan Event
+
+	// The events dropped as the subscriber fell behind, updated atomically.
+	dropped uint64
+}
+
+// Subscribe returns a subscription to the VM's events, queueing up to n of
+// them, at least one. The VM never waits for subscribers: one that falls
+// behind loses the oldest events queued, so the latest, such as whether the
+// tone is sounding, are always delivered. It's safe to call while the VM is
+// running.
+func (v *VM) Subscribe(n int) *Subscription {
+	if n < 1 {
+		n = 1
+	}
+	s := &Subscription{vm: v, c: make(chan Event, n)}
+
+	v.subsMu.Lock()
+	v.subs = append(v.subs, s)
+	v.subsMu.Unlock()
+	return s
+}
+
+// Events returns the channel the events are delivered on. It's closed by
+// Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.c
+}
+
+// Dropped returns the number of events lost because the subscriber fell
+// behind.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close unsubscribes from the VM's events and closes the channel.
+func (s *Subscription) Close() {
+	v := s.vm
+	v.subsMu.Lock()
+	defer v.subsMu.Unlock()
+
+	for i, sub := range v.subs {
+		if sub == s {
+			v.subs = append(v.subs[:i], v.subs[i+1:]...)
+			close(s.c)
+			return
+		}
+	}
+}
+
+// send queues e, making room by dropping the oldest event queued if the
+// subscriber has fallen behind.
+func (s *Subscription) send(e Event) {
+	for {
+		select {
+		case s.c <- e:
+			return
+		default:
+		}
+
+		select {
+		case <-s.c:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+	}
+}
+
+// publish delivers e to every subscriber.
+func (v *VM) publish(e Event) {
+	v.subsMu.Lock()
+	defer v.subsMu.Unlock()
+
+	for _, s := range v.subs {
+		s.send(e)
+	}
+}
@@ -0,0 +1,100 @@
+package chip8
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// drain returns the events queued on sub.
+func drain(sub *Subscription) []Event {
+	var events []Event
+	for {
+		select {
+		case e := <-sub.Events():
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEvents(t *testing.T) {
+	rom := []byte{
+		0x60, 0x02, // V0 = 2.
+		0xF0, 0x18, // Sound timer = V0.
+		0xD0, 0x01, // Draw.
+		0x00, 0xEE, // Return, with nothing to return to.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	a, b := v.Subscribe(8), v.Subscribe(8)
+	defer a.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := v.Cycle()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	want := []Event{BeepStartEvent{}, DrawEvent{}, ErrorEvent{Err: err}}
+	for _, sub := range []*Subscription{a, b} {
+		if got := drain(sub); !reflect.DeepEqual(got, want) {
+			t.Fatalf("expected %#v, got %#v", want, got)
+		}
+	}
+
+	// Once closed, b gets nothing more.
+	b.Close()
+	if _, ok := <-b.Events(); ok {
+		t.Fatal("expected the channel to be closed")
+	}
+	v.SetTimers(0, 0)
+	if got := drain(a); !reflect.DeepEqual(got, []Event{BeepStopEvent{}}) {
+		t.Fatalf("expected the tone to stop, got %#v", got)
+	}
+}
+
+func TestEventsDropOldest(t *testing.T) {
+	v := New()
+	sub := v.Subscribe(2)
+	defer sub.Close()
+
+	v.SetTimers(0, 5)
+	v.drew()
+	v.SetTimers(0, 0)
+
+	want := []Event{DrawEvent{}, BeepStopEvent{}}
+	if got := drain(sub); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %#v, got %#v", want, got)
+	}
+	if sub.Dropped() != 1 {
+		t.Fatalf("expected 1 event dropped, got %d", sub.Dropped())
+	}
+}
+
+func TestStateLoadedEvent(t *testing.T) {
+	v := New()
+	var buf bytes.Buffer
+	if err := v.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := v.Subscribe(4)
+	defer sub.Close()
+	if err := v.LoadState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	events := drain(sub)
+	if len(events) == 0 || events[len(events)-1] != (StateLoadedEvent{}) {
+		t.Fatalf("expected a state loaded event last, got %#v", events)
+	}
+}
@@ -0,0 +1,35 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzVM runs random ROMs on a random variant for a bounded number of cycles.
+// Bad ROMs should be reported as errors, never crash the emulator.
+func FuzzVM(f *testing.F) {
+	f.Add(byte(Chip8), []byte{0x60, 0x05, 0xF0, 0x29, 0xD0, 0x05, 0x12, 0x06})
+	f.Add(byte(Chip8), []byte{0xAF, 0xFF, 0xF0, 0x33, 0xFF, 0x65})
+	f.Add(byte(Chip8), []byte{0x22, 0x00})
+	f.Add(byte(Chip8), []byte{0x00, 0xEE})
+	f.Add(byte(Chip8), []byte{0x6F, 0xFF, 0xFF, 0x1E, 0xD0, 0x0F})
+
+	// One program exercising the extensions of each other variant.
+	f.Add(byte(MegaChip), []byte{0x00, 0x11, 0x03, 0x10, 0x04, 0x10, 0x01, 0xFF, 0xFF, 0xF0, 0xD0, 0x01, 0x00, 0xE0})
+	f.Add(byte(HiRes), []byte{0x12, 0x60, 0x60, 0x3F, 0xD0, 0x05})
+	f.Add(byte(SChip), []byte{0x00, 0xFF, 0x60, 0x01, 0xF0, 0x30, 0xD0, 0x00, 0x00, 0xC4, 0x00, 0xFD})
+	f.Add(byte(XOChip), []byte{0xF0, 0x00, 0xFF, 0xF0, 0xF3, 0x01, 0x50, 0xF2, 0xD0, 0x00, 0xF0, 0x02})
+
+	f.Fuzz(func(t *testing.T, vr byte, rom []byte) {
+		vm := NewVariant(Variant(int(vr) % len(Variants)))
+		if err := vm.Load(bytes.NewReader(rom)); err != nil {
+			return
+		}
+
+		for c := 0; c < 1000; c++ {
+			if err := vm.Cycle(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package chip8
+
+import "errors"
+
+// errNoHistory is returned by StepBack when there's no instruction to undo.
+var errNoHistory = errors.New("no history to step back through")
+
+// step is what an instruction changed, recorded so it can be undone.
+type step struct {
+	v          [16]byte
+	i          uint32
+	pc, sp     uint16
+	stack      [16]uint16
+	delayTimer byte
+	soundTimer byte
+	cycles     uint64
+	delaySet   uint64
+	halted     bool
+
+	keys    [16]byte
+	down    [16]bool
+	held    [16]int
+	keyWait int
+
+	// The display before the instruction, and its pixels if the instruction
+	// may have drawn.
+	disp *Display
+	px   []byte
+
+	// The bytes of memory written, with their old values, in order.
+	mem []memWrite
+
+	// Restores the core's own state, nil for cores without any.
+	core func()
+
+	// The random numbers CXNN generated.
+	rand []byte
+}
+
+// memWrite is a byte of memory written by an instruction.
+type memWrite struct {
+	addr uint32
+	old  byte
+}
+
+// rewinder is implemented by cores with state of their own, which save
+// returns a func to restore.
+type rewinder interface {
+	save() func()
+}
+
+// history is a ring buffer of the last instructions executed.
+type history struct {
+	steps []step
+	start int
+	n     int
+
+	// The step being recorded by the instruction executing, nil between
+	// instructions.
+	cur *step
+
+	// Random numbers generated by instructions undone, handed out again in
+	// order when they're executed again.
+	replay []byte
+}
+
+// EnableHistory records the changes made by the last n instructions, so they
+// can be undone one at a time by StepBack. Recording slows emulation, it's
+// meant for debugging. An n of 0 turns it off.
+func (v *VM) EnableHistory(n int) {
+	if n <= 0 {
+		v.hist = nil
+		return
+	}
+	v.hist = &history{steps: make([]step, n)}
+}
+
+// HistoryLen returns the number of instructions StepBack can undo.
+func (v *VM) HistoryLen() int {
+	if v.hist == nil {
+		return 0
+	}
+	return v.hist.n
+}
+
+// StepBack undoes the last instruction executed, restoring the VM to exactly
+// the state before it. Instructions executed again generate the same random
+// numbers. MegaChip's colour buffer isn't restored.
+func (v *VM) StepBack() error {
+	h := v.hist
+	if h == nil || h.n == 0 {
+		return errNoHistory
+	}
+
+	h.n--
+	s := &h.steps[(h.start+h.n)%len(h.steps)]
+
+	for i := len(s.mem) - 1; i >= 0; i-- {
+		v.mem[s.mem[i].addr] = s.mem[i].old
+	}
+	for i := len(s.rand) - 1; i >= 0; i-- {
+		h.replay = append(h.replay, s.rand[i])
+	}
+
+	v.v, v.i, v.pc, v.sp, v.stack = s.v, s.i, s.pc, s.sp, s.stack
+	v.delayTimer, v.delaySet = s.delayTimer, s.delaySet
+	v.setSound(s.soundTimer)
+	v.cycles, v.halted = s.cycles, s.halted
+	v.keys, v.down, v.held, v.keyWait = s.keys, s.down, s.held, s.keyWait
+	if s.core != nil {
+		s.core()
+	}
+
+	v.disp = s.disp
+	if s.px != nil {
+		copy(v.disp.px, s.px)
+		v.disp.markAll()
+	}
+	v.drew()
+
+	*s = step{}
+	return nil
+}
+
+// record starts recording the changes made by the instruction about to be
+// executed, if history is enabled.
+func (v *VM) record() {
+	h := v.hist
+	if h == nil {
+		return
+	}
+
+	// Reuse the oldest step once the buffer is full.
+	if h.n == len(h.steps) {
+		h.start = (h.start + 1) % len(h.steps)
+		h.n--
+	}
+	s := &h.steps[(h.start+h.n)%len(h.steps)]
+	h.n++
+
+	*s = step{
+		v:          v.v,
+		i:          v.i,
+		pc:         v.pc,
+		sp:         v.sp,
+		stack:      v.stack,
+		delayTimer: v.delayTimer,
+		soundTimer: v.soundTimer,
+		cycles:     v.cycles,
+		delaySet:   v.delaySet,
+		halted:     v.halted,
+		keys:       v.keys,
+		down:       v.down,
+		held:       v.held,
+		keyWait:    v.keyWait,
+		disp:       v.disp,
+		mem:        s.mem[:0],
+		rand:       s.rand[:0],
+	}
+
+	// Every variant draws with 0NNN and DXYN instructions.
+	if op := v.opc >> 12; op == 0x0 || op == 0xD {
+		s.px = append([]byte(nil), v.disp.px...)
+	}
+	if r, ok := v.core.(rewinder); ok {
+		s.core = r.save()
+	}
+	h.cur = s
+}
+
+// store writes b to memory at addr, recording the old value in the history
+// and calling the store hooks.
+func (v *VM) store(addr uint32, b byte) {
+	if v.hist != nil && v.hist.cur != nil {
+		v.hist.cur.mem = append(v.hist.cur.mem, memWrite{addr, v.mem[addr]})
+	}
+	v.mem[addr] = b
+
+	for _, h := range v.storeHooks {
+		h(addr, b)
+	}
+}
+
+// random returns a random byte for CXNN, replaying those of instructions
+// stepped back over first.
+func (v *VM) random() byte {
+	h := v.hist
+	if h == nil {
+		return v.nextRandom()
+	}
+
+	var b byte
+	if n := len(h.replay); n > 0 {
+		b, h.replay = h.replay[n-1], h.replay[:n-1]
+	} else {
+		b = v.nextRandom()
+	}
+	if h.cur != nil {
+		h.cur.rand = append(h.cur.rand, b)
+	}
+	return b
+}
+
+// save returns a func restoring the SUPER-CHIP state.
+func (c *schipCore) save() func() {
+	saved := *c
+	return func() { *c = saved }
+}
+
+// save returns a func restoring the XO-CHIP state.
+func (c *xoChipCore) save() func() {
+	saved := *c
+	return func() { *c = saved }
+}
+
+// save returns a func restoring the MegaChip settings.
+func (c *megaChipCore) save() func() {
+	saved := *c
+	return func() { *c = saved }
+}
@@ -0,0 +1,96 @@
+package chip8
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestStepBack(t *testing.T) {
+	rom := []byte{
+		0x6A, 0x0A, // 0x200: VA = 10
+		0xFA, 0x15, // 0x202: DT = VA
+		0xC0, 0xFF, // 0x204: V0 = random
+		0xA3, 0x00, // 0x206: I = 0x300
+		0xF0, 0x33, // 0x208: BCD of V0 at I
+		0xD0, 0x15, // 0x20A: draw the digits
+		0x22, 0x12, // 0x20C: call 0x212
+		0x12, 0x04, // 0x20E: jump to 0x204
+		0x00, 0x00, // 0x210
+		0x00, 0xE0, // 0x212: clear the display
+		0x00, 0xEE, // 0x214: return
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	v.EnableHistory(100)
+
+	var states []State
+	for i := 0; i < 40; i++ {
+		states = append(states, v.State())
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	end := v.State()
+
+	// Stepping back restores each state in turn.
+	for i := len(states) - 1; i >= 0; i-- {
+		if err := v.StepBack(); err != nil {
+			t.Fatalf("step back to %d: %s", i, err)
+		}
+		if st := v.State(); !reflect.DeepEqual(st, states[i]) {
+			t.Fatalf("expected state %d restored, got PC 0x%03X", i, st.PC)
+		}
+	}
+	if err := v.StepBack(); err == nil {
+		t.Fatal("expected error stepping back before the first instruction")
+	}
+
+	// Running forward again plays out the same, random numbers included.
+	for i := 0; i < 40; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if st := v.State(); !reflect.DeepEqual(st, end) {
+		t.Fatal("expected the same state running forward again")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x70, 0x01, 0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+	v.EnableHistory(3)
+
+	for i := 0; i < 10; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := v.HistoryLen(); n != 3 {
+		t.Fatalf("expected 3 instructions of history, got %d", n)
+	}
+
+	// V0 is incremented by every other instruction, so undoing the last 3
+	// leaves the 4 increments of the first 7.
+	for i := 0; i < 3; i++ {
+		if err := v.StepBack(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v.v[0] != 4 {
+		t.Fatalf("expected V0 of 4, got %d", v.v[0])
+	}
+
+	if err := v.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if n := v.HistoryLen(); n != 0 {
+		t.Fatalf("expected no history after a reset, got %d", n)
+	}
+}
@@ -0,0 +1,65 @@
+package chip8
+
+// keyEvent is a key transition queued by Press or Release.
+type keyEvent struct {
+	key  byte
+	down bool
+}
+
+// Press queues a press of key, applied at the next frame boundary. Unlike
+// KeyDown it's safe to call while the VM is running, and a key pressed and
+// released between two frames is still seen by the program for a frame.
+func (v *VM) Press(key byte) {
+	v.queueKey(key, true)
+}
+
+// Release queues a release of key, applied at the next frame boundary.
+func (v *VM) Release(key byte) {
+	v.queueKey(key, false)
+}
+
+// queueKey queues a transition of key.
+func (v *VM) queueKey(key byte, down bool) {
+	v.inputMu.Lock()
+	v.input = append(v.input, keyEvent{key: key & 0xF, down: down})
+	v.inputMu.Unlock()
+}
+
+// Held returns the number of frames key has been held down for, or 0 if it's
+// up. Only keys pressed with Press are counted.
+func (v *VM) Held(key byte) int {
+	return v.held[key&0xF]
+}
+
+// applyInput applies the queued key transitions at a frame boundary. Only one
+// transition of each key is applied per frame, the rest are left queued, so
+// a tap shorter than a frame is held for one.
+func (v *VM) applyInput() {
+	v.inputMu.Lock()
+	var seen [16]bool
+	rest := v.input[:0]
+	for _, e := range v.input {
+		if seen[e.key] {
+			rest = append(rest, e)
+			continue
+		}
+		seen[e.key] = true
+		v.down[e.key] = e.down
+		if !e.down {
+			v.keys[e.key] = 0
+		}
+	}
+	v.input = rest
+	v.inputMu.Unlock()
+
+	// Held keys are pressed again each frame, as EX9E and EXA1 release the
+	// keys they see.
+	for k, down := range v.down {
+		if down {
+			v.keys[k] = 1
+			v.held[k]++
+		} else {
+			v.held[k] = 0
+		}
+	}
+}
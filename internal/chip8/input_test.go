@@ -0,0 +1,110 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInputTap(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+
+	// A tap between two frames is still seen for a frame.
+	v.Press(3)
+	v.Release(3)
+	if v.keys[3] != 0 {
+		t.Fatal("expected key 3 not to be pressed before the frame")
+	}
+
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if v.keys[3] != 1 || v.Held(3) != 1 {
+		t.Fatalf("expected key 3 to be held for 1 frame, got %d", v.Held(3))
+	}
+
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if v.keys[3] != 0 || v.Held(3) != 0 {
+		t.Fatal("expected key 3 to be released")
+	}
+}
+
+func TestInputHeld(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+
+	v.Press(0xA)
+	for i := 0; i < 3; i++ {
+		if err := v.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+		// Held keys are pressed again after EX9E or EXA1 release them.
+		v.keys[0xA] = 0
+	}
+	if v.Held(0xA) != 3 {
+		t.Fatalf("expected key A to be held for 3 frames, got %d", v.Held(0xA))
+	}
+
+	v.Release(0xA)
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Held(0xA) != 0 {
+		t.Fatalf("expected key A to be released, held for %d frames", v.Held(0xA))
+	}
+}
+
+func TestGetKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyRelease bool
+	}{
+		{name: "press"},
+		{name: "release", keyRelease: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := New()
+			if err := v.Load(bytes.NewReader([]byte{0xF3, 0x0A, 0x12, 0x02})); err != nil {
+				t.Fatal(err)
+			}
+			v.SetQuirks(Quirks{KeyRelease: tc.keyRelease})
+
+			// Nothing happens until a key is pressed.
+			if err := v.Cycle(); err != nil {
+				t.Fatal(err)
+			}
+			if v.PC() != 0x200 {
+				t.Fatalf("expected FX0A to wait for a key, PC is 0x%X", v.PC())
+			}
+
+			v.KeyDown(7)
+			if err := v.Cycle(); err != nil {
+				t.Fatal(err)
+			}
+			if tc.keyRelease {
+				if v.PC() != 0x200 {
+					t.Fatalf("expected FX0A to wait for the key to be released, PC is 0x%X", v.PC())
+				}
+				v.KeyUp(7)
+				if err := v.Cycle(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if v.PC() != 0x202 {
+				t.Fatalf("expected PC to be 0x202, got 0x%X", v.PC())
+			}
+			if v.V(3) != 7 {
+				t.Fatalf("expected V3 to be 7, got %d", v.V(3))
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package chip8
+
+import "time"
+
+// Limits bound how much a VM runs, for servers running untrusted ROMs.
+type Limits struct {
+	// MaxCycles is the most instructions executed since the VM was last
+	// reset, after which Cycle returns ErrCycleLimit. Zero is no limit.
+	MaxCycles uint64
+
+	// Budget is the longest a Cycle or StepFrame may take, which they
+	// return a WatchdogError beyond. An instruction, or a hook it runs,
+	// can't be interrupted, so the budget is checked after each. Zero is no
+	// limit.
+	Budget time.Duration
+}
+
+// SetLimits sets the limits on running the VM. There are none by default.
+func (v *VM) SetLimits(l Limits) {
+	v.limits = l
+}
+
+// Limits returns the limits on running the VM.
+func (v *VM) Limits() Limits {
+	return v.limits
+}
+
+// startBudget returns the time a Cycle or StepFrame started, for checkBudget.
+// It's zero without a budget, saving reading the clock.
+func (v *VM) startBudget() time.Time {
+	if v.limits.Budget <= 0 {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// checkBudget returns a WatchdogError if the time since start is over the
+// budget.
+func (v *VM) checkBudget(start time.Time) error {
+	if v.limits.Budget <= 0 {
+		return nil
+	}
+	if d := time.Since(start); d > v.limits.Budget {
+		return &WatchdogError{Budget: v.limits.Budget, Elapsed: d}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaxCycles(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+	v.SetLimits(Limits{MaxCycles: 5})
+
+	for i := 0; i < 5; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := v.Cycle(); !errors.Is(err, ErrCycleLimit) {
+		t.Fatalf("expected the cycle limit, got %v", err)
+	}
+
+	// The limit counts from the last reset.
+	if err := v.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	rom := []byte{
+		0x03, 0x00, // Call a slow routine.
+		0x12, 0x02, // Halt.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	v.SetLimits(Limits{Budget: time.Millisecond})
+	v.OnSys(0x300, func() error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+
+	var werr *WatchdogError
+	if err := v.Cycle(); !errors.As(err, &werr) {
+		t.Fatalf("expected a watchdog error, got %v", err)
+	}
+	if werr.Budget != time.Millisecond || werr.Elapsed < 5*time.Millisecond {
+		t.Fatalf("expected a 1ms budget and at least 5ms elapsed, got %s and %s", werr.Budget, werr.Elapsed)
+	}
+
+	// Quick instructions are within budget.
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchdogFrame(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each instruction is within budget, the frame isn't.
+	v.SetLimits(Limits{Budget: 3 * time.Millisecond})
+	v.OnInstruction(func(pc, opc uint16) {
+		time.Sleep(time.Millisecond)
+	})
+	if err := v.StepFrame(); !errors.Is(err, ErrWatchdog) {
+		t.Fatalf("expected the watchdog to expire, got %v", err)
+	}
+}
@@ -0,0 +1,281 @@
+package chip8
+
+import "image/color"
+
+const (
+	// MegaDisplayWidth and MegaDisplayHeight are the display resolution in
+	// pixels while MegaChip mode is on.
+	MegaDisplayWidth  = 256
+	MegaDisplayHeight = 192
+
+	// megaMemSize is the amount of memory addressable by MegaChip's 24-bit
+	// index register.
+	megaMemSize = 1 << 24
+)
+
+// megaChipCore is the MegaChip8 extension. While MegaChip mode is on sprites
+// are drawn in colour to a back buffer, which is copied to the display each
+// time the screen is cleared.
+type megaChipCore struct {
+	v *VM
+
+	// Set while MegaChip mode is on, between 0011 and 0010.
+	on bool
+
+	// The buffer drawn to while MegaChip mode is on.
+	back *Display
+
+	// Size in pixels of the colour sprites drawn by DXYN, 0 meaning 256.
+	spriteW, spriteH byte
+
+	// DXYN sets VF when a sprite is drawn over a pixel of this colour.
+	collision byte
+
+	// Screen alpha and sprite blend mode. These are recorded but sprites
+	// are always drawn opaque.
+	alpha, blend byte
+
+	// Colours of the 256 pixel values, shared by both display buffers.
+	palette color.Palette
+}
+
+// reset extends memory to 16MB. MegaChip mode starts off.
+func (c *megaChipCore) reset() {
+	c.v.mem = make([]byte, megaMemSize)
+}
+
+func (c *megaChipCore) load([]byte) {}
+
+// handler returns the handler for the current opcode if MegaChip adds or
+// changes it. Other than 0011, which switches MegaChip mode on, MegaChip
+// instructions are only recognised while the mode is on.
+func (c *megaChipCore) handler() opcodeHandlerFunc {
+	opc := c.v.opc
+	if opc == 0x0011 {
+		return c.megaOn
+	}
+	if !c.on {
+		return nil
+	}
+
+	switch {
+	case opc == 0x00E0:
+		return c.update
+	case opc&0xF000 == 0xD000:
+		return c.draw
+	case opc == 0x0010:
+		return c.megaOff
+	case opc&0xFF00 == 0x0100:
+		return c.setI
+	case opc&0xFF00 == 0x0200:
+		return c.loadPalette
+	case opc&0xFF00 == 0x0300:
+		return c.spriteWidth
+	case opc&0xFF00 == 0x0400:
+		return c.spriteHeight
+	case opc&0xFF00 == 0x0500:
+		return c.setAlpha
+	case opc&0xFFF0 == 0x0600, opc == 0x0700:
+		return c.sound
+	case opc&0xFFF0 == 0x0800:
+		return c.setBlend
+	case opc&0xFF00 == 0x0900:
+		return c.setCollision
+	case opc&0xFFF0 == 0x00B0:
+		return c.scroll(0, -int(opc&0xF))
+	case opc&0xFFF0 == 0x00C0:
+		return c.scroll(0, int(opc&0xF))
+	case opc == 0x00FB:
+		return c.scroll(4, 0)
+	case opc == 0x00FC:
+		return c.scroll(-4, 0)
+	}
+
+	return nil
+}
+
+// instrLen returns 4 for 01NN NNNN while MegaChip mode is on, 2 otherwise.
+func (c *megaChipCore) instrLen(addr uint16) uint16 {
+	if c.on && int(addr) < len(c.v.mem) && c.v.mem[addr] == 0x01 {
+		return 4
+	}
+	return 2
+}
+
+// megaOn switches MegaChip mode on, clearing the screen and changing to the
+// 256x192 colour display.
+func (c *megaChipCore) megaOn() (uint16, error) {
+	v := c.v
+	if !c.on {
+		pal := make(color.Palette, 256)
+		pal[0] = color.Black
+		for i := 1; i < len(pal); i++ {
+			pal[i] = color.White
+		}
+
+		*c = megaChipCore{v: v, on: true, palette: pal}
+		v.disp = NewDisplay(MegaDisplayWidth, MegaDisplayHeight)
+		v.disp.Palette = pal
+		c.back = NewDisplay(MegaDisplayWidth, MegaDisplayHeight)
+		c.back.Palette = pal
+		v.drew()
+	}
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// megaOff switches MegaChip mode off, returning to the monochrome display.
+func (c *megaChipCore) megaOff() (uint16, error) {
+	v := c.v
+	*c = megaChipCore{v: v}
+	v.disp = NewDisplay(DisplayWidth, DisplayHeight)
+	v.drew()
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// setI sets I to the 24-bit address NNNNNN, the low 16 bits of which are the
+// following 2 bytes.
+func (c *megaChipCore) setI() (uint16, error) {
+	v := c.v
+	if err := v.checkMem(uint32(v.pc)+2, 2); err != nil {
+		return v.opc, err
+	}
+
+	v.i = uint32(v.opc&0xFF)<<16 | uint32(v.mem[v.pc+2])<<8 | uint32(v.mem[v.pc+3])
+	v.pc += 4
+
+	return v.opc, nil
+}
+
+// loadPalette loads NN colours, starting at colour 1, from memory at I. Each
+// colour is 4 bytes: alpha, red, green then blue.
+func (c *megaChipCore) loadPalette() (uint16, error) {
+	v := c.v
+	n := int(v.opc & 0xFF)
+	if err := v.checkMem(v.i, n*4); err != nil {
+		return v.opc, err
+	}
+
+	for i := 0; i < n && i+1 < len(c.palette); i++ {
+		b := v.mem[v.i+uint32(i*4):]
+		c.palette[i+1] = color.NRGBA{A: b[0], R: b[1], G: b[2], B: b[3]}
+	}
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// spriteWidth sets the width of colour sprites to NN.
+func (c *megaChipCore) spriteWidth() (uint16, error) {
+	c.spriteW = byte(c.v.opc)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// spriteHeight sets the height of colour sprites to NN.
+func (c *megaChipCore) spriteHeight() (uint16, error) {
+	c.spriteH = byte(c.v.opc)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// setAlpha sets the screen alpha to NN.
+func (c *megaChipCore) setAlpha() (uint16, error) {
+	c.alpha = byte(c.v.opc)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// sound plays (060N) or stops (0700) the digitised sound at I. Digitised sound
+// isn't supported, so both are ignored.
+func (c *megaChipCore) sound() (uint16, error) {
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// setBlend sets the sprite blend mode to N.
+func (c *megaChipCore) setBlend() (uint16, error) {
+	c.blend = byte(c.v.opc & 0xF)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// setCollision sets the collision colour to NN.
+func (c *megaChipCore) setCollision() (uint16, error) {
+	c.collision = byte(c.v.opc)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// scroll returns a handler that scrolls the screen by dx and dy pixels.
+func (c *megaChipCore) scroll(dx, dy int) opcodeHandlerFunc {
+	return func() (uint16, error) {
+		c.back.Scroll(dx, dy)
+		c.v.pc += 2
+
+		return c.v.opc, nil
+	}
+}
+
+// update shows everything drawn since the last update, then clears the screen
+// ready for the next frame. MegaChip is double buffered, 00E0 is how programs
+// present a frame.
+func (c *megaChipCore) update() (uint16, error) {
+	v := c.v
+	v.disp.copyFrom(c.back)
+	c.back.Clear()
+	v.drew()
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// draw draws a colour sprite from I at (VX, VY), using the sprite size set by
+// 03NN and 04NN. VF is set if it draws over the collision colour. Sprites in
+// the font area are drawn as ordinary monochrome sprites, N rows high.
+func (c *megaChipCore) draw() (uint16, error) {
+	var (
+		v = c.v
+		x = int(v.v[(v.opc&0x0F00)>>8])
+		y = int(v.v[(v.opc&0x00F0)>>4])
+	)
+
+	var collided bool
+	if v.i < 0x200 {
+		n := int(v.opc & 0xF)
+		if err := v.checkMem(v.i, n); err != nil {
+			return v.opc, err
+		}
+		collided = c.back.DrawSprite(x, y, v.mem[v.i:v.i+uint32(n)]) > 0
+	} else {
+		w, h := int(c.spriteW), int(c.spriteH)
+		if w == 0 {
+			w = 256
+		}
+		if h == 0 {
+			h = 256
+		}
+		if err := v.checkMem(v.i, w*h); err != nil {
+			return v.opc, err
+		}
+		collided = c.back.DrawIndexed(x, y, w, h, v.mem[v.i:v.i+uint32(w*h)], c.collision)
+	}
+
+	if collided {
+		v.v[0xF] = 1
+	} else {
+		v.v[0xF] = 0
+	}
+	v.pc += 2
+
+	return v.opc, nil
+}
@@ -0,0 +1,79 @@
+package chip8
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestMegaChipMode(t *testing.T) {
+	v := runVariant(t, MegaChip, []byte{0x00, 0x11}, 1)
+	if w, h := v.Display().Width(), v.Display().Height(); w != 256 || h != 192 {
+		t.Fatalf("expected a 256x192 display, got %dx%d", w, h)
+	}
+
+	v = runVariant(t, MegaChip, []byte{0x00, 0x11, 0x00, 0x10}, 2)
+	if w, h := v.Display().Width(), v.Display().Height(); w != 64 || h != 32 {
+		t.Fatalf("expected a 64x32 display, got %dx%d", w, h)
+	}
+}
+
+func TestMegaChipSetI(t *testing.T) {
+	v := runVariant(t, MegaChip, []byte{0x00, 0x11, 0x01, 0x12, 0x34, 0x56}, 2)
+	if v.I() != 0x123456 {
+		t.Fatalf("expected I to be 0x123456, got 0x%X", v.I())
+	}
+	if v.PC() != 0x206 {
+		t.Fatalf("expected PC to be 0x206, got 0x%X", v.PC())
+	}
+}
+
+func TestMegaChipSkip(t *testing.T) {
+	// SE V0, 0 skips the whole of the 4 byte 01NN NNNN.
+	v := runVariant(t, MegaChip, []byte{0x00, 0x11, 0x30, 0x00, 0x01, 0x12, 0x34, 0x56}, 2)
+	if v.PC() != 0x208 {
+		t.Fatalf("expected PC to be 0x208, got 0x%X", v.PC())
+	}
+}
+
+func TestMegaChipDraw(t *testing.T) {
+	rom := []byte{
+		0x00, 0x11, // Mega mode on.
+		0xA2, 0x10, // I = palette.
+		0x02, 0x01, // Load 1 colour.
+		0xA2, 0x14, // I = sprite.
+		0x03, 0x02, // Sprite width 2.
+		0x04, 0x01, // Sprite height 1.
+		0xD0, 0x00, // Draw at (0, 0).
+		0x00, 0xE0, // Update the screen.
+		0xFF, 0x11, 0x22, 0x33, // Palette.
+		0x01, 0x00, // Sprite.
+	}
+
+	v := runVariant(t, MegaChip, rom, 7)
+	if v.Display().Pixel(0, 0) {
+		t.Fatal("expected nothing shown before the screen is updated")
+	}
+
+	v = runVariant(t, MegaChip, rom, 8)
+	d := v.Display()
+	if got := d.Index(0, 0); got != 1 {
+		t.Fatalf("expected pixel (0, 0) to be colour 1, got %d", got)
+	}
+	if d.Pixel(1, 0) {
+		t.Fatal("expected pixel (1, 0) to be transparent")
+	}
+	if exp := (color.NRGBA{A: 0xFF, R: 0x11, G: 0x22, B: 0x33}); d.Palette[1] != exp {
+		t.Fatalf("expected colour 1 to be %v, got %v", exp, d.Palette[1])
+	}
+}
+
+func TestDisplayScroll(t *testing.T) {
+	d := NewDisplay(64, 32)
+	d.DrawSprite(0, 0, []byte{0x80})
+
+	d.Scroll(4, 2)
+	assertLit(t, d, []point{{4, 2}})
+
+	d.Scroll(-8, 0)
+	assertLit(t, d, nil)
+}
@@ -0,0 +1,46 @@
+package chip8
+
+import "github.com/danmrichards/chip8/internal/fonts"
+
+// The fonts are loaded into the memory below 0x200, where the interpreter
+// lived on the original machines: the small font at the conventional 0x50 and
+// the large font straight after it.
+const (
+	FontAddr    = 0x50
+	BigFontAddr = FontAddr + 80
+)
+
+// ProgramAddr is where ROMs are loaded and execution starts.
+const ProgramAddr = 0x200
+
+// Region is a named range of memory, from Start up to but not including End.
+type Region struct {
+	Name       string
+	Start, End uint32
+}
+
+// Regions returns the regions of memory the VM has laid out: the fonts and
+// the program, which runs to the end of memory.
+func (v *VM) Regions() []Region {
+	regions := []Region{{Name: "font", Start: FontAddr, End: FontAddr + uint32(len(v.font))}}
+	if v.bigFont() {
+		regions = append(regions, Region{Name: "big font", Start: BigFontAddr, End: BigFontAddr + uint32(len(fonts.Big))})
+	}
+	return append(regions, Region{Name: "program", Start: ProgramAddr, End: uint32(len(v.mem))})
+}
+
+// SetFont sets the small font loaded for FX29, replacing the one in memory
+// and loaded on resets. The default is fonts.Octo.
+func (v *VM) SetFont(f fonts.Set) {
+	v.font = f
+	copy(v.mem[FontAddr:], v.font[:])
+}
+
+// bigFont returns true if the variant has the large font.
+func (v *VM) bigFont() bool {
+	switch v.core.(type) {
+	case *schipCore, *xoChipCore:
+		return true
+	}
+	return false
+}
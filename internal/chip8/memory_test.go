@@ -0,0 +1,61 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/fonts"
+)
+
+func TestFont(t *testing.T) {
+	rom := []byte{
+		0x60, 0x0A, // V0 = A.
+		0xF0, 0x29, // I = the sprite of A.
+		0x12, 0x04, // Halt.
+	}
+
+	v := runVariant(t, Chip8, rom, 2)
+	if v.I() != FontAddr+50 {
+		t.Fatalf("expected I to be 0x%X, got 0x%X", FontAddr+50, v.I())
+	}
+	if v.Peek(FontAddr+5) != fonts.Octo[5] {
+		t.Fatal("expected the octo font to be loaded")
+	}
+
+	// The font set is kept across resets.
+	v.SetFont(fonts.Classic)
+	if err := v.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range fonts.Classic {
+		if got := v.Peek(FontAddr + uint16(i)); got != b {
+			t.Fatalf("expected 0x%X at 0x%X, got 0x%X", b, FontAddr+i, got)
+		}
+	}
+}
+
+func TestRegions(t *testing.T) {
+	tests := []struct {
+		vr  Variant
+		exp []Region
+	}{
+		{Chip8, []Region{{"font", 0x50, 0xA0}, {"program", 0x200, 0x1000}}},
+		{SChip, []Region{{"font", 0x50, 0xA0}, {"big font", 0xA0, 0x140}, {"program", 0x200, 0x1000}}},
+	}
+
+	for _, tc := range tests {
+		v := NewVariant(tc.vr)
+		if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+			t.Fatal(err)
+		}
+		got := v.Regions()
+		if len(got) != len(tc.exp) {
+			t.Fatalf("%s: expected %v, got %v", tc.vr, tc.exp, got)
+		}
+		for i := range got {
+			if got[i] != tc.exp[i] {
+				t.Fatalf("%s: expected %v, got %v", tc.vr, tc.exp, got)
+			}
+		}
+	}
+}
@@ -1,10 +1,9 @@
 package chip8
 
 import (
-	"errors"
 	"fmt"
-	"log"
-	"math/rand"
+
+	"github.com/danmrichards/chip8/internal/logging"
 )
 
 type opcodeHandler struct {
@@ -97,22 +96,46 @@ func (v *VM) handle() error {
 	// indicates the action to take.
 	h, ok := v.handlers[v.opc&0xF000]
 	if !ok {
-		return fmt.Errorf("unsupported opcode: 0x%X", v.opc)
+		return v.unknownOpcode()
+	}
+
+	// The variant's core takes precedence for opcodes it adds or changes.
+	if f := v.core.handler(); f != nil {
+		h = opcodeHandler{opcode: fmt.Sprintf("%s:%04X", v.variant, v.opc), handler: f}
 	}
 
+	// Remember where the opcode was fetched from, handlers move the program
+	// counter on.
+	pc := v.pc
+
 	// Handle the opcode.
 	val, err := h.handler()
 	if err != nil {
-		return fmt.Errorf("error handling opcode: %s value: 0x%X: %s", h.opcode, val, err)
+		return fmt.Errorf("error handling opcode: %s value: 0x%X: %w", h.opcode, val, err)
 	}
 
-	if v.Debug {
-		log.Printf("opcode: %s value: 0x%X\n", h.opcode, val)
+	if v.Debug && logging.Enabled(logging.Debug) {
+		logging.Debugf("pc: 0x%03X%s opcode: %s value: 0x%X", pc, v.label(pc), h.opcode, val)
 	}
 
 	return nil
 }
 
+// skip moves the program counter past the instruction after the current one.
+// Some variants have 4 byte instructions, which are skipped whole.
+func (v *VM) skip() {
+	v.pc += 2
+	v.pc += v.core.instrLen(v.pc)
+}
+
+// label returns the symbol name for addr, formatted for debug output.
+func (v *VM) label(addr uint16) string {
+	if name := v.Symbols.Describe(addr); name != "" {
+		return " <" + name + ">"
+	}
+	return ""
+}
+
 // handle0x0000 performs additional opcode parsing to determine the correct
 // action. Codes in this range cannot rely on the first 4 bits.
 func (v *VM) handle0x0000() (uint16, error) {
@@ -127,13 +150,13 @@ func (v *VM) handle0x0000() (uint16, error) {
 		return v.callSys()
 
 	default:
-		return v.opc, errors.New("unknown opcode [0x0000]")
+		return v.opc, v.unknownOpcode()
 	}
 }
 
 // clrDisp clears the display.
 func (v *VM) clrDisp() (uint16, error) {
-	v.disp = [64 * 32]byte{}
+	v.disp.Clear()
 	v.pc += 2
 
 	return v.opc & 0x00FF, nil
@@ -141,6 +164,10 @@ func (v *VM) clrDisp() (uint16, error) {
 
 // subRet returns from a subroutine.
 func (v *VM) subRet() (uint16, error) {
+	if v.sp == 0 {
+		return v.opc & 0x00FF, ErrStackUnderflow
+	}
+
 	// Return to the program counter stored in the stack (adding 2 for the
 	// next instruction as usual).
 	v.pc = v.stack[v.sp] + 2
@@ -150,9 +177,19 @@ func (v *VM) subRet() (uint16, error) {
 }
 
 // callSys calls RCA 1802 program at address NNN. Not necessary for most ROMs.
-// Only used on the original computers on which chip8 was implemented. Leaving
-// a noop here just for reference.
+// Only used on the original computers on which chip8 was implemented. The
+// routine can't be run, but one registered with OnSys is called in its place.
+// Without one this is a noop that doesn't advance, leaving the program stuck.
 func (v *VM) callSys() (uint16, error) {
+	f, ok := v.sysHooks[v.opc&0x0FFF]
+	if !ok {
+		return v.opc & 0xF000, nil
+	}
+
+	v.pc += 2
+	if err := f(); err != nil {
+		return v.opc & 0xF000, fmt.Errorf("machine code routine 0x%03X: %w", v.opc&0x0FFF, err)
+	}
 	return v.opc & 0xF000, nil
 }
 
@@ -168,6 +205,9 @@ func (v *VM) jump() (uint16, error) {
 func (v *VM) callSub() (uint16, error) {
 	// Store the current program counter temporarily while we jump to
 	// the subroutine. Incrementing the stack pointer to prevent overwrite.
+	if int(v.sp) >= len(v.stack)-1 {
+		return v.opc, ErrStackOverflow
+	}
 	v.sp++
 	v.stack[v.sp] = v.pc
 
@@ -183,10 +223,9 @@ func (v *VM) skipVxNN() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8 // Reverse the shift.
 	nn := byte(v.opc & 0x00FF) // Get the last 2 chars.
 
-	// Skip the next instruction by increasing the program counter by 4
-	// instead of the usual 2.
+	// Skip the next instruction by moving the program counter past it.
 	if v.v[x] == nn {
-		v.pc += 4
+		v.skip()
 	} else {
 		v.pc += 2
 	}
@@ -200,10 +239,9 @@ func (v *VM) skipVxNotNN() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8 // Reverse the shift.
 	nn := byte(v.opc & 0x00FF) // Get the last 2 chars.
 
-	// Skip the next instruction by increasing the program counter by 4
-	// instead of the usual 2.
+	// Skip the next instruction by moving the program counter past it.
 	if v.v[x] != nn {
-		v.pc += 4
+		v.skip()
 	} else {
 		v.pc += 2
 	}
@@ -215,7 +253,7 @@ func (v *VM) skipVxNotNN() (uint16, error) {
 // instruction is a jump to skip a code block.
 func (v *VM) skipVxVy() (uint16, error) {
 	if v.v[(v.opc&0x0F00)>>8] == v.v[(v.opc&0x00F0)>>4] {
-		v.pc += 4
+		v.skip()
 	} else {
 		v.pc += 2
 	}
@@ -280,7 +318,7 @@ func (v *VM) handle0x8000() (uint16, error) {
 		return v.setVFMostVx(x, y)()
 
 	default:
-		return v.opc & 0xFFFF, errors.New("TODO: handle0x8000")
+		return v.opc & 0xFFFF, v.unknownOpcode()
 	}
 }
 
@@ -294,30 +332,36 @@ func (v *VM) setVxVy(x, y uint16) opcodeHandlerFunc {
 	}
 }
 
-// setVxVxOrVy sets VX to VX or VY (bitwise OR operation).
+// setVxVxOrVy sets VX to VX or VY (bitwise OR operation). With the Logic
+// quirk VF is reset to 0 afterwards.
 func (v *VM) setVxVxOrVy(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
 		v.v[x] |= v.v[y]
+		v.resetFlag()
 		v.pc += 2
 
 		return v.opc & 0xFFFF, nil
 	}
 }
 
-// setVxAndVy sets VX to VX & VY (bitwise AND operation).
+// setVxAndVy sets VX to VX & VY (bitwise AND operation). With the Logic
+// quirk VF is reset to 0 afterwards.
 func (v *VM) setVxAndVy(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
 		v.v[x] &= v.v[y]
+		v.resetFlag()
 		v.pc += 2
 
 		return v.opc & 0xFFFF, nil
 	}
 }
 
-// setVxVxOrVy sets VX to VX xor VY (bitwise XOR operation).
+// setVxVxOrVy sets VX to VX xor VY (bitwise XOR operation). With the Logic
+// quirk VF is reset to 0 afterwards.
 func (v *VM) setVxVxXOrVy(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
 		v.v[x] ^= v.v[y]
+		v.resetFlag()
 		v.pc += 2
 
 		return v.opc & 0xFFFF, nil
@@ -325,15 +369,12 @@ func (v *VM) setVxVxXOrVy(x, y uint16) opcodeHandlerFunc {
 }
 
 // incVxVy adds VY to VX. VF is set to 1 when there's a carry, and to 0 when
-// there isn't.
+// there isn't. VF is set after VX, so the flag is kept when X is F.
 func (v *VM) incVxVy(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
-		if v.v[y] > (0xFF - v.v[x]) {
-			v.v[0xF] = 1
-		} else {
-			v.v[0xF] = 0
-		}
-		v.v[x] += v.v[y]
+		sum := uint16(v.v[x]) + uint16(v.v[y])
+		v.v[x] = byte(sum)
+		v.v[0xF] = byte(sum >> 8)
 
 		v.pc += 2
 
@@ -342,15 +383,15 @@ func (v *VM) incVxVy(x, y uint16) opcodeHandlerFunc {
 }
 
 // decVxVy VY is subtracted from VX. VF is set to 0 when there's a borrow, and 1
-// when there isn't.
+// when there isn't. VF is set after VX, so the flag is kept when X is F.
 func (v *VM) decVxVy(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
-		if v.v[y] > v.v[x] {
-			v.v[0xF] = 0
-		} else {
-			v.v[0xF] = 1
+		var flag byte
+		if v.v[x] >= v.v[y] {
+			flag = 1
 		}
 		v.v[x] -= v.v[y]
+		v.v[0xF] = flag
 
 		v.pc += 2
 
@@ -358,12 +399,14 @@ func (v *VM) decVxVy(x, y uint16) opcodeHandlerFunc {
 	}
 }
 
-// setVFLeastVx stores the least significant bit of VX in VF and then shifts VX
-// to the right by 1.
+// setVFLeastVx sets VX to VY shifted right by 1 and VF to the bit shifted
+// out. With the Shift quirk VX is shifted in place instead. VF is set after
+// VX, so the flag is kept when X is F.
 func (v *VM) setVFLeastVx(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
-		v.v[x] >>= 1
-		v.v[0xF] = v.v[x] & 1
+		src := v.shiftSource(x, y)
+		v.v[x] = src >> 1
+		v.v[0xF] = src & 1
 
 		v.pc += 2
 
@@ -372,15 +415,15 @@ func (v *VM) setVFLeastVx(x, y uint16) opcodeHandlerFunc {
 }
 
 // setVxVyMinusVx sets VX to VY minus VX. VF is set to 0 when there's a borrow,
-// and 1 when there isn't.
+// and 1 when there isn't. VF is set after VX, so the flag is kept when X is F.
 func (v *VM) setVxVyMinusVx(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
-		v.v[x] = v.v[x] - v.v[y]
-		if v.v[x] > v.v[y] {
-			v.v[0xF] = 0
-		} else {
-			v.v[0xF] = 1
+		var flag byte
+		if v.v[y] >= v.v[x] {
+			flag = 1
 		}
+		v.v[x] = v.v[y] - v.v[x]
+		v.v[0xF] = flag
 
 		v.pc += 2
 
@@ -388,12 +431,14 @@ func (v *VM) setVxVyMinusVx(x, y uint16) opcodeHandlerFunc {
 	}
 }
 
-// setVFMostVx stores the most significant bit of VX in VF and then shifts VX
-// to the left by 1.
+// setVFMostVx sets VX to VY shifted left by 1 and VF to the bit shifted out.
+// With the Shift quirk VX is shifted in place instead. VF is set after VX, so
+// the flag is kept when X is F.
 func (v *VM) setVFMostVx(x, y uint16) opcodeHandlerFunc {
 	return func() (uint16, error) {
-		v.v[x] <<= 1
-		v.v[0xF] = v.v[x] & 7
+		src := v.shiftSource(x, y)
+		v.v[x] = src << 1
+		v.v[0xF] = src >> 7
 
 		v.pc += 2
 
@@ -401,16 +446,32 @@ func (v *VM) setVFMostVx(x, y uint16) opcodeHandlerFunc {
 	}
 }
 
+// resetFlag resets VF to 0 after 8XY1, 8XY2 and 8XY3 with the Logic quirk, as
+// the COSMAC VIP's interpreter did.
+func (v *VM) resetFlag() {
+	if v.Quirks().Logic {
+		v.v[0xF] = 0
+	}
+}
+
+// shiftSource returns the register 8XY6 and 8XYE shift: VY, as the COSMAC VIP
+// did, or VX with the Shift quirk.
+func (v *VM) shiftSource(x, y uint16) byte {
+	if v.Quirks().Shift {
+		return v.v[x]
+	}
+	return v.v[y]
+}
+
 // skipVxNotVy skips the next instruction if VX doesn't equal VY. Usually the
 // next instruction is a jump to skip a code block.
 func (v *VM) skipVxNotVy() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8
 	y := (v.opc & 0x00F0) >> 4
 
-	// Skip the next instruction by increasing the program counter by 4
-	// instead of the usual 2.
+	// Skip the next instruction by moving the program counter past it.
 	if v.v[x] != v.v[y] {
-		v.pc += 4
+		v.skip()
 	} else {
 		v.pc += 2
 	}
@@ -420,7 +481,7 @@ func (v *VM) skipVxNotVy() (uint16, error) {
 
 // setAddress sets the index register to the address NNN.
 func (v *VM) setAddress() (uint16, error) {
-	v.i = v.opc & 0x0FFF
+	v.i = uint32(v.opc & 0x0FFF)
 	v.pc += 2 // Increase by 2 because each instruction is 2 bytes long.
 
 	return v.opc, nil
@@ -440,7 +501,7 @@ func (v *VM) setVxRand() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8 // Reverse the shift.
 	nn := byte(v.opc & 0x00FF) // Get the last 2 chars.
 
-	v.v[x] = byte(rand.Intn(256)) & nn
+	v.v[x] = v.random() & nn
 	v.pc += 2
 
 	return v.opc, nil
@@ -450,36 +511,27 @@ func (v *VM) setVxRand() (uint16, error) {
 // and a height of N pixels. Each row of 8 pixels is read as bit-coded starting
 // from memory location i; i doesn't change after the execution of this
 // instruction. VF is set to 1 if any screen pixels are flipped from set to
-// unset when the sprite is drawn, and to 0 if that doesn't happen.
+// unset when the sprite is drawn, and to 0 if that doesn't happen. Sprites
+// wrap around the edges of the display.
 func (v *VM) draw() (uint16, error) {
 	var (
-		pixel  uint16
-		x      = uint16(v.v[(v.opc&0x0F00)>>8])
-		y      = uint16(v.v[(v.opc&0x00F0)>>4])
+		x      = int(v.v[(v.opc&0x0F00)>>8])
+		y      = int(v.v[(v.opc&0x00F0)>>4])
 		height = v.opc & 0x000F
 	)
-	v.v[0xF] = 0
-
-	for cY := uint16(0); cY < height; cY++ {
-		pixel = uint16(v.mem[v.i+cY])
-		for cX := uint16(0); cX < 8; cX++ {
-			index := x + cX + ((y + cY) * 64)
-			if pixel&(0x80>>cX) == 0 || index >= uint16(len(v.disp)) {
-				continue
-			}
-
-			// If the pixel was already 'lit', set the VF register to 1.
-			// This indicates a collision.
-			if v.disp[index] == 1 {
-				v.v[0xF] = 1
-			}
-
-			// Bitwise XOR to 'flip' the pixel.
-			v.disp[index] ^= 1
-		}
+	if err := v.checkMem(v.i, int(height)); err != nil {
+		return v.opc, err
 	}
 
-	v.drawChan <- struct{}{}
+	// If any pixel was already 'lit', set the VF register to 1. This
+	// indicates a collision.
+	if v.disp.DrawSprite(x, y, v.mem[v.i:v.i+uint32(height)]) > 0 {
+		v.v[0xF] = 1
+	} else {
+		v.v[0xF] = 0
+	}
+
+	v.drew()
 	v.pc += 2
 
 	return v.opc, nil
@@ -494,7 +546,7 @@ func (v *VM) handle0xE000() (uint16, error) {
 	case 0x00A1:
 		return v.skipVxKeyNotPressed()
 	default:
-		return v.opc & 0xFFFF, errors.New("TODO: handle0xF000")
+		return v.opc & 0xFFFF, v.unknownOpcode()
 	}
 }
 
@@ -502,13 +554,12 @@ func (v *VM) handle0xE000() (uint16, error) {
 // pressed. Usually the next instruction is a jump to skip a code block.
 func (v *VM) skipVxKeyPressed() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8
-	k := v.v[x]
+	k := v.v[x] & 0xF // Only the low nibble is used for the key.
 
-	// Skip the next instruction by increasing the program counter by 4
-	// instead of the usual 2.
+	// Skip the next instruction by moving the program counter past it.
 	if v.keys[k] == 1 {
 		v.keys[k] = 0
-		v.pc += 4
+		v.skip()
 	} else {
 		v.pc += 2
 	}
@@ -520,12 +571,11 @@ func (v *VM) skipVxKeyPressed() (uint16, error) {
 // pressed. Usually the next instruction is a jump to skip a code block.
 func (v *VM) skipVxKeyNotPressed() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8
-	k := v.v[x]
+	k := v.v[x] & 0xF // Only the low nibble is used for the key.
 
-	// Skip the next instruction by increasing the program counter by 4
-	// instead of the usual 2.
+	// Skip the next instruction by moving the program counter past it.
 	if v.keys[k] == 0 {
-		v.pc += 4
+		v.skip()
 	} else {
 		v.keys[k] = 0
 		v.pc += 2
@@ -566,11 +616,11 @@ func (v *VM) handle0xF000() (uint16, error) {
 		return v.regLoad()
 
 	default:
-		return v.opc & 0x00FF, errors.New("TODO: handle0xF000")
+		return v.opc & 0x00FF, v.unknownOpcode()
 	}
 }
 
-// getDelayTimer sets the delay timer to VX.
+// getDelayTimer sets VX to the delay timer.
 func (v *VM) getDelayTimer() (uint16, error) {
 	v.v[(v.opc&0x0F00)>>8] = v.delayTimer
 	v.pc += 2
@@ -578,16 +628,33 @@ func (v *VM) getDelayTimer() (uint16, error) {
 	return v.opc & 0xFFFF, nil
 }
 
-// getKey waits for a key press and then stores in VX. Blocking Operation. All
-// instruction halted until next key event.
+// getKey waits for a key press and then stores the key in VX. Blocking
+// Operation. All instruction halted until next key event. With the KeyRelease
+// quirk the key is stored once it's released, so a key held down isn't taken
+// again by the next FX0A.
 func (v *VM) getKey() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8
-	for i := range v.keys {
-		if v.keys[i] == 1 {
-			v.v[x] = v.keys[i]
+
+	if v.keyWait >= 0 {
+		if v.keys[v.keyWait] == 0 {
+			v.v[x] = byte(v.keyWait)
+			v.keyWait = -1
 			v.pc += 2
+		}
+		return v.opc & 0xFFFF, nil
+	}
+
+	for i := range v.keys {
+		if v.keys[i] != 1 {
+			continue
+		}
+		if v.Quirks().KeyRelease {
+			v.keyWait = i
 			break
 		}
+		v.v[x] = byte(i)
+		v.pc += 2
+		break
 	}
 
 	return v.opc & 0xFFFF, nil
@@ -595,15 +662,15 @@ func (v *VM) getKey() (uint16, error) {
 
 // setDelayTimer sets the delay timer to VX.
 func (v *VM) setDelayTimer() (uint16, error) {
-	v.delayTimer = v.v[(v.opc&0x0F00)>>8]
+	v.setDelay(v.v[(v.opc&0x0F00)>>8])
 	v.pc += 2
 
 	return v.opc & 0xFFFF, nil
 }
 
-// setDelayTimer sets the sound timer to VX.
+// setSoundTimer sets the sound timer to VX.
 func (v *VM) setSoundTimer() (uint16, error) {
-	v.soundTimer = v.v[(v.opc&0x0F00)>>8]
+	v.setSound(v.v[(v.opc&0x0F00)>>8])
 	v.pc += 2
 
 	return v.opc & 0xFFFF, nil
@@ -611,16 +678,17 @@ func (v *VM) setSoundTimer() (uint16, error) {
 
 // incIVx adds VX to I.
 func (v *VM) incIVx() (uint16, error) {
-	v.i += uint16(v.v[(v.opc&0x0F00)>>8])
+	v.i += uint32(v.v[(v.opc&0x0F00)>>8])
 	v.pc += 2
 
 	return v.opc & 0xFFFF, nil
 }
 
 // loadFont sets i to the location of the sprite for the character in VX.
-// Characters 0-F (in hexadecimal) are represented by a 4x5 font.
+// Characters 0-F (in hexadecimal) are represented by a 4x5 font, loaded at
+// FontAddr.
 func (v *VM) loadFont() (uint16, error) {
-	v.i = uint16(v.v[(v.opc&0x0F00)>>8]) * 5
+	v.i = FontAddr + uint32(v.v[(v.opc&0x0F00)>>8]&0xF)*5
 	v.pc += 2
 
 	return v.opc & 0xFFFF, nil
@@ -633,10 +701,13 @@ func (v *VM) loadFont() (uint16, error) {
 // in i, the tens digit at location i+1, and the ones digit at location i+2).1
 func (v *VM) setBCD() (uint16, error) {
 	x := (v.opc & 0x0F00) >> 8
+	if err := v.checkMem(v.i, 3); err != nil {
+		return v.opc & 0xFFFF, err
+	}
 
-	v.mem[v.i] = v.v[x] / 100          // Hundreds.
-	v.mem[v.i+1] = (v.v[x] / 10) % 10  // Tens.
-	v.mem[v.i+2] = (v.v[x] % 100) % 10 // Ones.
+	v.store(v.i, v.v[x]/100)        // Hundreds.
+	v.store(v.i+1, (v.v[x]/10)%10)  // Tens.
+	v.store(v.i+2, (v.v[x]%100)%10) // Ones.
 	v.pc += 2
 
 	return v.opc & 0xFFFF, nil
@@ -646,8 +717,12 @@ func (v *VM) setBCD() (uint16, error) {
 // offset from i is increased by 1 for each value written, but i itself is left
 // unmodified.
 func (v *VM) regDump() (uint16, error) {
+	if err := v.checkMem(v.i, int((v.opc&0x0F00)>>8)+1); err != nil {
+		return v.opc & 0xFFFF, err
+	}
+
 	for i := uint16(0); i <= (v.opc&0x0F00)>>8; i++ {
-		v.mem[v.i+i] = v.v[i]
+		v.store(v.i+uint32(i), v.v[i])
 	}
 	v.pc += 2
 
@@ -658,8 +733,12 @@ func (v *VM) regDump() (uint16, error) {
 // offset from i is increased by 1 for each value written, but i itself is left
 // unmodified.
 func (v *VM) regLoad() (uint16, error) {
+	if err := v.checkMem(v.i, int((v.opc&0x0F00)>>8)+1); err != nil {
+		return v.opc & 0xFFFF, err
+	}
+
 	for i := uint16(0); i <= (v.opc&0x0F00)>>8; i++ {
-		v.v[i] = v.mem[v.i+i]
+		v.v[i] = v.mem[v.i+uint32(i)]
 	}
 	v.pc += 2
 
@@ -1,3 +1,161 @@
 package chip8
 
-// TODO: All the tests.
+import (
+	"bytes"
+	"testing"
+)
+
+// runArithmetic executes the single instruction op with V1 set to x and V2 to
+// y, or VF to x when op's X is F, and returns the VM.
+func runArithmetic(t *testing.T, op uint16, x, y byte, q Quirks) *VM {
+	t.Helper()
+
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{byte(op >> 8), byte(op)})); err != nil {
+		t.Fatal(err)
+	}
+	v.SetQuirks(q)
+	v.v[(op>>8)&0xF] = x
+	v.v[(op>>4)&0xF] = y
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestShift(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    uint16
+		x, y  byte
+		shift bool
+		want  byte
+		flag  byte
+	}{
+		{name: "right vy", op: 0x8126, x: 0x10, y: 0x05, want: 0x02, flag: 1},
+		{name: "right vx", op: 0x8126, x: 0x10, y: 0x05, shift: true, want: 0x08, flag: 0},
+		{name: "right vx carry", op: 0x8126, x: 0x11, y: 0x04, shift: true, want: 0x08, flag: 1},
+		{name: "left vy", op: 0x812E, x: 0x01, y: 0x81, want: 0x02, flag: 1},
+		{name: "left vx", op: 0x812E, x: 0x01, y: 0x81, shift: true, want: 0x02, flag: 0},
+		{name: "left vx carry", op: 0x812E, x: 0xC0, y: 0x01, shift: true, want: 0x80, flag: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := runArithmetic(t, tc.op, tc.x, tc.y, Quirks{Shift: tc.shift})
+			if v.V(1) != tc.want {
+				t.Fatalf("expected V1 to be 0x%02X, got 0x%02X", tc.want, v.V(1))
+			}
+			if v.V(0xF) != tc.flag {
+				t.Fatalf("expected VF to be %d, got %d", tc.flag, v.V(0xF))
+			}
+		})
+	}
+}
+
+// TestFlagRegister checks that when VF is the destination of an instruction
+// setting the flag, the flag is what's left in it rather than the result.
+func TestFlagRegister(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    uint16
+		x, y  byte
+		shift bool
+		flag  byte
+	}{
+		{name: "add carry", op: 0x8F14, x: 0xFF, y: 0x02, flag: 1},
+		{name: "add", op: 0x8F14, x: 0x01, y: 0x02, flag: 0},
+		{name: "sub", op: 0x8F15, x: 0x05, y: 0x02, flag: 1},
+		{name: "sub borrow", op: 0x8F15, x: 0x02, y: 0x05, flag: 0},
+		{name: "subn", op: 0x8F17, x: 0x02, y: 0x05, flag: 1},
+		{name: "subn borrow", op: 0x8F17, x: 0x05, y: 0x02, flag: 0},
+		{name: "right vy", op: 0x8F16, x: 0x00, y: 0x03, flag: 1},
+		{name: "right vx", op: 0x8F16, x: 0x03, y: 0x00, shift: true, flag: 1},
+		{name: "left vy", op: 0x8F1E, x: 0x00, y: 0x80, flag: 1},
+		{name: "left vx", op: 0x8F1E, x: 0x40, y: 0x80, shift: true, flag: 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			v := runArithmetic(t, tc.op, tc.x, tc.y, Quirks{Shift: tc.shift})
+			if v.V(0xF) != tc.flag {
+				t.Fatalf("expected VF to be %d, got %d", tc.flag, v.V(0xF))
+			}
+		})
+	}
+}
+
+// TestFlagOperand checks that the flag is worked out from VY before it's
+// overwritten when VY is VF.
+func TestFlagOperand(t *testing.T) {
+	v := runArithmetic(t, 0x81F4, 0xFF, 0x01, Quirks{})
+	if v.V(1) != 0 || v.V(0xF) != 1 {
+		t.Fatalf("expected V1 0 and VF 1, got %d and %d", v.V(1), v.V(0xF))
+	}
+
+	v = runArithmetic(t, 0x81F6, 0x00, 0x03, Quirks{})
+	if v.V(1) != 1 || v.V(0xF) != 1 {
+		t.Fatalf("expected V1 1 and VF 1, got %d and %d", v.V(1), v.V(0xF))
+	}
+}
+
+func TestLogic(t *testing.T) {
+	for _, op := range []uint16{0x8121, 0x8122, 0x8123} {
+		for _, logic := range []bool{false, true} {
+			v := New()
+			if err := v.Load(bytes.NewReader([]byte{byte(op >> 8), byte(op)})); err != nil {
+				t.Fatal(err)
+			}
+			v.SetQuirks(Quirks{Logic: logic})
+			v.v[0xF] = 1
+			if err := v.Cycle(); err != nil {
+				t.Fatal(err)
+			}
+
+			want := byte(1)
+			if logic {
+				want = 0
+			}
+			if v.V(0xF) != want {
+				t.Fatalf("0x%04X with logic %t: expected VF to be %d, got %d", op, logic, want, v.V(0xF))
+			}
+		}
+	}
+}
+
+func TestFineDelay(t *testing.T) {
+	for _, fine := range []bool{false, true} {
+		v := New()
+		// F015: DT = V0; F107: V1 = DT; F207: V2 = DT, a frame later.
+		rom := []byte{0xF0, 0x15, 0xF1, 0x07}
+		for i := 0; i < cyclesPerFrame-1; i++ {
+			rom = append(rom, 0x60, 0x00)
+		}
+		rom = append(rom, 0xF2, 0x07)
+		if err := v.Load(bytes.NewReader(rom)); err != nil {
+			t.Fatal(err)
+		}
+		v.SetQuirks(Quirks{FineDelay: fine})
+		v.v[0] = 1
+
+		// Set the delay timer on the last instruction of a frame.
+		v.cycles = cyclesPerFrame - 1
+		for i := 0; i < cyclesPerFrame+2; i++ {
+			if err := v.Cycle(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Counting down at the frame boundary, the delay is over at once.
+		want := byte(0)
+		if fine {
+			want = 1
+		}
+		if v.V(1) != want {
+			t.Fatalf("fine delay %t: expected FX07 to read %d straight after FX15, got %d", fine, want, v.V(1))
+		}
+		if v.V(2) != 0 {
+			t.Fatalf("fine delay %t: expected FX07 to read 0 a frame after FX15, got %d", fine, v.V(2))
+		}
+	}
+}
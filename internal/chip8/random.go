@@ -0,0 +1,65 @@
+package chip8
+
+import "math/rand"
+
+// Random is a source of the random bytes CXNN generates, see SetRandom.
+type Random interface {
+	// Byte returns the next random byte for v, which generators may read
+	// the memory of.
+	Byte(v *VM) byte
+
+	// Seed restarts the sequence from seed.
+	Seed(seed int64)
+}
+
+// mathRandom is the default source of random bytes, math/rand.
+type mathRandom struct {
+	r *rand.Rand
+}
+
+// Byte implements Random.
+func (m *mathRandom) Byte(*VM) byte {
+	return byte(m.r.Intn(256))
+}
+
+// Seed implements Random.
+func (m *mathRandom) Seed(seed int64) {
+	m.r = rand.New(rand.NewSource(seed))
+}
+
+// vipRandom generates random bytes as the COSMAC VIP interpreter did, used
+// with the VIPRandom quirk. Its state is the VIP's R9 register: for each
+// byte R9 is incremented and its low byte picks a byte of the page at 0x100,
+// where the interpreter itself lived, which is added to the high byte. The
+// sum is both the random byte and the new high byte. The sequence only
+// matches a VIP's with the interpreter in memory, from a memory image.
+type vipRandom struct {
+	r9 uint16
+}
+
+// Byte implements Random.
+func (p *vipRandom) Byte(v *VM) byte {
+	p.r9++
+	b := v.Peek(0x100|p.r9&0xFF) + byte(p.r9>>8)
+	p.r9 = uint16(b)<<8 | p.r9&0xFF
+	return b
+}
+
+// Seed implements Random, setting R9.
+func (p *vipRandom) Seed(seed int64) {
+	p.r9 = uint16(seed)
+}
+
+// SetRandom sets the source of the random bytes CXNN generates in place of
+// math/rand, unless the VIPRandom quirk is set. Seed seeds it.
+func (v *VM) SetRandom(r Random) {
+	v.rand = r
+}
+
+// nextRandom returns the next byte of the random source the quirks pick.
+func (v *VM) nextRandom() byte {
+	if v.Quirks().VIPRandom {
+		return v.vip.Byte(v)
+	}
+	return v.rand.Byte(v)
+}
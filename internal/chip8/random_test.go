@@ -0,0 +1,55 @@
+package chip8
+
+import (
+	"bytes"
+	"testing"
+)
+
+// constRandom is a source of random bytes always returning b.
+type constRandom byte
+
+func (c constRandom) Byte(*VM) byte { return byte(c) }
+func (constRandom) Seed(int64)      {}
+
+func TestSetRandom(t *testing.T) {
+	v := New()
+	v.SetRandom(constRandom(0x5A))
+	if err := v.Load(bytes.NewReader([]byte{0xC0, 0x0F})); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if v.V(0) != 0x0A {
+		t.Fatalf("expected V0 to be 0x0A, got 0x%02X", v.V(0))
+	}
+}
+
+func TestVIPRandom(t *testing.T) {
+	// Three CXFFs, drawing from a page at 0x100 counting up from 0x10.
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0xC0, 0xFF, 0xC1, 0xFF, 0xC2, 0xFF})); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 0x100; i++ {
+		v.Poke(uint16(0x100+i), byte(0x10+i))
+	}
+	q := v.Quirks()
+	q.VIPRandom = true
+	v.SetQuirks(q)
+	v.Seed(0x0203)
+
+	for i := 0; i < 3; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// R9 starts at 0x0203: 0x14+0x02, then 0x15+0x16 and 0x16+0x2B.
+	exp := []byte{0x16, 0x2B, 0x41}
+	for x, b := range exp {
+		if v.V(byte(x)) != b {
+			t.Fatalf("expected V%d to be 0x%02X, got 0x%02X", x, b, v.V(byte(x)))
+		}
+	}
+}
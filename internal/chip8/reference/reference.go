@@ -0,0 +1,319 @@
+// Package reference is a deliberately naive CHIP-8 interpreter, written
+// independently of the chip8 package as one big switch over the instruction
+// set. It exists only to cross-check the VM in differential tests, so it
+// favours obviously correct code over speed or features.
+package reference
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+const (
+	width  = 64
+	height = 32
+
+	// Timers count down once every this many instructions, matching the VM.
+	cyclesPerFrame = chip8.ClockSpeed / chip8.FrameRate
+)
+
+// Machine is the state of the reference interpreter.
+type Machine struct {
+	V     [16]byte
+	I     uint16
+	PC    uint16
+	Mem   [4096]byte
+	Disp  [width * height]byte
+	Keys  [16]bool
+	DT    byte
+	ST    byte
+	Stack []uint16
+
+	cycles int
+}
+
+// New returns a machine with rom loaded at 0x200.
+func New(rom []byte) (*Machine, error) {
+	if len(rom) > 4096-0x200 {
+		return nil, fmt.Errorf("ROM too large")
+	}
+
+	m := &Machine{PC: 0x200}
+	font := chip8.New().State().Mem
+	copy(m.Mem[:0x200], font[:0x200])
+	copy(m.Mem[0x200:], rom)
+
+	return m, nil
+}
+
+// Step executes a single instruction.
+func (m *Machine) Step() error {
+	if int(m.PC)+1 >= len(m.Mem) {
+		return fmt.Errorf("pc out of range")
+	}
+
+	op := uint16(m.Mem[m.PC])<<8 | uint16(m.Mem[m.PC+1])
+	m.PC += 2
+
+	var (
+		x   = op >> 8 & 0xF
+		y   = op >> 4 & 0xF
+		n   = op & 0xF
+		nn  = byte(op)
+		nnn = op & 0xFFF
+	)
+
+	switch {
+	case op == 0x00E0:
+		m.Disp = [width * height]byte{}
+
+	case op == 0x00EE:
+		if len(m.Stack) == 0 {
+			return fmt.Errorf("return with empty stack")
+		}
+		m.PC = m.Stack[len(m.Stack)-1]
+		m.Stack = m.Stack[:len(m.Stack)-1]
+
+	case op>>12 == 0x1:
+		m.PC = nnn
+
+	case op>>12 == 0x2:
+		if len(m.Stack) == 15 {
+			return fmt.Errorf("stack overflow")
+		}
+		m.Stack = append(m.Stack, m.PC)
+		m.PC = nnn
+
+	case op>>12 == 0x3:
+		if m.V[x] == nn {
+			m.PC += 2
+		}
+
+	case op>>12 == 0x4:
+		if m.V[x] != nn {
+			m.PC += 2
+		}
+
+	case op>>12 == 0x5 && n == 0:
+		if m.V[x] == m.V[y] {
+			m.PC += 2
+		}
+
+	case op>>12 == 0x6:
+		m.V[x] = nn
+
+	case op>>12 == 0x7:
+		m.V[x] += nn
+
+	case op>>12 == 0x8:
+		if err := m.alu(x, y, n); err != nil {
+			return err
+		}
+
+	case op>>12 == 0x9 && n == 0:
+		if m.V[x] != m.V[y] {
+			m.PC += 2
+		}
+
+	case op>>12 == 0xA:
+		m.I = nnn
+
+	case op>>12 == 0xB:
+		m.PC = nnn + uint16(m.V[0])
+
+	case op>>12 == 0xC:
+		// Random numbers can't be reproduced, callers copy the result over
+		// from the machine under test.
+		m.V[x] = 0
+
+	case op>>12 == 0xD:
+		if int(m.I)+int(n) > len(m.Mem) {
+			return fmt.Errorf("sprite out of range")
+		}
+		m.V[0xF] = 0
+		for row := 0; row < int(n); row++ {
+			b := m.Mem[int(m.I)+row]
+			for col := 0; col < 8; col++ {
+				if b&(0x80>>uint(col)) == 0 {
+					continue
+				}
+				px := (int(m.V[x])+col)%width + ((int(m.V[y])+row)%height)*width
+				if m.Disp[px] == 1 {
+					m.V[0xF] = 1
+				}
+				m.Disp[px] ^= 1
+			}
+		}
+
+	case op>>12 == 0xE && nn == 0x9E:
+		if m.Keys[m.V[x]&0xF] {
+			m.PC += 2
+		}
+
+	case op>>12 == 0xE && nn == 0xA1:
+		if !m.Keys[m.V[x]&0xF] {
+			m.PC += 2
+		}
+
+	case op>>12 == 0xF:
+		if err := m.misc(x, nn); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unknown opcode 0x%04X", op)
+	}
+
+	m.cycles++
+	if m.cycles%cyclesPerFrame == 0 {
+		if m.DT > 0 {
+			m.DT--
+		}
+		if m.ST > 0 {
+			m.ST--
+		}
+	}
+
+	return nil
+}
+
+// alu executes the 8XYN arithmetic and logic instructions.
+func (m *Machine) alu(x, y, n uint16) error {
+	vx, vy := m.V[x], m.V[y]
+
+	var flag byte
+	switch n {
+	case 0x0:
+		m.V[x] = vy
+		return nil
+	// The logic instructions reset the flag, as the VM does for CHIP-8.
+	case 0x1:
+		m.V[x] = vx | vy
+	case 0x2:
+		m.V[x] = vx & vy
+	case 0x3:
+		m.V[x] = vx ^ vy
+	case 0x4:
+		sum := int(vx) + int(vy)
+		m.V[x] = byte(sum)
+		if sum > 0xFF {
+			flag = 1
+		}
+	case 0x5:
+		m.V[x] = vx - vy
+		if vx >= vy {
+			flag = 1
+		}
+	case 0x6:
+		m.V[x] = vx >> 1
+		flag = vx & 1
+	case 0x7:
+		m.V[x] = vy - vx
+		if vy >= vx {
+			flag = 1
+		}
+	case 0xE:
+		m.V[x] = vx << 1
+		flag = vx >> 7
+	default:
+		return fmt.Errorf("unknown opcode 0x8%X%X%X", x, y, n)
+	}
+
+	// The flag is always written last, so it wins when X is F.
+	m.V[0xF] = flag
+
+	return nil
+}
+
+// misc executes the FXNN instructions.
+func (m *Machine) misc(x uint16, nn byte) error {
+	switch nn {
+	case 0x07:
+		m.V[x] = m.DT
+	case 0x0A:
+		pressed := false
+		for k, down := range m.Keys {
+			if down {
+				m.V[x] = byte(k)
+				pressed = true
+				break
+			}
+		}
+		if !pressed {
+			m.PC -= 2
+		}
+	case 0x15:
+		m.DT = m.V[x]
+	case 0x18:
+		m.ST = m.V[x]
+	case 0x1E:
+		m.I += uint16(m.V[x])
+	case 0x29:
+		m.I = chip8.FontAddr + uint16(m.V[x]&0xF)*5
+	case 0x33:
+		if int(m.I)+3 > len(m.Mem) {
+			return fmt.Errorf("BCD out of range")
+		}
+		m.Mem[m.I] = m.V[x] / 100
+		m.Mem[m.I+1] = m.V[x] / 10 % 10
+		m.Mem[m.I+2] = m.V[x] % 10
+	case 0x55:
+		if int(m.I)+int(x) >= len(m.Mem) {
+			return fmt.Errorf("register dump out of range")
+		}
+		copy(m.Mem[m.I:], m.V[:x+1])
+	case 0x65:
+		if int(m.I)+int(x) >= len(m.Mem) {
+			return fmt.Errorf("register load out of range")
+		}
+		copy(m.V[:x+1], m.Mem[m.I:])
+	default:
+		return fmt.Errorf("unknown opcode 0xF%X%02X", x, nn)
+	}
+
+	return nil
+}
+
+// Diff compares the architecturally visible parts of two states, returning a
+// description of each difference. Stack contents are not compared as
+// implementations are free to store either the call or return address, only
+// the depth is.
+func Diff(got chip8.State, m *Machine) []string {
+	var diffs []string
+
+	for i := range got.V {
+		if got.V[i] != m.V[i] {
+			diffs = append(diffs, fmt.Sprintf("V%X: got 0x%02X, expected 0x%02X", i, got.V[i], m.V[i]))
+		}
+	}
+	if got.I != uint32(m.I) {
+		diffs = append(diffs, fmt.Sprintf("I: got 0x%03X, expected 0x%03X", got.I, m.I))
+	}
+	if got.PC != m.PC {
+		diffs = append(diffs, fmt.Sprintf("PC: got 0x%03X, expected 0x%03X", got.PC, m.PC))
+	}
+	if int(got.SP) != len(m.Stack) {
+		diffs = append(diffs, fmt.Sprintf("stack depth: got %d, expected %d", got.SP, len(m.Stack)))
+	}
+	if got.DelayTimer != m.DT {
+		diffs = append(diffs, fmt.Sprintf("DT: got %d, expected %d", got.DelayTimer, m.DT))
+	}
+	if got.SoundTimer != m.ST {
+		diffs = append(diffs, fmt.Sprintf("ST: got %d, expected %d", got.SoundTimer, m.ST))
+	}
+	for i := range got.Mem {
+		if got.Mem[i] != m.Mem[i] {
+			diffs = append(diffs, fmt.Sprintf("mem 0x%03X: got 0x%02X, expected 0x%02X", i, got.Mem[i], m.Mem[i]))
+			break
+		}
+	}
+	for i := range got.Disp {
+		if got.Disp[i] != m.Disp[i] {
+			diffs = append(diffs, fmt.Sprintf("pixel (%d, %d): got %d, expected %d", i%width, i/width, got.Disp[i], m.Disp[i]))
+			break
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,211 @@
+package chip8
+
+import (
+	"image/color"
+
+	"github.com/danmrichards/chip8/internal/fonts"
+)
+
+const (
+	// SChipDisplayWidth and SChipDisplayHeight are the display resolution in
+	// pixels of the SUPER-CHIP high resolution mode.
+	SChipDisplayWidth  = 128
+	SChipDisplayHeight = 64
+)
+
+// schipCore is SUPER-CHIP 1.1.
+type schipCore struct {
+	v *VM
+
+	// Set while the high resolution mode is on, between 00FF and 00FE.
+	hires bool
+
+	// Set once the program has exited with 00FD.
+	exited bool
+
+	// The user flag registers, saved and loaded with FX75 and FX85.
+	flags []byte
+
+	// How sprites are drawn on new displays, set by variants extending
+	// SUPER-CHIP.
+	clip    bool
+	palette color.Palette
+}
+
+// reset loads the large font and starts in low resolution.
+func (c *schipCore) reset() {
+	c.flags = make([]byte, 8)
+	copy(c.v.mem[BigFontAddr:], fonts.Big[:])
+	c.setRes(false)
+}
+
+func (c *schipCore) load([]byte) {}
+
+// handler returns the handler for the current opcode if SUPER-CHIP adds or
+// changes it.
+func (c *schipCore) handler() opcodeHandlerFunc {
+	opc := c.v.opc
+
+	switch {
+	case opc&0xFFF0 == 0x00C0:
+		return c.scroll(0, int(opc&0xF))
+	case opc == 0x00FB:
+		return c.scroll(4, 0)
+	case opc == 0x00FC:
+		return c.scroll(-4, 0)
+	case opc == 0x00FD:
+		return c.exit
+	case opc == 0x00FE:
+		return c.lores
+	case opc == 0x00FF:
+		return c.hiresOn
+	case opc&0xF000 == 0xB000:
+		return c.jumpVx
+	case opc&0xF000 == 0xD000:
+		return c.draw
+	case opc&0xF0FF == 0xF030:
+		return c.loadBigFont
+	case opc&0xF0FF == 0xF075:
+		return c.saveFlags
+	case opc&0xF0FF == 0xF085:
+		return c.loadFlags
+	}
+
+	return nil
+}
+
+// instrLen returns 2, every instruction is 2 bytes.
+func (c *schipCore) instrLen(addr uint16) uint16 {
+	return 2
+}
+
+// setRes switches to the high or low resolution display, clearing it.
+func (c *schipCore) setRes(hires bool) {
+	c.hires = hires
+	if hires {
+		c.v.disp = NewDisplay(SChipDisplayWidth, SChipDisplayHeight)
+	} else {
+		c.v.disp = NewDisplay(DisplayWidth, DisplayHeight)
+	}
+	c.v.disp.Clip = c.clip
+	c.v.disp.Palette = c.palette
+	c.v.drew()
+}
+
+// scroll returns a handler that scrolls the screen by dx and dy pixels.
+func (c *schipCore) scroll(dx, dy int) opcodeHandlerFunc {
+	return func() (uint16, error) {
+		c.v.disp.Scroll(dx, dy)
+		c.v.drew()
+		c.v.pc += 2
+
+		return c.v.opc, nil
+	}
+}
+
+// exit stops the program. The program counter is left on 00FD, so the VM
+// keeps executing it without doing anything else.
+func (c *schipCore) exit() (uint16, error) {
+	c.exited = true
+
+	return c.v.opc, nil
+}
+
+// lores switches to the 64x32 display.
+func (c *schipCore) lores() (uint16, error) {
+	c.setRes(false)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// hiresOn switches to the 128x64 display.
+func (c *schipCore) hiresOn() (uint16, error) {
+	c.setRes(true)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// jumpVx jumps to the address XNN plus VX.
+func (c *schipCore) jumpVx() (uint16, error) {
+	v := c.v
+	v.pc = v.opc&0x0FFF + uint16(v.v[(v.opc&0x0F00)>>8])
+
+	return v.opc, nil
+}
+
+// draw draws an 8xN sprite, or a 16x16 sprite if N is 0, at (VX, VY). Sprites
+// are clipped at the edges of the display. In high resolution VF is set to
+// the number of rows that collided, otherwise to 1 if any did.
+func (c *schipCore) draw() (uint16, error) {
+	var (
+		v = c.v
+		x = int(v.v[(v.opc&0x0F00)>>8])
+		y = int(v.v[(v.opc&0x00F0)>>4])
+		n = int(v.opc & 0x000F)
+	)
+
+	var collisions int
+	if n == 0 {
+		if err := v.checkMem(v.i, 32); err != nil {
+			return v.opc, err
+		}
+		collisions = v.disp.DrawSprite16(x, y, v.mem[v.i:v.i+32])
+	} else {
+		if err := v.checkMem(v.i, n); err != nil {
+			return v.opc, err
+		}
+		collisions = v.disp.DrawSprite(x, y, v.mem[v.i:v.i+uint32(n)])
+	}
+
+	if !c.hires && collisions > 1 {
+		collisions = 1
+	}
+	v.v[0xF] = byte(collisions)
+
+	v.drew()
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// loadBigFont sets I to the location of the large sprite for the character
+// in VX.
+func (c *schipCore) loadBigFont() (uint16, error) {
+	v := c.v
+	v.i = BigFontAddr + uint32(v.v[(v.opc&0x0F00)>>8]&0xF)*10
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// saveFlags stores V0 to VX in the user flag registers.
+func (c *schipCore) saveFlags() (uint16, error) {
+	x := int(c.v.opc&0x0F00) >> 8
+	if x >= len(c.flags) {
+		return c.v.opc, ErrFlagRange
+	}
+
+	copy(c.flags, c.v.v[:x+1])
+	c.v.pc += 2
+
+	for _, h := range c.v.flagHooks {
+		h(c.v.Flags())
+	}
+
+	return c.v.opc, nil
+}
+
+// loadFlags loads V0 to VX from the user flag registers.
+func (c *schipCore) loadFlags() (uint16, error) {
+	x := int(c.v.opc&0x0F00) >> 8
+	if x >= len(c.flags) {
+		return c.v.opc, ErrFlagRange
+	}
+
+	copy(c.v.v[:x+1], c.flags)
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
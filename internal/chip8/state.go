@@ -0,0 +1,36 @@
+package chip8
+
+// State is a snapshot of the VM's registers, memory and display.
+type State struct {
+	V     [16]byte
+	I     uint32
+	PC    uint16
+	SP    uint16
+	Stack [16]uint16
+	Mem   [4096]byte // The first 4K of memory.
+	Disp  []byte
+
+	DelayTimer byte
+	SoundTimer byte
+
+	// Keys holds the state of the keypad, 1 being pressed.
+	Keys [16]byte
+}
+
+// State returns a snapshot of the current state of the VM.
+func (v *VM) State() State {
+	st := State{
+		V:          v.v,
+		I:          v.i,
+		PC:         v.pc,
+		SP:         v.sp,
+		Stack:      v.stack,
+		Disp:       append([]byte(nil), v.disp.px...),
+		DelayTimer: v.delayTimer,
+		SoundTimer: v.soundTimer,
+		Keys:       v.keys,
+	}
+	copy(st.Mem[:], v.mem)
+
+	return st
+}
@@ -0,0 +1,136 @@
+package chip8
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// StateVersion is the version of the state JSON format written by SaveState.
+const StateVersion = 1
+
+// stateJSON is the state JSON format, documented in the README. Names and
+// units follow Octo where it has an equivalent, so states can be compared
+// with other emulators.
+type stateJSON struct {
+	Version int      `json:"version"`
+	Variant string   `json:"variant"`
+	Quirks  Quirks   `json:"quirks"`
+	PC      uint16   `json:"pc"`
+	I       uint32   `json:"i"`
+	V       [16]byte `json:"v"`
+
+	// Stack holds the return addresses pushed, oldest first.
+	Stack []uint16 `json:"stack"`
+
+	DelayTimer byte     `json:"delayTimer"`
+	SoundTimer byte     `json:"soundTimer"`
+	Keys       [16]bool `json:"keys"`
+
+	// Memory is all of memory, base64 encoded.
+	Memory []byte `json:"memory"`
+
+	Display displayJSON `json:"display"`
+}
+
+// displayJSON is the display in the state JSON format.
+type displayJSON struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	// Pixels holds a byte per pixel in row order, base64 encoded. Each bit is
+	// a bit plane, monochrome displays only use the first.
+	Pixels []byte `json:"pixels"`
+}
+
+// SaveState writes the state of the VM to w as JSON. MegaChip's palette and
+// sprite settings aren't saved.
+func (v *VM) SaveState(w io.Writer) error {
+	st := stateJSON{
+		Version:    StateVersion,
+		Variant:    v.variant.String(),
+		Quirks:     v.Quirks(),
+		PC:         v.pc,
+		V:          v.v,
+		I:          v.i,
+		Stack:      append([]uint16{}, v.stack[1:v.sp+1]...),
+		DelayTimer: v.delayTimer,
+		SoundTimer: v.soundTimer,
+		Memory:     v.mem,
+		Display: displayJSON{
+			Width:  v.disp.w,
+			Height: v.disp.h,
+			Pixels: v.disp.px,
+		},
+	}
+	for k, down := range v.keys {
+		st.Keys[k] = down != 0
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(st)
+}
+
+// LoadState replaces the state of the VM, including its variant, with the
+// JSON state read from r. The quirks are informational, those of the variant
+// are used.
+func (v *VM) LoadState(r io.Reader) error {
+	var st stateJSON
+	if err := json.NewDecoder(r).Decode(&st); err != nil {
+		return fmt.Errorf("decode state: %s", err)
+	}
+	if st.Version != StateVersion {
+		return fmt.Errorf("unsupported state version %d, expected %d", st.Version, StateVersion)
+	}
+
+	vr, err := ParseVariant(st.Variant)
+	if err != nil {
+		return err
+	}
+
+	// Validate everything before touching the VM, so a bad state leaves it
+	// as it was.
+	switch {
+	case len(st.Memory) != vr.MemSize():
+		return fmt.Errorf("%s memory is %d bytes, got %d", vr, vr.MemSize(), len(st.Memory))
+	case len(st.Stack) > len(v.stack)-1:
+		return fmt.Errorf("stack holds %d addresses, at most %d allowed", len(st.Stack), len(v.stack)-1)
+	case st.Display.Width <= 0 || st.Display.Height <= 0:
+		return fmt.Errorf("invalid display size %dx%d", st.Display.Width, st.Display.Height)
+	case len(st.Display.Pixels) != st.Display.Width*st.Display.Height:
+		return fmt.Errorf("display is %dx%d, got %d pixels", st.Display.Width, st.Display.Height, len(st.Display.Pixels))
+	}
+
+	v.variant = vr
+	v.reset()
+
+	copy(v.mem, st.Memory)
+	v.v = st.V
+	v.i = st.I
+	v.pc = st.PC
+	v.sp = uint16(copy(v.stack[1:], st.Stack))
+	v.setDelay(st.DelayTimer)
+	v.setSound(st.SoundTimer)
+	v.SetKeys(st.Keys)
+
+	// Programs may have changed resolution, keep the variant's drawing
+	// settings on the restored display.
+	d := NewDisplay(st.Display.Width, st.Display.Height)
+	d.Clip, d.Palette = v.disp.Clip, v.disp.Palette
+	copy(d.px, st.Display.Pixels)
+	d.markAll()
+	v.disp = d
+
+	switch c := v.core.(type) {
+	case *schipCore:
+		c.hires = d.w == SChipDisplayWidth
+	case *xoChipCore:
+		c.hires = d.w == SChipDisplayWidth
+	}
+	v.drew()
+	v.publish(StateLoadedEvent{})
+
+	return nil
+}
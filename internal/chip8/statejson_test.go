@@ -0,0 +1,83 @@
+package chip8
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveLoadState(t *testing.T) {
+	// Switch to high resolution, call a subroutine that draws the large 0 and
+	// stops.
+	rom := []byte{
+		0x00, 0xFF, // High resolution.
+		0x22, 0x06, // Call 0x206.
+		0x12, 0x04, // Halt.
+		0x60, 0x00, // V0 = 0.
+		0xF0, 0x30, // I = large 0.
+		0xD0, 0x0A, // Draw at (0, 0).
+		0x12, 0x0C, // Halt.
+	}
+
+	v := runVariant(t, SChip, rom, 6)
+	v.SetTimers(30, 20)
+	v.KeyDown(0xA)
+
+	var buf bytes.Buffer
+	if err := v.SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := New()
+	if err := got.LoadState(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Variant() != SChip {
+		t.Fatalf("expected variant %s, got %s", SChip, got.Variant())
+	}
+	if w, h := got.Display().Width(), got.Display().Height(); w != SChipDisplayWidth || h != SChipDisplayHeight {
+		t.Fatalf("expected a %dx%d display, got %dx%d", SChipDisplayWidth, SChipDisplayHeight, w, h)
+	}
+	if !reflect.DeepEqual(got.State(), v.State()) {
+		t.Fatal("expected the loaded state to match the saved state")
+	}
+	if !got.core.(*schipCore).hires {
+		t.Fatal("expected high resolution to be restored")
+	}
+
+	// The restored VM carries on where the saved one left off.
+	if err := got.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if got.PC() != 0x20C {
+		t.Fatalf("expected PC 0x20C, got 0x%03X", got.PC())
+	}
+}
+
+func TestLoadStateErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := New().SaveState(&buf); err != nil {
+		t.Fatal(err)
+	}
+	valid := buf.String()
+
+	tests := map[string]string{
+		"version": strings.Replace(valid, `"version": 1`, `"version": 2`, 1),
+		"variant": strings.Replace(valid, `"variant": "chip8"`, `"variant": "nope"`, 1),
+		"memory":  strings.Replace(valid, `"variant": "chip8"`, `"variant": "xochip"`, 1),
+		"display": strings.Replace(valid, `"width": 64`, `"width": 65`, 1),
+		"json":    "nope",
+	}
+	for name, st := range tests {
+		v := New()
+		v.SetPC(0x300)
+		if err := v.LoadState(strings.NewReader(st)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if v.PC() != 0x300 {
+			t.Errorf("%s: expected the VM to be left unchanged", name)
+		}
+	}
+}
@@ -1,15 +1,182 @@
 package chip8
 
 import (
+	"bytes"
+	"fmt"
+	"image"
 	"io"
 	"io/ioutil"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/danmrichards/chip8/internal/fonts"
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+const (
+	// ClockSpeed is the number of instructions executed per second.
+	ClockSpeed = 300
+
+	// FrameRate is the rate, in Hz, at which the timers count down.
+	FrameRate = 60
+
+	// DisplayWidth and DisplayHeight are the display resolution in pixels.
+	DisplayWidth  = 64
+	DisplayHeight = 32
+
+	// HiResDisplayHeight is the display height of the HiRes variant.
+	HiResDisplayHeight = 64
+
+	// cyclesPerFrame is the number of instructions executed between each
+	// timer update.
+	cyclesPerFrame = ClockSpeed / FrameRate
+
+	// memSize is the amount of memory of the original interpreter, which
+	// variants may extend.
+	memSize = 4096
 )
 
+// Variant is a dialect of the Chip8 instruction set.
+type Variant int
+
+const (
+	// Chip8 is the original Chip8 interpreter.
+	Chip8 Variant = iota
+
+	// MegaChip is the MegaChip8 extension. Programs switch it on with 0011,
+	// giving a 256x192 display with a 256 colour palette, and 24-bit I
+	// addressing up to 16MB of memory.
+	MegaChip
+
+	// HiRes is the two page, 64x64, hi-res Chip8 used by Hi-Res ROMs.
+	// Execution starts at 0x2C0 and 0230 clears the screen.
+	HiRes
+
+	// SChip is SUPER-CHIP 1.1, adding a 128x64 high resolution mode, 16x16
+	// sprites, scrolling, a large font and persistent flag registers.
+	SChip
+
+	// XOChip is Octo's XO-CHIP, extending SUPER-CHIP with 64K of memory, a
+	// second bit plane giving 4 colours, and programmable audio.
+	XOChip
+)
+
+// Variants are the names of the variants, indexed by variant.
+var Variants = []string{"chip8", "megachip", "hires", "schip", "xochip"}
+
+// hiResStart is the first instruction of every Hi-Res ROM, a jump over the
+// interpreter patch they were distributed with.
+var hiResStart = []byte{0x12, 0x60}
+
+// hiResEntry is where execution of Hi-Res ROMs starts.
+const hiResEntry = 0x2C0
+
+// ParseVariant returns the variant called name.
+func ParseVariant(name string) (Variant, error) {
+	for i, n := range Variants {
+		if n == name {
+			return Variant(i), nil
+		}
+	}
+	return Chip8, fmt.Errorf("unknown variant %q, expected one of %q", name, Variants)
+}
+
+// DisplaySize returns the largest display resolution used by the variant.
+func (vr Variant) DisplaySize() (w, h int) {
+	switch vr {
+	case MegaChip:
+		return MegaDisplayWidth, MegaDisplayHeight
+	case HiRes:
+		return DisplayWidth, HiResDisplayHeight
+	case SChip, XOChip:
+		return SChipDisplayWidth, SChipDisplayHeight
+	}
+	return DisplayWidth, DisplayHeight
+}
+
+// MemSize returns the bytes of memory the variant has.
+func (vr Variant) MemSize() int {
+	switch vr {
+	case MegaChip:
+		return megaMemSize
+	case XOChip:
+		return xoMemSize
+	}
+	return memSize
+}
+
+// Quirks are the behaviours in which the variants disagree, named as in Octo.
+type Quirks struct {
+	// Shift is set if 8XY6 and 8XYE shift VX in place, ignoring VY.
+	Shift bool `json:"shift"`
+
+	// Logic is set if 8XY1, 8XY2 and 8XY3 reset VF to 0, as the COSMAC VIP
+	// did.
+	Logic bool `json:"logic"`
+
+	// LoadStore is set if FX55 and FX65 leave I unchanged.
+	LoadStore bool `json:"loadStore"`
+
+	// Jump is set if BNNN jumps to XNN plus VX rather than NNN plus V0.
+	Jump bool `json:"jump"`
+
+	// Clip is set if sprites are clipped at the edges of the display rather
+	// than wrapping.
+	Clip bool `json:"clip"`
+
+	// KeyRelease is set if FX0A waits for the key pressed to be released, as
+	// the COSMAC VIP did, rather than taking it as soon as it's pressed. No
+	// variant sets it, it's chosen with SetQuirks.
+	KeyRelease bool `json:"keyRelease"`
+
+	// VIPRandom is set if CXNN generates the COSMAC VIP interpreter's
+	// sequence of random numbers, which a few old games rely on, rather
+	// than those of the source set with SetRandom. No variant sets it.
+	VIPRandom bool `json:"vipRandom"`
+
+	// FineDelay is set if the delay timer counts down every 60th of a second
+	// from when it's set, rather than at the frame boundaries the sound timer
+	// and display share, so a delay lasts exactly as long as asked and FX07
+	// reads it to the instruction. Music ROMs timing notes with it rely on
+	// that. No variant sets it.
+	FineDelay bool `json:"fineDelay"`
+}
+
+// Quirks returns the quirks of the variant.
+func (vr Variant) Quirks() Quirks {
+	switch vr {
+	case SChip:
+		return Quirks{Shift: true, LoadStore: true, Jump: true, Clip: true}
+	case XOChip:
+		return Quirks{}
+	}
+	return Quirks{Shift: true, Logic: true, LoadStore: true}
+}
+
+// String returns the name of the variant.
+func (vr Variant) String() string {
+	if int(vr) < len(Variants) {
+		return Variants[vr]
+	}
+	return fmt.Sprintf("Variant(%d)", int(vr))
+}
+
 // VM is an implementation of the Chip8 virtual machine.
 type VM struct {
 	Debug bool
 
+	// Symbols, if set, are used to name addresses in debug output.
+	Symbols *symbol.Table
+
+	// The instruction set dialect being emulated, and the core implementing
+	// the parts of it that differ from Chip8.
+	variant Variant
+	core    core
+
+	// The ROM last loaded, reloaded by Reset.
+	rom []byte
+
 	// Stores the current opcode.
 	opc uint16
 
@@ -20,14 +187,16 @@ type VM struct {
 	// 0x000 -> 0x1FF - Chip 8 interpreter (contains font set in emu)
 	// 0x050 -> 0x0A0 - Used for the built in 4x5 pixel font set (0-F)
 	// 0x200 -> 0xFFF - Program ROM and work RAM
-	mem [4096]byte
+	//
+	// MegaChip extends memory to 16MB, addressed by a 24-bit I.
+	mem []byte
 
 	// CPU registers. The Chip 8 has 15 8-bit general purpose registers named
 	// V0,V1...VE. The 16th register (VF) is used  for the 'carry flag'.
 	v [16]byte
 
-	// Index register.
-	i uint16
+	// Index register. Only MegaChip uses more than the low 16 bits.
+	i uint32
 
 	// Program counter.
 	pc uint16
@@ -35,13 +204,13 @@ type VM struct {
 	// Chip8 display resolution is 64x32 pixels in monochrome. Drawing is done
 	// in XOR mode and if a pixel is turned off as a result of drawing, the VF
 	// register is set. This is used for collision detection.
-	disp [64 * 32]byte
+	disp *Display
 
 	// Interrupts and hardware registers. The Chip 8 has none, but there are two
 	// timer registers that count at 60 Hz. When set above zero they will count
 	// down to zero.
 	delayTimer byte // Intended for timing game events, can be set or read.
-	soundTimer byte // Used for sound events. Beep when value is non-zero.
+	soundTimer byte // Used for sound events. Tone sounds while non-zero.
 
 	// The Chip8 instruction set has opcodes that allow the program to jump to
 	// addresses or call subroutines. Consequently we need a stack to remember
@@ -54,119 +223,671 @@ type VM struct {
 	// Chip 8 has a HEX based keypad (0x0-0xF).
 	keys [16]byte
 
-	// Clock will run at 60Hz to keep the cycles at the correct speed.
-	clock *time.Ticker
+	// Key transitions queued by Press and Release, applied at the next frame
+	// boundary. down is the state of each key as last applied and held counts
+	// the frames it has been down for.
+	inputMu sync.Mutex
+	input   []keyEvent
+	down    [16]bool
+	held    [16]int
+
+	// The key FX0A is waiting to be released, or -1 if it isn't waiting.
+	keyWait int
+
+	// Quirks set in place of the variant's, nil if none are.
+	quirks *Quirks
+
+	// The small font loaded at FontAddr.
+	font fonts.Set
+
+	// How ROMs are loaded, see SetLoadOptions.
+	loadOpts LoadOptions
+
+	// The limits on running the VM, see SetLimits.
+	limits Limits
+
+	// The frames pixels stay lit after they're turned off, see SetFlicker.
+	flicker int
+
+	// Counts the cycles executed, the timers are updated every cyclesPerFrame
+	// cycles. Driving the timers from the cycle count rather than the wall
+	// clock keeps emulation deterministic, e.g. when running headless.
+	cycles uint64
+
+	// The cycle the delay timer was last set at, which it counts down from
+	// with the FineDelay quirk.
+	delaySet uint64
+
+	// Set while the timers are frozen, see FreezeTimers.
+	timersFrozen bool
 
 	// Each supported opcode has handler func.
 	handlers map[uint16]opcodeHandler
 
-	// Delivered to when the screen should be drawn.
-	drawChan chan struct{}
+	// Sources of the random numbers generated by CXNN, the VIP's used with
+	// the VIPRandom quirk.
+	rand Random
+	vip  vipRandom
+
+	// The subscribers to the VM's events, see Subscribe.
+	subsMu sync.Mutex
+	subs   []*Subscription
+
+	// The number of times the display has changed, updated atomically.
+	draws uint64
+
+	// Set once the program halts, until it's reset.
+	halted bool
+
+	// Callbacks run before every instruction, at every 60Hz frame, when a
+	// program saves the user flags and when it writes to memory.
+	instrHooks []func(pc, opc uint16)
+	frameHooks []func()
+	flagHooks  []func(flags []byte)
+	storeHooks []func(addr uint32, b byte)
+
+	// Callbacks run before and after every instruction with the state of
+	// the VM.
+	beforeHooks []func(in Instruction, st State)
+	afterHooks  []func(in Instruction, st State)
 
-	// Delivered to when a beep should be made.
-	beepChan chan struct{}
+	// Go stand-ins for machine code routines, by address, called by 0NNN.
+	sysHooks map[uint16]func() error
+
+	// The changes made by the last instructions, if they're being recorded
+	// for StepBack.
+	hist *history
 }
 
+// New returns a new Chip8 VM.
 func New() *VM {
+	return NewVariant(Chip8)
+}
+
+// NewVariant returns a new VM emulating vr.
+func NewVariant(vr Variant) *VM {
 	v := &VM{
-		drawChan: make(chan struct{}),
-		beepChan: make(chan struct{}),
+		variant: vr,
+		rand:    &mathRandom{},
+		font:    fonts.Octo,
 	}
+	v.Seed(time.Now().UnixNano())
 	v.reset()
 
 	return v
 }
 
-// Cycle emulates one clock cycle of the Chip8 CPU.
+// Variant returns the variant being emulated.
+func (v *VM) Variant() Variant {
+	return v.variant
+}
+
+// Quirks returns the quirks the VM is emulating, those of the variant unless
+// others have been set.
+func (v *VM) Quirks() Quirks {
+	if v.quirks != nil {
+		return *v.quirks
+	}
+	return v.variant.Quirks()
+}
+
+// SetQuirks sets the quirks to emulate in place of the variant's, until the
+// VM is reloaded. Only Shift, Logic, KeyRelease, VIPRandom and FineDelay can
+// be changed so far, the others are fixed by the variant.
+func (v *VM) SetQuirks(q Quirks) {
+	v.quirks = &q
+}
+
+// SetFlicker sets the frames pixels stay lit for after a program turns them
+// off, to reduce the flicker of games that erase sprites and draw them again
+// a moment later. One blends the last two frames, more hold pixels on for
+// longer, and zero, the default, shows the display as drawn. The display
+// shown is updated at each frame, the VM itself still sees the pixels drawn.
+func (v *VM) SetFlicker(frames int) {
+	v.flicker = frames
+}
+
+// Seed seeds the random numbers generated by CXNN, so that runs with the same
+// seed and input play out the same way.
+func (v *VM) Seed(seed int64) {
+	v.rand.Seed(seed)
+	v.vip.Seed(seed)
+}
+
+// Cycle emulates one clock cycle of the Chip8 CPU. Errors are published as
+// ErrorEvents too.
 func (v *VM) Cycle() error {
+	start := v.startBudget()
+	err := v.cycle()
+	if err == nil {
+		err = v.checkBudget(start)
+	}
+	if err != nil {
+		v.publish(ErrorEvent{Err: err})
+	}
+	return err
+}
+
+// cycle emulates one clock cycle.
+func (v *VM) cycle() error {
+	if v.limits.MaxCycles > 0 && v.cycles >= v.limits.MaxCycles {
+		return ErrCycleLimit
+	}
+
 	// Set the current opcode. The opcodes are two bytes long so we get two
 	// of them and merge together.
+	if err := v.checkMem(uint32(v.pc), 2); err != nil {
+		return fmt.Errorf("fetching opcode: %w", err)
+	}
 	v.opc = uint16(v.mem[v.pc])<<8 | uint16(v.mem[v.pc+1])
 
+	v.record()
+	if v.hist != nil {
+		defer func() { v.hist.cur = nil }()
+	}
+
+	for _, h := range v.instrHooks {
+		h(v.pc, v.opc)
+	}
+
+	// The instruction and states are only built for the hooks wanting them.
+	var in Instruction
+	if len(v.beforeHooks) > 0 || len(v.afterHooks) > 0 {
+		in = Instruction{Addr: v.pc, Opcode: v.opc, Len: v.core.instrLen(v.pc)}
+	}
+	if len(v.beforeHooks) > 0 {
+		st := v.State()
+		for _, h := range v.beforeHooks {
+			h(in, st)
+		}
+	}
+
 	// Handle the opcode.
 	if err := v.handle(); err != nil {
 		return err
 	}
 
-	// Use the ticker to slow down emulation cycles to a realistic speed.
-	select {
-	case <-v.clock.C:
+	if len(v.afterHooks) > 0 {
+		st := v.State()
+		for _, h := range v.afterHooks {
+			h(in, st)
+		}
+	}
+
+	halted := v.Halted()
+	if halted && !v.halted {
+		v.publish(HaltEvent{})
+	}
+	v.halted = halted
+
+	v.cycles++
+	if v.delayTimer > 0 && !v.timersFrozen && v.Quirks().FineDelay && (v.cycles-v.delaySet)%cyclesPerFrame == 0 {
+		v.delayTimer--
+	}
+	if v.cycles%cyclesPerFrame == 0 {
+		v.applyInput()
 		v.updateTimers()
-	default:
 	}
 
 	return nil
 }
 
-// Load loads the contents of rom into mem.
+// StepFrame executes instructions up to the next 60Hz frame boundary, when
+// the timers count down. It lets callers such as tests and bots drive the VM
+// one frame at a time without a clock.
+func (v *VM) StepFrame() error {
+	start := v.startBudget()
+	for {
+		if err := v.Cycle(); err != nil {
+			return err
+		}
+		if err := v.checkBudget(start); err != nil {
+			v.publish(ErrorEvent{Err: err})
+			return err
+		}
+		if v.cycles%cyclesPerFrame == 0 {
+			return nil
+		}
+	}
+}
+
+// LoadOptions change how Load places a ROM in memory.
+type LoadOptions struct {
+	// Entry is the address execution starts at. If zero it's ProgramAddr, or
+	// the variant's own entry point.
+	Entry uint16
+
+	// Image loads the ROM as an image of memory from address 0, such as a
+	// dump of the whole machine from another emulator, replacing the font
+	// along with the program.
+	Image bool
+}
+
+// SetLoadOptions sets how ROMs are loaded from now on, by Load and by Reset
+// and Reload.
+func (v *VM) SetLoadOptions(o LoadOptions) {
+	v.loadOpts = o
+}
+
+// Load loads the contents of rom into mem. Chip8 ROMs starting with the Hi-Res
+// startup sequence switch the VM to the HiRes variant.
 func (v *VM) Load(rom io.Reader) error {
 	data, err := ioutil.ReadAll(rom)
 	if err != nil {
 		return err
 	}
 
-	// Load byte into mem, offset by 512 bytes (0x200).
-	for i := 0; i < len(data); i++ {
-		v.mem[i+0x200] = data[i]
+	// ROMs are loaded at ProgramAddr, memory images from the start.
+	addr, prog := ProgramAddr, data
+	if v.loadOpts.Image {
+		if len(data) > len(v.mem) {
+			return fmt.Errorf("memory image too large: %d bytes, maximum is %d", len(data), len(v.mem))
+		}
+		addr, prog = 0, nil
+		if len(data) > ProgramAddr {
+			prog = data[ProgramAddr:]
+		}
+	} else if len(data) > len(v.mem)-ProgramAddr {
+		return fmt.Errorf("ROM too large: %d bytes, maximum is %d", len(data), len(v.mem)-ProgramAddr)
 	}
+	if e := int(v.loadOpts.Entry); e != 0 && e+1 >= len(v.mem) {
+		return fmt.Errorf("entry point 0x%03X is outside memory", e)
+	}
+	copy(v.mem[addr:], data)
+
+	if v.variant == Chip8 && bytes.HasPrefix(prog, hiResStart) {
+		v.variant = HiRes
+		v.core = newCore(v.variant, v)
+		v.core.reset()
+	}
+	v.core.load(prog)
+	if v.loadOpts.Entry != 0 {
+		v.pc = v.loadOpts.Entry
+	}
+	v.rom = data
 
 	return nil
 }
 
-// PixelSet returns true if the pixel at i is set.
+// Reset restarts the program, reloading the ROM last loaded. The user flags
+// are kept, as they were on the HP-48.
+func (v *VM) Reset() error {
+	flags := v.Flags()
+	v.reset()
+	v.drew()
+	if v.rom == nil {
+		return nil
+	}
+
+	err := v.Load(bytes.NewReader(v.rom))
+	v.SetFlags(flags)
+	return err
+}
+
+// Reload switches the VM to emulate vr and loads rom in place of the program
+// running. Hooks, subscriptions, load options and the random source are kept,
+// so frontends can swap ROMs without recreating everything holding the VM.
+func (v *VM) Reload(vr Variant, rom io.Reader) error {
+	v.variant = vr
+	v.quirks = nil
+	v.rom = nil
+	v.reset()
+	v.drew()
+
+	return v.Load(rom)
+}
+
+// Halted returns true if the program has stopped: it's jumping to itself, the
+// usual way programs end, or has exited with the SUPER-CHIP 00FD. Jumps are
+// checked anywhere in the variant's memory.
+func (v *VM) Halted() bool {
+	switch c := v.core.(type) {
+	case *schipCore:
+		if c.exited {
+			return true
+		}
+	case *xoChipCore:
+		if c.exited {
+			return true
+		}
+	}
+
+	if int(v.pc)+1 >= len(v.mem) {
+		return false
+	}
+	opc := uint16(v.mem[v.pc])<<8 | uint16(v.mem[v.pc+1])
+
+	// 1NNN only reaches the first 4K, while BNNN's offset by V0 reaches a
+	// little past it, into the memory of the larger variants.
+	switch opc & 0xF000 {
+	case 0x1000:
+		return opc&0x0FFF == v.pc
+	case 0xB000:
+		return uint16(v.v[0])+opc&0x0FFF == v.pc
+	}
+	return false
+}
+
+// PixelSet returns true if the pixel at i is lit. Like Display.Pixel it reads
+// the pixels shown, which lag those drawn with flicker reduction on.
 func (v *VM) PixelSet(i int) bool {
-	return v.disp[i] == 1
+	return v.disp.front()[i] != 0
+}
+
+// Frame returns a copy of the pixels shown, one value per pixel in row order,
+// true being lit. Use Display for its dimensions.
+func (v *VM) Frame() []bool {
+	px := v.disp.front()
+	f := make([]bool, len(px))
+	for i, p := range px {
+		f[i] = p != 0
+	}
+	return f
+}
+
+// Image returns an image of the current display, see Display.Image. Programs
+// changing resolution replace the display, so it should be fetched again
+// after each frame.
+func (v *VM) Image() *image.Paletted {
+	return v.disp.Image()
+}
+
+// Display returns the VM's display.
+func (v *VM) Display() *Display {
+	return v.disp
+}
+
+// Cycles returns the number of cycles executed since the VM was reset.
+func (v *VM) Cycles() uint64 {
+	return v.cycles
 }
 
-// Draw returns a read-only channel indicating when the screen should be drawn.
-func (v *VM) Draw() <-chan struct{} {
-	return v.drawChan
+// Draws returns the number of times the display has changed since the VM was
+// created. It's safe to call while the VM is running. Frontends compare it
+// between the frames they present to count the changes never shown, which
+// matters to games that flicker sprites by drawing them twice.
+func (v *VM) Draws() uint64 {
+	return atomic.LoadUint64(&v.draws)
 }
 
-// Beep returns a read-only channel indicating when a beep should happen.
-func (v *VM) Beep() <-chan struct{} {
-	return v.beepChan
+// drew counts a change to the display and publishes that it should be drawn.
+func (v *VM) drew() {
+	atomic.AddUint64(&v.draws, 1)
+	v.publish(DrawEvent{})
+}
+
+// Flags returns a copy of the user flag registers, saved by SUPER-CHIP and
+// XO-CHIP programs with FX75, or nil if the variant has none.
+func (v *VM) Flags() []byte {
+	if f := v.userFlags(); f != nil {
+		return append([]byte(nil), f...)
+	}
+	return nil
+}
+
+// SetFlags sets the user flag registers, e.g. to restore them from an earlier
+// run. Values beyond the registers of the variant are ignored.
+func (v *VM) SetFlags(flags []byte) {
+	copy(v.userFlags(), flags)
+}
+
+// userFlags returns the user flag registers of variants which have them.
+func (v *VM) userFlags() []byte {
+	switch c := v.core.(type) {
+	case *schipCore:
+		return c.flags
+	case *xoChipCore:
+		return c.flags
+	}
+	return nil
+}
+
+// Pattern returns the waveform a program has set for the tone, if any. Only
+// XO-CHIP programs can set one.
+func (v *VM) Pattern() (pattern [16]byte, ok bool) {
+	if c, isXO := v.core.(*xoChipCore); isXO && c.patternSet {
+		return c.pattern, true
+	}
+	return pattern, false
+}
+
+// SetKeys sets the state of every key at once, true being pressed.
+func (v *VM) SetKeys(keys [16]bool) {
+	for k, down := range keys {
+		if down {
+			v.keys[k] = 1
+		} else {
+			v.keys[k] = 0
+		}
+	}
 }
 
 // KeyDown marks key as pressed.
 func (v *VM) KeyDown(key byte) {
-	v.keys[key] = 1
+	v.keys[key&0xF] = 1
+}
+
+// KeyUp marks key as released.
+func (v *VM) KeyUp(key byte) {
+	v.keys[key&0xF] = 0
+}
+
+// OnInstruction registers f to be called before each instruction is executed
+// with the address and value of the opcode.
+func (v *VM) OnInstruction(f func(pc, opc uint16)) {
+	v.instrHooks = append(v.instrHooks, f)
+}
+
+// Instruction is an instruction executed by the VM.
+type Instruction struct {
+	// Addr is the address of the instruction and Opcode its first two bytes.
+	Addr   uint16
+	Opcode uint16
+
+	// Len is the length of the instruction in bytes, 4 for the XO-CHIP
+	// F000 NNNN.
+	Len uint16
+}
+
+// BeforeInstruction registers f to be called before each instruction is
+// executed, with the instruction and the state of the VM, for plugins such as
+// tracers and coverage tools. Building the state copies memory and the
+// display, hooks only needing the address and opcode should use
+// OnInstruction. Plugins changing the VM, such as cheats, do so through its
+// methods.
+func (v *VM) BeforeInstruction(f func(in Instruction, st State)) {
+	v.beforeHooks = append(v.beforeHooks, f)
+}
+
+// AfterInstruction registers f to be called after each instruction has been
+// executed without error, with the instruction and the state of the VM it
+// left.
+func (v *VM) AfterInstruction(f func(in Instruction, st State)) {
+	v.afterHooks = append(v.afterHooks, f)
+}
+
+// OnFlags registers f to be called with a copy of the user flag registers
+// whenever a program saves them with FX75, so they can be kept across runs as
+// the HP-48 did.
+func (v *VM) OnFlags(f func(flags []byte)) {
+	v.flagHooks = append(v.flagHooks, f)
+}
+
+// OnStore registers f to be called with each byte a program writes to memory,
+// with FX33, FX55 or the XO-CHIP 5XY2, after it's written, for devices mapped
+// into memory.
+func (v *VM) OnStore(f func(addr uint32, b byte)) {
+	v.storeHooks = append(v.storeHooks, f)
+}
+
+// OnSys registers f to be called in place of the machine code routine at addr
+// when a program calls it with 0NNN, so hybrid ROMs calling routines of the
+// original interpreter can be shimmed. f runs after the program counter has
+// moved past the instruction and may change the VM, an error it returns is
+// returned by Cycle.
+func (v *VM) OnSys(addr uint16, f func() error) {
+	if v.sysHooks == nil {
+		v.sysHooks = make(map[uint16]func() error)
+	}
+	v.sysHooks[addr&0x0FFF] = f
+}
+
+// OnFrame registers f to be called at each 60Hz frame, after the timers have
+// been updated.
+func (v *VM) OnFrame(f func()) {
+	v.frameHooks = append(v.frameHooks, f)
+}
+
+// Peek returns the byte of memory at addr.
+func (v *VM) Peek(addr uint16) byte {
+	return v.mem[int(addr)%len(v.mem)]
+}
+
+// Poke sets the byte of memory at addr to b.
+func (v *VM) Poke(addr uint16, b byte) {
+	v.mem[int(addr)%len(v.mem)] = b
+}
+
+// V returns the value of register VX.
+func (v *VM) V(x byte) byte {
+	return v.v[x&0xF]
+}
+
+// SetV sets register VX to b.
+func (v *VM) SetV(x, b byte) {
+	v.v[x&0xF] = b
+}
+
+// I returns the value of the index register.
+func (v *VM) I() uint32 {
+	return v.i
+}
+
+// SetI sets the index register to addr.
+func (v *VM) SetI(addr uint32) {
+	v.i = addr
+}
+
+// PC returns the value of the program counter.
+func (v *VM) PC() uint16 {
+	return v.pc
+}
+
+// SP returns the stack pointer, the depth of subroutine calls that haven't
+// returned.
+func (v *VM) SP() uint16 {
+	return v.sp
+}
+
+// SetPC sets the program counter to addr.
+func (v *VM) SetPC(addr uint16) {
+	v.pc = uint16(int(addr) % len(v.mem))
+}
+
+// Timers returns the current values of the delay and sound timers.
+func (v *VM) Timers() (delay, sound byte) {
+	return v.delayTimer, v.soundTimer
+}
+
+// SetTimers sets the delay and sound timers.
+func (v *VM) SetTimers(delay, sound byte) {
+	v.setDelay(delay)
+	v.setSound(sound)
+}
+
+// FreezeTimers stops the delay and sound timers counting down, or starts
+// them again, so debuggers can hold a program waiting on the delay timer or
+// skip the wait by setting it with SetTimers. Frozen timers stay frozen when
+// the VM is reloaded.
+func (v *VM) FreezeTimers(frozen bool) {
+	v.timersFrozen = frozen
+}
+
+// TimersFrozen returns true if the timers have been frozen with FreezeTimers.
+func (v *VM) TimersFrozen() bool {
+	return v.timersFrozen
+}
+
+// setDelay sets the delay timer, which counts down from now with the
+// FineDelay quirk.
+func (v *VM) setDelay(delay byte) {
+	v.delayTimer, v.delaySet = delay, v.cycles
+}
+
+// setSound sets the sound timer, starting or stopping the tone as needed.
+func (v *VM) setSound(st byte) {
+	switch {
+	case v.soundTimer == 0 && st > 0:
+		v.publish(BeepStartEvent{})
+	case v.soundTimer > 0 && st == 0:
+		v.publish(BeepStopEvent{})
+	}
+	v.soundTimer = st
 }
 
 // updateTimers updates the chip8 timers dispatching any additional events
 // based on the timer values.
 func (v *VM) updateTimers() {
-	if v.delayTimer > 0 {
+	if v.delayTimer > 0 && !v.timersFrozen && !v.Quirks().FineDelay {
 		v.delayTimer--
 	}
-	if v.soundTimer > 0 {
-		if v.soundTimer == 1 {
-			v.beepChan <- struct{}{}
-		}
-		v.soundTimer--
+	if v.soundTimer > 0 && !v.timersFrozen {
+		v.setSound(v.soundTimer - 1)
+	}
+	if v.disp.present(v.flicker) {
+		v.drew()
+	}
+
+	for _, h := range v.frameHooks {
+		h()
 	}
 }
 
 // reset initialises the Chip8 registers and mem.
 func (v *VM) reset() {
-	v.opc = 0                // Reset current opcode.
-	v.mem = [4096]byte{}     // Clear mem
-	v.v = [16]byte{}         // Clear registers V0-VF
-	v.i = 0                  // Reset the index register.
-	v.pc = 0x200             // Program counter starts at 0x200.
-	v.sp = 0                 // Reset the stack pointer.
-	v.disp = [64 * 32]byte{} // Clear display
-	v.stack = [16]uint16{}   // Clear stack
+	v.opc = 0              // Reset current opcode.
+	v.v = [16]byte{}       // Clear registers V0-VF
+	v.i = 0                // Reset the index register.
+	v.pc = 0x200           // Program counter starts at 0x200.
+	v.sp = 0               // Reset the stack pointer.
+	v.stack = [16]uint16{} // Clear stack
+
+	// Clear mem and display, the variant may replace either.
+	v.mem = make([]byte, memSize)
+	v.disp = NewDisplay(DisplayWidth, DisplayHeight)
+	v.core = newCore(v.variant, v)
+	v.core.reset()
 
 	// Load the font set into mem.
-	for i := 0; i < 80; i++ {
-		v.mem[i] = fontset[i]
-	}
+	copy(v.mem[FontAddr:], v.font[:])
 
 	// Reset timers
-	v.delayTimer, v.soundTimer = 0, 0
-
-	v.clock = time.NewTicker(time.Second / 60)
+	v.delayTimer = 0
+	v.setSound(0)
+
+	v.cycles = 0
+	v.halted = false
+
+	v.inputMu.Lock()
+	v.input = nil
+	v.inputMu.Unlock()
+	v.down = [16]bool{}
+	v.held = [16]int{}
+	v.keyWait = -1
+
+	// History from before the reset can't be stepped back through.
+	if v.hist != nil {
+		v.EnableHistory(len(v.hist.steps))
+	}
 
 	v.registerHandlers()
 }
+
+// checkMem returns an error if the n bytes of memory starting at addr are not
+// all addressable.
+func (v *VM) checkMem(addr uint32, n int) error {
+	if int(addr)+n > len(v.mem) {
+		return fmt.Errorf("%w: 0x%X-0x%X", ErrMemOutOfRange, addr, int(addr)+n-1)
+	}
+	return nil
+}
@@ -0,0 +1,476 @@
+package chip8
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLoadDetectsHiRes(t *testing.T) {
+	rom := make([]byte, 0xC6)
+	copy(rom, []byte{0x12, 0x60})
+	copy(rom[0xC0:], []byte{0xA0, 0x50, 0xD0, 0x11, 0x02, 0x30}) // Draw the font then clear.
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Variant() != HiRes {
+		t.Fatalf("expected the %s variant, got %s", HiRes, v.Variant())
+	}
+	if w, h := v.Display().Width(), v.Display().Height(); w != 64 || h != 64 {
+		t.Fatalf("expected a 64x64 display, got %dx%d", w, h)
+	}
+	if v.PC() != 0x2C0 {
+		t.Fatalf("expected PC to be 0x2C0, got 0x%X", v.PC())
+	}
+
+	v.SetV(1, 40)
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Display().Pixel(0, 40) {
+		t.Fatal("expected pixel (0, 40) to be lit")
+	}
+
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	assertLit(t, v.Display(), nil)
+}
+
+func TestLoadChip8(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+	if v.Variant() != Chip8 {
+		t.Fatalf("expected the %s variant, got %s", Chip8, v.Variant())
+	}
+	if v.PC() != 0x200 {
+		t.Fatalf("expected PC to be 0x200, got 0x%X", v.PC())
+	}
+}
+
+func TestLoadEntry(t *testing.T) {
+	v := New()
+	v.SetLoadOptions(LoadOptions{Entry: 0x204})
+	if err := v.Load(bytes.NewReader([]byte{0x00, 0x00, 0x00, 0x00, 0x12, 0x04})); err != nil {
+		t.Fatal(err)
+	}
+	if v.PC() != 0x204 {
+		t.Fatalf("expected PC to be 0x204, got 0x%X", v.PC())
+	}
+
+	// The entry point is kept on reset.
+	if err := v.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if v.PC() != 0x204 || !v.Halted() {
+		t.Fatalf("expected PC to be 0x204 and halted, got 0x%X", v.PC())
+	}
+
+	v.SetLoadOptions(LoadOptions{Entry: 0xFFF})
+	if err := v.Load(bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected an entry point outside memory to fail")
+	}
+}
+
+func TestLoadImage(t *testing.T) {
+	// A dump with its own font, stopped at 0x300 drawing its 0.
+	img := make([]byte, 4096)
+	copy(img[FontAddr:], []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF})
+	copy(img[0x300:], []byte{0xA0, 0x50, 0xD0, 0x05})
+
+	v := New()
+	v.SetLoadOptions(LoadOptions{Entry: 0x300, Image: true})
+	if err := v.Load(bytes.NewReader(img)); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The dump's font, a solid block, is drawn rather than the VM's.
+	for y := 0; y < 5; y++ {
+		for x := 0; x < 8; x++ {
+			if !v.Display().Pixel(x, y) {
+				t.Fatalf("expected pixel (%d, %d) to be lit", x, y)
+			}
+		}
+	}
+
+	if err := v.Load(bytes.NewReader(make([]byte, 4097))); err == nil {
+		t.Fatal("expected an image larger than memory to fail")
+	}
+}
+
+func TestStepFrame(t *testing.T) {
+	// Set V1 while key 1 is pressed.
+	rom := []byte{
+		0x70, 0x01, // V0 += 1.
+		0x62, 0x01, // V2 = 1.
+		0xE2, 0xA1, // Skip if key 1 isn't pressed.
+		0x61, 0x01, // V1 = 1.
+		0x12, 0x00, // Loop.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys [16]bool
+	keys[1] = true
+	v.SetKeys(keys)
+
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Cycles() != ClockSpeed/FrameRate {
+		t.Fatalf("expected %d cycles, got %d", ClockSpeed/FrameRate, v.Cycles())
+	}
+	if v.V(1) != 1 {
+		t.Fatal("expected key 1 to be pressed")
+	}
+}
+
+func TestDraws(t *testing.T) {
+	// Draw a sprite and erase it again, every frame.
+	rom := []byte{
+		0xD0, 0x01, // Draw.
+		0xD0, 0x01, // Erase.
+		0x12, 0x00, // Loop.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	before := v.Draws()
+	for i := 0; i < 3; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := v.Draws() - before; n != 2 {
+		t.Fatalf("expected 2 draws, got %d", n)
+	}
+}
+
+func TestFrame(t *testing.T) {
+	v := New()
+	v.Display().DrawSprite(1, 2, []byte{0x80})
+
+	f := v.Frame()
+	if len(f) != DisplayWidth*DisplayHeight {
+		t.Fatalf("expected %d pixels, got %d", DisplayWidth*DisplayHeight, len(f))
+	}
+	for i, lit := range f {
+		if lit != (i == 2*DisplayWidth+1) {
+			t.Fatalf("unexpected pixel %d: %v", i, lit)
+		}
+	}
+}
+
+func TestMemSize(t *testing.T) {
+	for i, name := range Variants {
+		vr := Variant(i)
+		if n := len(NewVariant(vr).mem); vr.MemSize() != n {
+			t.Errorf("%s: expected %d bytes of memory, got %d", name, n, vr.MemSize())
+		}
+	}
+}
+
+func TestFrameFlicker(t *testing.T) {
+	v := New()
+	v.SetFlicker(2)
+
+	// Draw a pixel and show it, then erase it. It stays lit in the frame
+	// until it has been off for 2 frames.
+	v.Display().DrawSprite(0, 0, []byte{0x80})
+	v.updateTimers()
+	v.Display().DrawSprite(0, 0, []byte{0x80})
+
+	for i := 0; i < 3; i++ {
+		if !v.Frame()[0] || !v.PixelSet(0) {
+			t.Fatalf("frame %d: expected the erased pixel to stay lit", i)
+		}
+		v.updateTimers()
+	}
+	if v.Frame()[0] || v.PixelSet(0) {
+		t.Fatal("expected the erased pixel to go out")
+	}
+}
+
+func TestHaltedAndReset(t *testing.T) {
+	rom := []byte{
+		0x70, 0x01, // V0 += 1.
+		0x12, 0x02, // Jump to self.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	if v.Halted() {
+		t.Fatal("expected the VM not to be halted before running")
+	}
+
+	for n := 0; n < 3; n++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !v.Halted() {
+		t.Fatal("expected the VM to be halted on a jump to self")
+	}
+
+	if err := v.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Halted() || v.PC() != 0x200 || v.V(0) != 0 {
+		t.Fatalf("expected a fresh VM, got PC 0x%03X, V0 %d", v.PC(), v.V(0))
+	}
+	if v.Peek(0x202) != 0x12 {
+		t.Fatal("expected the ROM to be reloaded")
+	}
+}
+
+func TestHaltedExit(t *testing.T) {
+	v := NewVariant(SChip)
+	if err := v.Load(bytes.NewReader([]byte{0x00, 0xFD})); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if !v.Halted() {
+		t.Fatal("expected the VM to be halted after 00FD")
+	}
+}
+
+func TestHaltedHigh(t *testing.T) {
+	v := NewVariant(XOChip)
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+
+	// BF11 at 0x1010 with V0 = 0xFF jumps to itself, above 4K.
+	v.Poke(0x1010, 0xBF)
+	v.Poke(0x1011, 0x11)
+	v.SetV(0, 0xFF)
+	v.SetPC(0x1010)
+	if !v.Halted() {
+		t.Fatal("expected a jump to itself above 4K to halt")
+	}
+
+	// 1010 at 0x1010 jumps back to 0x010, not to itself.
+	v.Poke(0x1010, 0x10)
+	v.Poke(0x1011, 0x10)
+	if v.Halted() {
+		t.Fatal("expected 1NNN above 4K not to halt")
+	}
+}
+
+func TestHaltEvent(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x60, 0x01, 0x61, 0x01, 0x12, 0x04})); err != nil {
+		t.Fatal(err)
+	}
+	sub := v.Subscribe(4)
+	defer sub.Close()
+
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("expected no event while running, got %#v", e)
+	default:
+	}
+
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-sub.Events():
+		if _, ok := e.(HaltEvent); !ok {
+			t.Fatalf("expected a halt event, got %#v", e)
+		}
+	default:
+		t.Fatal("expected a halt event")
+	}
+
+	// The event is published once, not on every cycle spent halted.
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-sub.Events():
+		t.Fatalf("expected a single halt event, got %#v", e)
+	default:
+	}
+}
+
+func TestReload(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x60, 0x05})); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+
+	var frames int
+	v.OnFrame(func() { frames++ })
+
+	if err := v.Reload(SChip, bytes.NewReader([]byte{0x00, 0xFF})); err != nil {
+		t.Fatal(err)
+	}
+	if v.Variant() != SChip || v.PC() != 0x200 || v.V(0) != 0 {
+		t.Fatalf("expected a fresh SCHIP VM, got %s at 0x%03X", v.Variant(), v.PC())
+	}
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := v.Variant().DisplaySize(); v.Display().Width() != w {
+		t.Fatal("expected the new ROM to switch to hires")
+	}
+	if frames != 1 {
+		t.Fatalf("expected the frame hook to be kept, ran %d times", frames)
+	}
+}
+
+func TestOnSys(t *testing.T) {
+	rom := []byte{
+		0x03, 0x00, // Call the routine at 0x300.
+		0x03, 0x10, // Call the routine at 0x310.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	v.OnSys(0x300, func() error {
+		calls++
+		v.SetV(0, 0x42)
+		return nil
+	})
+	v.OnSys(0x310, func() error {
+		return errors.New("boom")
+	})
+
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 || v.V(0) != 0x42 {
+		t.Fatalf("expected the routine to be called once, got %d calls", calls)
+	}
+	if v.PC() != 0x202 {
+		t.Fatalf("expected PC to be 0x202, got 0x%X", v.PC())
+	}
+
+	if err := v.Cycle(); err == nil {
+		t.Fatal("expected the routine's error")
+	}
+}
+
+func TestFreezeTimers(t *testing.T) {
+	v := New()
+	if err := v.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+	v.SetTimers(10, 10)
+
+	v.FreezeTimers(true)
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if dt, st := v.Timers(); dt != 10 || st != 10 {
+		t.Fatalf("expected frozen timers to stay at 10, got %d and %d", dt, st)
+	}
+
+	v.FreezeTimers(false)
+	if err := v.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if dt, st := v.Timers(); dt != 9 || st != 9 {
+		t.Fatalf("expected the timers to count down to 9, got %d and %d", dt, st)
+	}
+}
+
+func TestOnStore(t *testing.T) {
+	rom := []byte{
+		0x60, 0x01, // V0 = 1.
+		0x61, 0x02, // V1 = 2.
+		0xAF, 0x00, // I = 0xF00.
+		0xF1, 0x55, // Store V0 and V1.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	var stored []uint32
+	v.OnStore(func(addr uint32, b byte) {
+		if v.Peek(uint16(addr)) != b {
+			t.Errorf("expected 0x%03X to be written before the hook", addr)
+		}
+		stored = append(stored, addr)
+	})
+	for i := 0; i < 4; i++ {
+		if err := v.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(stored) != 2 || stored[0] != 0xF00 || stored[1] != 0xF01 {
+		t.Fatalf("expected writes to 0xF00 and 0xF01, got %X", stored)
+	}
+}
+
+func TestInstructionHooks(t *testing.T) {
+	rom := []byte{
+		0x60, 0x07, // V0 = 7.
+		0x12, 0x02, // Halt.
+	}
+
+	v := New()
+	if err := v.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	var before, after []byte
+	v.BeforeInstruction(func(in Instruction, st State) {
+		if in.Addr != st.PC {
+			t.Errorf("expected the instruction at 0x%X, got 0x%X", st.PC, in.Addr)
+		}
+		before = append(before, st.V[0])
+	})
+	v.AfterInstruction(func(in Instruction, st State) {
+		if in.Len != 2 {
+			t.Errorf("expected a 2 byte instruction, got %d", in.Len)
+		}
+		after = append(after, st.V[0])
+	})
+
+	if err := v.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+	if len(before) != 1 || before[0] != 0 {
+		t.Fatalf("expected V0 to be 0 before, got %v", before)
+	}
+	if len(after) != 1 || after[0] != 7 {
+		t.Fatalf("expected V0 to be 7 after, got %v", after)
+	}
+}
@@ -0,0 +1,301 @@
+package chip8
+
+import "image/color"
+
+// xoMemSize is the amount of memory addressable by XO-CHIP.
+const xoMemSize = 1 << 16
+
+// xoPalette is the colour of each combination of the two bit planes.
+var xoPalette = color.Palette{
+	color.Black,
+	color.RGBA{R: 0x24, G: 0xCC, B: 0x42, A: 0xFF},
+	color.RGBA{R: 0xFF, G: 0x66, B: 0x00, A: 0xFF},
+	color.RGBA{R: 0xFF, G: 0xCC, B: 0x00, A: 0xFF},
+}
+
+// xoChipCore is Octo's XO-CHIP. It extends SUPER-CHIP, though sprites wrap
+// and BNNN, the shifts and register loads and stores behave as in Chip8.
+type xoChipCore struct {
+	schipCore
+
+	// The bit planes drawn to, cleared and scrolled, selected by FN01.
+	planes byte
+
+	// The audio waveform loaded by F002, and its playback pitch set by FX3A.
+	pattern    [16]byte
+	patternSet bool
+	pitch      byte
+}
+
+// reset extends memory to 64K and selects the first bit plane.
+func (c *xoChipCore) reset() {
+	c.v.mem = make([]byte, xoMemSize)
+	c.schipCore.reset()
+	c.flags = make([]byte, 16)
+	c.planes = 1
+	c.pitch = 64
+}
+
+// handler returns the handler for the current opcode if XO-CHIP adds or
+// changes it.
+func (c *xoChipCore) handler() opcodeHandlerFunc {
+	opc := c.v.opc
+
+	switch {
+	case opc == 0x00E0:
+		return c.clear
+	case opc&0xFFF0 == 0x00C0:
+		return c.scroll(0, int(opc&0xF))
+	case opc&0xFFF0 == 0x00D0:
+		return c.scroll(0, -int(opc&0xF))
+	case opc == 0x00FB:
+		return c.scroll(4, 0)
+	case opc == 0x00FC:
+		return c.scroll(-4, 0)
+	case opc&0xF00F == 0x5002:
+		return c.saveRange
+	case opc&0xF00F == 0x5003:
+		return c.loadRange
+	case opc&0xF00F == 0x8006:
+		return c.shiftRight
+	case opc&0xF00F == 0x800E:
+		return c.shiftLeft
+	case opc&0xF000 == 0xB000:
+		// Unlike SUPER-CHIP, jumps are relative to V0.
+		return nil
+	case opc&0xF000 == 0xD000:
+		return c.draw
+	case opc == 0xF000:
+		return c.setI
+	case opc&0xF0FF == 0xF001:
+		return c.selectPlanes
+	case opc == 0xF002:
+		return c.loadPattern
+	case opc&0xF0FF == 0xF03A:
+		return c.setPitch
+	case opc&0xF0FF == 0xF055:
+		return c.regDump
+	case opc&0xF0FF == 0xF065:
+		return c.regLoad
+	}
+
+	return c.schipCore.handler()
+}
+
+// instrLen returns 4 for F000 NNNN, 2 otherwise.
+func (c *xoChipCore) instrLen(addr uint16) uint16 {
+	if int(addr)+1 < len(c.v.mem) && c.v.mem[addr] == 0xF0 && c.v.mem[addr+1] == 0x00 {
+		return 4
+	}
+	return 2
+}
+
+// clear clears the selected bit planes.
+func (c *xoChipCore) clear() (uint16, error) {
+	c.v.disp.clearPlanes(c.planes)
+	c.v.drew()
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// scroll returns a handler that scrolls the selected bit planes by dx and dy
+// pixels.
+func (c *xoChipCore) scroll(dx, dy int) opcodeHandlerFunc {
+	return func() (uint16, error) {
+		c.v.disp.scrollPlanes(dx, dy, c.planes)
+		c.v.drew()
+		c.v.pc += 2
+
+		return c.v.opc, nil
+	}
+}
+
+// regRange returns the registers X to Y of the current opcode, in the order
+// given, which may be descending.
+func (c *xoChipCore) regRange() []uint16 {
+	x := (c.v.opc & 0x0F00) >> 8
+	y := (c.v.opc & 0x00F0) >> 4
+
+	var regs []uint16
+	for r := x; ; {
+		regs = append(regs, r)
+		if r == y {
+			return regs
+		}
+		if x < y {
+			r++
+		} else {
+			r--
+		}
+	}
+}
+
+// saveRange stores VX to VY in memory starting at I, leaving I unchanged.
+func (c *xoChipCore) saveRange() (uint16, error) {
+	v := c.v
+	regs := c.regRange()
+	if err := v.checkMem(v.i, len(regs)); err != nil {
+		return v.opc, err
+	}
+
+	for n, r := range regs {
+		v.store(v.i+uint32(n), v.v[r])
+	}
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// loadRange loads VX to VY from memory starting at I, leaving I unchanged.
+func (c *xoChipCore) loadRange() (uint16, error) {
+	v := c.v
+	regs := c.regRange()
+	if err := v.checkMem(v.i, len(regs)); err != nil {
+		return v.opc, err
+	}
+
+	for n, r := range regs {
+		v.v[r] = v.mem[v.i+uint32(n)]
+	}
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// shiftRight sets VX to VY shifted right by 1, storing the bit shifted out in
+// VF.
+func (c *xoChipCore) shiftRight() (uint16, error) {
+	v := c.v
+	vy := v.v[(v.opc&0x00F0)>>4]
+	v.v[(v.opc&0x0F00)>>8] = vy >> 1
+	v.v[0xF] = vy & 1
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// shiftLeft sets VX to VY shifted left by 1, storing the bit shifted out in
+// VF.
+func (c *xoChipCore) shiftLeft() (uint16, error) {
+	v := c.v
+	vy := v.v[(v.opc&0x00F0)>>4]
+	v.v[(v.opc&0x0F00)>>8] = vy << 1
+	v.v[0xF] = vy >> 7
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// draw draws an 8xN sprite, or a 16x16 sprite if N is 0, at (VX, VY) to each
+// selected bit plane. The sprite data for each plane follows the last. VF is
+// set to 1 if any pixel was turned off.
+func (c *xoChipCore) draw() (uint16, error) {
+	var (
+		v    = c.v
+		x    = int(v.v[(v.opc&0x0F00)>>8])
+		y    = int(v.v[(v.opc&0x00F0)>>4])
+		n    = int(v.opc & 0x000F)
+		wide = n == 0
+	)
+	if wide {
+		n = 32
+	}
+
+	planes := 0
+	for p := byte(1); p <= 2; p <<= 1 {
+		if c.planes&p != 0 {
+			planes++
+		}
+	}
+	if err := v.checkMem(v.i, n*planes); err != nil {
+		return v.opc, err
+	}
+
+	addr := v.i
+	v.v[0xF] = 0
+	for p := byte(1); p <= 2; p <<= 1 {
+		if c.planes&p == 0 {
+			continue
+		}
+		if v.disp.blit(x, y, spriteRows(v.mem[addr:addr+uint32(n)], wide), p) > 0 {
+			v.v[0xF] = 1
+		}
+		addr += uint32(n)
+	}
+
+	v.drew()
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// setI sets I to the 16-bit address in the following 2 bytes.
+func (c *xoChipCore) setI() (uint16, error) {
+	v := c.v
+	if err := v.checkMem(uint32(v.pc)+2, 2); err != nil {
+		return v.opc, err
+	}
+
+	v.i = uint32(v.mem[v.pc+2])<<8 | uint32(v.mem[v.pc+3])
+	v.pc += 4
+
+	return v.opc, nil
+}
+
+// selectPlanes selects the bit planes in N for drawing, clearing and
+// scrolling.
+func (c *xoChipCore) selectPlanes() (uint16, error) {
+	c.planes = byte(c.v.opc>>8) & 0x3
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// loadPattern loads the 16 byte audio waveform from memory at I.
+func (c *xoChipCore) loadPattern() (uint16, error) {
+	v := c.v
+	if err := v.checkMem(v.i, 16); err != nil {
+		return v.opc, err
+	}
+
+	copy(c.pattern[:], v.mem[v.i:])
+	c.patternSet = true
+	v.pc += 2
+
+	return v.opc, nil
+}
+
+// setPitch sets the audio playback pitch to VX.
+func (c *xoChipCore) setPitch() (uint16, error) {
+	c.pitch = c.v.v[(c.v.opc&0x0F00)>>8]
+	c.v.pc += 2
+
+	return c.v.opc, nil
+}
+
+// regDump stores V0 to VX in memory starting at I, leaving I pointing after
+// the last.
+func (c *xoChipCore) regDump() (uint16, error) {
+	v := c.v
+	x := uint32(v.opc&0x0F00) >> 8
+	if _, err := v.regDump(); err != nil {
+		return v.opc, err
+	}
+	v.i += x + 1
+
+	return v.opc, nil
+}
+
+// regLoad loads V0 to VX from memory starting at I, leaving I pointing after
+// the last.
+func (c *xoChipCore) regLoad() (uint16, error) {
+	v := c.v
+	x := uint32(v.opc&0x0F00) >> 8
+	if _, err := v.regLoad(); err != nil {
+		return v.opc, err
+	}
+	v.i += x + 1
+
+	return v.opc, nil
+}
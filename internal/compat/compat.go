@@ -0,0 +1,208 @@
+// Package compat runs ROMs headlessly for a bounded time and reports how each
+// fared, whether it hit an opcode the emulator doesn't support, crashed,
+// halted or drew anything, as a compatibility table across a ROM collection.
+package compat
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Status is how a ROM fared.
+type Status int
+
+// The statuses, from best to worst.
+const (
+	// OK ran for the whole time and drew to the display.
+	OK Status = iota
+
+	// Halted stopped, jumping to itself or exiting, after drawing.
+	Halted
+
+	// Blank ran without error but never drew to the display.
+	Blank
+
+	// Unsupported hit an opcode the emulator doesn't support.
+	Unsupported
+
+	// Crashed stopped with any other error.
+	Crashed
+)
+
+// String returns the status as shown in reports.
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Halted:
+		return "halted"
+	case Blank:
+		return "blank"
+	case Unsupported:
+		return "unsupported"
+	default:
+		return "crashed"
+	}
+}
+
+// Result is how a ROM fared.
+type Result struct {
+	ROM     string
+	Variant chip8.Variant
+	Status  Status
+
+	// Cycles is the number of instructions executed and Frames the number of
+	// 60Hz frames the display changed in.
+	Cycles uint64
+	Frames int
+
+	// Err is the error the ROM stopped with, if any.
+	Err string
+}
+
+// Run runs rom, named name in the result, for seconds of emulated time at the
+// default clock speed. The variant is detected from the ROM.
+func Run(name string, rom []byte, seconds int) (r Result) {
+	r = Result{ROM: name}
+	r.Variant, _ = chip8.Detect(rom)
+
+	// A ROM bad enough to panic the emulator is a crash like any other, it
+	// mustn't take the rest of the report with it.
+	defer func() {
+		if p := recover(); p != nil {
+			r.Status, r.Err = Crashed, fmt.Sprintf("panic: %v", p)
+		}
+	}()
+
+	vm := chip8.NewVariant(r.Variant)
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		r.Status, r.Err = Crashed, err.Error()
+		return r
+	}
+
+	disp := vm.Display()
+	disp.MarkClean()
+	vm.OnFrame(func() {
+		if disp.Dirty() {
+			r.Frames++
+			disp.MarkClean()
+		}
+	})
+
+	cycles := uint64(seconds * chip8.ClockSpeed)
+	for ; r.Cycles < cycles && !vm.Halted(); r.Cycles++ {
+		if err := vm.Cycle(); err != nil {
+			r.Status, r.Err = Crashed, err.Error()
+			if unsupported(err) {
+				r.Status = Unsupported
+			}
+			return r
+		}
+	}
+	// Catch anything drawn since the last frame.
+	if disp.Dirty() {
+		r.Frames++
+	}
+
+	switch {
+	case r.Frames == 0:
+		r.Status = Blank
+	case vm.Halted():
+		r.Status = Halted
+	default:
+		r.Status = OK
+	}
+	return r
+}
+
+// unsupported returns true if err is from an opcode the emulator doesn't
+// implement.
+func unsupported(err error) bool {
+	return errors.Is(err, chip8.ErrUnknownOpcode)
+}
+
+// Summary counts the results of each status.
+func Summary(results []Result) map[Status]int {
+	counts := make(map[Status]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	return counts
+}
+
+// statuses are the statuses in the order reported.
+var statuses = []Status{OK, Halted, Blank, Unsupported, Crashed}
+
+// WriteMarkdown writes results as a markdown table, followed by the count of
+// each status.
+func WriteMarkdown(w io.Writer, results []Result) error {
+	var b strings.Builder
+	b.WriteString("| ROM | Variant | Status | Frames drawn | Cycles | Error |\n")
+	b.WriteString("| --- | --- | --- | ---: | ---: | --- |\n")
+	for _, r := range results {
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %d | %s |\n",
+			mdEscape(r.ROM), r.Variant, r.Status, r.Frames, r.Cycles, mdEscape(r.Err))
+	}
+
+	counts := Summary(results)
+	b.WriteString("\n")
+	for i, s := range statuses {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%d %s", counts[s], s)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdEscape escapes the pipes in s that would end a table cell.
+func mdEscape(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
+
+// htmlReport is the template of the HTML report.
+var htmlReport = template.Must(template.New("compat").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CHIP-8 compatibility</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+td.num { text-align: right; }
+.ok { background: #cfc; }
+.halted { background: #dfd; }
+.blank { background: #ffc; }
+.unsupported { background: #fdb; }
+.crashed { background: #fcc; }
+</style>
+</head>
+<body>
+<h1>CHIP-8 compatibility</h1>
+<p>{{range $i, $s := .Statuses}}{{if $i}}, {{end}}{{index $.Counts $s}} {{$s}}{{end}}</p>
+<table>
+<tr><th>ROM</th><th>Variant</th><th>Status</th><th>Frames drawn</th><th>Cycles</th><th>Error</th></tr>
+{{range .Results}}<tr class="{{.Status}}"><td>{{.ROM}}</td><td>{{.Variant}}</td><td>{{.Status}}</td><td class="num">{{.Frames}}</td><td class="num">{{.Cycles}}</td><td>{{.Err}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML writes results as an HTML page, with rows coloured by status.
+func WriteHTML(w io.Writer, results []Result) error {
+	return htmlReport.Execute(w, struct {
+		Results  []Result
+		Statuses []Status
+		Counts   map[Status]int
+	}{results, statuses, Summary(results)})
+}
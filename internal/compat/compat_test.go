@@ -0,0 +1,89 @@
+package compat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		rom    []byte
+		status Status
+	}{
+		{
+			name: "drawing forever",
+			// A200: I = 0x200; D015: draw; 1202: jump to the draw.
+			rom:    []byte{0xA2, 0x00, 0xD0, 0x15, 0x12, 0x02},
+			status: OK,
+		},
+		{
+			name: "drawing then halting",
+			// A206: I = 0x206; D015: draw; 1204: jump to itself.
+			rom:    []byte{0xA2, 0x06, 0xD0, 0x15, 0x12, 0x04, 0xF0},
+			status: Halted,
+		},
+		{
+			name:   "never drawing",
+			rom:    []byte{0x12, 0x00},
+			status: Blank,
+		},
+		{
+			name:   "unsupported opcode",
+			rom:    []byte{0x80, 0x1F},
+			status: Unsupported,
+		},
+		{
+			name:   "returning with an empty stack",
+			rom:    []byte{0x00, 0xEE},
+			status: Crashed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := Run(tc.name, tc.rom, 1)
+			if r.Status != tc.status {
+				t.Fatalf("expected %s, got %s (%s)", tc.status, r.Status, r.Err)
+			}
+			if (r.Err != "") != (tc.status >= Unsupported) {
+				t.Fatalf("unexpected error %q for %s", r.Err, r.Status)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	results := []Result{
+		{ROM: "a.ch8", Status: OK, Frames: 60, Cycles: 300},
+		{ROM: "b|c.ch8", Status: Crashed, Cycles: 1, Err: "stack underflow"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"| a.ch8 | chip8 | ok | 60 | 300 |  |",
+		`| b\|c.ch8 | chip8 | crashed | 0 | 1 | stack underflow |`,
+		"1 ok, 0 halted, 0 blank, 0 unsupported, 1 crashed",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, []Result{{ROM: "<a>.ch8", Status: Blank}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<tr class="blank"><td>&lt;a&gt;.ch8</td>`, "0 ok, 0 halted, 1 blank"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
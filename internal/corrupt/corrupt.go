@@ -0,0 +1,36 @@
+// Package corrupt flips random bits of a loaded ROM, to stress the emulator's
+// error handling and to play glitched variants of games.
+package corrupt
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Flip flips n bits of the program of size bytes loaded into vm, picked by
+// seed, and returns the addresses of the bytes changed in order. The same
+// seed flips the same bits, so a corruption worth keeping can be played
+// again.
+func Flip(vm *chip8.VM, size, n int, seed int64) []uint16 {
+	bits := size * 8
+	if n > bits {
+		n = bits
+	}
+
+	var addrs []uint16
+	changed := make(map[uint16]bool)
+	r := rand.New(rand.NewSource(seed))
+	for _, bit := range r.Perm(bits)[:n] {
+		addr := uint16(chip8.ProgramAddr + bit/8)
+		vm.Poke(addr, vm.Peek(addr)^1<<uint(bit%8))
+		if !changed[addr] {
+			changed[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i] < addrs[j] })
+	return addrs
+}
@@ -0,0 +1,59 @@
+package corrupt
+
+import (
+	"bytes"
+	"math/bits"
+	"reflect"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// load returns a VM with a ROM of size zero bytes loaded.
+func load(t *testing.T, size int) *chip8.VM {
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(make([]byte, size))); err != nil {
+		t.Fatal(err)
+	}
+	return vm
+}
+
+// flipped counts the bits set in the program of size bytes in vm, each a bit
+// flipped from the zeroed ROM.
+func flipped(vm *chip8.VM, size int) int {
+	n := 0
+	for a := 0; a < size; a++ {
+		n += bits.OnesCount8(vm.Peek(uint16(chip8.ProgramAddr + a)))
+	}
+	return n
+}
+
+func TestFlip(t *testing.T) {
+	vm := load(t, 64)
+	addrs := Flip(vm, 64, 10, 1)
+	if got := flipped(vm, 64); got != 10 {
+		t.Fatalf("expected 10 bits flipped, got %d", got)
+	}
+	for _, a := range addrs {
+		if a < chip8.ProgramAddr || a >= chip8.ProgramAddr+64 || vm.Peek(a) == 0 {
+			t.Fatalf("unexpected address 0x%03X changed", a)
+		}
+	}
+
+	// The same seed flips the same bits, another seed others.
+	again := load(t, 64)
+	if got := Flip(again, 64, 10, 1); !reflect.DeepEqual(got, addrs) {
+		t.Fatalf("expected %v with the same seed, got %v", addrs, got)
+	}
+	other := load(t, 64)
+	if got := Flip(other, 64, 10, 2); reflect.DeepEqual(got, addrs) {
+		t.Fatalf("expected another seed to change other bytes, got %v", got)
+	}
+
+	// No more bits are flipped than the ROM has.
+	small := load(t, 2)
+	Flip(small, 2, 100, 1)
+	if got := flipped(small, 2); got != 16 {
+		t.Fatalf("expected all 16 bits flipped, got %d", got)
+	}
+}
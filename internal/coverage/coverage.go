@@ -0,0 +1,169 @@
+// Package coverage records which bytes of a ROM a program executes and which
+// it only reads as data, such as sprites, for reverse engineering ROMs and
+// checking how much of one a test exercises.
+package coverage
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/disasm"
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+// Marks on each byte of memory.
+const (
+	exec byte = 1 << iota
+	data
+)
+
+// Map records the coverage of a ROM run by a VM.
+type Map struct {
+	vm    *chip8.VM
+	size  int
+	marks []byte
+}
+
+// New returns a map recording the coverage of the ROM of size bytes loaded
+// into vm, from the next instruction executed.
+func New(vm *chip8.VM, size int) *Map {
+	m := &Map{vm: vm}
+	m.Reset(size)
+	vm.OnInstruction(m.instruction)
+	return m
+}
+
+// Reset clears the coverage recorded, for a ROM of size bytes loaded in place
+// of the last.
+func (m *Map) Reset(size int) {
+	regions := m.vm.Regions()
+	m.size = size
+	m.marks = make([]byte, regions[len(regions)-1].End)
+}
+
+// instruction marks the instruction at pc as executed and the memory it
+// reads as data.
+func (m *Map) instruction(pc, opc uint16) {
+	vr := m.vm.Variant()
+	xo := vr == chip8.XOChip
+
+	n := 2
+	if xo && opc == 0xF000 {
+		n = 4
+	}
+	m.mark(uint32(pc), n, exec)
+
+	x, y := byte(opc>>8&0xF), byte(opc>>4&0xF)
+	switch {
+	case opc&0xF000 == 0xD000:
+		n := int(opc & 0xF)
+		if n == 0 && (vr == chip8.SChip || xo) {
+			n = 32 // A 16x16 sprite.
+		}
+		m.mark(m.vm.I(), n, data)
+	case opc&0xF0FF == 0xF065:
+		m.mark(m.vm.I(), int(x)+1, data)
+	case xo && opc&0xF00F == 0x5003:
+		n := int(x) - int(y)
+		if n < 0 {
+			n = -n
+		}
+		m.mark(m.vm.I(), n+1, data)
+	case xo && opc == 0xF002:
+		m.mark(m.vm.I(), 16, data)
+	}
+}
+
+// mark marks n bytes from addr.
+func (m *Map) mark(addr uint32, n int, mark byte) {
+	for a := int(addr); a < int(addr)+n && a < len(m.marks); a++ {
+		m.marks[a] |= mark
+	}
+}
+
+// Executed returns true if the byte at addr has been executed.
+func (m *Map) Executed(addr uint32) bool {
+	return int(addr) < len(m.marks) && m.marks[addr]&exec != 0
+}
+
+// Data returns true if the byte at addr has been read as data but never
+// executed.
+func (m *Map) Data(addr uint32) bool {
+	return int(addr) < len(m.marks) && m.marks[addr] == data
+}
+
+// Summary counts the bytes of the ROM covered.
+type Summary struct {
+	Size     int
+	Executed int
+	Data     int
+}
+
+// String returns the summary as a line of a report.
+func (s Summary) String() string {
+	pct := 0.0
+	if s.Size > 0 {
+		pct = float64(s.Executed+s.Data) * 100 / float64(s.Size)
+	}
+	return fmt.Sprintf("%d of %d bytes executed, %d read as data, %.1f%% covered", s.Executed, s.Size, s.Data, pct)
+}
+
+// Summary returns the coverage of the ROM.
+func (m *Map) Summary() Summary {
+	s := Summary{Size: m.size}
+	for a := uint32(chip8.ProgramAddr); a < chip8.ProgramAddr+uint32(m.size); a++ {
+		switch {
+		case m.Executed(a):
+			s.Executed++
+		case m.Data(a):
+			s.Data++
+		}
+	}
+	return s
+}
+
+// ANSI colours of the lines of a listing.
+const (
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	grey   = "\x1b[90m"
+	reset  = "\x1b[0m"
+)
+
+// WriteListing writes a disassembly of the ROM marking each line executed
+// (>), read as data (=) or untouched, coloured green, yellow and grey if
+// colour is set. syms, if set, names addresses as in disasm.
+func (m *Map) WriteListing(w io.Writer, syms *symbol.Table, colour bool) error {
+	end := chip8.ProgramAddr + m.size
+	mem := make([]byte, end+1)
+	for a := chip8.ProgramAddr; a < end; a++ {
+		mem[a] = m.vm.Peek(uint16(a))
+	}
+
+	for _, l := range disasm.Range(mem, chip8.ProgramAddr, uint16(end), syms) {
+		marker, col := "  ", grey
+		switch a := uint32(l.Addr); {
+		case m.Executed(a) || m.Executed(a+1):
+			marker, col = "> ", green
+		case m.Data(a) || m.Data(a+1):
+			marker, col = "= ", yellow
+		}
+
+		if l.Label != "" {
+			if _, err := fmt.Fprintln(w, l.Label+":"); err != nil {
+				return err
+			}
+			l.Label = ""
+		}
+
+		s := marker + l.String()
+		if colour {
+			s = col + s + reset
+		}
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestMap(t *testing.T) {
+	rom := []byte{
+		0xA2, 0x06, // I = sprite.
+		0xD0, 0x01, // Draw it.
+		0x12, 0x04, // Halt.
+		0x80, 0x00, // Sprite, then a byte never touched.
+	}
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	m := New(vm, len(rom))
+	for i := 0; i < 4; i++ {
+		if err := vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	exp := Summary{Size: 8, Executed: 6, Data: 1}
+	if s := m.Summary(); s != exp {
+		t.Fatalf("expected %+v, got %+v", exp, s)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteListing(&buf, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %q", lines)
+	}
+	for i, marker := range []string{"> ", "> ", "> ", "= "} {
+		if !strings.HasPrefix(lines[i], marker) {
+			t.Errorf("expected line %d to start %q, got %q", i, marker, lines[i])
+		}
+	}
+
+	m.Reset(len(rom))
+	if s := m.Summary(); s.Executed != 0 || s.Data != 0 {
+		t.Fatalf("expected no coverage after a reset, got %+v", s)
+	}
+}
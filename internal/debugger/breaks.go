@@ -0,0 +1,71 @@
+package debugger
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// The events the game can be paused on, a bit each.
+const (
+	breakDraw int32 = 1 << iota
+	breakTone
+	breakKeys
+)
+
+// breakNames are the names of the events.
+var breakNames = map[int32]string{
+	breakDraw: "draw",
+	breakTone: "tone",
+	breakKeys: "keys",
+}
+
+// toggleBreak turns breaking on the event b on or off.
+func (w *Window) toggleBreak(b int32) {
+	for {
+		old := atomic.LoadInt32(&w.breaks)
+		if atomic.CompareAndSwapInt32(&w.breaks, old, old^b) {
+			return
+		}
+	}
+}
+
+// breaksOn returns the names of the events broken on.
+func (w *Window) breaksOn() string {
+	breaks := atomic.LoadInt32(&w.breaks)
+	var on []string
+	for _, b := range []int32{breakDraw, breakTone, breakKeys} {
+		if breaks&b != 0 {
+			on = append(on, breakNames[b])
+		}
+	}
+	return strings.Join(on, ", ")
+}
+
+// breakAt returns the event the instruction at the program counter causes,
+// and true if it's one broken on: a sprite drawn by DXYN, the tone started by
+// FX18 with VX non-zero, or the keypad read by EX9E, EXA1 or FX0A.
+func (w *Window) breakAt() (string, bool) {
+	breaks := atomic.LoadInt32(&w.breaks)
+	if breaks == 0 {
+		return "", false
+	}
+
+	vm := w.vm
+	pc := vm.PC()
+	opc := uint16(vm.Peek(pc))<<8 | uint16(vm.Peek(pc+1))
+	x := byte(opc >> 8 & 0xF)
+
+	var e int32
+	switch {
+	case opc&0xF000 == 0xD000:
+		e = breakDraw
+	case opc&0xF0FF == 0xF018 && vm.V(x) > 0:
+		e = breakTone
+	case opc&0xF0FF == 0xE09E, opc&0xF0FF == 0xE0A1, opc&0xF0FF == 0xF00A:
+		e = breakKeys
+	}
+	if breaks&e == 0 {
+		return "", false
+	}
+	return breakNames[e], true
+}
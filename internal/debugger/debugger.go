@@ -0,0 +1,544 @@
+// Package debugger implements a window shown beside the game with the live
+// state of the VM: disassembly around the program counter, registers, the
+// stack, the keypad, a map of memory and the sprite at I. The game can be
+// paused, stepped through and stepped back through from the window.
+package debugger
+
+import (
+	"fmt"
+	"image/color"
+	"sync/atomic"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/disasm"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/memscan"
+	"github.com/danmrichards/chip8/internal/sprites"
+	"github.com/danmrichards/chip8/internal/symbol"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// keypad is the layout of the Chip8 hex keypad.
+var keypad = [4][4]byte{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// disasmWindow is the number of instructions shown either side of the program
+// counter.
+const disasmWindow = 12
+
+// historyLen is the number of instructions that can be stepped back through,
+// a minute at the default clock speed.
+const historyLen = 60 * chip8.ClockSpeed
+
+// op is an operation of a command.
+type op int
+
+const (
+	pause op = iota
+	resume
+	step
+	stepBack
+	stepOver
+	stepOut
+	runTo
+	scan
+	freeze
+)
+
+// command is a command given in the debugger window, run on the emulation
+// goroutine.
+type command struct {
+	op op
+
+	// The address run to by runTo.
+	addr uint16
+
+	// The words of a command typed, for the memory scanner or setting a
+	// timer, run by scan.
+	args []string
+}
+
+// Window is a debugger window.
+type Window struct {
+	win   *pixelgl.Window
+	vm    *chip8.VM
+	atlas *text.Atlas
+
+	// The symbols naming addresses, set on the emulation goroutine and sent
+	// to the window with each snapshot.
+	syms *symbol.Table
+
+	// Snapshots of the VM state, taken on the emulation goroutine every
+	// frame. Only the latest snapshot is kept.
+	states chan snapshot
+
+	// The recent accesses to memory, recorded on the emulation goroutine.
+	act memActivity
+
+	// Commands from the window waiting to run, and whether the game is
+	// paused, set by the emulation goroutine.
+	cmds   chan command
+	paused int32
+
+	// Set while running to a point with step over, step out or run to
+	// cursor, returning true once it's reached. Only used on the emulation
+	// goroutine.
+	until func() bool
+
+	// The events to break on, a bit each, set by the window goroutine. After
+	// breaking, or stepping, the next instruction runs without breaking.
+	breaks    int32
+	skipBreak bool
+
+	// The address of the disassembly cursor, or -1 if it's following the
+	// program counter. Only used on the window goroutine.
+	cursor int
+
+	// The size of the last sprite drawn, set on the emulation goroutine.
+	spriteW, spriteH int
+
+	// The memory scanner, used on the emulation goroutine, and the scanner
+	// command being typed, nil if there isn't one, on the window goroutine.
+	scan *memscan.Scanner
+	line []rune
+}
+
+// snapshot is the state of the VM drawn by the window.
+type snapshot struct {
+	st      chip8.State
+	regions []chip8.Region
+	act     memActivity
+	syms    *symbol.Table
+	sprite  sprites.Sprite
+	scan    scanView
+
+	// Whether the timers are frozen.
+	frozen bool
+}
+
+// New opens a debugger window for vm. Symbols, if not nil, are used to name
+// addresses in the disassembly. The VM records the history of the last
+// instructions from now on, for stepping back through.
+func New(vm *chip8.VM, syms *symbol.Table) (*Window, error) {
+	win, err := pixelgl.NewWindow(pixelgl.WindowConfig{
+		Title:  "chip8 debugger",
+		Bounds: pixel.R(0, 0, 1040, 480),
+		VSync:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Window{
+		win:     win,
+		vm:      vm,
+		syms:    syms,
+		atlas:   text.NewAtlas(basicfont.Face7x13, text.ASCII),
+		states:  make(chan snapshot, 1),
+		cmds:    make(chan command, 16),
+		cursor:  -1,
+		spriteW: 8,
+		spriteH: 15,
+		scan:    memscan.New(vm),
+	}
+
+	vm.EnableHistory(historyLen)
+	vm.OnInstruction(func(pc, opc uint16) {
+		w.act.instruction(vm, pc, opc)
+		if sw, sh, ok := spriteSize(vm.Variant(), opc); ok {
+			w.spriteW, w.spriteH = sw, sh
+		}
+	})
+	vm.OnFrame(func() {
+		w.act.frame()
+		w.publish()
+	})
+
+	return w, nil
+}
+
+// publish sends a snapshot of the VM to the window, replacing any that
+// hasn't been drawn yet.
+func (w *Window) publish() {
+	select {
+	case <-w.states:
+	default:
+	}
+	st := w.vm.State()
+	w.states <- snapshot{st: st, regions: w.vm.Regions(), act: w.act, syms: w.syms, sprite: w.decodeSprite(st), scan: w.scanView(), frozen: w.vm.TimersFrozen()}
+}
+
+// SetSymbols replaces the symbols naming addresses, for when the ROM is
+// reloaded. It must be called from the emulation goroutine.
+func (w *Window) SetSymbols(syms *symbol.Table) {
+	w.syms = syms
+	w.publish()
+}
+
+// Cycle runs the commands given in the window then, unless the game is
+// paused, a cycle of the VM. Emulation loops call it in place of the VM's
+// Cycle. Errors pause the game rather than being returned, so the
+// instructions leading up to them can be stepped back through.
+func (w *Window) Cycle() error {
+	for len(w.cmds) > 0 {
+		w.run(<-w.cmds)
+	}
+
+	if w.Paused() {
+		return nil
+	}
+	if !w.skipBreak {
+		if e, ok := w.breakAt(); ok {
+			atomic.StoreInt32(&w.paused, 1)
+			w.skipBreak = true
+			pc := w.vm.PC()
+			logging.Infof("Paused on %s at 0x%03X %s", e, pc, w.syms.Describe(pc))
+			w.publish()
+			return nil
+		}
+	}
+	w.skipBreak = false
+
+	if err := w.vm.Cycle(); err != nil {
+		w.stop(err)
+		return nil
+	}
+
+	if w.until != nil && w.until() {
+		w.until = nil
+		atomic.StoreInt32(&w.paused, 1)
+		pc := w.vm.PC()
+		logging.Infof("Paused at 0x%03X %s", pc, w.syms.Describe(pc))
+		w.publish()
+	}
+	return nil
+}
+
+// run runs c on the emulation goroutine.
+func (w *Window) run(c command) {
+	// Scanning memory and the timer commands leave the game running.
+	switch c.op {
+	case scan:
+		if !w.runTimers(c.args) {
+			w.runScan(c.args)
+		}
+		return
+	case freeze:
+		w.freezeTimers()
+		return
+	}
+
+	vm := w.vm
+	w.until = nil
+	w.skipBreak = true
+	atomic.StoreInt32(&w.paused, 1)
+
+	switch c.op {
+	case resume:
+		atomic.StoreInt32(&w.paused, 0)
+	case step:
+		if err := vm.Cycle(); err != nil {
+			w.stop(err)
+		}
+	case stepBack:
+		if err := vm.StepBack(); err != nil {
+			logging.Warnf("Could not step back: %s", err)
+		}
+	case stepOver:
+		// Calls are run until they return, anything else is a step.
+		pc, sp := vm.PC(), vm.SP()
+		if vm.Peek(pc)&0xF0 != 0x20 {
+			w.run(command{op: step})
+			return
+		}
+		w.runUntil(func() bool { return vm.SP() == sp && vm.PC() == pc+2 })
+	case stepOut:
+		sp := vm.SP()
+		if sp == 0 {
+			logging.Warnf("Not in a subroutine to step out of")
+			break
+		}
+		w.runUntil(func() bool { return vm.SP() < sp })
+	case runTo:
+		w.runUntil(func() bool { return vm.PC() == c.addr })
+	}
+	w.publish()
+}
+
+// runUntil resumes the game until done returns true.
+func (w *Window) runUntil(done func() bool) {
+	w.until = done
+	atomic.StoreInt32(&w.paused, 0)
+}
+
+// stop pauses the game on err.
+func (w *Window) stop(err error) {
+	logging.Errorf("Paused on an error, step back to find its cause: %s", err)
+	atomic.StoreInt32(&w.paused, 1)
+	w.publish()
+}
+
+// Paused returns true if the game has been paused from the window.
+func (w *Window) Paused() bool {
+	return atomic.LoadInt32(&w.paused) == 1
+}
+
+// send queues c to run on the emulation goroutine, dropping it if too many
+// are queued already.
+func (w *Window) send(c command) {
+	select {
+	case w.cmds <- c:
+	default:
+	}
+}
+
+// pressed returns true if b has just been pressed or is repeating.
+func (w *Window) pressed(b pixelgl.Button) bool {
+	return w.win.JustPressed(b) || w.win.Repeated(b)
+}
+
+// input sends the commands for the keys pressed in the window: Space pauses
+// and resumes, S steps an instruction, B steps back one, O steps over a call,
+// U steps out of one and C runs to the cursor. D, T and K toggle breaking on
+// draws, the tone and reading the keypad, and F freezes the timers. Up and
+// Down move the cursor an instruction, Page Up and Page Down to the previous
+// or next label, and Escape puts it back on the program counter. / starts
+// typing a memory scanner or timer command. pc is the program counter last drawn and syms the symbols
+// it was drawn with.
+func (w *Window) input(pc uint16, syms *symbol.Table) {
+	if w.typeCommand() {
+		return
+	}
+
+	switch {
+	case w.win.JustPressed(pixelgl.KeySpace) && w.Paused():
+		w.send(command{op: resume})
+	case w.win.JustPressed(pixelgl.KeySpace):
+		w.send(command{op: pause})
+	case w.pressed(pixelgl.KeyS):
+		w.send(command{op: step})
+	case w.pressed(pixelgl.KeyB):
+		w.send(command{op: stepBack})
+	case w.pressed(pixelgl.KeyO):
+		w.send(command{op: stepOver})
+	case w.win.JustPressed(pixelgl.KeyU):
+		w.send(command{op: stepOut})
+	case w.win.JustPressed(pixelgl.KeyC) && w.cursor >= 0:
+		w.send(command{op: runTo, addr: uint16(w.cursor)})
+	case w.win.JustPressed(pixelgl.KeyEscape):
+		w.cursor = -1
+	case w.win.JustPressed(pixelgl.KeyD):
+		w.toggleBreak(breakDraw)
+	case w.win.JustPressed(pixelgl.KeyT):
+		w.toggleBreak(breakTone)
+	case w.win.JustPressed(pixelgl.KeyK):
+		w.toggleBreak(breakKeys)
+	case w.win.JustPressed(pixelgl.KeyF):
+		w.send(command{op: freeze})
+	case w.pressed(pixelgl.KeyUp):
+		w.moveCursor(pc, -2)
+	case w.pressed(pixelgl.KeyDown):
+		w.moveCursor(pc, 2)
+	case w.pressed(pixelgl.KeyPageUp):
+		w.jumpCursor(pc, syms, false)
+	case w.pressed(pixelgl.KeyPageDown):
+		w.jumpCursor(pc, syms, true)
+	}
+}
+
+// moveCursor moves the cursor by d bytes, starting from pc if it's following
+// the program counter.
+func (w *Window) moveCursor(pc uint16, d int) {
+	c := w.cursor
+	if c < 0 {
+		c = int(pc)
+	}
+	if c+d >= 0 && c+d < len(chip8.State{}.Mem) {
+		w.cursor = c + d
+	}
+}
+
+// jumpCursor moves the cursor to the next code label after it, or the
+// previous one before it.
+func (w *Window) jumpCursor(pc uint16, syms *symbol.Table, next bool) {
+	c := w.cursor
+	if c < 0 {
+		c = int(pc)
+	}
+
+	labels := syms.Symbols()
+	if !next {
+		for i := len(labels) - 1; i >= 0; i-- {
+			if labels[i].Kind == symbol.Code && int(labels[i].Addr) < c {
+				w.cursor = int(labels[i].Addr)
+				return
+			}
+		}
+		return
+	}
+	for _, s := range labels {
+		if s.Kind == symbol.Code && int(s.Addr) > c {
+			w.cursor = int(s.Addr)
+			return
+		}
+	}
+}
+
+// Run redraws the window with the latest VM state until it is closed.
+func (w *Window) Run() {
+	var last snapshot
+
+	// Keep polling window events even if the VM stops producing frames.
+	tick := time.NewTicker(time.Second / 10)
+	defer tick.Stop()
+
+	for !w.win.Closed() {
+		select {
+		case last = <-w.states:
+			w.draw(last)
+		case <-tick.C:
+			// A command being typed is redrawn while the game is paused.
+			if w.line != nil {
+				w.draw(last)
+			} else {
+				w.win.UpdateInput()
+			}
+		}
+		w.input(last.st.PC, last.syms)
+	}
+}
+
+// draw renders snap to the window.
+func (w *Window) draw(snap snapshot) {
+	st := snap.st
+	w.win.Clear(colornames.Black)
+
+	top := w.win.Bounds().H() - 20
+
+	left := text.New(pixel.V(10, top), w.atlas)
+	left.Color = colornames.White
+	w.registers(left, snap)
+	left.Draw(w.win, pixel.IM)
+
+	right := text.New(pixel.V(300, top), w.atlas)
+	right.Color = colornames.White
+	w.disassembly(right, st, snap.syms)
+	right.Draw(w.win, pixel.IM)
+
+	mem := text.New(pixel.V(620, top), w.atlas)
+	mem.Color = colornames.White
+	fmt.Fprintln(mem, "Memory")
+	mem.Draw(w.win, pixel.IM)
+	w.drawMemory(snap, pixel.V(620, top-12))
+
+	legend := text.New(pixel.V(620, top-12-memCells*memCellSize-16), w.atlas)
+	for _, l := range []struct {
+		c    color.RGBA
+		text string
+	}{
+		{execColour, "executed"},
+		{readColour, "read"},
+		{writeColour, "written"},
+		{strayColour, "written outside the program"},
+		{iColour, "I"},
+		{pcColour, "PC"},
+	} {
+		legend.Color = l.c
+		fmt.Fprintln(legend, l.text)
+	}
+	legend.Draw(w.win, pixel.IM)
+	w.drawSprite(snap, pixel.V(620, legend.Dot.Y-8))
+
+	scanText := text.New(pixel.V(860, top), w.atlas)
+	scanText.Color = colornames.White
+	drawScan(scanText, snap.scan)
+	scanText.Draw(w.win, pixel.IM)
+
+	status := "Running   Space pause  F freeze timers"
+	if w.Paused() {
+		status = "Paused    Space resume  S step  B back  O over  U out  C run to *  Up/Down/PgUp/PgDn move *  F freeze timers"
+	}
+	if on := w.breaksOn(); on != "" {
+		status = "Break on " + on + "   " + status
+	}
+	if w.line != nil {
+		status = w.prompt()
+	}
+	foot := text.New(pixel.V(10, 10), w.atlas)
+	foot.Color = colornames.Yellow
+	fmt.Fprint(foot, status)
+	foot.Draw(w.win, pixel.IM)
+
+	w.win.Update()
+}
+
+// registers writes the registers, timers, stack and keypad to txt.
+func (w *Window) registers(txt *text.Text, snap snapshot) {
+	st, syms := snap.st, snap.syms
+	fmt.Fprintf(txt, "PC  0x%03X %s\n", st.PC, syms.Describe(st.PC))
+	fmt.Fprintf(txt, "I   0x%03X %s\n", st.I, syms.Describe(uint16(st.I)))
+	fmt.Fprintf(txt, "DT  %3d   ST  %3d", st.DelayTimer, st.SoundTimer)
+	if snap.frozen {
+		fmt.Fprint(txt, "  frozen")
+	}
+	fmt.Fprint(txt, "\n\n")
+
+	for i := 0; i < 16; i += 2 {
+		fmt.Fprintf(txt, "V%X  0x%02X  V%X  0x%02X\n", i, st.V[i], i+1, st.V[i+1])
+	}
+
+	fmt.Fprintf(txt, "\nStack (SP %d)\n", st.SP)
+	for i := int(st.SP); i > 0; i-- {
+		fmt.Fprintf(txt, "  %2d  0x%03X %s\n", i, st.Stack[i], syms.Describe(st.Stack[i]))
+	}
+
+	fmt.Fprintln(txt, "\nKeypad")
+	for _, row := range keypad {
+		fmt.Fprint(txt, "  ")
+		for _, k := range row {
+			if st.Keys[k] == 1 {
+				fmt.Fprintf(txt, "[%X]", k)
+			} else {
+				fmt.Fprintf(txt, " %X ", k)
+			}
+		}
+		fmt.Fprintln(txt)
+	}
+}
+
+// disassembly writes the instructions around the cursor, or the program
+// counter if it's following it, to txt.
+func (w *Window) disassembly(txt *text.Text, st chip8.State, syms *symbol.Table) {
+	centre := st.PC
+	if w.cursor >= 0 {
+		centre = uint16(w.cursor)
+	}
+	from := centre - disasmWindow*2
+	if centre < disasmWindow*2 {
+		from = 0
+	}
+
+	for _, l := range disasm.Range(st.Mem[:], from, centre+disasmWindow*2, syms) {
+		if l.Label != "" {
+			fmt.Fprintf(txt, "%s:\n", l.Label)
+		}
+
+		marker := "  "
+		switch {
+		case l.Addr == st.PC:
+			marker = "> "
+		case int(l.Addr) == w.cursor:
+			marker = "* "
+		}
+		fmt.Fprintf(txt, "%s0x%03X  %s\n", marker, l.Addr, l.Text)
+	}
+}
@@ -0,0 +1,172 @@
+package debugger
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/faiface/pixel"
+)
+
+// The memory panel draws the first 4K of memory as a square of memCells by
+// memCells cells, a byte each, memCellSize pixels wide.
+const (
+	memCells    = 64
+	memCellSize = 3
+)
+
+// The ways memory is accessed, shown on the memory panel.
+const (
+	accExec = iota
+	accRead
+	accWrite
+	accKinds
+)
+
+// accessFrames is the number of frames an access stays shown for, fading
+// out.
+const accessFrames = 30
+
+// memActivity is the recent accesses to the first 4K of memory, by the frames
+// each kind of access to each byte has left to be shown for.
+type memActivity [accKinds][memCells * memCells]byte
+
+// instruction marks the bytes the instruction opc at pc executes, reads and
+// writes, before it's executed by vm.
+func (m *memActivity) instruction(vm *chip8.VM, pc, opc uint16) {
+	vr := vm.Variant()
+	xo := vr == chip8.XOChip
+
+	n := 2
+	if xo && opc == 0xF000 {
+		n = 4
+	}
+	m.mark(accExec, uint32(pc), n)
+
+	i := vm.I()
+	x, y := int(opc>>8&0xF), int(opc>>4&0xF)
+	switch {
+	case opc&0xF000 == 0xD000:
+		n := int(opc & 0xF)
+		if n == 0 && (vr == chip8.SChip || xo) {
+			n = 32 // A 16x16 sprite.
+		}
+		m.mark(accRead, i, n)
+	case opc&0xF0FF == 0xF065:
+		m.mark(accRead, i, x+1)
+	case opc&0xF0FF == 0xF055:
+		m.mark(accWrite, i, x+1)
+	case opc&0xF0FF == 0xF033:
+		m.mark(accWrite, i, 3)
+	case xo && opc&0xF00E == 0x5002:
+		n := x - y
+		if n < 0 {
+			n = -n
+		}
+		kind := accWrite
+		if opc&1 == 1 {
+			kind = accRead
+		}
+		m.mark(kind, i, n+1)
+	case xo && opc == 0xF002:
+		m.mark(accRead, i, 16)
+	}
+}
+
+// mark marks n bytes from addr as accessed.
+func (m *memActivity) mark(kind int, addr uint32, n int) {
+	for a := int(addr); a < int(addr)+n && a < len(m[kind]); a++ {
+		m[kind][a] = accessFrames
+	}
+}
+
+// frame fades the accesses shown by a frame.
+func (m *memActivity) frame() {
+	for k := range m {
+		for a, left := range m[k] {
+			if left > 0 {
+				m[k][a] = left - 1
+			}
+		}
+	}
+}
+
+// The colours of the memory panel.
+var (
+	interpreterColour = color.RGBA{0x30, 0x30, 0x30, 0xFF}
+	fontColour        = color.RGBA{0x20, 0x30, 0x80, 0xFF}
+	programColour     = color.RGBA{0x10, 0x40, 0x10, 0xFF}
+	execColour        = color.RGBA{0x40, 0xFF, 0x40, 0xFF}
+	readColour        = color.RGBA{0xFF, 0xE0, 0x20, 0xFF}
+	writeColour       = color.RGBA{0xFF, 0x90, 0x00, 0xFF}
+	strayColour       = color.RGBA{0xFF, 0x00, 0x00, 0xFF}
+	iColour           = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+	pcColour          = color.RGBA{0x00, 0xFF, 0xFF, 0xFF}
+)
+
+// memoryImage returns an image of the first 4K of memory, a pixel a byte in
+// rows of memCells from the top left. Each region has its own colour, and
+// bytes recently executed, read and written are lit over it, writes outside
+// the program brightest of all. I and the program counter are marked.
+func memoryImage(st chip8.State, regions []chip8.Region, act *memActivity) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, memCells, memCells))
+
+	for a := range st.Mem {
+		c := interpreterColour
+		inProgram := false
+		for _, r := range regions {
+			if uint32(a) >= r.Start && uint32(a) < r.End {
+				if r.Name == "program" {
+					c, inProgram = programColour, true
+				} else {
+					c = fontColour
+				}
+			}
+		}
+		// Non-zero bytes are a shade lighter, so the ROM's extent shows.
+		if st.Mem[a] != 0 {
+			c = blend(c, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}, 0.15)
+		}
+
+		for _, acc := range []struct {
+			kind   int
+			colour color.RGBA
+		}{{accExec, execColour}, {accRead, readColour}, {accWrite, writeColour}} {
+			left := act[acc.kind][a]
+			if left == 0 {
+				continue
+			}
+			lit := acc.colour
+			if acc.kind == accWrite && !inProgram {
+				lit = strayColour
+			}
+			c = blend(c, lit, float64(left)/accessFrames)
+		}
+
+		img.SetRGBA(a%memCells, a/memCells, c)
+	}
+
+	if int(st.I) < len(st.Mem) {
+		img.SetRGBA(int(st.I)%memCells, int(st.I)/memCells, iColour)
+	}
+	if int(st.PC) < len(st.Mem) {
+		img.SetRGBA(int(st.PC)%memCells, int(st.PC)/memCells, pcColour)
+	}
+
+	return img
+}
+
+// blend returns a mixed with t of b.
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x)*(1-t) + float64(y)*t) }
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 0xFF}
+}
+
+// drawMemory draws the memory panel with its top left corner at pos.
+func (w *Window) drawMemory(snap snapshot, pos pixel.Vec) {
+	pic := pixel.PictureDataFromImage(memoryImage(snap.st, snap.regions, &snap.act))
+	size := float64(memCells * memCellSize)
+	pixel.NewSprite(pic, pic.Bounds()).Draw(w.win, pixel.IM.
+		Scaled(pixel.ZV, memCellSize).
+		Moved(pos.Add(pixel.V(size/2, -size/2))))
+}
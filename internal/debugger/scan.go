@@ -0,0 +1,123 @@
+package debugger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+// scanShown is the number of addresses found by the memory scanner listed.
+const scanShown = 12
+
+// scanAddr is an address listed in the scanner panel with its value.
+type scanAddr struct {
+	addr   uint16
+	value  byte
+	frozen bool
+}
+
+// scanView is the state of the memory scanner drawn by the window.
+type scanView struct {
+	found   int
+	shown   []scanAddr
+	watched []scanAddr
+}
+
+// scanView returns the state of the memory scanner, on the emulation goroutine.
+func (w *Window) scanView() scanView {
+	found := w.scan.Found()
+	v := scanView{found: len(found)}
+	if found == nil {
+		v.found = -1
+	}
+	for i, a := range found {
+		if i == scanShown {
+			break
+		}
+		v.shown = append(v.shown, scanAddr{addr: a, value: w.vm.Peek(a)})
+	}
+	for _, a := range w.scan.Watched() {
+		_, frozen := w.scan.Frozen(a)
+		v.watched = append(v.watched, scanAddr{addr: a, value: w.vm.Peek(a), frozen: frozen})
+	}
+	return v
+}
+
+// runScan runs a memory scanner command on the emulation goroutine.
+func (w *Window) runScan(args []string) {
+	msg, err := w.scan.Exec(args)
+	if err != nil {
+		logging.Warnf("Scan: %s", err)
+	} else {
+		logging.Infof("Scan: %s", msg)
+	}
+	w.publish()
+}
+
+// typeCommand reads the scanner or timer command being typed, sending it on Enter and
+// dropping it on Escape. It returns false if no command is being typed.
+func (w *Window) typeCommand() bool {
+	if w.line == nil {
+		if !w.win.JustPressed(pixelgl.KeySlash) {
+			return false
+		}
+		w.line = []rune{}
+		w.win.Typed() // Drop the slash.
+		return true
+	}
+
+	switch {
+	case w.win.JustPressed(pixelgl.KeyEscape):
+		w.line = nil
+	case w.win.JustPressed(pixelgl.KeyEnter):
+		if args := strings.Fields(string(w.line)); len(args) > 0 {
+			w.send(command{op: scan, args: args})
+		}
+		w.line = nil
+	case w.pressed(pixelgl.KeyBackspace) && len(w.line) > 0:
+		w.line = w.line[:len(w.line)-1]
+	default:
+		w.line = append(w.line, []rune(w.win.Typed())...)
+	}
+	return true
+}
+
+// drawScan writes the addresses found by the memory scanner and those
+// watched to txt.
+func drawScan(txt *text.Text, v scanView) {
+	fmt.Fprintln(txt, "Scan  / to type")
+	switch {
+	case v.found < 0:
+		fmt.Fprintln(txt, "  find <value>")
+	case v.found == 0:
+		fmt.Fprintln(txt, "  nothing found")
+	default:
+		fmt.Fprintf(txt, "  %d found\n", v.found)
+	}
+	for _, a := range v.shown {
+		fmt.Fprintf(txt, "  0x%03X  %3d\n", a.addr, a.value)
+	}
+	if v.found > len(v.shown) {
+		fmt.Fprintln(txt, "  ...")
+	}
+
+	fmt.Fprintln(txt, "\nWatch")
+	for _, a := range v.watched {
+		marker := ""
+		if a.frozen {
+			marker = "  frozen"
+		}
+		fmt.Fprintf(txt, "  0x%03X  %3d%s\n", a.addr, a.value, marker)
+	}
+}
+
+// scanHelp lists the scanner and timer commands, shown while one is typed.
+const scanHelp = "find N  eq N  changed  unchanged  inc  dec  watch A  freeze A [N]  unwatch A  reset  dt N  st N"
+
+// prompt returns the command being typed, as shown in the footer.
+func (w *Window) prompt() string {
+	return fmt.Sprintf("/%s_   %s", string(w.line), scanHelp)
+}
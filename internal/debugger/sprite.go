@@ -0,0 +1,50 @@
+package debugger
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/sprites"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+)
+
+// spriteScale is the size in pixels of each pixel of the sprite at I.
+const spriteScale = 4
+
+// spriteSize returns the size of the sprite drawn by opc, false if opc isn't
+// DXYN.
+func spriteSize(vr chip8.Variant, opc uint16) (w, h int, ok bool) {
+	if opc&0xF000 != 0xD000 {
+		return 0, 0, false
+	}
+	if n := int(opc & 0xF); n > 0 || (vr != chip8.SChip && vr != chip8.XOChip) {
+		return 8, n, true
+	}
+	return 16, 16, true
+}
+
+// drawSprite draws the sprite at I, the size of the last one drawn, with its
+// top left corner at pos.
+func (w *Window) drawSprite(snap snapshot, pos pixel.Vec) {
+	s := snap.sprite
+	label := text.New(pos, w.atlas)
+	label.Color = colornames.White
+	fmt.Fprintf(label, "Sprite at I (%dx%d)", s.Width, s.Height)
+	label.Draw(w.win, pixel.IM)
+	if s.Height == 0 {
+		return
+	}
+
+	pic := pixel.PictureDataFromImage(s.Image(iColour, interpreterColour))
+	size := pixel.V(float64(s.Width*spriteScale), float64(s.Height*spriteScale))
+	pixel.NewSprite(pic, pic.Bounds()).Draw(w.win, pixel.IM.
+		Scaled(pixel.ZV, spriteScale).
+		Moved(pos.Add(pixel.V(size.X/2, -12-size.Y/2))))
+}
+
+// decodeSprite returns the sprite at I in st, the size of the last one drawn.
+func (w *Window) decodeSprite(st chip8.State) sprites.Sprite {
+	return sprites.Decode(st.Mem[:], uint16(st.I), w.spriteW, w.spriteH)
+}
@@ -0,0 +1,50 @@
+package debugger
+
+import (
+	"strconv"
+
+	"github.com/danmrichards/chip8/internal/logging"
+)
+
+// runTimers runs a timer command on the emulation goroutine: dt N or st N
+// sets the delay or sound timer to N. It returns false if args isn't a timer
+// command.
+func (w *Window) runTimers(args []string) bool {
+	if args[0] != "dt" && args[0] != "st" {
+		return false
+	}
+	defer w.publish()
+
+	if len(args) != 2 {
+		logging.Warnf("Expected %s N", args[0])
+		return true
+	}
+	n, err := strconv.ParseUint(args[1], 0, 8)
+	if err != nil {
+		logging.Warnf("Invalid timer value %q, expected 0 to 255", args[1])
+		return true
+	}
+
+	dt, st := w.vm.Timers()
+	if args[0] == "dt" {
+		dt = byte(n)
+	} else {
+		st = byte(n)
+	}
+	w.vm.SetTimers(dt, st)
+	logging.Infof("Set %s to %d", args[0], n)
+	return true
+}
+
+// freezeTimers freezes the timers, or starts them again, on the emulation
+// goroutine.
+func (w *Window) freezeTimers() {
+	frozen := !w.vm.TimersFrozen()
+	w.vm.FreezeTimers(frozen)
+	if frozen {
+		logging.Infof("Froze the timers")
+	} else {
+		logging.Infof("Started the timers again")
+	}
+	w.publish()
+}
@@ -0,0 +1,129 @@
+// Package dialog opens the desktop's native file and message dialogs by
+// running the tools each platform provides: zenity or kdialog on Linux and the BSDs, osascript
+// on macOS and PowerShell on Windows. No cgo or GUI toolkit is needed.
+package dialog
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnsupported is returned when no dialog tool could be found.
+var ErrUnsupported = errors.New("no dialog available, install zenity or kdialog")
+
+// OpenFile shows a dialog titled title for picking a file with one of exts,
+// such as ".ch8". It returns the path picked and true, or false if the dialog
+// was cancelled.
+func OpenFile(title string, exts []string) (string, bool, error) {
+	args := find(openCommands(runtime.GOOS, title, exts))
+	if args == nil {
+		return "", false, ErrUnsupported
+	}
+
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	path := strings.TrimSpace(string(out))
+
+	// The tools exit 1 when cancelled, PowerShell just prints nothing.
+	if e, ok := err.(*exec.ExitError); ok && e.ExitCode() == 1 {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return path, path != "", nil
+}
+
+// Error shows an error dialog titled title reporting msg, returning once it's
+// dismissed.
+func Error(title, msg string) error {
+	args := find(errorCommands(runtime.GOOS, title, msg))
+	if args == nil {
+		return ErrUnsupported
+	}
+	return exec.Command(args[0], args[1:]...).Run()
+}
+
+// find returns the first of cmds whose tool is installed, or nil if none are.
+func find(cmds [][]string) []string {
+	for _, args := range cmds {
+		if _, err := exec.LookPath(args[0]); err == nil {
+			return args
+		}
+	}
+	return nil
+}
+
+// openCommands returns the commands which may show the file dialog on goos,
+// in order of preference.
+func openCommands(goos, title string, exts []string) [][]string {
+	var globs []string
+	for _, e := range exts {
+		globs = append(globs, "*"+e)
+	}
+
+	switch goos {
+	case "darwin":
+		return [][]string{{
+			"osascript", "-e",
+			"POSIX path of (choose file with prompt " + appleQuote(title) + ")",
+		}}
+	case "windows":
+		return [][]string{{
+			"powershell", "-NoProfile", "-Command", strings.Join([]string{
+				"Add-Type -AssemblyName System.Windows.Forms",
+				"$d = New-Object System.Windows.Forms.OpenFileDialog",
+				"$d.Title = " + psQuote(title),
+				"$d.Filter = " + psQuote("ROMs|"+strings.Join(globs, ";")+"|All files|*.*"),
+				"if ($d.ShowDialog() -eq 'OK') { $d.FileName }",
+			}, "; "),
+		}}
+	default:
+		return [][]string{
+			{
+				"zenity", "--file-selection", "--title=" + title,
+				"--file-filter=ROMs | " + strings.Join(globs, " "),
+				"--file-filter=All files | *",
+			},
+			{
+				"kdialog", "--title", title, "--getopenfilename", ".",
+				strings.Join(globs, " ") + "|ROMs",
+			},
+		}
+	}
+}
+
+// errorCommands returns the commands which may show the error dialog on goos,
+// in order of preference.
+func errorCommands(goos, title, msg string) [][]string {
+	switch goos {
+	case "darwin":
+		return [][]string{{
+			"osascript", "-e",
+			"display alert " + appleQuote(title) + " message " + appleQuote(msg) + " as critical",
+		}}
+	case "windows":
+		return [][]string{{
+			"powershell", "-NoProfile", "-Command", strings.Join([]string{
+				"Add-Type -AssemblyName System.Windows.Forms",
+				"[System.Windows.Forms.MessageBox]::Show(" + psQuote(msg) + ", " + psQuote(title) + ", 'OK', 'Error') | Out-Null",
+			}, "; "),
+		}}
+	default:
+		return [][]string{
+			{"zenity", "--error", "--no-markup", "--title=" + title, "--text=" + msg},
+			{"kdialog", "--title", title, "--error", msg},
+		}
+	}
+}
+
+// appleQuote quotes s as an AppleScript string.
+func appleQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// psQuote quotes s as a PowerShell string.
+func psQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,47 @@
+package dialog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOpenCommands(t *testing.T) {
+	exts := []string{".ch8", ".sc8"}
+
+	linux := openCommands("linux", "Open", exts)
+	if len(linux) != 2 || linux[0][0] != "zenity" || linux[1][0] != "kdialog" {
+		t.Fatalf("expected zenity then kdialog, got %q", linux)
+	}
+	want := []string{
+		"zenity", "--file-selection", "--title=Open",
+		"--file-filter=ROMs | *.ch8 *.sc8",
+		"--file-filter=All files | *",
+	}
+	if !reflect.DeepEqual(linux[0], want) {
+		t.Fatalf("expected %q, got %q", want, linux[0])
+	}
+
+	mac := openCommands("darwin", `Say "hi"`, exts)
+	if got := mac[0][2]; got != `POSIX path of (choose file with prompt "Say \"hi\"")` {
+		t.Fatalf("unexpected script %q", got)
+	}
+
+	win := openCommands("windows", "Don't", exts)
+	if got := win[0][3]; !strings.Contains(got, "'Don''t'") || !strings.Contains(got, "'ROMs|*.ch8;*.sc8|All files|*.*'") {
+		t.Fatalf("unexpected script %q", got)
+	}
+}
+
+func TestErrorCommands(t *testing.T) {
+	linux := errorCommands("linux", "chip8", "<oops>")
+	want := []string{"zenity", "--error", "--no-markup", "--title=chip8", "--text=<oops>"}
+	if !reflect.DeepEqual(linux[0], want) {
+		t.Fatalf("expected %q, got %q", want, linux[0])
+	}
+
+	win := errorCommands("windows", "chip8", "it's broken")
+	if got := win[0][3]; !strings.Contains(got, "Show('it''s broken', 'chip8', 'OK', 'Error')") {
+		t.Fatalf("unexpected script %q", got)
+	}
+}
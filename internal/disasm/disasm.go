@@ -0,0 +1,172 @@
+// Package disasm disassembles CHIP-8 machine code into the mnemonics used by
+// Cowgod's technical reference, e.g. "LD V0, 0x05" or "DRW V1, V2, 5".
+package disasm
+
+import (
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+// Line is a single disassembled instruction.
+type Line struct {
+	Addr   uint16
+	Opcode uint16
+
+	// Label is the name of the symbol at Addr, if any.
+	Label string
+
+	// Text is the disassembled instruction.
+	Text string
+}
+
+// String returns the line formatted as it would appear in a listing.
+func (l Line) String() string {
+	s := fmt.Sprintf("0x%03X  %04X  %s", l.Addr, l.Opcode, l.Text)
+	if l.Label != "" {
+		s = l.Label + ":\n" + s
+	}
+	return s
+}
+
+// Range disassembles the instructions in mem starting at from, up to but not
+// including to. Addresses covered by a data symbol are output as raw bytes
+// rather than instructions.
+func Range(mem []byte, from, to uint16, syms *symbol.Table) []Line {
+	var lines []Line
+
+	for addr := from; addr < to && int(addr)+1 < len(mem); addr += 2 {
+		opc := uint16(mem[addr])<<8 | uint16(mem[addr+1])
+
+		l := Line{
+			Addr:   addr,
+			Opcode: opc,
+			Text:   Instruction(opc, syms),
+		}
+		if s, ok := syms.Lookup(addr); ok {
+			l.Label = s.Name
+		}
+		if isData(syms, addr) {
+			l.Text = fmt.Sprintf("DB 0x%02X, 0x%02X", mem[addr], mem[addr+1])
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines
+}
+
+// isData returns true if addr falls inside a data symbol.
+func isData(syms *symbol.Table, addr uint16) bool {
+	if syms == nil {
+		return false
+	}
+	for _, s := range syms.Symbols() {
+		if s.Kind == symbol.Data && addr >= s.Addr && uint32(addr) < uint32(s.Addr)+uint32(s.Size) {
+			return true
+		}
+	}
+	return false
+}
+
+// Instruction disassembles a single opcode. Addresses are replaced with symbol
+// names where syms has one. Unknown opcodes are output as raw data.
+func Instruction(opc uint16, syms *symbol.Table) string {
+	var (
+		x   = opc >> 8 & 0xF
+		y   = opc >> 4 & 0xF
+		n   = opc & 0xF
+		nn  = opc & 0xFF
+		nnn = opc & 0xFFF
+	)
+
+	addr := fmt.Sprintf("0x%03X", nnn)
+	if name := syms.Describe(nnn); name != "" {
+		addr = name
+	}
+
+	switch opc >> 12 {
+	case 0x0:
+		switch opc {
+		case 0x00E0:
+			return "CLS"
+		case 0x00EE:
+			return "RET"
+		}
+		return "SYS " + addr
+	case 0x1:
+		return "JP " + addr
+	case 0x2:
+		return "CALL " + addr
+	case 0x3:
+		return fmt.Sprintf("SE V%X, 0x%02X", x, nn)
+	case 0x4:
+		return fmt.Sprintf("SNE V%X, 0x%02X", x, nn)
+	case 0x5:
+		if n == 0 {
+			return fmt.Sprintf("SE V%X, V%X", x, y)
+		}
+	case 0x6:
+		return fmt.Sprintf("LD V%X, 0x%02X", x, nn)
+	case 0x7:
+		return fmt.Sprintf("ADD V%X, 0x%02X", x, nn)
+	case 0x8:
+		if op, ok := aluOps[n]; ok {
+			if n == 0x6 || n == 0xE {
+				return fmt.Sprintf("%s V%X {, V%X}", op, x, y)
+			}
+			return fmt.Sprintf("%s V%X, V%X", op, x, y)
+		}
+	case 0x9:
+		if n == 0 {
+			return fmt.Sprintf("SNE V%X, V%X", x, y)
+		}
+	case 0xA:
+		return "LD I, " + addr
+	case 0xB:
+		return "JP V0, " + addr
+	case 0xC:
+		return fmt.Sprintf("RND V%X, 0x%02X", x, nn)
+	case 0xD:
+		return fmt.Sprintf("DRW V%X, V%X, %d", x, y, n)
+	case 0xE:
+		switch nn {
+		case 0x9E:
+			return fmt.Sprintf("SKP V%X", x)
+		case 0xA1:
+			return fmt.Sprintf("SKNP V%X", x)
+		}
+	case 0xF:
+		if f, ok := miscOps[nn]; ok {
+			return fmt.Sprintf(f, x)
+		}
+	}
+
+	return fmt.Sprintf("DW 0x%04X", opc)
+}
+
+// aluOps are the mnemonics for the 8XYN instructions.
+var aluOps = map[uint16]string{
+	0x0: "LD",
+	0x1: "OR",
+	0x2: "AND",
+	0x3: "XOR",
+	0x4: "ADD",
+	0x5: "SUB",
+	0x6: "SHR",
+	0x7: "SUBN",
+	0xE: "SHL",
+}
+
+// miscOps are the formats of the FXNN instructions.
+var miscOps = map[uint16]string{
+	0x07: "LD V%X, DT",
+	0x0A: "LD V%X, K",
+	0x15: "LD DT, V%X",
+	0x18: "LD ST, V%X",
+	0x1E: "ADD I, V%X",
+	0x29: "LD F, V%X",
+	0x33: "LD B, V%X",
+	0x55: "LD [I], V%X",
+	0x65: "LD V%X, [I]",
+}
@@ -0,0 +1,83 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+func TestInstruction(t *testing.T) {
+	syms, err := symbol.Parse(strings.NewReader("0x200 start\n0x300 sprite data 0x5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[uint16]string{
+		0x00E0: "CLS",
+		0x00EE: "RET",
+		0x0123: "SYS 0x123",
+		0x1200: "JP start",
+		0x2456: "CALL 0x456",
+		0x3A12: "SE VA, 0x12",
+		0x4A12: "SNE VA, 0x12",
+		0x5AB0: "SE VA, VB",
+		0x5AB1: "DW 0x5AB1",
+		0x6105: "LD V1, 0x05",
+		0x71FF: "ADD V1, 0xFF",
+		0x8120: "LD V1, V2",
+		0x8124: "ADD V1, V2",
+		0x8126: "SHR V1 {, V2}",
+		0x8127: "SUBN V1, V2",
+		0x812E: "SHL V1 {, V2}",
+		0x8128: "DW 0x8128",
+		0x9120: "SNE V1, V2",
+		0xA302: "LD I, sprite+2",
+		0xB300: "JP V0, sprite",
+		0xC10F: "RND V1, 0x0F",
+		0xD125: "DRW V1, V2, 5",
+		0xE19E: "SKP V1",
+		0xE1A1: "SKNP V1",
+		0xE1A2: "DW 0xE1A2",
+		0xF107: "LD V1, DT",
+		0xF10A: "LD V1, K",
+		0xF115: "LD DT, V1",
+		0xF118: "LD ST, V1",
+		0xF11E: "ADD I, V1",
+		0xF129: "LD F, V1",
+		0xF133: "LD B, V1",
+		0xF155: "LD [I], V1",
+		0xF165: "LD V1, [I]",
+		0xF1FF: "DW 0xF1FF",
+	}
+	for opc, exp := range tests {
+		if got := Instruction(opc, syms); got != exp {
+			t.Errorf("0x%04X: expected %q, got %q", opc, exp, got)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	syms, err := symbol.Parse(strings.NewReader("0x200 start\n0x204 sprite data 0x2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mem := make([]byte, 4096)
+	copy(mem[0x200:], []byte{0x60, 0x05, 0x12, 0x00, 0xF0, 0x90})
+
+	lines := Range(mem, 0x200, 0x206, syms)
+	exp := []string{
+		"start:\n0x200  6005  LD V0, 0x05",
+		"0x202  1200  JP start",
+		"sprite:\n0x204  F090  DB 0xF0, 0x90",
+	}
+	if len(lines) != len(exp) {
+		t.Fatalf("expected %d lines, got %d", len(exp), len(lines))
+	}
+	for i, l := range lines {
+		if l.String() != exp[i] {
+			t.Errorf("line %d: expected %q, got %q", i, exp[i], l.String())
+		}
+	}
+}
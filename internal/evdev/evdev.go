@@ -0,0 +1,137 @@
+// Package evdev reads the keypad from Linux input devices, /dev/input/event*,
+// with no windowing system, for consoles drawing to a framebuffer or
+// terminal.
+package evdev
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// The event types and codes used, from linux/input-event-codes.h.
+const (
+	evKey = 0x01
+	evAbs = 0x03
+
+	keyEsc = 1
+
+	absHat0X = 0x10
+	absHat0Y = 0x11
+)
+
+// Keymap maps the key and button codes of linux/input-event-codes.h to keys
+// on the keypad.
+type Keymap map[uint16]byte
+
+// DefaultKeymap maps the keyboard as in the window, and a gamepad's D-pad to
+// 2, 4, 6 and 8 with its south and east buttons as 5 and 0.
+var DefaultKeymap = Keymap{
+	2: 0x1, 3: 0x2, 4: 0x3, 5: 0xC, // 1 2 3 4
+	16: 0x4, 17: 0x5, 18: 0x6, 19: 0xD, // Q W E R
+	30: 0x7, 31: 0x8, 32: 0x9, 33: 0xE, // A S D F
+	44: 0xA, 45: 0x0, 46: 0xB, 47: 0xF, // Z X C V
+
+	0x220: 0x2, 0x221: 0x8, 0x222: 0x4, 0x223: 0x6, // BTN_DPAD_UP, DOWN, LEFT, RIGHT
+	0x130: 0x5, 0x131: 0x0, // BTN_SOUTH, BTN_EAST
+}
+
+// hatKeys are the keys pressed by a gamepad's hat, for D-pads reported as an
+// axis, by axis and direction.
+var hatKeys = map[uint16][2]byte{
+	absHat0X: {0x4, 0x6},
+	absHat0Y: {0x2, 0x8},
+}
+
+// ErrEscape is returned by Next when Escape is pressed.
+var ErrEscape = errors.New("escape pressed")
+
+// Event is a key on the keypad pressed or released.
+type Event struct {
+	Key  byte
+	Down bool
+}
+
+// Device is an input device read for the keypad.
+type Device struct {
+	r      io.Reader
+	f      *os.File
+	keymap Keymap
+
+	// Events decoded but not yet returned, as a hat moving from one side to
+	// the other releases one key and presses another.
+	pending []Event
+}
+
+// timevalSize is the size of the timestamp starting each event, which
+// depends on the architecture.
+const timevalSize = int(unsafe.Sizeof(syscall.Timeval{}))
+
+// eventSize is the size of struct input_event.
+const eventSize = timevalSize + 8
+
+// Open opens the input device at path, such as /dev/input/event0, mapping
+// its keys with keymap.
+func Open(path string, keymap Keymap) (*Device, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{r: f, f: f, keymap: keymap}, nil
+}
+
+// NewDevice returns a device reading struct input_event from r, mapping its
+// keys with keymap.
+func NewDevice(r io.Reader, keymap Keymap) *Device {
+	return &Device{r: r, keymap: keymap}
+}
+
+// Close closes the device.
+func (d *Device) Close() error {
+	if d.f == nil {
+		return nil
+	}
+	return d.f.Close()
+}
+
+// Next blocks until a key on the keypad is pressed or released and returns
+// it. Keys not on the keypad and key repeats are skipped.
+func (d *Device) Next() (Event, error) {
+	buf := make([]byte, eventSize)
+	for len(d.pending) == 0 {
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return Event{}, err
+		}
+
+		typ := binary.LittleEndian.Uint16(buf[timevalSize:])
+		code := binary.LittleEndian.Uint16(buf[timevalSize+2:])
+		value := int32(binary.LittleEndian.Uint32(buf[timevalSize+4:]))
+
+		switch typ {
+		case evKey:
+			if code == keyEsc && value == 1 {
+				return Event{}, ErrEscape
+			}
+			// A value of 2 is a repeat of a held key.
+			if k, ok := d.keymap[code]; ok && value < 2 {
+				d.pending = append(d.pending, Event{Key: k, Down: value == 1})
+			}
+		case evAbs:
+			keys, ok := hatKeys[code]
+			if !ok {
+				continue
+			}
+			d.pending = append(d.pending,
+				Event{Key: keys[0], Down: value < 0},
+				Event{Key: keys[1], Down: value > 0},
+			)
+		}
+	}
+
+	e := d.pending[0]
+	d.pending = d.pending[1:]
+	return e, nil
+}
@@ -0,0 +1,82 @@
+package evdev
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// encode encodes events of type, code and value as struct input_event.
+func encode(events ...[3]int) []byte {
+	var buf bytes.Buffer
+	for _, e := range events {
+		b := make([]byte, eventSize)
+		binary.LittleEndian.PutUint16(b[timevalSize:], uint16(e[0]))
+		binary.LittleEndian.PutUint16(b[timevalSize+2:], uint16(e[1]))
+		binary.LittleEndian.PutUint32(b[timevalSize+4:], uint32(int32(e[2])))
+		buf.Write(b)
+	}
+	return buf.Bytes()
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		events [][3]int
+		want   []Event
+		err    error
+	}{
+		{
+			name:   "key press and release",
+			events: [][3]int{{evKey, 16, 1}, {0, 0, 0}, {evKey, 16, 0}},
+			want:   []Event{{Key: 0x4, Down: true}, {Key: 0x4}},
+			err:    io.EOF,
+		},
+		{
+			name:   "repeats and unmapped keys skipped",
+			events: [][3]int{{evKey, 45, 1}, {evKey, 45, 2}, {evKey, 57, 1}, {evKey, 45, 0}},
+			want:   []Event{{Key: 0x0, Down: true}, {Key: 0x0}},
+			err:    io.EOF,
+		},
+		{
+			name:   "hat",
+			events: [][3]int{{evAbs, absHat0X, -1}, {evAbs, absHat0X, 1}, {evAbs, 0x00, 100}},
+			want: []Event{
+				{Key: 0x4, Down: true}, {Key: 0x6},
+				{Key: 0x4}, {Key: 0x6, Down: true},
+			},
+			err: io.EOF,
+		},
+		{
+			name:   "escape",
+			events: [][3]int{{evKey, 0x130, 1}, {evKey, keyEsc, 1}, {evKey, 16, 1}},
+			want:   []Event{{Key: 0x5, Down: true}},
+			err:    ErrEscape,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d := NewDevice(bytes.NewReader(encode(tc.events...)), DefaultKeymap)
+
+			var got []Event
+			var err error
+			for {
+				var e Event
+				if e, err = d.Next(); err != nil {
+					break
+				}
+				got = append(got, e)
+			}
+
+			if err != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package evdev
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ioctlGrab is EVIOCGRAB.
+const ioctlGrab = 0x40044590
+
+// Grab takes the device for this process only, so key presses don't also
+// reach the console the emulator is running on.
+func (d *Device) Grab() error {
+	if d.f == nil {
+		return errors.New("only opened devices can be grabbed")
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.f.Fd(), ioctlGrab, 1); errno != 0 {
+		return errno
+	}
+	return nil
+}
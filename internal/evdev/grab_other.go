@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package evdev
+
+import "errors"
+
+// Grab takes the device for this process only. Input devices are only
+// supported on Linux.
+func (d *Device) Grab() error {
+	return errors.New("input devices are only supported on Linux")
+}
@@ -0,0 +1,60 @@
+package event
+
+import (
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// caption is an Overlay of fixed lines.
+type caption []string
+
+// Lines implements Overlay.
+func (c caption) Lines() []string {
+	return c
+}
+
+// Demo plays frames of vm, set up to play back a movie, in win as an attract
+// mode, with lines drawn over it, until a key or mouse button is pressed or
+// the frames are over. It returns false if the window was closed.
+func Demo(win *pixelgl.Window, vm *chip8.VM, frames int, lines []string) bool {
+	h := NewHandler(win, vm)
+	h.SetOverlay(caption(lines))
+
+	t := time.NewTicker(time.Second / chip8.FrameRate)
+	defer t.Stop()
+
+	for f := 0; f < frames && !vm.Halted(); f++ {
+		<-t.C
+		if win.Closed() {
+			return false
+		}
+		if anyPressed(win) {
+			// The press only leaves the demo, so isn't seen again.
+			win.UpdateInput()
+			return true
+		}
+		if err := vm.StepFrame(); err != nil {
+			break
+		}
+		h.draw()
+	}
+	return !win.Closed()
+}
+
+// anyPressed returns whether any key or mouse button was pressed since the
+// window's input was last updated.
+func anyPressed(win *pixelgl.Window) bool {
+	for b := pixelgl.KeySpace; b <= pixelgl.KeyLast; b++ {
+		if win.JustPressed(b) {
+			return true
+		}
+	}
+	for b := pixelgl.MouseButton1; b <= pixelgl.MouseButtonLast; b++ {
+		if win.JustPressed(b) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,22 @@
 package event
 
 import (
-	"log"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/macro"
 	"github.com/danmrichards/chip8/internal/sound"
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/imdraw"
 	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
 	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
 )
 
 var keys = map[byte]pixelgl.Button{
@@ -18,77 +26,606 @@ var keys = map[byte]pixelgl.Button{
 	0xA: pixelgl.KeyZ, 0x0: pixelgl.KeyX, 0xB: pixelgl.KeyC, 0xF: pixelgl.KeyV,
 }
 
+// Overlay provides lines of text to draw over the display.
+type Overlay interface {
+	Lines() []string
+}
+
+// VisualBeep is a way of showing on screen that the tone is sounding.
+type VisualBeep int
+
+const (
+	// NoVisualBeep shows nothing.
+	NoVisualBeep VisualBeep = iota
+
+	// BorderBeep draws a border around the display.
+	BorderBeep
+
+	// InvertBeep inverts the colours of the display.
+	InvertBeep
+)
+
+// VisualBeeps are the names of the visual beep modes, indexed by mode.
+var VisualBeeps = []string{"none", "border", "invert"}
+
+// ParseVisualBeep returns the visual beep mode called name.
+func ParseVisualBeep(name string) (VisualBeep, error) {
+	for i, n := range VisualBeeps {
+		if n == name {
+			return VisualBeep(i), nil
+		}
+	}
+	return NoVisualBeep, fmt.Errorf("unknown visual beep %q, expected one of %q", name, VisualBeeps)
+}
+
+// DefaultFrameRate is the default rate, in Hz, at which frames are presented.
+const DefaultFrameRate = 60
+
 // Handler is responsible for handling input and output for the vm.
 type Handler struct {
-	window *pixelgl.Window
-	vm     *chip8.VM
+	window  *pixelgl.Window
+	vm      chip8.Core
+	overlay Overlay
+	atlas   *text.Atlas
+	audio   sound.Audio
+
+	// The display is rendered into canvas, one texel per pixel, from pixels
+	// and drawn scaled to the window. imd draws the border beep, reused
+	// between frames so its buffers aren't reallocated every draw.
+	canvas *pixelgl.Canvas
+	pixels []uint8
+	imd    *imdraw.IMDraw
+
+	// With a shader set the scaled frame is drawn into post, which runs the
+	// shader as it's drawn to the window. shaderTime is the uTime uniform.
+	shader     string
+	post       *pixelgl.Canvas
+	shaderTime float32
+	start      time.Time
+
+	// Maximum number of frames presented per second. Zero presents a frame
+	// for every draw signal from the VM.
+	fps int
+
+	// How the tone is shown on screen while it sounds.
+	visualBeep VisualBeep
+	toneOn     bool
+
+	// Set when the window needs redrawing even though the display hasn't
+	// changed.
+	stale bool
+
+	// Set while the program has halted, when the game over message is drawn.
+	halted bool
+
+	// The state of each key at the last poll, so only changes are queued.
+	keys [16]bool
+
+	// Set by Pause, atomically as it's called from the emulation loop, and
+	// paused once the handler has acted on it.
+	pause  int32
+	paused bool
+
+	// With the latency HUD shown, signalled is when the VM first signalled a
+	// draw since the last frame, and frameLatency the time from that signal
+	// to the last frame being presented.
+	hud          bool
+	signalled    time.Time
+	frameLatency time.Duration
+
+	// Statistics of the frames presented, shown in the HUD, and the count
+	// of them kept atomically for Frames.
+	stats  frameStats
+	frames uint64
+
+	// When integerScale is set each display pixel is drawn as an exact NxN
+	// block of window pixels, centred in the window, rather than being
+	// stretched to fill it.
+	integerScale bool
+
+	// The rotation, in degrees clockwise, and mirroring of the display.
+	rotate int
+	mirror Mirror
+
+	// The heatmap drawn over the display, if set, with heatIMD reused to
+	// draw it.
+	heatmap Heatmap
+	heatIMD *imdraw.IMDraw
+
+	// The save state menu, drawn over everything while it's open.
+	menu *SlotMenu
+
+	// Set while the on-screen keypad is shown, and keypadKey while the key
+	// toggling it is held.
+	keypad    bool
+	keypadKey bool
+
+	// The macros bound to host keys, if any, the keys they're bound to and
+	// the CHIP-8 keys they hold.
+	macros       *macro.Player
+	macroButtons map[string]pixelgl.Button
+	macroKeys    [16]bool
+
+	// view maps the display, centred on the origin, to the window as last
+	// drawn, and viewW and viewH are its size. They're read by DisplayPoint
+	// from other goroutines.
+	viewMu       sync.Mutex
+	view         pixel.Matrix
+	viewW, viewH int
 }
 
-// NewHandler returns a new event handler.
-func NewHandler(win *pixelgl.Window, vm *chip8.VM) Handler {
+// NewHandler returns a new event handler for vm, which may be any variant.
+func NewHandler(win *pixelgl.Window, vm chip8.Core) Handler {
 	return Handler{
 		window: win,
 		vm:     vm,
+		fps:    DefaultFrameRate,
+		audio:  sound.Null{},
+	}
+}
+
+// SetAudio sets the backend used to play the tone.
+func (h *Handler) SetAudio(a sound.Audio) {
+	h.audio = a
+}
+
+// SetFrameRate sets the maximum number of frames presented per second. Draw
+// signals from the VM between frames are coalesced, so the window is redrawn
+// at most fps times a second regardless of VSync or the monitor refresh rate.
+// Zero disables the limit.
+func (h *Handler) SetFrameRate(fps int) {
+	h.fps = fps
+}
+
+// SetVisualBeep sets how the tone is shown on screen while it sounds, for
+// players who can't hear it or when there is no audio device.
+func (h *Handler) SetVisualBeep(vb VisualBeep) {
+	h.visualBeep = vb
+}
+
+// SetIntegerScale sets whether the display is scaled by whole multiples only,
+// keeping pixels crisp and square at any window size.
+func (h *Handler) SetIntegerScale(on bool) {
+	h.integerScale = on
+}
+
+// SetShader sets a GLSL fragment shader to post-process the scaled frame
+// with, e.g. to curve it like a CRT. Besides the uniforms pixel gives every
+// canvas shader it's given uTime, the seconds since the first frame.
+func (h *Handler) SetShader(src string) {
+	h.shader = src
+}
+
+// SetHUD sets whether the frame statistics and the frame and audio latency
+// are shown in the window, for tuning the frame rate, VSync and audio buffer.
+func (h *Handler) SetHUD(on bool) {
+	h.hud = on
+}
+
+// Frames returns the number of frames presented. It's safe to call while the
+// handler runs.
+func (h *Handler) Frames() uint64 {
+	return atomic.LoadUint64(&h.frames)
+}
+
+// SetOverlay sets the source of text drawn over the display.
+func (h *Handler) SetOverlay(o Overlay) {
+	h.overlay = o
+	if h.atlas == nil {
+		h.atlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	}
+}
+
+// SetSlotMenu sets the save state menu drawn over the display while it's
+// open.
+func (h *Handler) SetSlotMenu(m *SlotMenu) {
+	h.menu = m
+	if h.atlas == nil {
+		h.atlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
 	}
 }
 
 // Handle continually loops while the VM window is open; handling events.
-// Events are handled with a non-blocking select. Draw and sound events are
-// handled independently with input being treated as the default event to check.
+// Events are handled with a non-blocking select. The VM's draw and beep events
+// are handled as they're published, with input being treated as the default
+// event to check.
 func (h *Handler) Handle() {
+	// With a frame rate set, draw signals only mark a frame as pending and
+	// the window is redrawn on the next frame tick.
+	var (
+		frame   <-chan time.Time
+		pending bool
+	)
+	if h.fps > 0 {
+		t := time.NewTicker(time.Second / time.Duration(h.fps))
+		defer t.Stop()
+		frame = t.C
+	}
+
+	macroTick, stop := h.macroTicker()
+	defer stop()
+
+	events := h.vm.Subscribe(16)
+	defer events.Close()
+
 	for !h.window.Closed() {
 		select {
-		case <-h.vm.Draw():
-			h.draw()
-		case <-h.vm.Beep():
-			if err := sound.Beep(); err != nil {
-				log.Printf("Error playing beep: %q\n", err)
+		case e := <-events.Events():
+			switch e.(type) {
+			case chip8.DrawEvent:
+				if h.signalled.IsZero() {
+					h.signalled = time.Now()
+				}
+			case chip8.BeepStartEvent, chip8.BeepStopEvent:
+				_, on := e.(chip8.BeepStartEvent)
+				h.tone(on)
+				if h.visualBeep == NoVisualBeep {
+					continue
+				}
+				h.stale = true
+			default:
+				continue
+			}
+			if frame == nil {
+				h.draw()
+			} else {
+				pending = true
 			}
+		case <-frame:
+			if pending {
+				h.draw()
+				pending = false
+			}
+		case <-macroTick:
+			h.playMacros()
 		default:
-			h.input()
+			if h.input() {
+				h.stale = true
+				if frame == nil {
+					h.draw()
+				} else {
+					pending = true
+				}
+			}
+			if paused := atomic.LoadInt32(&h.pause) == 1; paused != h.paused {
+				h.paused = paused
+				h.pauseTone()
+				h.stale = true
+				if frame == nil {
+					h.draw()
+				} else {
+					pending = true
+				}
+			}
+			if halted := h.vm.Halted(); halted != h.halted {
+				h.halted = halted
+				h.stale = true
+				if frame == nil {
+					h.draw()
+				} else {
+					pending = true
+				}
+			}
+			if h.menu != nil && h.menu.changed() {
+				h.stale = true
+				if frame == nil {
+					h.draw()
+				} else {
+					pending = true
+				}
+			}
 		}
 	}
 }
 
-// input iterates over the keyset, checking if any of them are pressed and
-// updates the vm accordingly.
-func (h *Handler) input() {
+// Pause shows the emulator as paused, or not, silencing the tone while it is.
+// It's safe to call from any goroutine.
+func (h *Handler) Pause(paused bool) {
+	var p int32
+	if paused {
+		p = 1
+	}
+	atomic.StoreInt32(&h.pause, p)
+}
+
+// pauseTone silences the tone while paused, and restarts it after if it was
+// sounding.
+func (h *Handler) pauseTone() {
+	var err error
+	if h.paused {
+		err = h.audio.StopTone()
+	} else if h.toneOn {
+		h.tone(true)
+	}
+	if err != nil {
+		logging.Warnf("Error stopping tone: %q", err)
+	}
+}
+
+// tone starts or stops the tone.
+func (h *Handler) tone(on bool) {
+	h.toneOn = on
+
+	var err error
+	if pattern, ok := h.vm.Pattern(); on && ok {
+		err = h.audio.PlayPattern(pattern)
+	} else if on {
+		err = h.audio.StartTone()
+	} else {
+		err = h.audio.StopTone()
+	}
+	if err != nil {
+		logging.Warnf("Error playing tone: %q", err)
+	}
+}
+
+// input iterates over the keyset, queueing a press or release on the vm for
+// each key that has changed since the last poll. The vm applies them at its
+// next frame, so taps between polls of the program aren't missed. Keys of the
+// on-screen keypad held with the mouse, and keys held by macros, count as
+// pressed. It returns true if the keypad needs redrawing.
+func (h *Handler) input() bool {
+	redraw := h.toggleKeypad()
+	clicked, clicking := h.clickedKey()
 	for i, key := range keys {
-		if h.window.Pressed(key) {
-			h.vm.KeyDown(i)
+		down := h.window.Pressed(key) || (clicking && clicked == i) || h.macroKeys[i]
+		if down == h.keys[i] {
+			continue
 		}
+		h.keys[i] = down
+		if down {
+			h.vm.Press(i)
+		} else {
+			h.vm.Release(i)
+		}
+		redraw = redraw || h.keypad
 	}
+	return redraw
 }
 
 // draw updates the window based on the current state of the VM graphics array.
+// Nothing is redrawn if the display hasn't changed since the last draw.
 func (h *Handler) draw() {
-	h.window.Clear(colornames.Black)
+	// The changes are taken as the display is marked clean, so that any made
+	// by the VM while drawing are kept for the next draw.
+	disp := h.vm.Display()
+	dirty := len(disp.TakeDirtyRects()) > 0
+	if !dirty && h.overlay == nil && h.heatmap == nil && !h.hud && !h.stale {
+		return
+	}
+	h.stale = false
 
-	imd := imdraw.New(nil)
-	imd.Color = pixel.RGB(0.14, 0.8, 0.26)
+	var (
+		bg = pixel.ToRGBA(colornames.Black)
+		fg = pixel.RGB(0.14, 0.8, 0.26)
+	)
+	if h.visualBeep == InvertBeep && h.toneOn {
+		bg, fg = fg, bg
+	}
+
+	h.window.Clear(bg)
 
 	scrW := h.window.Bounds().W()
 	scrH := h.window.Bounds().H()
 
-	// Calculate the screen ratio.
-	rW, rH := scrW/64, scrH/32
+	// Calculate the screen ratio, from the display as it's seen once
+	// rotated.
+	w, ht := RotatedSize(disp.Width(), disp.Height(), h.rotate)
+	rW, rH := scrW/float64(w), scrH/float64(ht)
 
-	for x := 0; x < 64; x++ {
-		for y := 0; y < 32; y++ {
-			if !h.vm.PixelSet((31-y)*64 + x) {
-				continue
-			}
+	// With integer scaling use the largest whole ratio that fits both ways
+	// and centre the display, leaving a border around it.
+	var offX, offY float64
+	if h.integerScale {
+		r := math.Floor(math.Min(rW, rH))
+		if r < 1 {
+			r = 1
+		}
+		rW, rH = r, r
+		offX = math.Floor((scrW - r*float64(w)) / 2)
+		offY = math.Floor((scrH - r*float64(ht)) / 2)
+	}
 
-			// Scale the pixel co-ords.
-			sX := rW * float64(x)
-			sY := rH * float64(y)
+	// Draw the frame into the shader's canvas if there is one, otherwise
+	// straight to the window.
+	var target pixel.Target = h.window
+	if h.shader != "" {
+		h.prepareShader(bg)
+		target = h.post
+	}
 
-			imd.Push(pixel.V(sX, sY))
-			imd.Push(pixel.V(sX+rW, sY+rH))
-			imd.Rectangle(0)
+	h.render(disp, bg, fg)
+	m := h.orient().
+		ScaledXY(pixel.ZV, pixel.V(rW, rH)).
+		Moved(pixel.V(offX+rW*float64(w)/2, offY+rH*float64(ht)/2))
+	h.canvas.Draw(target, m)
+	h.setView(m, disp.Width(), disp.Height())
+
+	if h.visualBeep == BorderBeep && h.toneOn {
+		if h.imd == nil {
+			h.imd = imdraw.New(nil)
 		}
+		h.imd.Clear()
+		h.imd.Reset()
+		h.imd.Color = colornames.Orange
+		h.imd.Push(pixel.V(0, 0), pixel.V(scrW, scrH))
+		h.imd.Rectangle(16)
+		h.imd.Draw(target)
 	}
 
-	imd.Draw(h.window)
+	if h.post != nil {
+		h.shaderTime = float32(time.Since(h.start).Seconds())
+		h.post.Draw(h.window, pixel.IM.Moved(h.window.Bounds().Center()))
+	}
+
+	h.drawHeatmap(m)
+	h.drawOverlay()
+	h.drawKeypad()
+	switch {
+	case h.menu != nil && h.menu.Open():
+		h.menu.draw(h.window, h.atlas)
+	case h.paused:
+		h.drawBanner(pausedLines)
+	case h.halted:
+		h.drawBanner(haltedLines)
+	}
+	h.drawHUD()
 	h.window.Update()
+	h.stats.present(h.vm.Draws(), time.Now())
+	atomic.StoreUint64(&h.frames, h.stats.presented)
+
+	if !h.signalled.IsZero() {
+		h.frameLatency = time.Since(h.signalled)
+		h.signalled = time.Time{}
+	}
+}
+
+// setView records how the display was last drawn, for DisplayPoint.
+func (h *Handler) setView(m pixel.Matrix, w, ht int) {
+	h.viewMu.Lock()
+	defer h.viewMu.Unlock()
+
+	h.view, h.viewW, h.viewH = m, w, ht
+}
+
+// DisplayPoint returns the point of the display, in pixels from its top left,
+// shown at p in the window, taking the scaling and orientation into account.
+// It's false until the display has been drawn. It's safe to call from any
+// goroutine.
+func (h *Handler) DisplayPoint(p pixel.Vec) (x, y float64, ok bool) {
+	h.viewMu.Lock()
+	defer h.viewMu.Unlock()
+
+	if h.viewH == 0 {
+		return 0, 0, false
+	}
+	d := h.view.Unproject(p)
+	return d.X + float64(h.viewW)/2, float64(h.viewH)/2 - d.Y, true
+}
+
+// drawHUD draws the frame statistics and the frame and audio latency in the
+// bottom left of the window, if the HUD is shown.
+func (h *Handler) drawHUD() {
+	if !h.hud {
+		return
+	}
+	if h.atlas == nil {
+		h.atlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	}
+
+	txt := text.New(pixel.V(4, 4+3*h.atlas.LineHeight()), h.atlas)
+	txt.Color = colornames.White
+	avg, max := h.stats.interval()
+	fmt.Fprintf(txt, "frames %d, %d draws dropped (%.1f%%)\n", h.stats.presented, h.stats.dropped, h.stats.droppedPercent())
+	fmt.Fprintf(txt, "interval %.1fms avg, %.1fms max\n", avg.Seconds()*1000, max.Seconds()*1000)
+	fmt.Fprintf(txt, "frame %.1fms\n", h.frameLatency.Seconds()*1000)
+	fmt.Fprintf(txt, "audio %.1fms", h.audio.Latency().Seconds()*1000)
+	txt.Draw(h.window, pixel.IM)
+}
+
+// render renders disp into the canvas, resizing it to match the display. The
+// canvas isn't smoothed, so it scales with nearest-neighbour filtering and
+// pixels stay sharp.
+func (h *Handler) render(disp *chip8.Display, bg, fg pixel.RGBA) {
+	w, ht := disp.Width(), disp.Height()
+	bounds := pixel.R(0, 0, float64(w), float64(ht))
+	if h.canvas == nil {
+		h.canvas = pixelgl.NewCanvas(bounds)
+		h.canvas.SetSmooth(false)
+	} else if h.canvas.Bounds() != bounds {
+		h.canvas.SetBounds(bounds)
+	}
+	if len(h.pixels) != 4*w*ht {
+		h.pixels = make([]uint8, 4*w*ht)
+	}
+
+	// The canvas rows run from the bottom of the display up.
+	for y := 0; y < ht; y++ {
+		row := ht - 1 - y
+		for x := 0; x < w; x++ {
+			c := bg
+			if disp.Pixel(x, row) {
+				c = fg
+				if disp.Palette != nil {
+					c = pixel.ToRGBA(disp.Palette[disp.Index(x, row)])
+				}
+			}
+
+			i := 4 * (y*w + x)
+			h.pixels[i] = uint8(c.R * 255)
+			h.pixels[i+1] = uint8(c.G * 255)
+			h.pixels[i+2] = uint8(c.B * 255)
+			h.pixels[i+3] = uint8(c.A * 255)
+		}
+	}
+	h.canvas.SetPixels(h.pixels)
+}
+
+// prepareShader creates or resizes the canvas running the shader to match the
+// window, and clears it to bg.
+func (h *Handler) prepareShader(bg pixel.RGBA) {
+	bounds := h.window.Bounds()
+	if h.post == nil {
+		h.post = pixelgl.NewCanvas(bounds)
+		h.post.SetUniform("uTime", &h.shaderTime)
+		h.post.SetFragmentShader(h.shader)
+		h.start = time.Now()
+	} else if h.post.Bounds() != bounds {
+		h.post.SetBounds(bounds)
+	}
+	h.post.Clear(bg)
+}
+
+// haltedLines and pausedLines are the messages drawn once the program halts
+// and while the emulator is paused.
+var (
+	haltedLines = []string{"GAME OVER", "Press R to reset"}
+	pausedLines = []string{"PAUSED"}
+)
+
+// drawBanner draws lines of text, large and centred in the window.
+func (h *Handler) drawBanner(lines []string) {
+	if h.atlas == nil {
+		h.atlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	}
+
+	txt := text.New(pixel.ZV, h.atlas)
+	txt.Color = colornames.White
+	for _, l := range lines {
+		txt.Dot.X -= txt.BoundsOf(l).W() / 2
+		fmt.Fprintln(txt, l)
+	}
+
+	// Draw the text twice its size, on a dark backing to stand out from the
+	// display.
+	const scale = 2
+	centre := h.window.Bounds().Center()
+	m := pixel.IM.Scaled(pixel.ZV, scale).Moved(centre.Sub(txt.Bounds().Center().Scaled(scale)))
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGBA{A: 0.75}
+	b := txt.Bounds()
+	imd.Push(m.Project(b.Min.Sub(pixel.V(8, 8))), m.Project(b.Max.Add(pixel.V(8, 8))))
+	imd.Rectangle(0)
+	imd.Draw(h.window)
+
+	txt.Draw(h.window, m)
+}
+
+// drawOverlay draws the overlay text, if any, in the top left of the window.
+func (h *Handler) drawOverlay() {
+	if h.overlay == nil {
+		return
+	}
+	lines := h.overlay.Lines()
+	if len(lines) == 0 {
+		return
+	}
+
+	txt := text.New(pixel.V(4, h.window.Bounds().H()-16), h.atlas)
+	txt.Color = colornames.White
+	for _, l := range lines {
+		fmt.Fprintln(txt, l)
+	}
+	txt.Draw(h.window, pixel.IM)
 }
@@ -0,0 +1,68 @@
+package event
+
+import (
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+)
+
+// Heatmap provides how often each cell of the display is drawn and where in
+// memory the program is executing, each between 0 and 1, to draw over the
+// display.
+type Heatmap interface {
+	Cells() (w, h int, heat []float64)
+	PCs() []float64
+}
+
+// pcBarHeight is the height in window pixels of the memory activity bar.
+const pcBarHeight = 32
+
+// SetHeatmap sets the source of the heatmap drawn over the display, showing
+// the cells drawn to most and a bar of the memory executed along the bottom.
+func (h *Handler) SetHeatmap(m Heatmap) {
+	h.heatmap = m
+}
+
+// drawHeatmap draws the heatmap over the window, the display's pixels placed
+// by m as the display canvas is.
+func (h *Handler) drawHeatmap(m pixel.Matrix) {
+	if h.heatmap == nil {
+		return
+	}
+	if h.heatIMD == nil {
+		h.heatIMD = imdraw.New(nil)
+	}
+	imd := h.heatIMD
+	imd.Clear()
+	imd.Reset()
+
+	// Rows run from the top of the display but up the canvas, which is
+	// centred on the origin.
+	w, ht, cells := h.heatmap.Cells()
+	imd.SetMatrix(m)
+	for y := 0; y < ht; y++ {
+		for x := 0; x < w; x++ {
+			heat := cells[y*w+x]
+			if heat < 0.01 {
+				continue
+			}
+			imd.Color = pixel.RGBA{R: 1, G: 0.2, B: 0, A: 1}.Mul(pixel.Alpha(0.6 * heat))
+			x0, y0 := float64(x)-float64(w)/2, float64(ht-1-y)-float64(ht)/2
+			imd.Push(pixel.V(x0, y0), pixel.V(x0+1, y0+1))
+			imd.Rectangle(0)
+		}
+	}
+
+	imd.SetMatrix(pixel.IM)
+	pcs := h.heatmap.PCs()
+	bw := h.window.Bounds().W() / float64(len(pcs))
+	for i, heat := range pcs {
+		if heat < 0.01 {
+			continue
+		}
+		imd.Color = pixel.RGBA{R: 1, G: 0.8, B: 0, A: 1}.Mul(pixel.Alpha(0.7))
+		imd.Push(pixel.V(float64(i)*bw, 0), pixel.V(float64(i+1)*bw-1, heat*pcBarHeight))
+		imd.Rectangle(0)
+	}
+
+	imd.Draw(h.window)
+}
@@ -0,0 +1,119 @@
+package event
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// keypadKeys are the keys of the on-screen keypad, row by row from the top,
+// laid out like the COSMAC VIP's.
+var keypadKeys = [4][4]byte{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// keypadToggle shows and hides the on-screen keypad.
+const keypadToggle = pixelgl.KeyF3
+
+// SetKeypad sets whether the on-screen keypad is shown, so games can be
+// played by clicking or touching its keys. F3 shows and hides it.
+func (h *Handler) SetKeypad(on bool) {
+	h.keypad = on
+	if h.atlas == nil {
+		h.atlas = text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	}
+}
+
+// toggleKeypad shows or hides the keypad when F3 is pressed, returning true
+// if it did. The key's state is tracked here rather than with JustPressed, as
+// input is polled many times between updates of the window's input.
+func (h *Handler) toggleKeypad() bool {
+	down := h.window.Pressed(keypadToggle)
+	if down == h.keypadKey {
+		return false
+	}
+	h.keypadKey = down
+	if down {
+		h.SetKeypad(!h.keypad)
+	}
+	return down
+}
+
+// clickedKey returns the key of the keypad held with the mouse or a touch,
+// if it's shown.
+func (h *Handler) clickedKey() (byte, bool) {
+	if !h.keypad || !h.window.Pressed(pixelgl.MouseButtonLeft) {
+		return 0, false
+	}
+	return keypadAt(keypadRect(h.window.Bounds()), h.window.MousePosition())
+}
+
+// keypadRect returns where the keypad is drawn in a window of bounds b: a
+// square in the bottom right corner, two fifths of the window's shorter side.
+func keypadRect(b pixel.Rect) pixel.Rect {
+	s := math.Floor(math.Min(b.W(), b.H()) * 2 / 5)
+	return pixel.R(b.Max.X-s-8, b.Min.Y+8, b.Max.X-8, b.Min.Y+8+s)
+}
+
+// keypadAt returns the key of the keypad in r at p, false if p isn't on it.
+func keypadAt(r pixel.Rect, p pixel.Vec) (byte, bool) {
+	if !r.Contains(p) {
+		return 0, false
+	}
+	col := int((p.X - r.Min.X) / r.W() * 4)
+	row := int((r.Max.Y - p.Y) / r.H() * 4)
+	if col < 0 || col > 3 || row < 0 || row > 3 {
+		return 0, false
+	}
+	return keypadKeys[row][col], true
+}
+
+// drawKeypad draws the keypad, if it's shown, with the keys held lit.
+func (h *Handler) drawKeypad() {
+	if !h.keypad {
+		return
+	}
+
+	r := keypadRect(h.window.Bounds())
+	cell := r.W() / 4
+
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGBA{A: 0.6}
+	imd.Push(r.Min, r.Max)
+	imd.Rectangle(0)
+
+	labels := text.New(pixel.ZV, h.atlas)
+	labels.Color = colornames.White
+	for row, keys := range keypadKeys {
+		for col, k := range keys {
+			min := pixel.V(r.Min.X+float64(col)*cell, r.Max.Y-float64(row+1)*cell)
+			key := pixel.Rect{Min: min.Add(pixel.V(2, 2)), Max: min.Add(pixel.V(cell-2, cell-2))}
+
+			imd.Color = pixel.RGBA{R: 1, G: 1, B: 1, A: 0.5}
+			if h.keys[k] {
+				imd.Color = pixel.RGB(0.14, 0.8, 0.26)
+				imd.Push(key.Min, key.Max)
+				imd.Rectangle(0)
+			}
+			imd.Push(key.Min, key.Max)
+			imd.Rectangle(1)
+
+			l := fmt.Sprintf("%X", k)
+			c := key.Center()
+			labels.Dot = c.Sub(pixel.V(labels.BoundsOf(l).W()/2, h.atlas.LineHeight()/4))
+			fmt.Fprint(labels, l)
+		}
+	}
+
+	imd.Draw(h.window)
+	labels.Draw(h.window, pixel.IM)
+}
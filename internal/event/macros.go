@@ -0,0 +1,58 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/macro"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// ParseButton returns the keyboard key called name, as pixelgl names them,
+// such as "G", "Space" or "LeftShift", in any case.
+func ParseButton(name string) (pixelgl.Button, error) {
+	for b := pixelgl.KeySpace; b <= pixelgl.KeyLast; b++ {
+		if s := b.String(); s != "Invalid" && strings.EqualFold(s, name) {
+			return b, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown key %q", name)
+}
+
+// SetMacros sets the player of the macros bound to host keys in the keymap.
+// It returns an error if a macro is bound to a key that doesn't exist.
+func (h *Handler) SetMacros(p *macro.Player) error {
+	buttons := map[string]pixelgl.Button{}
+	for _, name := range p.Names() {
+		b, err := ParseButton(name)
+		if err != nil {
+			return err
+		}
+		buttons[name] = b
+	}
+	h.macros, h.macroButtons = p, buttons
+	return nil
+}
+
+// macroTicker returns the channel ticking each frame the macros are played
+// on, nil without macros, and the function stopping it.
+func (h *Handler) macroTicker() (<-chan time.Time, func()) {
+	if h.macros == nil {
+		return nil, func() {}
+	}
+	t := time.NewTicker(time.Second / chip8.FrameRate)
+	return t.C, t.Stop
+}
+
+// playMacros plays a frame of the macros, unless the game is paused, holding
+// the keys they hold from the next poll of input.
+func (h *Handler) playMacros() {
+	if h.paused {
+		return
+	}
+	h.macroKeys = h.macros.Frame(func(name string) bool {
+		return h.window.Pressed(h.macroButtons[name])
+	})
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/faiface/pixel"
+)
+
+// Mirror is a way of flipping the display as it's drawn.
+type Mirror int
+
+const (
+	// NoMirror draws the display as it is.
+	NoMirror Mirror = iota
+
+	// MirrorHorizontal flips the display left to right.
+	MirrorHorizontal
+
+	// MirrorVertical flips the display top to bottom.
+	MirrorVertical
+)
+
+// Mirrors are the names of the mirror modes, indexed by mode.
+var Mirrors = []string{"none", "horizontal", "vertical"}
+
+// ParseMirror returns the mirror mode called name.
+func ParseMirror(name string) (Mirror, error) {
+	for i, n := range Mirrors {
+		if n == name {
+			return Mirror(i), nil
+		}
+	}
+	return NoMirror, fmt.Errorf("unknown mirror %q, expected one of %q", name, Mirrors)
+}
+
+// Rotations are the angles, in degrees clockwise, the display can be rotated
+// by.
+var Rotations = []int{0, 90, 180, 270}
+
+// ValidRotation returns an error if the display can't be rotated by degrees.
+func ValidRotation(degrees int) error {
+	for _, r := range Rotations {
+		if r == degrees {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid rotation %d, expected one of %v", degrees, Rotations)
+}
+
+// SetOrientation sets the rotation, in degrees clockwise, and mirroring of
+// the display as it's drawn, for screens mounted on their side or seen in a
+// mirror. The rotation must be one of Rotations. Keys are unaffected.
+func (h *Handler) SetOrientation(degrees int, m Mirror) {
+	h.rotate, h.mirror = degrees, m
+}
+
+// RotatedSize returns the size of a w by h display rotated by degrees.
+func RotatedSize(w, h, degrees int) (int, int) {
+	if degrees == 90 || degrees == 270 {
+		return h, w
+	}
+	return w, h
+}
+
+// orient returns the matrix mirroring and rotating the display, centred on
+// the origin as the canvas is drawn.
+func (h *Handler) orient() pixel.Matrix {
+	m := pixel.IM
+	switch h.mirror {
+	case MirrorHorizontal:
+		m = m.ScaledXY(pixel.ZV, pixel.V(-1, 1))
+	case MirrorVertical:
+		m = m.ScaledXY(pixel.ZV, pixel.V(1, -1))
+	}
+
+	// Pixel rotates anticlockwise. Rounded, quarter turns map pixels to
+	// pixels exactly.
+	m = m.Rotated(pixel.ZV, -float64(h.rotate)*math.Pi/180)
+	for i := 0; i < 4; i++ {
+		m[i] = math.Round(m[i])
+	}
+	return m
+}
@@ -0,0 +1,164 @@
+package event
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/slots"
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+)
+
+// slotCols is the number of slots in each row of the menu.
+const slotCols = 5
+
+// SlotMenu is the save state menu drawn over the game: the thumbnail and the
+// time saved of each slot, one of them selected. It's changed from the
+// emulation loop and drawn by the handler, so is safe for concurrent use.
+type SlotMenu struct {
+	mu    sync.Mutex
+	open  bool
+	slots []slots.Slot
+	pics  []*pixel.PictureData
+	sel   int
+
+	// Set when the menu has changed since it was last drawn.
+	dirty bool
+}
+
+// Show opens the menu on the slots given, or updates it if it's open.
+func (m *SlotMenu) Show(list []slots.Slot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.open, m.slots, m.dirty = true, list, true
+	m.pics = make([]*pixel.PictureData, len(list))
+	for i, s := range list {
+		if s.Thumb != nil {
+			m.pics[i] = pixel.PictureDataFromImage(s.Thumb)
+		}
+	}
+	if m.sel >= len(list) {
+		m.sel = 0
+	}
+}
+
+// Hide closes the menu.
+func (m *SlotMenu) Hide() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.open, m.dirty = false, true
+}
+
+// Open returns true if the menu is shown.
+func (m *SlotMenu) Open() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.open
+}
+
+// Move moves the selection by d slots, wrapping around.
+func (m *SlotMenu) Move(d int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if n := len(m.slots); n > 0 {
+		m.sel = ((m.sel+d)%n + n) % n
+		m.dirty = true
+	}
+}
+
+// MoveRow moves the selection by d rows, wrapping around.
+func (m *SlotMenu) MoveRow(d int) {
+	m.Move(d * slotCols)
+}
+
+// Selected returns the number of the slot selected.
+func (m *SlotMenu) Selected() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sel >= len(m.slots) {
+		return 0
+	}
+	return m.slots[m.sel].N
+}
+
+// changed returns true if the menu needs redrawing, once per change.
+func (m *SlotMenu) changed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c := m.dirty
+	m.dirty = false
+	return c
+}
+
+// draw draws the menu over the window, if it's open, in rows of slotCols.
+func (m *SlotMenu) draw(win *pixelgl.Window, atlas *text.Atlas) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.open {
+		return
+	}
+
+	b := win.Bounds()
+	imd := imdraw.New(nil)
+	imd.Color = pixel.RGBA{A: 0.85}
+	imd.Push(b.Min, b.Max)
+	imd.Rectangle(0)
+
+	title := text.New(pixel.V(16, b.H()-24), atlas)
+	title.Color = colornames.White
+	fmt.Fprint(title, "Save states   Arrows pick   Space save   Enter load   F2 close")
+
+	// Thumbnails keep the display's 2:1 shape, with room for a caption.
+	const pad = 16
+	cellW := (b.W() - pad) / slotCols
+	thumbW := cellW - pad
+	thumbH := thumbW / 2
+	cellH := thumbH + 2*atlas.LineHeight() + pad
+	top := b.H() - 48
+
+	captions := text.New(pixel.ZV, atlas)
+	captions.Color = colornames.White
+	var thumbs []func()
+	for i, s := range m.slots {
+		min := pixel.V(pad+float64(i%slotCols)*cellW, top-float64(i/slotCols+1)*cellH+2*atlas.LineHeight()+pad)
+		rect := pixel.Rect{Min: min, Max: min.Add(pixel.V(thumbW, thumbH))}
+
+		imd.Color = colornames.Black
+		imd.Push(rect.Min, rect.Max)
+		imd.Rectangle(0)
+		if i == m.sel {
+			imd.Color = colornames.Yellow
+			imd.Push(rect.Min.Sub(pixel.V(3, 3)), rect.Max.Add(pixel.V(3, 3)))
+			imd.Rectangle(3)
+		}
+
+		if pic := m.pics[i]; pic != nil {
+			sc := pixel.V(rect.W()/pic.Bounds().W(), rect.H()/pic.Bounds().H())
+			sprite := pixel.NewSprite(pic, pic.Bounds())
+			at := rect.Center()
+			thumbs = append(thumbs, func() {
+				sprite.Draw(win, pixel.IM.ScaledXY(pixel.ZV, sc).Moved(at))
+			})
+		}
+
+		saved := "Empty"
+		if !s.Empty() {
+			saved = s.Saved.Format("Jan 2 15:04")
+		}
+		captions.Dot = pixel.V(rect.Min.X, rect.Min.Y-atlas.LineHeight())
+		fmt.Fprintf(captions, "Slot %d\n", s.N)
+		captions.Dot.X = rect.Min.X
+		fmt.Fprint(captions, saved)
+	}
+
+	imd.Draw(win)
+	for _, t := range thumbs {
+		t()
+	}
+	title.Draw(win, pixel.IM)
+	captions.Draw(win, pixel.IM)
+}
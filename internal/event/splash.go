@@ -0,0 +1,221 @@
+package event
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+	"golang.org/x/image/colornames"
+	"golang.org/x/image/font/basicfont"
+)
+
+// glyphWidth is the width in pixels of each character of basicfont.Face7x13.
+const glyphWidth = 7
+
+// splashLines are the instructions drawn above the ROM browser.
+var splashLines = []string{
+	"CHIP-8",
+	"",
+	"The keypad is mapped to the keys:",
+	"",
+	"  1 2 3 4        1 2 3 C",
+	"  Q W E R   ->   4 5 6 D",
+	"  A S D F        7 8 9 E",
+	"  Z X C V        A 0 B F",
+	"",
+	"Escape quits. Run chip8 help for the commands.",
+	"",
+}
+
+// Preview is what the ROM browser shows of the ROM selected, beside the
+// list.
+type Preview struct {
+	// Picture is its cover art or thumbnail, nil if it has neither.
+	Picture image.Image
+
+	// Text describes it, wrapped to fit.
+	Text string
+}
+
+// Pick is how the splash screen was left.
+type Pick int
+
+// The ways the splash screen is left.
+const (
+	// Quit closed it without picking a ROM.
+	Quit Pick = iota
+
+	// Picked picked a ROM.
+	Picked
+
+	// Idle left it to nobody for the idle time given, for an attract mode
+	// to play a demo.
+	Idle
+)
+
+// Splash shows a splash screen with the controls and a browser of roms in
+// win, until one is picked with the arrow keys and Enter or the splash is
+// closed with Escape. label, if set, returns a note shown beside each ROM,
+// such as its high score. preview, if set, returns what's shown of the ROM
+// selected, looked up only as ROMs are selected. Without input for idle, if
+// set, it returns Idle. It returns the ROM picked with Picked.
+func Splash(win *pixelgl.Window, roms []string, label func(rom string) string, preview func(rom string) Preview, idle time.Duration) (string, Pick) {
+	atlas := text.NewAtlas(basicfont.Face7x13, text.ASCII)
+	sel := 0
+	lastInput, mouse := time.Now(), win.MousePosition()
+
+	// Previews are kept once looked up, with their pictures ready to draw.
+	type shown struct {
+		Preview
+		pic *pixel.PictureData
+	}
+	previews := map[int]shown{}
+
+	// Labels are found once, they may read files.
+	labels := make([]string, len(roms))
+	if label != nil {
+		for i, r := range roms {
+			labels[i] = label(r)
+		}
+	}
+
+	for !win.Closed() {
+		if m := win.MousePosition(); anyPressed(win) || m != mouse {
+			lastInput, mouse = time.Now(), m
+		} else if idle > 0 && time.Since(lastInput) >= idle {
+			return "", Idle
+		}
+
+		switch {
+		case win.JustPressed(pixelgl.KeyEscape):
+			return "", Quit
+		case win.JustPressed(pixelgl.KeyEnter) && len(roms) > 0:
+			return roms[sel], Picked
+		case win.JustPressed(pixelgl.KeyUp) && sel > 0:
+			sel--
+		case win.JustPressed(pixelgl.KeyDown) && sel < len(roms)-1:
+			sel++
+		}
+
+		win.Clear(colornames.Black)
+
+		// With a preview, the list takes the left half of the window and
+		// the preview the right.
+		b := win.Bounds()
+		cols := 0
+		if preview != nil && len(roms) > 0 {
+			p, ok := previews[sel]
+			if !ok {
+				p.Preview = preview(roms[sel])
+				if p.Picture != nil {
+					p.pic = pixel.PictureDataFromImage(p.Picture)
+				}
+				previews[sel] = p
+			}
+			drawPreview(win, atlas, p.pic, p.Text, pixel.R(b.W()/2, 16, b.W()-16, b.H()-24))
+			cols = int((b.W()/2 - 32) / glyphWidth)
+		}
+
+		txt := text.New(pixel.V(16, b.H()-24), atlas)
+		txt.Color = pixel.RGB(0.14, 0.8, 0.26)
+		for _, l := range splashLines {
+			fmt.Fprintln(txt, l)
+		}
+
+		if len(roms) == 0 {
+			fmt.Fprintln(txt, "No ROMs found, pass one as an argument: chip8 game.ch8")
+		} else {
+			fmt.Fprintln(txt, "Pick a ROM with the arrow keys and Enter:")
+			fmt.Fprintln(txt)
+		}
+
+		// Scroll the list to keep the selected ROM in view.
+		rows := int((txt.Dot.Y-16)/atlas.LineHeight()) - 1
+		if rows < 1 {
+			rows = 1
+		}
+		first := 0
+		if sel >= rows {
+			first = sel - rows + 1
+		}
+		for i := first; i < len(roms) && i < first+rows; i++ {
+			marker := "  "
+			if i == sel {
+				marker = "> "
+			}
+			line := marker + roms[i]
+			if labels[i] != "" {
+				line += "  (" + labels[i] + ")"
+			}
+			if cols > 0 && len(line) > cols {
+				line = line[:cols]
+			}
+			fmt.Fprintln(txt, line)
+		}
+
+		txt.Draw(win, pixel.IM)
+		win.Update()
+	}
+
+	return "", Quit
+}
+
+// drawPreview draws pic, if set, at the top of rect, as large as fits keeping
+// its shape, with desc wrapped below it.
+func drawPreview(win *pixelgl.Window, atlas *text.Atlas, pic *pixel.PictureData, desc string, rect pixel.Rect) {
+	top := rect.Max.Y
+	if pic != nil {
+		pb := pic.Bounds()
+		s := rect.W() / pb.W()
+		if h := rect.H() / 2; pb.H()*s > h {
+			s = h / pb.H()
+		}
+		at := pixel.V(rect.Min.X+pb.W()*s/2, top-pb.H()*s/2)
+		pixel.NewSprite(pic, pb).Draw(win, pixel.IM.Scaled(pixel.ZV, s).Moved(at))
+		top -= pb.H()*s + atlas.LineHeight()
+	}
+
+	txt := text.New(pixel.V(rect.Min.X, top-atlas.LineHeight()), atlas)
+	txt.Color = colornames.White
+	cols := int(rect.W() / glyphWidth)
+	for _, l := range wrap(desc, cols) {
+		fmt.Fprintln(txt, l)
+	}
+	txt.Draw(win, pixel.IM)
+}
+
+// wrap splits s into lines of at most cols characters, breaking between
+// words where it can. Line breaks in s are kept.
+func wrap(s string, cols int) []string {
+	if cols < 1 {
+		cols = 1
+	}
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		line := ""
+		for _, w := range strings.Fields(para) {
+			for len(w) > cols {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				lines, w = append(lines, w[:cols]), w[cols:]
+			}
+			switch {
+			case line == "":
+				line = w
+			case len(line)+1+len(w) <= cols:
+				line += " " + w
+			default:
+				lines = append(lines, line)
+				line = w
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,70 @@
+package event
+
+import "time"
+
+// statsWindow is the number of recent frames the frame intervals are taken
+// over.
+const statsWindow = 60
+
+// frameStats are statistics of the frames presented: how many, the changes
+// to the display never shown because the VM changed it again before a frame
+// was presented, and the intervals between recent frames. Games that flicker
+// sprites by erasing and redrawing them look different depending on which
+// changes are shown.
+type frameStats struct {
+	presented, dropped uint64
+
+	// The VM's count of display changes when the first and last frames were
+	// presented, and when the last was.
+	first, draws uint64
+	last         time.Time
+
+	// The intervals between the last statsWindow frames, a ring buffer
+	// written at next.
+	intervals [statsWindow]time.Duration
+	next      int
+}
+
+// present records a frame presented at now, showing the display as it was
+// after draws changes.
+func (s *frameStats) present(draws uint64, now time.Time) {
+	if s.presented == 0 {
+		s.first = draws
+	} else {
+		if draws > s.draws+1 {
+			s.dropped += draws - s.draws - 1
+		}
+		s.intervals[s.next] = now.Sub(s.last)
+		s.next = (s.next + 1) % statsWindow
+	}
+	s.presented++
+	s.draws, s.last = draws, now
+}
+
+// interval returns the average and longest interval between recent frames.
+func (s *frameStats) interval() (avg, max time.Duration) {
+	var n time.Duration
+	for _, d := range s.intervals {
+		if d == 0 {
+			continue
+		}
+		avg += d
+		n++
+		if d > max {
+			max = d
+		}
+	}
+	if n > 0 {
+		avg /= n
+	}
+	return avg, max
+}
+
+// droppedPercent returns the changes dropped as a percentage of all those
+// made since the first frame.
+func (s *frameStats) droppedPercent() float64 {
+	if s.draws == s.first {
+		return 0
+	}
+	return float64(s.dropped) * 100 / float64(s.draws-s.first)
+}
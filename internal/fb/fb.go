@@ -0,0 +1,101 @@
+// Package fb draws the display straight to a Linux framebuffer device, with
+// no windowing system or OpenGL, for running the emulator as a dedicated
+// console such as a bare Raspberry Pi.
+package fb
+
+import (
+	"fmt"
+	"image"
+)
+
+// Framebuffer is a framebuffer device's memory mapped for drawing.
+type Framebuffer struct {
+	// The visible resolution in pixels, and bytes per row and pixel of mem.
+	w, h   int
+	stride int
+	bpp    int
+
+	// The offset and length in bits of each colour channel within a pixel.
+	red, green, blue channel
+
+	mem   []byte
+	close func() error
+}
+
+// channel is where a colour channel sits within a pixel.
+type channel struct {
+	offset, length uint32
+}
+
+// Size returns the visible resolution of the framebuffer in pixels.
+func (f *Framebuffer) Size() (w, h int) {
+	return f.w, f.h
+}
+
+// Close unmaps the framebuffer and closes the device.
+func (f *Framebuffer) Close() error {
+	if f.close == nil {
+		return nil
+	}
+	return f.close()
+}
+
+// Draw draws img scaled up by the largest whole multiple that fits, centred
+// with a black border around it. Only the regions of img in rects are drawn,
+// or the whole framebuffer, border included, if rects is nil.
+func (f *Framebuffer) Draw(img *image.Paletted, rects []image.Rectangle) error {
+	if f.bpp < 2 || f.bpp > 4 {
+		return fmt.Errorf("unsupported framebuffer depth of %d bits", 8*f.bpp)
+	}
+
+	iw, ih := img.Rect.Dx(), img.Rect.Dy()
+	scale := f.w / iw
+	if s := f.h / ih; s < scale {
+		scale = s
+	}
+	if scale < 1 {
+		return fmt.Errorf("framebuffer of %dx%d is smaller than the display of %dx%d", f.w, f.h, iw, ih)
+	}
+	offX, offY := (f.w-iw*scale)/2, (f.h-ih*scale)/2
+
+	palette := make([]uint32, len(img.Palette))
+	for i, c := range img.Palette {
+		r, g, b, _ := c.RGBA()
+		palette[i] = f.red.pack(r) | f.green.pack(g) | f.blue.pack(b)
+	}
+
+	if rects == nil {
+		f.fill(image.Rect(0, 0, f.w, f.h), img, palette, scale, offX, offY)
+		return nil
+	}
+	for _, r := range rects {
+		r = r.Intersect(img.Rect).Sub(img.Rect.Min)
+		r = image.Rect(offX+r.Min.X*scale, offY+r.Min.Y*scale, offX+r.Max.X*scale, offY+r.Max.Y*scale)
+		f.fill(r, img, palette, scale, offX, offY)
+	}
+	return nil
+}
+
+// fill draws the pixels of the framebuffer within r, from img scaled by scale
+// and offset by offX and offY. Pixels outside img are black.
+func (f *Framebuffer) fill(r image.Rectangle, img *image.Paletted, palette []uint32, scale, offX, offY int) {
+	iw, ih := img.Rect.Dx(), img.Rect.Dy()
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		row := f.mem[y*f.stride : y*f.stride+f.w*f.bpp]
+		iy := (y - offY) / scale
+		for x := r.Min.X; x < r.Max.X; x++ {
+			var px uint32
+			if ix := (x - offX) / scale; x >= offX && y >= offY && ix < iw && iy < ih {
+				px = palette[img.Pix[iy*img.Stride+ix]]
+			}
+			for b := 0; b < f.bpp; b++ {
+				row[x*f.bpp+b] = byte(px >> (8 * b))
+			}
+		}
+	}
+}
+
+// pack returns the 16-bit colour value v placed in the channel.
+func (c channel) pack(v uint32) uint32 {
+	return v >> (16 - c.length) << c.offset
+}
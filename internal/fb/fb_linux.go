@@ -0,0 +1,99 @@
+package fb
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// The framebuffer ioctls, from linux/fb.h.
+const (
+	ioctlGetVarInfo = 0x4600
+	ioctlGetFixInfo = 0x4602
+)
+
+// varInfo is struct fb_var_screeninfo.
+type varInfo struct {
+	xres, yres                uint32
+	xresVirtual, yresVirtual  uint32
+	xoffset, yoffset          uint32
+	bitsPerPixel, grayscale   uint32
+	red, green, blue, transp  bitfield
+	nonstd, activate          uint32
+	height, width, accelFlags uint32
+	pixclock                  uint32
+	leftMargin, rightMargin   uint32
+	upperMargin, lowerMargin  uint32
+	hsyncLen, vsyncLen, sync  uint32
+	vmode, rotate, colorspace uint32
+	reserved                  [4]uint32
+}
+
+// bitfield is struct fb_bitfield.
+type bitfield struct {
+	offset, length, msbRight uint32
+}
+
+// fixInfo is struct fb_fix_screeninfo.
+type fixInfo struct {
+	id                 [16]byte
+	smemStart          uintptr
+	smemLen            uint32
+	typ, typeAux       uint32
+	visual             uint32
+	xpanstep, ypanstep uint16
+	ywrapstep          uint16
+	lineLength         uint32
+	mmioStart          uintptr
+	mmioLen            uint32
+	accel              uint32
+	capabilities       uint16
+	reserved           [2]uint16
+}
+
+// Open opens and maps the framebuffer device at path, such as /dev/fb0.
+func Open(path string) (*Framebuffer, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var vi varInfo
+	var fi fixInfo
+	if err = ioctl(f, ioctlGetVarInfo, unsafe.Pointer(&vi)); err == nil {
+		err = ioctl(f, ioctlGetFixInfo, unsafe.Pointer(&fi))
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	mem, err := syscall.Mmap(int(f.Fd()), 0, int(fi.smemLen), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &Framebuffer{
+		w:      int(vi.xres),
+		h:      int(vi.yres),
+		stride: int(fi.lineLength),
+		bpp:    int(vi.bitsPerPixel) / 8,
+		red:    channel{vi.red.offset, vi.red.length},
+		green:  channel{vi.green.offset, vi.green.length},
+		blue:   channel{vi.blue.offset, vi.blue.length},
+		mem:    mem,
+		close: func() error {
+			syscall.Munmap(mem)
+			return f.Close()
+		},
+	}, nil
+}
+
+// ioctl performs the ioctl req on f with arg.
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(arg)); errno != 0 {
+		return errno
+	}
+	return nil
+}
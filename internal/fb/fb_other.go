@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package fb
+
+import "errors"
+
+// Open opens and maps the framebuffer device at path. Framebuffers are only
+// supported on Linux.
+func Open(path string) (*Framebuffer, error) {
+	return nil, errors.New("framebuffers are only supported on Linux")
+}
@@ -0,0 +1,76 @@
+package fb
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestDraw(t *testing.T) {
+	// A 10x4 XRGB framebuffer, which a 4x2 display fills at twice the size
+	// with a border of a pixel either side.
+	f := &Framebuffer{
+		w: 10, h: 4, stride: 40, bpp: 4,
+		red:   channel{16, 8},
+		green: channel{8, 8},
+		blue:  channel{0, 8},
+		mem:   make([]byte, 40*4),
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, 4, 2), color.Palette{color.Black, color.RGBA{R: 0xFF, G: 0x80, A: 0xFF}})
+	img.SetColorIndex(1, 1, 1)
+
+	if err := f.Draw(img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			px := f.mem[y*f.stride+x*4:]
+			lit := x >= 3 && x < 5 && y >= 2
+			if lit && (px[2] != 0xFF || px[1] != 0x80 || px[0] != 0) {
+				t.Fatalf("expected (%d, %d) to be orange, got % X", x, y, px[:4])
+			}
+			if !lit && (px[0] != 0 || px[1] != 0 || px[2] != 0) {
+				t.Fatalf("expected (%d, %d) to be black, got % X", x, y, px[:4])
+			}
+		}
+	}
+}
+
+func TestDrawDirty(t *testing.T) {
+	// A 4x2 display drawn at twice the size to an 8x4 XRGB framebuffer.
+	f := &Framebuffer{
+		w: 8, h: 4, stride: 32, bpp: 4,
+		red:   channel{16, 8},
+		green: channel{8, 8},
+		blue:  channel{0, 8},
+		mem:   make([]byte, 32*4),
+	}
+
+	// Fill the framebuffer, so any pixel drawn shows as black.
+	for i := range f.mem {
+		f.mem[i] = 0xFF
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, 4, 2), color.Palette{color.Black, color.White})
+	if err := f.Draw(img, []image.Rectangle{image.Rect(1, 1, 3, 2)}); err != nil {
+		t.Fatal(err)
+	}
+
+	for y := 0; y < f.h; y++ {
+		for x := 0; x < f.w; x++ {
+			drawn := x >= 2 && x < 6 && y >= 2
+			if got := f.mem[y*f.stride+x*4] == 0; got != drawn {
+				t.Fatalf("expected (%d, %d) drawn to be %t", x, y, drawn)
+			}
+		}
+	}
+}
+
+func TestDrawTooSmall(t *testing.T) {
+	f := &Framebuffer{w: 32, h: 16, stride: 64, bpp: 2, mem: make([]byte, 64*16)}
+	if err := f.Draw(image.NewPaletted(image.Rect(0, 0, 64, 32), color.Palette{color.Black}), nil); err == nil {
+		t.Fatal("expected an error drawing a display larger than the framebuffer")
+	}
+}
@@ -0,0 +1,107 @@
+// Package fonts holds the font sets interpreters load into memory for programs
+// to draw the hex digits with FX29, and the SUPER-CHIP large font used by FX30.
+package fonts
+
+import "fmt"
+
+// Set is a small font, the sprites of the hex digits 0-F. Each character is
+// 5 bytes, a row per byte, drawn from the high 4 bits.
+type Set [80]byte
+
+// Octo is the font used by Octo and most modern interpreters, and the
+// default.
+var Octo = Set{
+	0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
+	0x20, 0x60, 0x20, 0x20, 0x70, // 1
+	0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
+	0xF0, 0x10, 0xF0, 0x10, 0xF0, // 3
+	0x90, 0x90, 0xF0, 0x10, 0x10, // 4
+	0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
+	0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
+	0xF0, 0x10, 0x20, 0x40, 0x40, // 7
+	0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
+	0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
+	0xF0, 0x90, 0xF0, 0x90, 0x90, // A
+	0xE0, 0x90, 0xE0, 0x90, 0xE0, // B
+	0xF0, 0x80, 0x80, 0x80, 0xF0, // C
+	0xE0, 0x90, 0x90, 0x90, 0xE0, // D
+	0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
+	0xF0, 0x80, 0xF0, 0x80, 0x80, // F
+}
+
+// Classic is the font of the original COSMAC VIP interpreter.
+var Classic = Set{
+	0xF0, 0x90, 0x90, 0x90, 0xF0, // 0
+	0x60, 0x20, 0x20, 0x20, 0x70, // 1
+	0xF0, 0x10, 0xF0, 0x80, 0xF0, // 2
+	0xF0, 0x10, 0x70, 0x10, 0xF0, // 3
+	0xA0, 0xA0, 0xF0, 0x20, 0x20, // 4
+	0xF0, 0x80, 0xF0, 0x10, 0xF0, // 5
+	0xF0, 0x80, 0xF0, 0x90, 0xF0, // 6
+	0xF0, 0x10, 0x10, 0x10, 0x10, // 7
+	0xF0, 0x90, 0xF0, 0x90, 0xF0, // 8
+	0xF0, 0x90, 0xF0, 0x10, 0xF0, // 9
+	0xF0, 0x90, 0xF0, 0x90, 0x90, // A
+	0xF0, 0x50, 0x70, 0x50, 0xF0, // B
+	0xF0, 0x80, 0x80, 0x80, 0xF0, // C
+	0xF0, 0x50, 0x50, 0x50, 0xF0, // D
+	0xF0, 0x80, 0xF0, 0x80, 0xF0, // E
+	0xF0, 0x80, 0xF0, 0x80, 0x80, // F
+}
+
+// Dream6800 is the narrower font of the DREAM 6800's CHIPOS, 3px wide.
+var Dream6800 = Set{
+	0xE0, 0xA0, 0xA0, 0xA0, 0xE0, // 0
+	0x40, 0x40, 0x40, 0x40, 0x40, // 1
+	0xE0, 0x20, 0xE0, 0x80, 0xE0, // 2
+	0xE0, 0x20, 0xE0, 0x20, 0xE0, // 3
+	0x80, 0xA0, 0xA0, 0xE0, 0x20, // 4
+	0xE0, 0x80, 0xE0, 0x20, 0xE0, // 5
+	0xE0, 0x80, 0xE0, 0xA0, 0xE0, // 6
+	0xE0, 0x20, 0x20, 0x20, 0x20, // 7
+	0xE0, 0xA0, 0xE0, 0xA0, 0xE0, // 8
+	0xE0, 0xA0, 0xE0, 0x20, 0xE0, // 9
+	0xE0, 0xA0, 0xE0, 0xA0, 0xA0, // A
+	0xC0, 0xA0, 0xE0, 0xA0, 0xC0, // B
+	0xE0, 0x80, 0x80, 0x80, 0xE0, // C
+	0xC0, 0xA0, 0xA0, 0xA0, 0xC0, // D
+	0xE0, 0x80, 0xE0, 0x80, 0xE0, // E
+	0xE0, 0x80, 0xC0, 0x80, 0x80, // F
+}
+
+// Styles are the names of the small fonts, the default first.
+var Styles = []string{"octo", "classic", "dream6800"}
+
+// Parse returns the small font called name.
+func Parse(name string) (Set, error) {
+	switch name {
+	case "octo":
+		return Octo, nil
+	case "classic":
+		return Classic, nil
+	case "dream6800":
+		return Dream6800, nil
+	}
+	return Set{}, fmt.Errorf("unknown font %q, expected one of %q", name, Styles)
+}
+
+// Big is the SUPER-CHIP large font, as extended to 0-F by XO-CHIP. Each
+// character is 10 bytes, a row per byte, 8px wide.
+var Big = [160]byte{
+	0xFF, 0xFF, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, // 0
+	0x18, 0x78, 0x78, 0x18, 0x18, 0x18, 0x18, 0x18, 0xFF, 0xFF, // 1
+	0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // 2
+	0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 3
+	0xC3, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0x03, 0x03, // 4
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 5
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 6
+	0xFF, 0xFF, 0x03, 0x03, 0x06, 0x0C, 0x18, 0x18, 0x18, 0x18, // 7
+	0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, // 8
+	0xFF, 0xFF, 0xC3, 0xC3, 0xFF, 0xFF, 0x03, 0x03, 0xFF, 0xFF, // 9
+	0x7E, 0xFF, 0xC3, 0xC3, 0xC3, 0xFF, 0xFF, 0xC3, 0xC3, 0xC3, // A
+	0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, 0xC3, 0xC3, 0xFC, 0xFC, // B
+	0x3C, 0xFF, 0xC3, 0xC0, 0xC0, 0xC0, 0xC0, 0xC3, 0xFF, 0x3C, // C
+	0xFC, 0xFE, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xC3, 0xFE, 0xFC, // D
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, // E
+	0xFF, 0xFF, 0xC0, 0xC0, 0xFF, 0xFF, 0xC0, 0xC0, 0xC0, 0xC0, // F
+}
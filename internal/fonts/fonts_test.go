@@ -0,0 +1,23 @@
+package fonts
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for _, name := range Styles {
+		if _, err := Parse(name); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+	}
+
+	f, err := Parse("classic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != Classic {
+		t.Fatal("expected the classic font")
+	}
+
+	if _, err = Parse("comic"); err == nil {
+		t.Fatal("expected an error for an unknown font")
+	}
+}
@@ -0,0 +1,152 @@
+// Package frontend runs the emulator behind a display and keypad other than
+// the window, such as a terminal. Frontends register themselves by name from
+// init, each in a file of this package, optionally behind a build tag.
+package frontend
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/logging"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+// Frontend shows the display of a VM and reads its keypad.
+type Frontend interface {
+	// Draw shows the display, redrawing only the regions in rects, or all
+	// of it if rects is nil.
+	Draw(img *image.Paletted, rects []image.Rectangle) error
+
+	// Poll returns the keys held, and whether the player has asked to quit.
+	// It's called once a frame.
+	Poll() (keys [16]bool, quit bool)
+
+	// Close gives back the display and keypad.
+	Close() error
+}
+
+// Factory opens a frontend.
+type Factory func() (Frontend, error)
+
+// frontends are the frontends registered, by name.
+var frontends = map[string]Factory{}
+
+// Register makes a frontend available to New as name. It panics if name is
+// already registered.
+func Register(name string, f Factory) {
+	if _, ok := frontends[name]; ok {
+		panic(fmt.Sprintf("frontend: %q registered twice", name))
+	}
+	frontends[name] = f
+}
+
+// Names returns the names of the registered frontends, sorted.
+func Names() []string {
+	names := make([]string, 0, len(frontends))
+	for name := range frontends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New opens the frontend called name.
+func New(name string) (Frontend, error) {
+	f, ok := frontends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown frontend %q, expected one of %q", name, Names())
+	}
+	return f()
+}
+
+// Run runs vm a frame at a time at ips instructions a second, drawing the
+// display to fe whenever it changes and sounding the tone with au. It returns
+// when the player quits, or once the program has halted and the tone has
+// finished.
+func Run(vm *chip8.VM, fe Frontend, au sound.Audio, ips int) error {
+	tick := time.NewTicker(time.Second / chip8.FrameRate)
+	defer tick.Stop()
+
+	perFrame := ips / chip8.FrameRate
+	if perFrame < 1 {
+		perFrame = 1
+	}
+
+	events := vm.Subscribe(4)
+	defer events.Close()
+
+	// The VM replaces its display as the resolution changes, which is then
+	// redrawn in full.
+	drawn := vm.Display()
+	if err := fe.Draw(vm.Image(), nil); err != nil {
+		return err
+	}
+	drawn.MarkClean()
+
+	for range tick.C {
+		keys, quit := fe.Poll()
+		if quit {
+			return nil
+		}
+		vm.SetKeys(keys)
+
+		for i := 0; i < perFrame; i++ {
+			if err := vm.Cycle(); err != nil {
+				return err
+			}
+		}
+
+		// The display is drawn below if it's dirty, only the tone needs
+		// acting on.
+	drain:
+		for {
+			select {
+			case e := <-events.Events():
+				switch e.(type) {
+				case chip8.BeepStartEvent:
+					tone(vm, au, true)
+				case chip8.BeepStopEvent:
+					tone(vm, au, false)
+				}
+			default:
+				break drain
+			}
+		}
+
+		if disp := vm.Display(); disp != drawn || disp.Dirty() {
+			var rects []image.Rectangle
+			if disp == drawn {
+				rects = disp.DirtyRects()
+			}
+			if err := fe.Draw(vm.Image(), rects); err != nil {
+				return err
+			}
+			disp.MarkClean()
+			drawn = disp
+		}
+
+		if _, st := vm.Timers(); vm.Halted() && st == 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+// tone starts or stops the tone, playing the program's waveform if it has set
+// one.
+func tone(vm *chip8.VM, au sound.Audio, on bool) {
+	var err error
+	if pattern, ok := vm.Pattern(); on && ok {
+		err = au.PlayPattern(pattern)
+	} else if on {
+		err = au.StartTone()
+	} else {
+		err = au.StopTone()
+	}
+	if err != nil {
+		logging.Warnf("Error playing tone: %s", err)
+	}
+}
@@ -0,0 +1,65 @@
+package frontend
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/sound"
+)
+
+// recorder is a frontend counting the frames drawn and recording the regions
+// redrawn.
+type recorder struct {
+	Null
+	draws int
+	rects [][]image.Rectangle
+}
+
+func (r *recorder) Draw(_ *image.Paletted, rects []image.Rectangle) error {
+	r.draws++
+	r.rects = append(r.rects, rects)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	// Draw a 0 then jump to itself, halting.
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader([]byte{0x60, 0x00, 0xF0, 0x29, 0xD0, 0x05, 0x12, 0x06})); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recorder{}
+	if err := Run(vm, r, sound.Null{}, chip8.ClockSpeed); err != nil {
+		t.Fatal(err)
+	}
+	if !vm.Halted() {
+		t.Fatal("expected Run to return once the program halted")
+	}
+	if r.draws != 2 {
+		t.Fatalf("expected the display drawn at the start and once the 0 was, got %d draws", r.draws)
+	}
+
+	// Only the 4x5 sprite is redrawn after the first frame.
+	if r.rects[0] != nil {
+		t.Fatalf("expected the first frame drawn in full, got %v", r.rects[0])
+	}
+	if exp := image.Rect(0, 0, 4, 5); len(r.rects[1]) != 1 || r.rects[1][0] != exp {
+		t.Fatalf("expected %v redrawn, got %v", exp, r.rects[1])
+	}
+}
+
+func TestNew(t *testing.T) {
+	fe, err := New("null")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fe.(Null); !ok {
+		t.Fatalf("expected the null frontend, got %T", fe)
+	}
+
+	if _, err = New("nope"); err == nil {
+		t.Fatal("expected an unknown frontend to fail")
+	}
+}
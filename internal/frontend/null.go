@@ -0,0 +1,22 @@
+package frontend
+
+import "image"
+
+func init() {
+	Register("null", func() (Frontend, error) {
+		return Null{}, nil
+	})
+}
+
+// Null is a frontend with no display and no keys held, to run programs
+// headlessly until they halt, say to record their coverage or a trace.
+type Null struct{}
+
+// Draw implements Frontend.
+func (Null) Draw(*image.Paletted, []image.Rectangle) error { return nil }
+
+// Poll implements Frontend.
+func (Null) Poll() ([16]bool, bool) { return [16]bool{}, false }
+
+// Close implements Frontend.
+func (Null) Close() error { return nil }
@@ -0,0 +1,191 @@
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"os"
+)
+
+func init() {
+	Register("term", func() (Frontend, error) {
+		return NewTerm(os.Stdin, os.Stdout)
+	})
+}
+
+// termKeys maps the keys typed to the keypad, laid out as in the window.
+var termKeys = map[byte]byte{
+	'1': 0x1, '2': 0x2, '3': 0x3, '4': 0xC,
+	'q': 0x4, 'w': 0x5, 'e': 0x6, 'r': 0xD,
+	'a': 0x7, 's': 0x8, 'd': 0x9, 'f': 0xE,
+	'z': 0xA, 'x': 0x0, 'c': 0xB, 'v': 0xF,
+}
+
+// termHold is the number of frames a key typed is held for. Terminals only
+// report keys being typed, repeating them after a delay while they're held,
+// so a key is taken as held until it has gone unrepeated for a while.
+const termHold = 15
+
+// ctrlC is the byte typed for Ctrl+C, which quits.
+const ctrlC = 0x03
+
+// halfBlocks are the characters drawing a pair of pixels, one above the
+// other, indexed by the top pixel being lit plus twice the bottom.
+var halfBlocks = [4]string{" ", "▀", "▄", "█"}
+
+// Term is a frontend drawing the display as text to a terminal and reading
+// the keys typed into it, for playing over SSH or without a desktop. Each
+// character shows two pixels, one above the other.
+type Term struct {
+	w   io.Writer
+	buf bytes.Buffer
+
+	// The keys typed, and the frames left until each key is released.
+	in   chan byte
+	held [16]int
+
+	// Puts the terminal back as it was, nil for writers other than
+	// terminals.
+	restore func() error
+}
+
+// NewTerm returns a frontend drawing to out and reading keys from the
+// terminal in, which is put in raw mode until the frontend is closed.
+func NewTerm(in *os.File, out io.Writer) (*Term, error) {
+	restore, err := makeRaw(in)
+	if err != nil {
+		return nil, fmt.Errorf("could not put the terminal in raw mode: %s", err)
+	}
+
+	t := newTerm(in, out)
+	t.restore = restore
+
+	// Clear the screen and hide the cursor.
+	if _, err = io.WriteString(out, "\x1b[2J\x1b[?25l"); err != nil {
+		restore()
+		return nil, err
+	}
+	return t, nil
+}
+
+// newTerm returns a frontend drawing to w and reading the keys typed from r.
+func newTerm(r io.Reader, w io.Writer) *Term {
+	t := &Term{w: w, in: make(chan byte, 64)}
+	go t.read(r)
+	return t
+}
+
+// read passes on the keys typed until r runs out.
+func (t *Term) read(r io.Reader) {
+	buf := make([]byte, 64)
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			t.in <- b
+		}
+		if err != nil {
+			close(t.in)
+			return
+		}
+	}
+}
+
+// Draw implements Frontend. Only the characters covering rects are written,
+// each row of them after moving the cursor there.
+func (t *Term) Draw(img *image.Paletted, rects []image.Rectangle) error {
+	t.buf.Reset()
+
+	b := img.Bounds()
+	if rects == nil {
+		t.buf.WriteString("\x1b[H")
+		for y := b.Min.Y; y < b.Max.Y; y += 2 {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				t.buf.WriteString(halfBlock(img, x, y))
+			}
+
+			// Erase what's left of a wider display drawn before.
+			t.buf.WriteString("\x1b[K\r\n")
+		}
+		t.buf.WriteString("\x1b[J")
+	}
+
+	for _, r := range rects {
+		r = r.Intersect(b)
+
+		// Rows of characters start at even rows of pixels.
+		for y := r.Min.Y - (r.Min.Y-b.Min.Y)%2; y < r.Max.Y; y += 2 {
+			fmt.Fprintf(&t.buf, "\x1b[%d;%dH", (y-b.Min.Y)/2+1, r.Min.X-b.Min.X+1)
+			for x := r.Min.X; x < r.Max.X; x++ {
+				t.buf.WriteString(halfBlock(img, x, y))
+			}
+		}
+	}
+
+	if t.buf.Len() == 0 {
+		return nil
+	}
+	_, err := t.w.Write(t.buf.Bytes())
+	return err
+}
+
+// halfBlock returns the character drawing the pixel at (x, y) of img and the
+// one below it.
+func halfBlock(img *image.Paletted, x, y int) string {
+	i := 0
+	if img.ColorIndexAt(x, y) != 0 {
+		i |= 1
+	}
+	if y+1 < img.Rect.Max.Y && img.ColorIndexAt(x, y+1) != 0 {
+		i |= 2
+	}
+	return halfBlocks[i]
+}
+
+// Poll implements Frontend.
+func (t *Term) Poll() (keys [16]bool, quit bool) {
+	for k := range t.held {
+		if t.held[k] > 0 {
+			t.held[k]--
+		}
+	}
+
+read:
+	for {
+		select {
+		case b, ok := <-t.in:
+			if !ok {
+				t.in = nil
+				break read
+			}
+			if b == ctrlC {
+				quit = true
+			}
+			if b >= 'A' && b <= 'Z' {
+				b += 'a' - 'A'
+			}
+			if k, ok := termKeys[b]; ok {
+				t.held[k] = termHold
+			}
+		default:
+			break read
+		}
+	}
+
+	for k, n := range t.held {
+		keys[k] = n > 0
+	}
+	return keys, quit
+}
+
+// Close implements Frontend, showing the cursor again and putting the
+// terminal back as it was.
+func (t *Term) Close() error {
+	if _, err := io.WriteString(t.w, "\x1b[?25h"); err != nil {
+		return err
+	}
+	if t.restore != nil {
+		return t.restore()
+	}
+	return nil
+}
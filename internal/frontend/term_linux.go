@@ -0,0 +1,37 @@
+package frontend
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// makeRaw puts the terminal f in raw mode, so keys are read as they're typed
+// without being echoed, returning a function putting it back as it was.
+func makeRaw(f *os.File) (func() error, error) {
+	var old syscall.Termios
+	if err := termios(f, syscall.TCGETS, &old); err != nil {
+		return nil, err
+	}
+
+	raw := old
+	raw.Iflag &^= syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := termios(f, syscall.TCSETS, &raw); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return termios(f, syscall.TCSETS, &old)
+	}, nil
+}
+
+// termios gets or sets, by req, the terminal attributes of f.
+func termios(f *os.File, req uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), req, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+// +build !linux
+
+package frontend
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw puts the terminal f in raw mode. The terminal frontend is only
+// supported on Linux.
+func makeRaw(f *os.File) (func() error, error) {
+	return nil, errors.New("the terminal frontend is only supported on Linux")
+}
@@ -0,0 +1,93 @@
+package frontend
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTermDraw(t *testing.T) {
+	var out bytes.Buffer
+	term := newTerm(strings.NewReader(""), &out)
+
+	// A 3x3 display, drawn as two rows of characters.
+	img := image.NewPaletted(image.Rect(0, 0, 3, 3), color.Palette{color.Black, color.White})
+	img.SetColorIndex(0, 0, 1)
+	img.SetColorIndex(1, 1, 1)
+	img.SetColorIndex(2, 0, 1)
+	img.SetColorIndex(2, 1, 1)
+	img.SetColorIndex(0, 2, 1)
+
+	if err := term.Draw(img, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "\x1b[H▀▄█\x1b[K\r\n▀  \x1b[K\r\n\x1b[J"
+	if got := out.String(); got != exp {
+		t.Fatalf("expected %q, got %q", exp, got)
+	}
+}
+
+func TestTermDrawDirty(t *testing.T) {
+	var out bytes.Buffer
+	term := newTerm(strings.NewReader(""), &out)
+
+	img := image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White})
+	img.SetColorIndex(1, 1, 1)
+	img.SetColorIndex(2, 2, 1)
+
+	// Rows 1 and 2 are drawn as halves of the first two rows of characters.
+	if err := term.Draw(img, []image.Rectangle{image.Rect(1, 1, 3, 3)}); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "\x1b[1;2H▄ \x1b[2;2H ▀"
+	if got := out.String(); got != exp {
+		t.Fatalf("expected %q, got %q", exp, got)
+	}
+
+	out.Reset()
+	if err := term.Draw(img, []image.Rectangle{}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("expected nothing drawn, got %q", out.String())
+	}
+}
+
+func TestTermPoll(t *testing.T) {
+	term := newTerm(strings.NewReader("wX?"), &bytes.Buffer{})
+
+	// Wait for the keys to be read.
+	var keys [16]bool
+	for i := 0; i < 100 && !keys[0x0]; i++ {
+		time.Sleep(time.Millisecond)
+		keys, _ = term.Poll()
+	}
+	if !keys[0x5] || !keys[0x0] {
+		t.Fatalf("expected keys 5 and 0 held, got %v", keys)
+	}
+
+	// Untyped, the keys are released after a while.
+	for i := 0; i < termHold; i++ {
+		keys, _ = term.Poll()
+	}
+	if keys != [16]bool{} {
+		t.Fatalf("expected the keys released, got %v", keys)
+	}
+}
+
+func TestTermQuit(t *testing.T) {
+	term := newTerm(strings.NewReader("\x03"), &bytes.Buffer{})
+
+	for i := 0; i < 100; i++ {
+		if _, quit := term.Poll(); quit {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("expected Ctrl+C to quit")
+}
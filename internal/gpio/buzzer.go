@@ -0,0 +1,109 @@
+package gpio
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultBuzzerFreq is the frequency a passive buzzer is driven at, the tone
+// of the other audio backends.
+const DefaultBuzzerFreq = 500
+
+// Buzzer is a piezo buzzer wired to a GPIO pin, as the audio of a console
+// with no sound card. It implements sound.Audio.
+//
+// An active buzzer sounds while its pin is high. A passive one is driven with
+// a square wave, toggling the pin in software.
+type Buzzer struct {
+	pin  line
+	freq int
+
+	mu   sync.Mutex
+	stop chan struct{}
+	done chan struct{}
+}
+
+// OpenBuzzer opens the buzzer wired to pin n. freq is the frequency a passive
+// buzzer is driven at, or zero for an active buzzer.
+func OpenBuzzer(n, freq int) (*Buzzer, error) {
+	p, err := Open(n, Low)
+	if err != nil {
+		return nil, err
+	}
+	return &Buzzer{pin: p, freq: freq}, nil
+}
+
+// StartTone starts sounding the buzzer.
+func (b *Buzzer) StartTone() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.freq == 0 {
+		return b.pin.Write(true)
+	}
+	if b.stop != nil {
+		return nil
+	}
+
+	b.stop, b.done = make(chan struct{}), make(chan struct{})
+	go b.toggle(b.stop, b.done)
+	return nil
+}
+
+// toggle drives the pin with a square wave at the buzzer's frequency until
+// stop is closed, then closes done.
+func (b *Buzzer) toggle(stop, done chan struct{}) {
+	defer close(done)
+
+	tick := time.NewTicker(time.Second / time.Duration(2*b.freq))
+	defer tick.Stop()
+
+	high := false
+	for {
+		select {
+		case <-stop:
+			b.pin.Write(false)
+			return
+		case <-tick.C:
+			high = !high
+			if b.pin.Write(high) != nil {
+				return
+			}
+		}
+	}
+}
+
+// StopTone silences the buzzer.
+func (b *Buzzer) StopTone() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.freq == 0 {
+		return b.pin.Write(false)
+	}
+	if b.stop == nil {
+		return nil
+	}
+
+	close(b.stop)
+	<-b.done
+	b.stop, b.done = nil, nil
+	return nil
+}
+
+// PlayPattern sounds the buzzer. A buzzer can't play waveforms, so XO-CHIP
+// patterns are played as the tone.
+func (b *Buzzer) PlayPattern(pattern [16]byte) error {
+	return b.StartTone()
+}
+
+// Latency returns zero, the buzzer sounds as its pin is driven.
+func (b *Buzzer) Latency() time.Duration {
+	return 0
+}
+
+// Close silences the buzzer and closes its pin.
+func (b *Buzzer) Close() error {
+	b.StopTone()
+	return b.pin.Close()
+}
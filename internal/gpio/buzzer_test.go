@@ -0,0 +1,75 @@
+package gpio
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePin records the writes to a pin.
+type fakePin struct {
+	mu     sync.Mutex
+	high   bool
+	writes int
+}
+
+func (f *fakePin) Read() (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.high, nil
+}
+
+func (f *fakePin) Write(high bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.high = high
+	f.writes++
+	return nil
+}
+
+func (f *fakePin) Close() error { return nil }
+
+func TestBuzzerActive(t *testing.T) {
+	p := &fakePin{}
+	b := &Buzzer{pin: p}
+
+	if err := b.StartTone(); err != nil {
+		t.Fatal(err)
+	}
+	if high, _ := p.Read(); !high {
+		t.Fatal("expected pin high while the tone sounds")
+	}
+	if err := b.StopTone(); err != nil {
+		t.Fatal(err)
+	}
+	if high, _ := p.Read(); high {
+		t.Fatal("expected pin low after the tone stops")
+	}
+}
+
+func TestBuzzerPassive(t *testing.T) {
+	p := &fakePin{}
+	b := &Buzzer{pin: p, freq: 500}
+
+	if err := b.StartTone(); err != nil {
+		t.Fatal(err)
+	}
+	// Starting twice doesn't start a second wave.
+	if err := b.PlayPattern([16]byte{}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := b.StopTone(); err != nil {
+		t.Fatal(err)
+	}
+
+	p.mu.Lock()
+	writes := p.writes
+	p.mu.Unlock()
+	if writes < 10 {
+		t.Fatalf("expected the pin toggled at 1000 writes a second, got %d writes in 50ms", writes)
+	}
+	if high, _ := p.Read(); high {
+		t.Fatal("expected pin low after the tone stops")
+	}
+}
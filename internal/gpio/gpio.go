@@ -0,0 +1,89 @@
+// Package gpio reads a matrix keypad and drives a buzzer wired to the GPIO
+// pins of boards such as the Raspberry Pi, for building a physical CHIP-8
+// machine around the emulator. Pins are driven through the sysfs interface,
+// /sys/class/gpio.
+package gpio
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// root is the sysfs GPIO directory, replaced in tests.
+var root = "/sys/class/gpio"
+
+// Pin is a GPIO pin exported through sysfs.
+type Pin struct {
+	n     int
+	value *os.File
+}
+
+// Direction is the direction a pin is opened in.
+type Direction string
+
+// The directions of a pin, an input or an output starting high or low.
+const (
+	In   Direction = "in"
+	High Direction = "high"
+	Low  Direction = "low"
+)
+
+// Open exports pin n, numbered as the kernel does (BCM numbering on the
+// Raspberry Pi), in direction d.
+func Open(n int, d Direction) (*Pin, error) {
+	dir := filepath.Join(root, "gpio"+strconv.Itoa(n))
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err = ioutil.WriteFile(filepath.Join(root, "export"), []byte(strconv.Itoa(n)), 0); err != nil {
+			return nil, fmt.Errorf("export pin %d: %s", n, err)
+		}
+	}
+
+	// The pin's files are made by udev after it's exported and aren't
+	// writable straight away.
+	var err error
+	for i := 0; i < 20; i++ {
+		if err = ioutil.WriteFile(filepath.Join(dir, "direction"), []byte(d), 0); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set pin %d direction: %s", n, err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, "value"), os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open pin %d: %s", n, err)
+	}
+	return &Pin{n: n, value: f}, nil
+}
+
+// Read returns true if the pin is high.
+func (p *Pin) Read() (bool, error) {
+	b := make([]byte, 1)
+	if _, err := p.value.ReadAt(b, 0); err != nil {
+		return false, fmt.Errorf("read pin %d: %s", p.n, err)
+	}
+	return b[0] == '1', nil
+}
+
+// Write drives the pin high or low.
+func (p *Pin) Write(high bool) error {
+	v := []byte("0")
+	if high {
+		v[0] = '1'
+	}
+	if _, err := p.value.WriteAt(v, 0); err != nil {
+		return fmt.Errorf("write pin %d: %s", p.n, err)
+	}
+	return nil
+}
+
+// Close closes the pin, leaving it exported.
+func (p *Pin) Close() error {
+	return p.value.Close()
+}
@@ -0,0 +1,158 @@
+package gpio
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gpio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer func(r string) { root = r }(root)
+	root = dir
+
+	// The kernel makes the pin's directory when it's exported.
+	if err = os.Mkdir(filepath.Join(dir, "gpio17"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "gpio17", "value"), []byte("0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Open(17, High)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	direction, _ := ioutil.ReadFile(filepath.Join(dir, "gpio17", "direction"))
+	if string(direction) != "high" {
+		t.Fatalf("expected direction %q, got %q", "high", direction)
+	}
+
+	for _, high := range []bool{true, false} {
+		if err = p.Write(high); err != nil {
+			t.Fatal(err)
+		}
+		got, err := p.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != high {
+			t.Fatalf("expected %v, got %v", high, got)
+		}
+	}
+
+	if _, err = Open(18, In); err == nil {
+		t.Fatal("expected error opening a pin that wasn't exported")
+	}
+}
+
+func TestParseKeypadPins(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    KeypadPins
+		wantErr bool
+	}{
+		{s: "5,6,13,19:12,16,20,21", want: KeypadPins{Rows: [4]int{5, 6, 13, 19}, Cols: [4]int{12, 16, 20, 21}}},
+		{s: "5, 6, 13, 19 : 12, 16, 20, 21", want: KeypadPins{Rows: [4]int{5, 6, 13, 19}, Cols: [4]int{12, 16, 20, 21}}},
+		{s: "5,6,13,19", wantErr: true},
+		{s: "5,6,13:12,16,20,21", wantErr: true},
+		{s: "5,6,13,x:12,16,20,21", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.s, func(t *testing.T) {
+			got, err := ParseKeypadPins(tc.s)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+			if got != tc.want && !tc.wantErr {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+// matrix is a fake keypad matrix, reading a column low while the row of a
+// pressed key in it is driven low.
+type matrix struct {
+	pressed map[[2]int]bool
+	rows    [4]bool
+}
+
+type fakeRow struct {
+	m *matrix
+	r int
+}
+
+func (f fakeRow) Read() (bool, error)   { return f.m.rows[f.r], nil }
+func (f fakeRow) Write(high bool) error { f.m.rows[f.r] = high; return nil }
+func (f fakeRow) Close() error          { return nil }
+
+type fakeCol struct {
+	m *matrix
+	c int
+}
+
+func (f fakeCol) Read() (bool, error) {
+	for r, high := range f.m.rows {
+		if !high && f.m.pressed[[2]int{r, f.c}] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+func (f fakeCol) Write(bool) error { return nil }
+func (f fakeCol) Close() error     { return nil }
+
+func TestKeypadScan(t *testing.T) {
+	m := &matrix{pressed: make(map[[2]int]bool), rows: [4]bool{true, true, true, true}}
+	k := &Keypad{layout: COSMACLayout}
+	for i := 0; i < 4; i++ {
+		k.rows[i] = fakeRow{m, i}
+		k.cols[i] = fakeCol{m, i}
+	}
+
+	type change struct {
+		key  byte
+		down bool
+	}
+	scan := func() []change {
+		var got []change
+		if err := k.Scan(func(key byte, down bool) { got = append(got, change{key, down}) }); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// 5 and B, debounced over two scans.
+	m.pressed[[2]int{1, 1}] = true
+	m.pressed[[2]int{3, 2}] = true
+	if got := scan(); len(got) != 0 {
+		t.Fatalf("expected no changes on the first scan, got %v", got)
+	}
+	if got := scan(); len(got) != 2 || got[0] != (change{0x5, true}) || got[1] != (change{0xB, true}) {
+		t.Fatalf("expected 5 and B pressed, got %v", got)
+	}
+
+	// A bounce of one scan is ignored.
+	delete(m.pressed, [2]int{1, 1})
+	scan()
+	m.pressed[[2]int{1, 1}] = true
+	if got := scan(); len(got) != 0 {
+		t.Fatalf("expected bounce ignored, got %v", got)
+	}
+
+	delete(m.pressed, [2]int{3, 2})
+	scan()
+	if got := scan(); len(got) != 1 || got[0] != (change{0xB, false}) {
+		t.Fatalf("expected B released, got %v", got)
+	}
+}
@@ -0,0 +1,134 @@
+package gpio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// line is a GPIO line, a Pin or a fake in tests.
+type line interface {
+	Read() (bool, error)
+	Write(high bool) error
+	Close() error
+}
+
+// KeypadPins are the pins a 4x4 matrix keypad is wired to, from the top row
+// and left column.
+type KeypadPins struct {
+	Rows [4]int
+	Cols [4]int
+}
+
+// ParseKeypadPins parses pins written as the rows then the columns, such as
+// "5,6,13,19:12,16,20,21".
+func ParseKeypadPins(s string) (KeypadPins, error) {
+	var pins KeypadPins
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return pins, fmt.Errorf("invalid keypad pins %q, expected rows:cols", s)
+	}
+	for i, dst := range []*[4]int{&pins.Rows, &pins.Cols} {
+		nums := strings.Split(parts[i], ",")
+		if len(nums) != 4 {
+			return pins, fmt.Errorf("invalid keypad pins %q, expected 4 rows and 4 columns", s)
+		}
+		for j, n := range nums {
+			p, err := strconv.Atoi(strings.TrimSpace(n))
+			if err != nil {
+				return pins, fmt.Errorf("invalid keypad pin %q", n)
+			}
+			dst[j] = p
+		}
+	}
+	return pins, nil
+}
+
+// Layout is the keypad keys at each position of the matrix, by row and
+// column.
+type Layout [4][4]byte
+
+// COSMACLayout is the layout of the COSMAC VIP's keypad, which membrane
+// keypads labelled 1 2 3 A / 4 5 6 B / 7 8 9 C / * 0 # D are played as.
+var COSMACLayout = Layout{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// Keypad is a 4x4 matrix keypad. It's scanned by driving each row low in
+// turn and reading the columns, which must be pulled up, for the keys
+// pressed.
+type Keypad struct {
+	rows   [4]line
+	cols   [4]line
+	layout Layout
+
+	// The keys reported down, and those read down on the last scan, for
+	// debouncing.
+	down [16]bool
+	last [16]bool
+}
+
+// OpenKeypad opens the keypad wired to pins, laid out as layout.
+func OpenKeypad(pins KeypadPins, layout Layout) (*Keypad, error) {
+	k := &Keypad{layout: layout}
+	for i := 0; i < 4; i++ {
+		r, err := Open(pins.Rows[i], High)
+		if err != nil {
+			k.Close()
+			return nil, err
+		}
+		k.rows[i] = r
+
+		c, err := Open(pins.Cols[i], In)
+		if err != nil {
+			k.Close()
+			return nil, err
+		}
+		k.cols[i] = c
+	}
+	return k, nil
+}
+
+// Scan reads the keypad, calling f for each key pressed or released since the
+// last scan. A key must read the same on two scans in a row to change, so
+// scanning at the frame rate debounces it.
+func (k *Keypad) Scan(f func(key byte, down bool)) error {
+	var now [16]bool
+	for r, row := range k.rows {
+		if err := row.Write(false); err != nil {
+			return err
+		}
+		for c, col := range k.cols {
+			high, err := col.Read()
+			if err != nil {
+				return err
+			}
+			now[k.layout[r][c]] = !high
+		}
+		if err := row.Write(true); err != nil {
+			return err
+		}
+	}
+
+	for key, d := range now {
+		if d == k.last[key] && d != k.down[key] {
+			k.down[key] = d
+			f(byte(key), d)
+		}
+	}
+	k.last = now
+	return nil
+}
+
+// Close closes the keypad's pins.
+func (k *Keypad) Close() error {
+	for _, l := range append(k.rows[:], k.cols[:]...) {
+		if l != nil {
+			l.Close()
+		}
+	}
+	return nil
+}
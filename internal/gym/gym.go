@@ -0,0 +1,81 @@
+// Package gym wraps the VM as a reinforcement learning environment, in the
+// style of OpenAI Gym. Each step holds a set of keys for one frame and
+// observes the display at the end of it. Given the same seed and keys, every
+// episode plays out identically.
+package gym
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Env is an environment playing a ROM.
+type Env struct {
+	// MaxFrames, if non-zero, ends episodes after that many frames.
+	MaxFrames int
+
+	rom     []byte
+	variant chip8.Variant
+	seed    int64
+
+	vm     *chip8.VM
+	frames int
+}
+
+// New returns an environment playing rom as vr, seeding the random numbers
+// generated by the program with seed. Reset must be called to start the
+// first episode.
+func New(rom []byte, vr chip8.Variant, seed int64) *Env {
+	return &Env{
+		rom:     rom,
+		variant: vr,
+		seed:    seed,
+	}
+}
+
+// Reset starts a new episode, reloading the ROM, and returns the first
+// observation.
+func (e *Env) Reset() ([]bool, error) {
+	vm := chip8.NewVariant(e.variant)
+	vm.Seed(e.seed)
+	if err := vm.Load(bytes.NewReader(e.rom)); err != nil {
+		return nil, fmt.Errorf("load ROM: %s", err)
+	}
+
+	e.vm = vm
+	e.frames = 0
+
+	return vm.Frame(), nil
+}
+
+// Step holds keys for one frame, returning the observation at the end of it
+// and whether the episode is done. Episodes are done when the program halts
+// or MaxFrames is reached.
+func (e *Env) Step(keys [16]bool) (obs []bool, done bool, err error) {
+	if e.vm == nil {
+		return nil, true, fmt.Errorf("step before reset")
+	}
+
+	e.vm.SetKeys(keys)
+	if err = e.vm.StepFrame(); err != nil {
+		return nil, true, fmt.Errorf("frame %d: %s", e.frames, err)
+	}
+	e.frames++
+
+	done = e.vm.Halted() || (e.MaxFrames > 0 && e.frames >= e.MaxFrames)
+
+	return e.vm.Frame(), done, nil
+}
+
+// Frames returns the number of frames stepped in the current episode.
+func (e *Env) Frames() int {
+	return e.frames
+}
+
+// VM returns the VM of the current episode, nil before the first Reset. It
+// gives access to the display dimensions and memory, e.g. to read the score.
+func (e *Env) VM() *chip8.VM {
+	return e.vm
+}
@@ -0,0 +1,82 @@
+package gym
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// random draws a pixel at a random position each frame, until key 0 is
+// pressed.
+var random = []byte{
+	0xA2, 0x10, // I = sprite.
+	0xC0, 0x3F, // V0 = rand & 63.
+	0xC1, 0x1F, // V1 = rand & 31.
+	0xD0, 0x11, // Draw at (V0, V1).
+	0xE2, 0x9E, // Skip if key V2 (0) is pressed.
+	0x12, 0x02, // Loop.
+	0x12, 0x0C, // Halt.
+	0x00, 0x00,
+	0x80, // Sprite.
+}
+
+func episode(t *testing.T, seed int64, frames int) [][]bool {
+	e := New(random, chip8.Chip8, seed)
+	e.MaxFrames = frames
+
+	obs, err := e.Reset()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all := [][]bool{obs}
+	for done := false; !done; {
+		if obs, done, err = e.Step([16]bool{}); err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, obs)
+	}
+
+	return all
+}
+
+func TestDeterministic(t *testing.T) {
+	a := episode(t, 42, 10)
+	if len(a) != 11 {
+		t.Fatalf("expected 11 observations, got %d", len(a))
+	}
+	if !reflect.DeepEqual(a, episode(t, 42, 10)) {
+		t.Fatal("expected episodes with the same seed to match")
+	}
+	if reflect.DeepEqual(a, episode(t, 7, 10)) {
+		t.Fatal("expected episodes with different seeds to differ")
+	}
+}
+
+func TestHalt(t *testing.T) {
+	e := New(random, chip8.Chip8, 1)
+	if _, err := e.Reset(); err != nil {
+		t.Fatal(err)
+	}
+
+	var keys [16]bool
+	keys[0] = true
+
+	for f := 0; f < 5; f++ {
+		_, done, err := e.Step(keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done {
+			return
+		}
+	}
+	t.Fatal("expected the episode to end once the program halted")
+}
+
+func TestStepBeforeReset(t *testing.T) {
+	if _, _, err := New(random, chip8.Chip8, 1).Step([16]bool{}); err == nil {
+		t.Fatal("expected an error stepping before reset")
+	}
+}
@@ -0,0 +1,122 @@
+// Package heatmap counts how often each cell of the display is drawn to and
+// where in memory the program is executing, for a debug overlay showing the
+// structure of a ROM at a glance.
+package heatmap
+
+import (
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Buckets is the number of ranges of memory instructions are counted in.
+const Buckets = 64
+
+// decay is how much of the heat is kept each frame, so the map shows recent
+// activity.
+const decay = 0.9
+
+// Map counts the sprites drawn over each cell of a VM's display and the
+// instructions executed in each range of its memory. It's safe to read while
+// the VM runs.
+type Map struct {
+	vm *chip8.VM
+
+	mu      sync.Mutex
+	w, h    int
+	cells   []float64
+	pcs     [Buckets]float64
+	memSize int
+}
+
+// New returns a map counting the activity of vm.
+func New(vm *chip8.VM) *Map {
+	m := &Map{vm: vm}
+	m.frame()
+	vm.OnInstruction(m.instruction)
+	vm.OnFrame(m.frame)
+	return m
+}
+
+// instruction counts the instruction at pc and, if it draws a sprite, the
+// cells the sprite covers.
+func (m *Map) instruction(pc, opc uint16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pcs[int(pc)*Buckets/m.memSize%Buckets]++
+
+	if opc&0xF000 != 0xD000 {
+		return
+	}
+	x := int(m.vm.V(byte(opc>>8&0xF))) % m.w
+	y := int(m.vm.V(byte(opc>>4&0xF))) % m.h
+	sw, sh := 8, int(opc&0xF)
+	if sh == 0 && (m.vm.Variant() == chip8.SChip || m.vm.Variant() == chip8.XOChip) {
+		sw, sh = 16, 16
+	}
+	for cy := y; cy < y+sh && cy < m.h; cy++ {
+		for cx := x; cx < x+sw && cx < m.w; cx++ {
+			m.cells[cy*m.w+cx]++
+		}
+	}
+}
+
+// frame cools the map, and resizes it if the display or memory has changed
+// size.
+func (m *Map) frame() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d := m.vm.Display()
+	if d.Width() != m.w || d.Height() != m.h {
+		m.w, m.h = d.Width(), d.Height()
+		m.cells = make([]float64, m.w*m.h)
+	}
+	regions := m.vm.Regions()
+	m.memSize = int(regions[len(regions)-1].End)
+
+	for i := range m.cells {
+		m.cells[i] *= decay
+	}
+	for i := range m.pcs {
+		m.pcs[i] *= decay
+	}
+}
+
+// Cells returns the heat of each cell of the display, in rows from the top
+// left, relative to the hottest cell so between 0 and 1.
+func (m *Map) Cells() (w, h int, heat []float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.w, m.h, normalise(m.cells)
+}
+
+// PCs returns the heat of each range of memory, from the start, relative to
+// the hottest so between 0 and 1.
+func (m *Map) PCs() []float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return normalise(m.pcs[:])
+}
+
+// normalise returns a copy of heat scaled so the hottest is 1.
+func normalise(heat []float64) []float64 {
+	max := 0.0
+	for _, v := range heat {
+		if v > max {
+			max = v
+		}
+	}
+
+	n := make([]float64, len(heat))
+	if max == 0 {
+		return n
+	}
+	for i, v := range heat {
+		n[i] = v / max
+	}
+	return n
+}
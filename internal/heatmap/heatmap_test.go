@@ -0,0 +1,49 @@
+package heatmap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestMap(t *testing.T) {
+	rom := []byte{
+		0x60, 0x04, // V0 = 4.
+		0x61, 0x02, // V1 = 2.
+		0xA2, 0x0A, // I = sprite.
+		0xD0, 0x12, // Draw 2 rows at (4, 2).
+		0x12, 0x06, // Draw again.
+		0xFF, 0xFF, // Sprite.
+	}
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	m := New(vm)
+	for i := 0; i < 6; i++ {
+		if err := vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, h, heat := m.Cells()
+	if w != chip8.DisplayWidth || h != chip8.DisplayHeight {
+		t.Fatalf("expected a %dx%d map, got %dx%d", chip8.DisplayWidth, chip8.DisplayHeight, w, h)
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			drawn := x >= 4 && x < 12 && y >= 2 && y < 4
+			if got := heat[y*w+x]; (got == 1) != drawn || (!drawn && got != 0) {
+				t.Fatalf("unexpected heat %.2f at (%d, %d)", got, x, y)
+			}
+		}
+	}
+
+	// Every instruction is in the range of memory holding 0x200.
+	pcs := m.PCs()
+	if pcs[Buckets*0x200/4096] != 1 {
+		t.Fatalf("expected the program's range to be hottest, got %v", pcs)
+	}
+}
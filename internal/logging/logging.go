@@ -0,0 +1,92 @@
+// Package logging adds levels to the standard log package. Messages below the
+// level set are dropped, the rest are written through log, prefixed with their
+// level, so log.SetOutput and log.SetFlags still apply.
+package logging
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+)
+
+// Level is the severity of a message.
+type Level int32
+
+const (
+	// Debug is for tracing, such as every instruction executed.
+	Debug Level = iota
+
+	// Info is for progress, such as the variant a ROM is run as.
+	Info
+
+	// Warn is for problems the emulator carries on from, such as a failing
+	// audio device.
+	Warn
+
+	// Error is for problems stopping something from working.
+	Error
+)
+
+// Levels are the names of the levels, indexed by level.
+var Levels = []string{"debug", "info", "warn", "error"}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	if l >= 0 && int(l) < len(Levels) {
+		return Levels[l]
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevel returns the level called name.
+func ParseLevel(name string) (Level, error) {
+	for i, n := range Levels {
+		if n == name {
+			return Level(i), nil
+		}
+	}
+	return Info, fmt.Errorf("unknown log level %q, expected one of %q", name, Levels)
+}
+
+// level is the minimum level logged.
+var level = int32(Info)
+
+// SetLevel sets the minimum level of the messages logged.
+func SetLevel(l Level) {
+	atomic.StoreInt32(&level, int32(l))
+}
+
+// Enabled returns true if messages at l are logged, so callers can skip
+// building messages which would be dropped.
+func Enabled(l Level) bool {
+	return int32(l) >= atomic.LoadInt32(&level)
+}
+
+// Debugf logs a message at the debug level.
+func Debugf(format string, v ...interface{}) {
+	output(Debug, format, v...)
+}
+
+// Infof logs a message at the info level.
+func Infof(format string, v ...interface{}) {
+	output(Info, format, v...)
+}
+
+// Warnf logs a message at the warn level.
+func Warnf(format string, v ...interface{}) {
+	output(Warn, format, v...)
+}
+
+// Errorf logs a message at the error level.
+func Errorf(format string, v ...interface{}) {
+	output(Error, format, v...)
+}
+
+// output logs the message if l is enabled.
+func output(l Level, format string, v ...interface{}) {
+	if !Enabled(l) {
+		return
+	}
+	// Skip output and the level func to report the caller, with log.Lshortfile.
+	log.Output(3, fmt.Sprintf("%-5s ", l)+fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"testing"
+)
+
+func TestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+		SetLevel(Info)
+	}()
+
+	SetLevel(Warn)
+	Debugf("debug %d", 1)
+	Infof("info %d", 2)
+	Warnf("warn %d", 3)
+	Errorf("error %d", 4)
+
+	if want := "warn  warn 3\nerror error 4\n"; buf.String() != want {
+		t.Fatalf("expected %q, got %q", want, buf.String())
+	}
+	if Enabled(Info) || !Enabled(Error) {
+		t.Fatal("expected only warn and above to be enabled")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for i, name := range Levels {
+		l, err := ParseLevel(name)
+		if err != nil || l != Level(i) || l.String() != name {
+			t.Fatalf("%s: got %s, %v", name, l, err)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+}
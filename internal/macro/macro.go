@@ -0,0 +1,219 @@
+// Package macro plays macros bound to host keys in the keymap: turbo buttons
+// tapping a CHIP-8 key rapidly while they're held, and sequences of CHIP-8
+// key presses played through each time they're pressed.
+//
+// The keymap is keymap.json in the chip8 config directory, its macros keyed
+// by the name of the host key they're bound to:
+//
+//	{
+//		"macros": {
+//			"LeftShift": {"turbo": 5, "rate": 15},
+//			"G": {"sequence": [
+//				{"keys": [5], "frames": 2},
+//				{"frames": 10},
+//				{"keys": [4, 6], "frames": 30}
+//			]}
+//		}
+//	}
+//
+// Macros are played a 60Hz frame at a time, so they play the same however
+// often input is polled.
+package macro
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// DefaultRate is the rate, in Hz, turbo buttons tap their key at unless
+// another is given.
+const DefaultRate = 15
+
+// Step is a step of a sequence.
+type Step struct {
+	// Keys are the CHIP-8 keys held for the step, none to wait.
+	Keys []byte `json:"keys,omitempty"`
+
+	// Frames is how long the step lasts, in 60Hz frames.
+	Frames int `json:"frames"`
+}
+
+// Macro is what a host key does.
+type Macro struct {
+	// Turbo, if set, is the CHIP-8 key tapped while the host key is held,
+	// Rate times a second. Each tap holds the key for half its time.
+	Turbo *byte `json:"turbo,omitempty"`
+	Rate  int   `json:"rate,omitempty"`
+
+	// Sequence is played through each time the host key is pressed. It
+	// isn't restarted by pressing the host key while it's playing.
+	Sequence []Step `json:"sequence,omitempty"`
+}
+
+// Keymap binds macros to host keys by name.
+type Keymap struct {
+	Macros map[string]Macro `json:"macros"`
+}
+
+// Path returns the path of the user's keymap, in their config directory.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "keymap.json"), nil
+}
+
+// Load reads the keymap at path. It returns nil and no error if there isn't
+// one.
+func Load(path string) (*Keymap, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var km Keymap
+	if err = json.Unmarshal(b, &km); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	for name, m := range km.Macros {
+		if err = m.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %s", path, name, err)
+		}
+	}
+	return &km, nil
+}
+
+// validate returns an error if the macro does nothing or isn't possible.
+func (m Macro) validate() error {
+	if (m.Turbo == nil) == (m.Sequence == nil) {
+		return fmt.Errorf("a macro is either a turbo or a sequence")
+	}
+	if m.Turbo != nil {
+		if *m.Turbo > 0xF {
+			return fmt.Errorf("turbo key must be 0 to 15")
+		}
+		// A tap needs a frame down and a frame up.
+		if m.Rate < 0 || m.Rate > chip8.FrameRate/2 {
+			return fmt.Errorf("turbo rate must be 1 to %dHz", chip8.FrameRate/2)
+		}
+	}
+	for i, s := range m.Sequence {
+		if s.Frames < 1 {
+			return fmt.Errorf("step %d lasts no frames", i)
+		}
+		for _, k := range s.Keys {
+			if k > 0xF {
+				return fmt.Errorf("step %d: keys must be 0 to 15", i)
+			}
+		}
+	}
+	return nil
+}
+
+// binding is a macro as it's being played.
+type binding struct {
+	name  string
+	macro Macro
+
+	// held is whether the host key was held at the last frame, and frame
+	// the frames since it was pressed, or the sequence started, -1 if it
+	// isn't playing.
+	held  bool
+	frame int
+}
+
+// Player plays the macros of a keymap.
+type Player struct {
+	bindings []*binding
+}
+
+// NewPlayer returns a player of the macros of km, which may be nil.
+func NewPlayer(km *Keymap) *Player {
+	p := &Player{}
+	if km == nil {
+		return p
+	}
+	for name, m := range km.Macros {
+		p.bindings = append(p.bindings, &binding{name: name, macro: m, frame: -1})
+	}
+	sort.Slice(p.bindings, func(i, j int) bool { return p.bindings[i].name < p.bindings[j].name })
+	return p
+}
+
+// Names returns the names of the host keys macros are bound to.
+func (p *Player) Names() []string {
+	names := make([]string, len(p.bindings))
+	for i, b := range p.bindings {
+		names[i] = b.name
+	}
+	return names
+}
+
+// Frame plays a frame of the macros, with held reporting whether the host key
+// of the name given is held, and returns the CHIP-8 keys they hold.
+func (p *Player) Frame(held func(name string) bool) [16]bool {
+	var keys [16]bool
+	for _, b := range p.bindings {
+		down := held(b.name)
+		pressed := down && !b.held
+		b.held = down
+
+		if b.macro.Turbo != nil {
+			if !down {
+				b.frame = -1
+				continue
+			}
+			if pressed {
+				b.frame = 0
+			}
+			rate := b.macro.Rate
+			if rate == 0 {
+				rate = DefaultRate
+			}
+			period := chip8.FrameRate / rate
+			if b.frame%period < (period+1)/2 {
+				keys[*b.macro.Turbo] = true
+			}
+			b.frame++
+			continue
+		}
+
+		if pressed && b.frame < 0 {
+			b.frame = 0
+		}
+		if b.frame < 0 {
+			continue
+		}
+		if step, ok := b.step(); ok {
+			for _, k := range step.Keys {
+				keys[k] = true
+			}
+			b.frame++
+		} else {
+			b.frame = -1
+		}
+	}
+	return keys
+}
+
+// step returns the step of the sequence being played, false once it's over.
+func (b *binding) step() (Step, bool) {
+	f := b.frame
+	for _, s := range b.macro.Sequence {
+		if f < s.Frames {
+			return s, true
+		}
+		f -= s.Frames
+	}
+	return Step{}, false
+}
@@ -0,0 +1,109 @@
+package macro
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// play plays frames of p with the host keys in held down, in order, and
+// returns whether key was held each frame.
+func play(p *Player, key byte, held ...map[string]bool) []bool {
+	var got []bool
+	for _, h := range held {
+		keys := p.Frame(func(name string) bool { return h[name] })
+		got = append(got, keys[key])
+	}
+	return got
+}
+
+// repeat returns held n times.
+func repeat(held map[string]bool, n int) []map[string]bool {
+	var frames []map[string]bool
+	for i := 0; i < n; i++ {
+		frames = append(frames, held)
+	}
+	return frames
+}
+
+func TestTurbo(t *testing.T) {
+	five := byte(5)
+	p := NewPlayer(&Keymap{Macros: map[string]Macro{"T": {Turbo: &five}}})
+
+	frames := append(repeat(map[string]bool{"T": true}, 9), repeat(nil, 2)...)
+	got := play(p, 5, frames...)
+	want := []bool{true, true, false, false, true, true, false, false, true, false, false}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("frame %d: expected key 5 held %t, got %v", i, want[i], got)
+		}
+	}
+
+	// Pressing the host key again starts a tap at once.
+	if got = play(p, 5, map[string]bool{"T": true}); !got[0] {
+		t.Fatal("expected the turbo to tap as soon as it's pressed again")
+	}
+}
+
+func TestSequence(t *testing.T) {
+	p := NewPlayer(&Keymap{Macros: map[string]Macro{"G": {Sequence: []Step{
+		{Keys: []byte{5}, Frames: 2},
+		{Frames: 1},
+		{Keys: []byte{5, 6}, Frames: 1},
+	}}}})
+
+	// The sequence plays through though the host key is only tapped, and
+	// holding the key doesn't restart it.
+	press := map[string]bool{"G": true}
+	got := play(p, 5, press, nil, nil, nil, nil, press)
+	want := []bool{true, true, false, true, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("frame %d: expected key 5 held %t, got %v", i, want[i], got)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "macro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "keymap.json")
+
+	if km, err := Load(path); km != nil || err != nil {
+		t.Fatalf("expected no keymap and no error for a missing file, got %v, %v", km, err)
+	}
+
+	src := `{"macros": {"T": {"turbo": 5, "rate": 30}, "G": {"sequence": [{"keys": [1], "frames": 3}]}}}`
+	if err = ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	km, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := NewPlayer(km).Names(); len(names) != 2 || names[0] != "G" || names[1] != "T" {
+		t.Fatalf("expected macros G and T, got %v", names)
+	}
+
+	bad := map[string]string{
+		"both": `{"macros": {"T": {"turbo": 5, "sequence": [{"frames": 1}]}}}`,
+		"none": `{"macros": {"T": {}}}`,
+		"key":  `{"macros": {"T": {"turbo": 16}}}`,
+		"rate": `{"macros": {"T": {"turbo": 5, "rate": 60}}}`,
+		"step": `{"macros": {"G": {"sequence": [{"keys": [1]}]}}}`,
+		"keys": `{"macros": {"G": {"sequence": [{"keys": [16], "frames": 1}]}}}`,
+		"json": `{`,
+	}
+	for name, src := range bad {
+		if err = ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Load(path); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+// Package memscan finds where a program keeps a value, such as its lives or
+// score, by scanning memory for it and narrowing the addresses found down as
+// the value changes between scans, the way cheat finders do. Addresses found
+// can then be watched or frozen.
+package memscan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Size is the size of the memory scanned, the first 4K, where programs keep
+// their variables.
+const Size = 0x1000
+
+// Cond is a condition the bytes at the addresses kept by a scan meet.
+type Cond int
+
+// The conditions, compared with the value given or with the byte at the last
+// scan.
+const (
+	Equal Cond = iota
+	Changed
+	Unchanged
+	Increased
+	Decreased
+)
+
+// conds are the conditions by name, as given to Exec.
+var conds = map[string]Cond{
+	"eq":        Equal,
+	"changed":   Changed,
+	"unchanged": Unchanged,
+	"inc":       Increased,
+	"dec":       Decreased,
+}
+
+// holds returns true if b, which was prev at the last scan, meets c.
+func (c Cond) holds(b, prev, v byte) bool {
+	switch c {
+	case Changed:
+		return b != prev
+	case Unchanged:
+		return b == prev
+	case Increased:
+		return b > prev
+	case Decreased:
+		return b < prev
+	default:
+		return b == v
+	}
+}
+
+// Scanner scans the memory of a VM. It isn't safe for concurrent use, it's
+// used on the goroutine running the VM.
+type Scanner struct {
+	vm *chip8.VM
+
+	// The addresses kept by the scans so far, nil before the first, and
+	// memory as it was at the last.
+	found []uint16
+	prev  [Size]byte
+
+	// The addresses watched, and those frozen with the value they're held
+	// at.
+	watched []uint16
+	frozen  map[uint16]byte
+}
+
+// New returns a scanner of vm's memory. Frozen addresses are written back
+// every frame.
+func New(vm *chip8.VM) *Scanner {
+	s := &Scanner{vm: vm, frozen: make(map[uint16]byte)}
+	vm.OnFrame(s.freeze)
+	return s
+}
+
+// Scan keeps the addresses whose bytes meet c, v being the value compared
+// with by Equal. The first scan after New or Reset considers every address,
+// so only Equal narrows anything down. It returns the number of addresses
+// kept.
+func (s *Scanner) Scan(c Cond, v byte) int {
+	if s.found == nil {
+		s.found = make([]uint16, Size)
+		for a := range s.found {
+			s.found[a] = uint16(a)
+		}
+	}
+
+	kept := s.found[:0]
+	for _, a := range s.found {
+		if c.holds(s.vm.Peek(a), s.prev[a], v) {
+			kept = append(kept, a)
+		}
+	}
+	s.found = kept
+
+	for a := range s.prev {
+		s.prev[a] = s.vm.Peek(uint16(a))
+	}
+	return len(s.found)
+}
+
+// Reset forgets the addresses found, the next scan starting afresh.
+func (s *Scanner) Reset() {
+	s.found = nil
+}
+
+// Found returns the addresses kept by the scans so far.
+func (s *Scanner) Found() []uint16 {
+	return s.found
+}
+
+// Watch adds addr to the addresses watched.
+func (s *Scanner) Watch(addr uint16) {
+	for _, a := range s.watched {
+		if a == addr {
+			return
+		}
+	}
+	s.watched = append(s.watched, addr)
+	sort.Slice(s.watched, func(i, j int) bool { return s.watched[i] < s.watched[j] })
+}
+
+// Unwatch removes addr from the addresses watched, and unfreezes it.
+func (s *Scanner) Unwatch(addr uint16) {
+	delete(s.frozen, addr)
+	for i, a := range s.watched {
+		if a == addr {
+			s.watched = append(s.watched[:i], s.watched[i+1:]...)
+			return
+		}
+	}
+}
+
+// Watched returns the addresses watched.
+func (s *Scanner) Watched() []uint16 {
+	return s.watched
+}
+
+// Freeze holds addr at b, writing it back every frame. Frozen addresses are
+// watched.
+func (s *Scanner) Freeze(addr uint16, b byte) {
+	s.Watch(addr)
+	s.frozen[addr] = b
+	s.vm.Poke(addr, b)
+}
+
+// Frozen returns the value addr is frozen at, false if it isn't.
+func (s *Scanner) Frozen(addr uint16) (byte, bool) {
+	b, ok := s.frozen[addr]
+	return b, ok
+}
+
+// freeze writes the frozen values back to memory.
+func (s *Scanner) freeze() {
+	for a, b := range s.frozen {
+		s.vm.Poke(a, b)
+	}
+}
+
+// Exec runs a scanner command, returning a message describing the result:
+//
+//	find <value>              Start a new scan for the value.
+//	eq <value>                Keep the addresses holding the value.
+//	changed, unchanged        Keep the addresses that have, or haven't, changed.
+//	inc, dec                  Keep those that have increased or decreased.
+//	watch <addr>              Watch the byte at addr.
+//	freeze <addr> [value]     Hold addr at value, or the value it has now.
+//	unwatch <addr>            Stop watching, or freezing, addr.
+//	reset                     Forget the addresses found.
+//
+// Values and addresses are decimal or 0x prefixed hexadecimal.
+func (s *Scanner) Exec(args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("no command given")
+	}
+
+	name, args := args[0], args[1:]
+	switch name {
+	case "find", "eq":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: %s <value>", name)
+		}
+		v, err := parse(args[0], 0xFF)
+		if err != nil {
+			return "", err
+		}
+		if name == "find" {
+			s.Reset()
+		}
+		return fmt.Sprintf("%d addresses hold %d", s.Scan(Equal, byte(v)), v), nil
+	case "changed", "unchanged", "inc", "dec":
+		if len(args) != 0 {
+			return "", fmt.Errorf("usage: %s", name)
+		}
+		if s.found == nil {
+			return "", fmt.Errorf("find a value first")
+		}
+		return fmt.Sprintf("%d addresses %s", s.Scan(conds[name], 0), name), nil
+	case "watch", "unwatch":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: %s <addr>", name)
+		}
+		addr, err := parse(args[0], Size-1)
+		if err != nil {
+			return "", err
+		}
+		if name == "watch" {
+			s.Watch(uint16(addr))
+		} else {
+			s.Unwatch(uint16(addr))
+		}
+		return fmt.Sprintf("%sed 0x%03X", name, addr), nil
+	case "freeze":
+		if len(args) != 1 && len(args) != 2 {
+			return "", fmt.Errorf("usage: freeze <addr> [value]")
+		}
+		addr, err := parse(args[0], Size-1)
+		if err != nil {
+			return "", err
+		}
+		v := uint64(s.vm.Peek(uint16(addr)))
+		if len(args) == 2 {
+			if v, err = parse(args[1], 0xFF); err != nil {
+				return "", err
+			}
+		}
+		s.Freeze(uint16(addr), byte(v))
+		return fmt.Sprintf("froze 0x%03X at %d", addr, v), nil
+	case "reset":
+		s.Reset()
+		return "forgot the addresses found", nil
+	default:
+		return "", fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// parse parses a decimal or hexadecimal number no greater than max.
+func parse(s string, max uint64) (uint64, error) {
+	n, err := strconv.ParseUint(s, 0, 64)
+	if err != nil || n > max {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
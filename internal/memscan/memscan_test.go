@@ -0,0 +1,104 @@
+package memscan
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func newVM(t *testing.T) *chip8.VM {
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader([]byte{0x12, 0x00})); err != nil {
+		t.Fatal(err)
+	}
+	return vm
+}
+
+func TestScan(t *testing.T) {
+	vm := newVM(t)
+	s := New(vm)
+
+	// Lives at 0x300 and a decoy at 0x301, both 3.
+	vm.Poke(0x300, 3)
+	vm.Poke(0x301, 3)
+	before := s.Scan(Equal, 3)
+	if before < 2 {
+		t.Fatalf("expected at least 2 addresses holding 3, got %d", before)
+	}
+
+	vm.Poke(0x300, 2)
+	if n := s.Scan(Decreased, 0); n != 1 || s.Found()[0] != 0x300 {
+		t.Fatalf("expected only 0x300 to have decreased, got %#v", s.Found())
+	}
+	if n := s.Scan(Unchanged, 0); n != 1 {
+		t.Fatalf("expected 0x300 to be unchanged, got %#v", s.Found())
+	}
+
+	s.Reset()
+	if n := s.Scan(Changed, 0); n != 0 {
+		t.Fatalf("expected nothing to have changed on a fresh scan, got %d", n)
+	}
+}
+
+func TestFreeze(t *testing.T) {
+	vm := newVM(t)
+	s := New(vm)
+
+	s.Freeze(0x300, 9)
+	vm.Poke(0x300, 1)
+	if err := vm.StepFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if b := vm.Peek(0x300); b != 9 {
+		t.Fatalf("expected the frozen byte to be written back, got %d", b)
+	}
+	if w := s.Watched(); len(w) != 1 || w[0] != 0x300 {
+		t.Fatalf("expected the frozen address to be watched, got %#v", w)
+	}
+
+	s.Unwatch(0x300)
+	if _, ok := s.Frozen(0x300); ok || len(s.Watched()) != 0 {
+		t.Fatal("expected unwatching to unfreeze")
+	}
+}
+
+func TestExec(t *testing.T) {
+	vm := newVM(t)
+	s := New(vm)
+	vm.Poke(0x3F0, 0xAB)
+
+	tests := []struct {
+		cmd  string
+		want string
+		err  bool
+	}{
+		{cmd: "changed", err: true},
+		{cmd: "find 0xAB", want: "addresses hold 171"},
+		{cmd: "unchanged", want: "addresses unchanged"},
+		{cmd: "find 256", err: true},
+		{cmd: "freeze 0x3F0", want: "froze 0x3F0 at 171"},
+		{cmd: "freeze 0x3F0 5", want: "froze 0x3F0 at 5"},
+		{cmd: "watch 0x1000", err: true},
+		{cmd: "unwatch 0x3F0", want: "unwatched 0x3F0"},
+		{cmd: "jump", err: true},
+	}
+
+	for _, tc := range tests {
+		got, err := s.Exec(strings.Fields(tc.cmd))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %q", tc.cmd, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.cmd, err)
+			continue
+		}
+		if !strings.Contains(got, tc.want) {
+			t.Errorf("%s: expected %q in %q", tc.cmd, tc.want, got)
+		}
+	}
+}
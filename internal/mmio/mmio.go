@@ -0,0 +1,49 @@
+// Package mmio maps devices into the memory of the VM, as experimental
+// extensions for homebrew beyond anything a real CHIP-8 had. None are
+// attached unless asked for, so standard ROMs run as they always have.
+package mmio
+
+import (
+	"io"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// SerialStart and SerialEnd bound the memory, from SerialStart up to but not
+// including SerialEnd, a serial port is mapped to: 16 bytes, enough for an
+// FX55 storing every register.
+const (
+	SerialStart = 0xF00
+	SerialEnd   = 0xF10
+)
+
+// Serial is a port streaming the bytes a program writes to its memory, a
+// printf for homebrew: storing registers at SerialStart with FX55 sends
+// their values, in order.
+type Serial struct {
+	w   io.Writer
+	err error
+}
+
+// NewSerial returns a serial port writing to w.
+func NewSerial(w io.Writer) *Serial {
+	return &Serial{w: w}
+}
+
+// Attach maps the port into the memory of vm.
+func (s *Serial) Attach(vm *chip8.VM) {
+	vm.OnStore(s.store)
+}
+
+// Err returns the error the port stopped on, nil if it's still writing.
+func (s *Serial) Err() error {
+	return s.err
+}
+
+// store sends b if it's written to the port's memory.
+func (s *Serial) store(addr uint32, b byte) {
+	if addr < SerialStart || addr >= SerialEnd || s.err != nil {
+		return
+	}
+	_, s.err = s.w.Write([]byte{b})
+}
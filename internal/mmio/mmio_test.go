@@ -0,0 +1,39 @@
+package mmio
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestSerial(t *testing.T) {
+	rom := []byte{
+		0x60, 'h', // V0 = 'h'.
+		0x61, 'i', // V1 = 'i'.
+		0xAF, 0x00, // I = SerialStart.
+		0xF1, 0x55, // Store V0 and V1, sending them.
+		0xA3, 0x00, // I = 0x300.
+		0xF1, 0x55, // Store them outside the port.
+	}
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	s := NewSerial(&out)
+	s.Attach(vm)
+	for i := 0; i < 6; i++ {
+		if err := vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if out.String() != "hi" {
+		t.Fatalf("expected hi, got %q", out.String())
+	}
+	if s.Err() != nil {
+		t.Fatal(s.Err())
+	}
+}
@@ -0,0 +1,205 @@
+// Package movie records the keys pressed while a ROM runs, frame by frame,
+// so the run can be played back exactly: by a test, or offscreen to render a
+// video. Movies are JSON:
+//
+//	{
+//		"version": 1,
+//		"rom": "pong.ch8",
+//		"sha1": "2f1d1c9e...",
+//		"variant": "chip8",
+//		"font": "octo",
+//		"seed": 1589112468,
+//		"frames": 3600,
+//		"inputs": [
+//			{"frame": 61, "key": 5, "down": true},
+//			{"frame": 75, "key": 5, "down": false}
+//		]
+//	}
+//
+// The random numbers of CXNN come from the seed, and key presses are applied
+// at frame boundaries, so the same inputs give the same run.
+package movie
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Ext is the extension of movie files.
+const Ext = ".c8m"
+
+// version is the version of the format written.
+const version = 1
+
+// Movie is a recorded run of a ROM.
+type Movie struct {
+	Version int `json:"version"`
+
+	// ROM is the path of the ROM as recorded and SHA1 the hex encoded hash
+	// of its contents, checked before playing.
+	ROM  string `json:"rom"`
+	SHA1 string `json:"sha1"`
+
+	// The settings the ROM was run with.
+	Variant    string `json:"variant"`
+	Font       string `json:"font"`
+	KeyRelease bool   `json:"keyRelease,omitempty"`
+	VIPRandom  bool   `json:"vipRandom,omitempty"`
+	Logic      bool   `json:"logic,omitempty"`
+	FineDelay  bool   `json:"fineDelay,omitempty"`
+	Seed       int64  `json:"seed"`
+
+	// Entry is the address execution started at, if not the usual, and
+	// Image whether the ROM was a memory image, see chip8.LoadOptions.
+	Entry uint16 `json:"entry,omitempty"`
+	Image bool   `json:"image,omitempty"`
+
+	// Frames is the length of the run in 60Hz frames.
+	Frames int     `json:"frames"`
+	Inputs []Input `json:"inputs"`
+}
+
+// Input is a key pressed or released, applied at the boundary of the given
+// frame, counting from 1.
+type Input struct {
+	Frame int  `json:"frame"`
+	Key   byte `json:"key"`
+	Down  bool `json:"down"`
+}
+
+// Hash returns the hex encoded SHA1 hash of rom, as recorded in movies.
+func Hash(rom []byte) string {
+	sum := sha1.Sum(rom)
+	return hex.EncodeToString(sum[:])
+}
+
+// Load reads the movie at path.
+func Load(path string) (*Movie, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Movie{}
+	if err = json.Unmarshal(b, m); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if m.Version != version {
+		return nil, fmt.Errorf("%s: unsupported version %d", path, m.Version)
+	}
+	return m, nil
+}
+
+// Save writes the movie to path.
+func (m *Movie) Save(path string) error {
+	b, err := json.MarshalIndent(m, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Check returns an error if rom isn't the ROM the movie was recorded with.
+func (m *Movie) Check(rom []byte) error {
+	if h := Hash(rom); h != m.SHA1 {
+		return fmt.Errorf("the ROM's hash %s doesn't match the movie's %s", h, m.SHA1)
+	}
+	return nil
+}
+
+// Recorder records the keys pressed on a VM into a movie.
+type Recorder struct {
+	vm    *chip8.VM
+	movie *Movie
+	down  [16]bool
+
+	// Set once recording has stopped.
+	stopped bool
+}
+
+// Record starts recording the keys pressed on vm, which has just loaded rom
+// from path with the settings in m, the inputs and frames of which are
+// filled in as the VM runs. The VM's random numbers are seeded with m.Seed.
+// Keys must be pressed with Press and Release, which apply them at frame
+// boundaries.
+func Record(vm *chip8.VM, m *Movie, rom []byte) *Recorder {
+	r := &Recorder{vm: vm}
+	r.Reset(m, rom)
+	vm.OnFrame(r.frame)
+	return r
+}
+
+// Reset starts a new movie, m, once the VM has loaded another ROM or
+// restarted this one, forgetting the movie recorded so far.
+func (r *Recorder) Reset(m *Movie, rom []byte) {
+	m.Version, m.SHA1 = version, Hash(rom)
+	r.vm.Seed(m.Seed)
+	r.movie, r.down, r.stopped = m, [16]bool{}, false
+}
+
+// Restart starts the movie afresh with the same settings and seed, once the
+// VM has restarted the ROM.
+func (r *Recorder) Restart() {
+	m := *r.movie
+	m.Frames, m.Inputs = 0, nil
+	r.vm.Seed(m.Seed)
+	r.movie, r.down, r.stopped = &m, [16]bool{}, false
+}
+
+// Stop stops recording, ending the movie at the last frame, for when the VM
+// changes in a way the movie can't play back, such as loading a save state.
+func (r *Recorder) Stop() {
+	r.stopped = true
+}
+
+// frame records the keys that changed at the frame boundary just passed.
+func (r *Recorder) frame() {
+	if r.stopped {
+		return
+	}
+
+	m := r.movie
+	m.Frames++
+	for k := range r.down {
+		down := r.vm.Held(byte(k)) > 0
+		if down != r.down[k] {
+			r.down[k] = down
+			m.Inputs = append(m.Inputs, Input{Frame: m.Frames, Key: byte(k), Down: down})
+		}
+	}
+}
+
+// Movie returns the movie recorded so far.
+func (r *Recorder) Movie() *Movie {
+	return r.movie
+}
+
+// Play queues the inputs of m on vm as it runs, from its next frame, which
+// should be the first after the ROM was loaded. It seeds the VM's random
+// numbers with m.Seed.
+func Play(vm *chip8.VM, m *Movie) {
+	vm.Seed(m.Seed)
+
+	frame, next := 0, 0
+	queue := func() {
+		for ; next < len(m.Inputs) && m.Inputs[next].Frame <= frame+1; next++ {
+			if in := m.Inputs[next]; in.Down {
+				vm.Press(in.Key)
+			} else {
+				vm.Release(in.Key)
+			}
+		}
+	}
+
+	// The inputs of each frame are queued at the end of the one before.
+	queue()
+	vm.OnFrame(func() {
+		frame++
+		queue()
+	})
+}
@@ -0,0 +1,119 @@
+package movie
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// rom sums random numbers into V1 and counts the frames key 0 is held for in
+// V2.
+var rom = []byte{
+	0xC3, 0xFF, // V3 = rand.
+	0x81, 0x34, // V1 += V3.
+	0xE0, 0x9E, // Skip if key 0 is pressed.
+	0x12, 0x00, // Loop.
+	0x72, 0x01, // V2 += 1.
+	0x12, 0x00, // Loop.
+}
+
+func load(t *testing.T) *chip8.VM {
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	return vm
+}
+
+func step(t *testing.T, vm *chip8.VM, frames int) {
+	for i := 0; i < frames; i++ {
+		if err := vm.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRecordPlay(t *testing.T) {
+	vm := load(t)
+	r := Record(vm, &Movie{ROM: "test.ch8", Seed: 42}, rom)
+	step(t, vm, 3)
+	vm.Press(0)
+	step(t, vm, 5)
+	vm.Release(0)
+	vm.Press(0)
+	step(t, vm, 1)
+	vm.Release(0)
+	step(t, vm, 4)
+
+	m := r.Movie()
+	want := []Input{{4, 0, true}, {9, 0, false}, {10, 0, true}, {11, 0, false}}
+	if m.Frames != 13 || !reflect.DeepEqual(m.Inputs, want) {
+		t.Fatalf("expected 13 frames of %v, got %d of %v", want, m.Frames, m.Inputs)
+	}
+	if vm.V(2) != 6 {
+		t.Fatalf("expected key 0 to be held for 6 frames, got %d", vm.V(2))
+	}
+
+	dir, err := ioutil.TempDir("", "movie")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "test"+Ext)
+	if err = m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = loaded.Check(rom); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := load(t)
+	Play(replay, loaded)
+	step(t, replay, loaded.Frames)
+	for x := byte(0); x < 16; x++ {
+		if replay.V(x) != vm.V(x) {
+			t.Fatalf("v%X: expected 0x%02X, got 0x%02X", x, vm.V(x), replay.V(x))
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	m := &Movie{SHA1: Hash(rom)}
+	if err := m.Check(rom); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Check(rom[:2]); err == nil {
+		t.Fatal("expected a different ROM to fail")
+	}
+}
+
+func TestRestartStop(t *testing.T) {
+	vm := load(t)
+	r := Record(vm, &Movie{Seed: 1}, rom)
+	vm.Press(0)
+	step(t, vm, 2)
+	if err := vm.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	r.Restart()
+	vm.Press(1)
+	step(t, vm, 2)
+	r.Stop()
+	vm.Release(1)
+	step(t, vm, 2)
+
+	m := r.Movie()
+	want := []Input{{1, 1, true}}
+	if m.Frames != 2 || m.Seed != 1 || !reflect.DeepEqual(m.Inputs, want) {
+		t.Fatalf("expected 2 frames of %v, got %d of %v", want, m.Frames, m.Inputs)
+	}
+}
@@ -0,0 +1,1060 @@
+// Package octo assembles programs written in Octo, the de facto modern CHIP-8
+// assembly language, into ROMs.
+//
+// Labels, :const, :alias, :macro, :org, :byte, :unpack and :call are
+// supported, as are structured loops (loop, while, again) and conditionals
+// (if then, if begin else end) including the <, >, <= and >= comparisons,
+// which use VF. Expressions (:calc), :next, :stringmode and :assert are not.
+//
+// As an extension, :print vX, :dump and :fail call the emulator's debug
+// routines, printing registers on the host or failing a test run, see
+// chip8.EnableDebug. Each is a single instruction, so can follow if then.
+package octo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/symbol"
+)
+
+// start is the address programs are loaded at.
+const start = 0x200
+
+// Program is an assembled program.
+type Program struct {
+	// ROM is the program, to be loaded at 0x200.
+	ROM []byte
+
+	// Symbols holds the labels. Where several share an address only the
+	// first is kept.
+	Symbols *symbol.Table
+}
+
+// Error is an error in the source, at the given line.
+type Error struct {
+	Line int
+	Msg  string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// token is a word of source.
+type token struct {
+	text string
+	line int
+}
+
+// macro is a macro defined with :macro.
+type macro struct {
+	args []string
+	body []token
+}
+
+// fixupKind is the way a label's address is written into an instruction.
+type fixupKind int
+
+const (
+	// fixAddr fills the low 12 bits of the instruction.
+	fixAddr fixupKind = iota
+
+	// fixLong fills the 16 bit word.
+	fixLong
+
+	// fixUnpack fills the low nibble of the first and the byte of the second
+	// of a pair of 6XNN instructions.
+	fixUnpack
+)
+
+// fixup is a reference to a label that wasn't defined when it was used.
+type fixup struct {
+	kind fixupKind
+	at   uint16
+	name string
+	line int
+}
+
+// loop is an open loop.
+type loop struct {
+	addr uint16
+
+	// The jumps out of the loop made by while, filled in by again.
+	breaks []uint16
+}
+
+// assembler holds the state of an assembly.
+type assembler struct {
+	toks []token
+	line int
+
+	rom  []byte
+	here uint16
+
+	labels  map[string]uint16
+	order   []token
+	consts  map[string]int
+	aliases map[string]byte
+	macros  map[string]macro
+	fixups  []fixup
+
+	loops []loop
+
+	// The addresses of the jumps made by open if begin blocks, to be filled
+	// in by else or end.
+	ifs []uint16
+}
+
+// Assemble assembles the Octo source src.
+func Assemble(src []byte) (*Program, error) {
+	a := &assembler{
+		here:    start + 2, // Room for the jump to main.
+		labels:  make(map[string]uint16),
+		consts:  make(map[string]int),
+		aliases: make(map[string]byte),
+		macros:  make(map[string]macro),
+		rom:     make([]byte, 2),
+	}
+	a.tokenize(src)
+
+	for len(a.toks) > 0 {
+		if err := a.statement(); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case len(a.loops) > 0:
+		return nil, a.errorf("loop without again")
+	case len(a.ifs) > 0:
+		return nil, a.errorf("if begin without end")
+	}
+
+	main, ok := a.labels["main"]
+	if !ok {
+		return nil, a.errorf("no main label")
+	}
+	a.put(start, 0x1000|main)
+
+	for _, f := range a.fixups {
+		addr, ok := a.labels[f.name]
+		if !ok {
+			return nil, &Error{Line: f.line, Msg: fmt.Sprintf("undefined name %q", f.name)}
+		}
+		if err := a.fix(f, addr); err != nil {
+			return nil, err
+		}
+	}
+
+	syms := symbol.NewTable()
+	for _, l := range a.order {
+		addr := a.labels[l.text]
+		if _, taken := syms.Lookup(addr); taken {
+			continue
+		}
+		if err := syms.Add(symbol.Symbol{Addr: addr, Name: l.text}); err != nil {
+			return nil, &Error{Line: l.line, Msg: err.Error()}
+		}
+	}
+
+	return &Program{ROM: a.rom, Symbols: syms}, nil
+}
+
+// tokenize splits src into tokens, dropping comments.
+func (a *assembler) tokenize(src []byte) {
+	sc := bufio.NewScanner(bytes.NewReader(src))
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, f := range strings.Fields(line) {
+			a.toks = append(a.toks, token{text: f, line: n})
+		}
+	}
+}
+
+// errorf returns an error at the current line.
+func (a *assembler) errorf(format string, args ...interface{}) error {
+	return &Error{Line: a.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next token.
+func (a *assembler) next() (string, error) {
+	if len(a.toks) == 0 {
+		return "", a.errorf("unexpected end of source")
+	}
+	t := a.toks[0]
+	a.toks = a.toks[1:]
+	a.line = t.line
+
+	return t.text, nil
+}
+
+// peek returns the next token without consuming it.
+func (a *assembler) peek() string {
+	if len(a.toks) == 0 {
+		return ""
+	}
+	return a.toks[0].text
+}
+
+// expect consumes the next token, which must be want.
+func (a *assembler) expect(want string) error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+	if t != want {
+		return a.errorf("expected %q, got %q", want, t)
+	}
+	return nil
+}
+
+// emit appends bytes at the current address.
+func (a *assembler) emit(b ...byte) {
+	for _, v := range b {
+		i := int(a.here) - start
+		for len(a.rom) <= i {
+			a.rom = append(a.rom, 0)
+		}
+		a.rom[i] = v
+		a.here++
+	}
+}
+
+// op emits the instructions opcs.
+func (a *assembler) op(opcs ...uint16) {
+	for _, opc := range opcs {
+		a.emit(byte(opc>>8), byte(opc))
+	}
+}
+
+// put overwrites the word at addr with w.
+func (a *assembler) put(addr, w uint16) {
+	a.rom[addr-start] = byte(w >> 8)
+	a.rom[addr-start+1] = byte(w)
+}
+
+// word returns the word at addr.
+func (a *assembler) word(addr uint16) uint16 {
+	return uint16(a.rom[addr-start])<<8 | uint16(a.rom[addr-start+1])
+}
+
+// fix writes addr into the instruction referencing it.
+func (a *assembler) fix(f fixup, addr uint16) error {
+	switch f.kind {
+	case fixAddr:
+		if addr > 0xFFF {
+			return &Error{Line: f.line, Msg: fmt.Sprintf("address of %q too large: 0x%X", f.name, addr)}
+		}
+		a.put(f.at, a.word(f.at)&0xF000|addr)
+	case fixLong:
+		a.put(f.at, addr)
+	case fixUnpack:
+		a.put(f.at, a.word(f.at)|addr>>8&0xF)
+		a.put(f.at+2, a.word(f.at+2)|addr&0xFF)
+	}
+	return nil
+}
+
+// statement assembles the next statement.
+func (a *assembler) statement() error {
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	if m, ok := a.macros[t]; ok {
+		return a.expand(m)
+	}
+	if _, ok := a.reg(t); ok {
+		return a.regOp(t)
+	}
+
+	switch t {
+	case ":":
+		return a.label()
+	case ":const":
+		return a.constant()
+	case ":alias":
+		return a.alias()
+	case ":macro":
+		return a.macro()
+	case ":org":
+		n, err := a.number(0xFFFF)
+		if err != nil {
+			return err
+		}
+		if n < start+2 {
+			return a.errorf(":org 0x%X is before the program", n)
+		}
+		a.here = uint16(n)
+	case ":byte":
+		n, err := a.number(0xFF)
+		if err != nil {
+			return err
+		}
+		a.emit(byte(n))
+	case ":unpack":
+		return a.unpack()
+	case ":call":
+		return a.addrOp(0x2000)
+	case ":breakpoint":
+		_, err = a.next()
+		return err
+	case ":monitor":
+		if _, err = a.next(); err == nil {
+			_, err = a.next()
+		}
+		return err
+	case ":print":
+		x, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		a.op(chip8.DebugPrint | x)
+	case ":dump":
+		a.op(chip8.DebugDump)
+	case ":fail":
+		a.op(chip8.DebugFail)
+	case ":calc", ":next", ":stringmode", ":assert":
+		return a.errorf("%s is not supported", t)
+
+	case ";", "return":
+		a.op(0x00EE)
+	case "clear":
+		a.op(0x00E0)
+	case "hires":
+		a.op(0x00FF)
+	case "lores":
+		a.op(0x00FE)
+	case "exit":
+		a.op(0x00FD)
+	case "scroll-left":
+		a.op(0x00FC)
+	case "scroll-right":
+		a.op(0x00FB)
+	case "scroll-down", "scroll-up", "plane":
+		n, err := a.number(0xF)
+		if err != nil {
+			return err
+		}
+		switch t {
+		case "scroll-down":
+			a.op(0x00C0 | uint16(n))
+		case "scroll-up":
+			a.op(0x00D0 | uint16(n))
+		default:
+			a.op(0xF001 | uint16(n)<<8)
+		}
+	case "audio":
+		a.op(0xF002)
+	case "jump":
+		return a.addrOp(0x1000)
+	case "jump0":
+		return a.addrOp(0xB000)
+	case "bcd", "saveflags", "loadflags":
+		x, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		a.op(map[string]uint16{"bcd": 0xF033, "saveflags": 0xF075, "loadflags": 0xF085}[t] | x<<8)
+	case "save", "load":
+		return a.saveLoad(t == "load")
+	case "sprite":
+		return a.sprite()
+	case "delay", "buzzer", "pitch":
+		if err = a.expect(":="); err != nil {
+			return err
+		}
+		x, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		a.op(map[string]uint16{"delay": 0xF015, "buzzer": 0xF018, "pitch": 0xF03A}[t] | x<<8)
+	case "i":
+		return a.iOp()
+	case "loop":
+		a.loops = append(a.loops, loop{addr: a.here})
+	case "while":
+		return a.while()
+	case "again":
+		return a.again()
+	case "if":
+		return a.ifStatement()
+	case "else":
+		return a.elseStatement()
+	case "end":
+		return a.end()
+
+	default:
+		if n, err := parseNumber(t); err == nil {
+			if n < -128 || n > 0xFF {
+				return a.errorf("byte out of range: %s", t)
+			}
+			a.emit(byte(n))
+			return nil
+		}
+		if !isName(t) {
+			return a.errorf("unexpected %q", t)
+		}
+		// A bare name calls the subroutine.
+		a.toks = append([]token{{text: t, line: a.line}}, a.toks...)
+		return a.addrOp(0x2000)
+	}
+
+	return nil
+}
+
+// label defines a label at the current address.
+func (a *assembler) label() error {
+	name, err := a.nextName()
+	if err != nil {
+		return err
+	}
+	if _, ok := a.labels[name]; ok {
+		return a.errorf("duplicate label %q", name)
+	}
+	a.labels[name] = a.here
+	a.order = append(a.order, token{text: name, line: a.line})
+
+	return nil
+}
+
+// constant defines a constant.
+func (a *assembler) constant() error {
+	name, err := a.nextName()
+	if err != nil {
+		return err
+	}
+	n, err := a.number(0xFFFF)
+	if err != nil {
+		return err
+	}
+	a.consts[name] = n
+
+	return nil
+}
+
+// alias defines another name for a register.
+func (a *assembler) alias() error {
+	name, err := a.nextName()
+	if err != nil {
+		return err
+	}
+	x, err := a.nextReg()
+	if err != nil {
+		return err
+	}
+	a.aliases[name] = byte(x)
+
+	return nil
+}
+
+// macro defines a macro, its arguments followed by its body in braces.
+func (a *assembler) macro() error {
+	name, err := a.nextName()
+	if err != nil {
+		return err
+	}
+
+	var m macro
+	for {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		if t == "{" {
+			break
+		}
+		m.args = append(m.args, t)
+	}
+
+	for depth := 1; ; {
+		if len(a.toks) == 0 {
+			return a.errorf("macro %q without closing }", name)
+		}
+		t := a.toks[0]
+		a.toks = a.toks[1:]
+
+		switch t.text {
+		case "{":
+			depth++
+		case "}":
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+		m.body = append(m.body, t)
+	}
+	a.macros[name] = m
+
+	return nil
+}
+
+// expand replaces a macro invocation with its body.
+func (a *assembler) expand(m macro) error {
+	args := make(map[string]string, len(m.args))
+	for _, name := range m.args {
+		t, err := a.next()
+		if err != nil {
+			return err
+		}
+		args[name] = t
+	}
+
+	body := make([]token, len(m.body))
+	for i, t := range m.body {
+		if v, ok := args[t.text]; ok {
+			t.text = v
+		}
+		t.line = a.line
+		body[i] = t
+	}
+	a.toks = append(body, a.toks...)
+
+	return nil
+}
+
+// unpack loads the address of a label into V0 and V1, with a nibble in the
+// high bits of V0.
+func (a *assembler) unpack() error {
+	nibble, err := a.number(0xF)
+	if err != nil {
+		return err
+	}
+	name, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	at := a.here
+	a.op(0x6000|uint16(nibble)<<4, 0x6100)
+
+	return a.ref(fixup{kind: fixUnpack, at: at, name: name, line: a.line})
+}
+
+// addrOp emits opc with the address in the next token.
+func (a *assembler) addrOp(opc uint16) error {
+	name, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	at := a.here
+	if n, err := a.value(name); err == nil {
+		if n < 0 || n > 0xFFF {
+			return a.errorf("address out of range: %s", name)
+		}
+		a.op(opc | uint16(n))
+		return nil
+	}
+	a.op(opc)
+
+	return a.ref(fixup{kind: fixAddr, at: at, name: name, line: a.line})
+}
+
+// ref fills in f now if the label is defined, or once the source has been
+// assembled.
+func (a *assembler) ref(f fixup) error {
+	if !isName(f.name) {
+		return a.errorf("invalid name %q", f.name)
+	}
+	if addr, ok := a.labels[f.name]; ok {
+		return a.fix(f, addr)
+	}
+	a.fixups = append(a.fixups, f)
+
+	return nil
+}
+
+// saveLoad assembles save and load, of V0 to VX or of a range VX - VY.
+func (a *assembler) saveLoad(load bool) error {
+	x, err := a.nextReg()
+	if err != nil {
+		return err
+	}
+
+	if a.peek() != "-" {
+		if load {
+			a.op(0xF065 | x<<8)
+		} else {
+			a.op(0xF055 | x<<8)
+		}
+		return nil
+	}
+
+	a.next()
+	y, err := a.nextReg()
+	if err != nil {
+		return err
+	}
+	if load {
+		a.op(0x5003 | x<<8 | y<<4)
+	} else {
+		a.op(0x5002 | x<<8 | y<<4)
+	}
+
+	return nil
+}
+
+// sprite assembles sprite VX VY N.
+func (a *assembler) sprite() error {
+	x, err := a.nextReg()
+	if err != nil {
+		return err
+	}
+	y, err := a.nextReg()
+	if err != nil {
+		return err
+	}
+	n, err := a.number(0xF)
+	if err != nil {
+		return err
+	}
+	a.op(0xD000 | x<<8 | y<<4 | uint16(n))
+
+	return nil
+}
+
+// iOp assembles the assignments to I.
+func (a *assembler) iOp() error {
+	op, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	switch op {
+	case "+=":
+		x, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		a.op(0xF01E | x<<8)
+		return nil
+	case ":=":
+	default:
+		return a.errorf("unknown operator i %s", op)
+	}
+
+	switch a.peek() {
+	case "hex", "bighex":
+		t, _ := a.next()
+		x, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		if t == "hex" {
+			a.op(0xF029 | x<<8)
+		} else {
+			a.op(0xF030 | x<<8)
+		}
+		return nil
+	case "long":
+		a.next()
+		name, err := a.next()
+		if err != nil {
+			return err
+		}
+		a.op(0xF000)
+		if n, err := a.value(name); err == nil {
+			a.op(uint16(n))
+			return nil
+		}
+		at := a.here
+		a.op(0)
+		return a.ref(fixup{kind: fixLong, at: at, name: name, line: a.line})
+	}
+
+	return a.addrOp(0xA000)
+}
+
+// regOp assembles the operations on the register x.
+func (a *assembler) regOp(name string) error {
+	x, _ := a.reg(name)
+	op, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	// Operations taking a register on the right.
+	regOps := map[string]uint16{
+		"|=": 0x8001, "&=": 0x8002, "^=": 0x8003, "=-": 0x8007,
+		">>=": 0x8006, "<<=": 0x800E,
+	}
+	if opc, ok := regOps[op]; ok {
+		y, err := a.nextReg()
+		if err != nil {
+			return err
+		}
+		a.op(opc | x<<8 | y<<4)
+		return nil
+	}
+
+	rhs, err := a.next()
+	if err != nil {
+		return err
+	}
+	y, isReg := a.reg(rhs)
+
+	switch op {
+	case ":=":
+		switch {
+		case isReg:
+			a.op(0x8000 | x<<8 | y<<4)
+		case rhs == "key":
+			a.op(0xF00A | x<<8)
+		case rhs == "delay":
+			a.op(0xF007 | x<<8)
+		case rhs == "random":
+			n, err := a.number(0xFF)
+			if err != nil {
+				return err
+			}
+			a.op(0xC000 | x<<8 | uint16(n))
+		default:
+			n, err := a.byteValue(rhs)
+			if err != nil {
+				return err
+			}
+			a.op(0x6000 | x<<8 | uint16(n))
+		}
+	case "+=":
+		if isReg {
+			a.op(0x8004 | x<<8 | y<<4)
+			return nil
+		}
+		n, err := a.byteValue(rhs)
+		if err != nil {
+			return err
+		}
+		a.op(0x7000 | x<<8 | uint16(n))
+	case "-=":
+		if isReg {
+			a.op(0x8005 | x<<8 | y<<4)
+			return nil
+		}
+		n, err := a.byteValue(rhs)
+		if err != nil {
+			return err
+		}
+		a.op(0x7000 | x<<8 | uint16(-int(n))&0xFF)
+	default:
+		return a.errorf("unknown operator %s %s", name, op)
+	}
+
+	return nil
+}
+
+// condition assembles a condition, returning the skip instruction that skips
+// the next instruction when it's false. Its inverse, skipping when true, is
+// the skip ^ invert.
+func (a *assembler) condition() (skip uint16, err error) {
+	x, err := a.nextReg()
+	if err != nil {
+		return 0, err
+	}
+	op, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+
+	switch op {
+	case "key":
+		return 0xE0A1 | x<<8, nil
+	case "-key":
+		return 0xE09E | x<<8, nil
+	}
+
+	rhs, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	y, isReg := a.reg(rhs)
+
+	var n byte
+	if !isReg {
+		if n, err = a.byteValue(rhs); err != nil {
+			return 0, err
+		}
+	}
+
+	switch op {
+	case "==":
+		if isReg {
+			return 0x9000 | x<<8 | y<<4, nil
+		}
+		return 0x4000 | x<<8 | uint16(n), nil
+	case "!=":
+		if isReg {
+			return 0x5000 | x<<8 | y<<4, nil
+		}
+		return 0x3000 | x<<8 | uint16(n), nil
+	case "<", ">", "<=", ">=":
+	default:
+		return 0, a.errorf("unknown comparison %q", op)
+	}
+
+	// Subtract in VF, the flag left in VF telling which is larger.
+	if isReg {
+		a.op(0x8F00 | y<<4)
+	} else {
+		a.op(0x6F00 | uint16(n))
+	}
+	switch op {
+	case "<", ">=":
+		a.op(0x8F07 | x<<4) // VF = VX - rhs, 1 if VX >= rhs.
+	default:
+		a.op(0x8F05 | x<<4) // VF = rhs - VX, 1 if rhs >= VX.
+	}
+	if op == "<" || op == ">" {
+		return 0x4F00, nil
+	}
+	return 0x4F01, nil
+}
+
+// invert returns the skip instruction skipping when the condition of skip,
+// as returned by condition, is true.
+func invert(skip uint16) uint16 {
+	switch skip & 0xF000 {
+	case 0x3000:
+		return skip&^0xF000 | 0x4000
+	case 0x4000:
+		return skip&^0xF000 | 0x3000
+	case 0x5000:
+		return skip&^0xF000 | 0x9000
+	case 0x9000:
+		return skip&^0xF000 | 0x5000
+	}
+	// EXA1 and EX9E.
+	return skip ^ (0xA1 ^ 0x9E)
+}
+
+// ifStatement assembles if then, skipping the next statement when false, and
+// if begin, jumping to else or end when false.
+func (a *assembler) ifStatement() error {
+	skip, err := a.condition()
+	if err != nil {
+		return err
+	}
+	t, err := a.next()
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case "then":
+		a.op(skip)
+	case "begin":
+		a.op(invert(skip))
+		a.ifs = append(a.ifs, a.here)
+		a.op(0x1000)
+	default:
+		return a.errorf("expected then or begin, got %q", t)
+	}
+
+	return nil
+}
+
+// elseStatement ends the true branch of an if begin by jumping past the
+// false branch, which starts here.
+func (a *assembler) elseStatement() error {
+	if len(a.ifs) == 0 {
+		return a.errorf("else without if begin")
+	}
+	jump := a.ifs[len(a.ifs)-1]
+
+	a.ifs[len(a.ifs)-1] = a.here
+	a.op(0x1000)
+	a.put(jump, 0x1000|a.here)
+
+	return nil
+}
+
+// end ends an if begin.
+func (a *assembler) end() error {
+	if len(a.ifs) == 0 {
+		return a.errorf("end without if begin")
+	}
+	a.put(a.ifs[len(a.ifs)-1], 0x1000|a.here)
+	a.ifs = a.ifs[:len(a.ifs)-1]
+
+	return nil
+}
+
+// while breaks out of the current loop when its condition is false.
+func (a *assembler) while() error {
+	if len(a.loops) == 0 {
+		return a.errorf("while outside a loop")
+	}
+	skip, err := a.condition()
+	if err != nil {
+		return err
+	}
+
+	a.op(invert(skip))
+	l := &a.loops[len(a.loops)-1]
+	l.breaks = append(l.breaks, a.here)
+	a.op(0x1000)
+
+	return nil
+}
+
+// again jumps back to the start of the current loop.
+func (a *assembler) again() error {
+	if len(a.loops) == 0 {
+		return a.errorf("again without loop")
+	}
+	l := a.loops[len(a.loops)-1]
+	a.loops = a.loops[:len(a.loops)-1]
+
+	a.op(0x1000 | l.addr)
+	for _, b := range l.breaks {
+		a.put(b, 0x1000|a.here)
+	}
+
+	return nil
+}
+
+// reg returns the register named by t, directly or by an alias.
+func (a *assembler) reg(t string) (uint16, bool) {
+	if x, ok := a.aliases[t]; ok {
+		return uint16(x), true
+	}
+	if len(t) != 2 || (t[0] != 'v' && t[0] != 'V') {
+		return 0, false
+	}
+	x, err := strconv.ParseUint(t[1:], 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(x), true
+}
+
+// nextReg consumes a register.
+func (a *assembler) nextReg() (uint16, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	x, ok := a.reg(t)
+	if !ok {
+		return 0, a.errorf("expected a register, got %q", t)
+	}
+	return x, nil
+}
+
+// nextName consumes a name being defined.
+func (a *assembler) nextName() (string, error) {
+	t, err := a.next()
+	if err != nil {
+		return "", err
+	}
+	if !isName(t) {
+		return "", a.errorf("invalid name %q", t)
+	}
+	return t, nil
+}
+
+// value returns the value of a number, constant or defined label.
+func (a *assembler) value(t string) (int, error) {
+	if n, ok := a.consts[t]; ok {
+		return n, nil
+	}
+	if addr, ok := a.labels[t]; ok {
+		return int(addr), nil
+	}
+	return parseNumber(t)
+}
+
+// number consumes a value between 0 and max. Negative numbers count back from
+// max + 1.
+func (a *assembler) number(max int) (int, error) {
+	t, err := a.next()
+	if err != nil {
+		return 0, err
+	}
+	n, err := a.value(t)
+	if err != nil {
+		return 0, a.errorf("expected a number, got %q", t)
+	}
+	if n < -(max+1)/2 || n > max {
+		return 0, a.errorf("number out of range: %s", t)
+	}
+	if n < 0 {
+		n += max + 1
+	}
+	return n, nil
+}
+
+// byteValue returns the byte value of t.
+func (a *assembler) byteValue(t string) (byte, error) {
+	n, err := a.value(t)
+	if err != nil {
+		return 0, a.errorf("expected a number, got %q", t)
+	}
+	if n < -128 || n > 0xFF {
+		return 0, a.errorf("byte out of range: %s", t)
+	}
+	return byte(n), nil
+}
+
+// parseNumber parses a decimal, 0x hexadecimal or 0b binary number.
+func parseNumber(t string) (int, error) {
+	s, neg := t, false
+	if strings.HasPrefix(s, "-") {
+		s, neg = s[1:], true
+	}
+
+	var (
+		n   uint64
+		err error
+	)
+	switch {
+	case strings.HasPrefix(s, "0x"), strings.HasPrefix(s, "0X"):
+		n, err = strconv.ParseUint(s[2:], 16, 16)
+	case strings.HasPrefix(s, "0b"), strings.HasPrefix(s, "0B"):
+		n, err = strconv.ParseUint(s[2:], 2, 16)
+	default:
+		n, err = strconv.ParseUint(s, 10, 16)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", t)
+	}
+	if neg {
+		return -int(n), nil
+	}
+	return int(n), nil
+}
+
+// isName returns true if t can name a label, constant, alias or macro.
+func isName(t string) bool {
+	if t == "" || strings.ContainsAny(t, ":;{}") {
+		return false
+	}
+	if _, err := parseNumber(t); err == nil {
+		return false
+	}
+	return !reserved[t]
+}
+
+// reserved are the words of the language, which can't be used as names.
+var reserved = map[string]bool{
+	"clear": true, "return": true, "hires": true, "lores": true, "exit": true,
+	"scroll-left": true, "scroll-right": true, "scroll-down": true,
+	"scroll-up": true, "plane": true, "audio": true, "jump": true,
+	"jump0": true, "bcd": true, "save": true, "load": true, "saveflags": true,
+	"loadflags": true, "sprite": true, "delay": true, "buzzer": true,
+	"pitch": true, "i": true, "loop": true, "while": true, "again": true,
+	"if": true, "then": true, "begin": true, "else": true, "end": true,
+	"key": true, "-key": true, "random": true, "hex": true, "bighex": true,
+	"long": true,
+}
@@ -0,0 +1,207 @@
+package octo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestAssemble(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []byte
+	}{
+		{
+			name: "statements",
+			src: `: main
+				clear
+				v0 := 5  v1 := v0  v2 += 1  v2 += v1  v3 -= 1  v3 -= v2  v4 =- v3
+				v5 |= v6  v5 &= v6  v5 ^= v6  v5 >>= v6  v5 <<= v6
+				v6 := random 0xFF  v7 := key  v8 := delay
+				delay := v1  buzzer := v2
+				i := 0x300  i += v1  i := hex v2  bcd v3  save v4  load v5
+				sprite v1 v2 5
+				return`,
+			want: []byte{
+				0x12, 0x02,
+				0x00, 0xE0,
+				0x60, 0x05, 0x81, 0x00, 0x72, 0x01, 0x82, 0x14, 0x73, 0xFF, 0x83, 0x25, 0x84, 0x37,
+				0x85, 0x61, 0x85, 0x62, 0x85, 0x63, 0x85, 0x66, 0x85, 0x6E,
+				0xC6, 0xFF, 0xF7, 0x0A, 0xF8, 0x07,
+				0xF1, 0x15, 0xF2, 0x18,
+				0xA3, 0x00, 0xF1, 0x1E, 0xF2, 0x29, 0xF3, 0x33, 0xF4, 0x55, 0xF5, 0x65,
+				0xD1, 0x25,
+				0x00, 0xEE,
+			},
+		},
+		{
+			name: "labels, constants and aliases",
+			src: `:const speed 3
+				:alias x v4
+				: main
+				x := speed
+				draw
+				jump main
+				: draw
+				i := dot
+				;
+				: dot 0x80`,
+			want: []byte{
+				0x12, 0x02,
+				0x64, 0x03,
+				0x22, 0x08,
+				0x12, 0x02,
+				0xA2, 0x0C,
+				0x00, 0xEE,
+				0x80,
+			},
+		},
+		{
+			name: "macros",
+			src: `:macro move reg amount { reg += amount }
+				: main
+				move v1 2
+				move v2 -1`,
+			want: []byte{0x12, 0x02, 0x71, 0x02, 0x72, 0xFF},
+		},
+		{
+			name: "conditionals",
+			src: `: main
+				if v0 == 1 then v1 := 2
+				if v0 != v1 then v1 := 2
+				if v0 key then v1 := 2
+				if v0 == 1 begin
+					v1 := 2
+				else
+					v1 := 3
+				end
+				if v0 < v1 then v1 := 2`,
+			want: []byte{
+				0x12, 0x02,
+				0x40, 0x01, 0x61, 0x02,
+				0x50, 0x10, 0x61, 0x02,
+				0xE0, 0xA1, 0x61, 0x02,
+				0x30, 0x01, 0x12, 0x16, 0x61, 0x02, 0x12, 0x18, 0x61, 0x03,
+				0x8F, 0x10, 0x8F, 0x07, 0x4F, 0x00, 0x61, 0x02,
+			},
+		},
+		{
+			name: "loops",
+			src: `: main
+				loop
+					v0 += 1
+					while v0 != 10
+				again`,
+			want: []byte{
+				0x12, 0x02,
+				0x70, 0x01, 0x40, 0x0A, 0x12, 0x0A, 0x12, 0x02,
+			},
+		},
+		{
+			name: "xo-chip",
+			src: `: main
+				hires plane 3 scroll-down 4 scroll-up 2 scroll-left scroll-right
+				save v1 - v3 load v3 - v1 i := long data audio pitch := v1
+				:org 0x400 : data 0xFF`,
+			want: append([]byte{
+				0x12, 0x02,
+				0x00, 0xFF, 0xF3, 0x01, 0x00, 0xC4, 0x00, 0xD2, 0x00, 0xFC, 0x00, 0xFB,
+				0x51, 0x32, 0x53, 0x13, 0xF0, 0x00, 0x04, 0x00, 0xF0, 0x02, 0xF1, 0x3A,
+			}, append(make([]byte, 0x200-26), 0xFF)...),
+		},
+		{
+			name: "unpack",
+			src: `: main :unpack 0xA data
+				:org 0x234 : data`,
+			want: []byte{0x12, 0x02, 0x60, 0xA2, 0x61, 0x34},
+		},
+		{
+			name: "debug",
+			src: `: main :print v3 :dump
+				if v0 != 5 then :fail`,
+			want: []byte{0x12, 0x02, 0x0F, 0xF3, 0x0F, 0xD0, 0x30, 0x05, 0x0F, 0xD1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := Assemble([]byte(tc.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p.ROM, tc.want) {
+				t.Fatalf("expected:\n% X\ngot:\n% X", tc.want, p.ROM)
+			}
+		})
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	tests := map[string]string{
+		"no main":        "clear",
+		"undefined":      ": main jump nowhere",
+		"duplicate":      ": main : main",
+		"bad register":   ": main sprite v0 x 1",
+		"byte range":     ": main v0 := 256",
+		"unclosed loop":  ": main loop",
+		"unclosed if":    ": main if v0 == 1 begin",
+		"stray else":     ": main else",
+		"unsupported":    ": main :calc x { 1 + 2 }",
+		"unknown op":     ": main v0 %= v1",
+		"missing then":   ": main if v0 == 1 clear",
+		"unexpected end": ": main v0 :=",
+	}
+
+	for name, src := range tests {
+		if _, err := Assemble([]byte(src)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	_, err := Assemble([]byte(": main\nclear\nv0 := 256"))
+	if e, ok := err.(*Error); !ok || e.Line != 3 {
+		t.Fatalf("expected an error on line 3, got %v", err)
+	}
+}
+
+func TestAssembleRun(t *testing.T) {
+	// Count to 10 in V0, then draw a dot at (V0, V0).
+	p, err := Assemble([]byte(`
+		: main
+			loop
+				v0 += 1
+				while v0 != 10
+			again
+			i := dot
+			sprite v0 v0 1
+		: halt
+			jump halt
+		: dot 0x80
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := chip8.New()
+	if err = vm.Load(bytes.NewReader(p.ROM)); err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 100; n++ {
+		if err = vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !vm.Display().Pixel(10, 10) {
+		t.Fatal("expected a dot at (10, 10)")
+	}
+
+	if addr, ok := p.Symbols.Addr("halt"); !ok || vm.PC() != addr {
+		t.Fatalf("expected to halt at 0x%03X, got 0x%03X", addr, vm.PC())
+	}
+	if !strings.Contains(p.Symbols.Describe(vm.PC()), "halt") {
+		t.Fatalf("expected the PC to be described as halt, got %s", p.Symbols.Describe(vm.PC()))
+	}
+}
@@ -0,0 +1,120 @@
+package remote
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// metrics counts what the server's VMs have done, for /metrics. They count
+// across ROM loads, as Prometheus counters must only go up.
+type metrics struct {
+	mu sync.Mutex
+
+	// Instructions executed, by the top nibble of their opcode.
+	instrs [16]uint64
+
+	// Frames stepped, those in which the display changed, and the changes
+	// overwritten by another in the same frame, never seen by clients.
+	frames, draws, dropped uint64
+
+	// Error responses, by status code.
+	errors map[int]uint64
+}
+
+// instruction counts an instruction, as an instruction hook.
+func (m *metrics) instruction(pc, opc uint16) {
+	m.mu.Lock()
+	m.instrs[opc>>12]++
+	m.mu.Unlock()
+}
+
+// frame counts a frame, and a draw if drawn is set, changes being the number
+// of times the display changed during it.
+func (m *metrics) frame(drawn bool, changes uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.frames++
+	if drawn {
+		m.draws++
+	}
+	if changes > 1 {
+		m.dropped += changes - 1
+	}
+}
+
+// error counts an error response with the status code.
+func (m *metrics) error(code int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.errors == nil {
+		m.errors = make(map[int]uint64)
+	}
+	m.errors[code]++
+}
+
+// write writes the metrics in the Prometheus text format, along with
+// whether the VM has halted.
+func (m *metrics) write(w io.Writer, halted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var cycles uint64
+	for _, n := range m.instrs {
+		cycles += n
+	}
+
+	header := func(name, typ, help string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	header("chip8_cycles_total", "counter", "Instructions executed.")
+	fmt.Fprintf(w, "chip8_cycles_total %d\n", cycles)
+
+	header("chip8_instructions_total", "counter", "Instructions executed, by opcode group.")
+	for op, n := range m.instrs {
+		fmt.Fprintf(w, "chip8_instructions_total{group=\"%XNNN\"} %d\n", op, n)
+	}
+
+	header("chip8_frames_total", "counter", "Frames stepped.")
+	fmt.Fprintf(w, "chip8_frames_total %d\n", m.frames)
+
+	header("chip8_draws_total", "counter", "Frames in which the display changed.")
+	fmt.Fprintf(w, "chip8_draws_total %d\n", m.draws)
+
+	header("chip8_draws_dropped_total", "counter", "Display changes overwritten by another in the same frame, never shown.")
+	fmt.Fprintf(w, "chip8_draws_dropped_total %d\n", m.dropped)
+
+	header("chip8_errors_total", "counter", "Error responses, by status code.")
+	codes := make([]int, 0, len(m.errors))
+	for c := range m.errors {
+		codes = append(codes, c)
+	}
+	sort.Ints(codes)
+	for _, c := range codes {
+		fmt.Fprintf(w, "chip8_errors_total{code=\"%d\"} %d\n", c, m.errors[c])
+	}
+
+	header("chip8_halted", "gauge", "Whether the program has halted.")
+	h := 0
+	if halted {
+		h = 1
+	}
+	fmt.Fprintf(w, "chip8_halted %d\n", h)
+}
+
+// writeMetrics writes the metrics.
+func (s *Server) writeMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	s.metrics.write(w, s.vm.Halted())
+}
+
+// error replies with the error message and status code, counting it.
+func (s *Server) error(w http.ResponseWriter, msg string, code int) {
+	s.metrics.error(code)
+	http.Error(w, msg, code)
+}
@@ -0,0 +1,231 @@
+// Package remote serves an HTTP API for driving a headless VM, so tools in any
+// language can load ROMs, hold keys, step frames and read back the display and
+// state.
+//
+// The endpoints are:
+//
+//	POST /rom?variant=auto  Load the ROM in the request body.
+//	PUT  /keys              Hold the keys in the JSON array of 16 booleans.
+//	POST /step?frames=1     Step the given number of frames, at most 3600.
+//	GET  /frame             The display as a PNG.
+//	GET  /state             The state of the VM as JSON, see VM.SaveState.
+//	PUT  /state             Restore the JSON state in the request body.
+//	GET  /metrics           Counters in the Prometheus text format.
+//
+// ROMs are at most 16MB, the memory of MegaChip, the largest of any variant.
+// Servers running untrusted ROMs should bound how much each request runs
+// with SetLimits.
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/romfmt"
+)
+
+const (
+	// maxROM is the largest ROM loaded, in bytes: the 16MB MegaChip
+	// addresses, the most memory of any variant.
+	maxROM = 1 << 24
+
+	// maxFrames is the most frames a step may ask for, a minute.
+	maxFrames = 60 * chip8.FrameRate
+)
+
+// Server is an http.Handler driving a single VM. Requests are handled one at
+// a time.
+type Server struct {
+	mu      sync.Mutex
+	vm      *chip8.VM
+	mux     *http.ServeMux
+	metrics metrics
+
+	// The limits set on each VM, see SetLimits.
+	limits chip8.Limits
+}
+
+// NewServer returns a server with a VM that has no ROM loaded.
+func NewServer() *Server {
+	s := &Server{
+		mux: http.NewServeMux(),
+	}
+	s.setVM(chip8.New())
+	s.mux.HandleFunc("/rom", s.method(http.MethodPost, s.loadROM))
+	s.mux.HandleFunc("/keys", s.method(http.MethodPut, s.setKeys))
+	s.mux.HandleFunc("/step", s.method(http.MethodPost, s.step))
+	s.mux.HandleFunc("/frame", s.method(http.MethodGet, s.frame))
+	s.mux.HandleFunc("/state", s.state)
+	s.mux.HandleFunc("/metrics", s.method(http.MethodGet, s.writeMetrics))
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// SetLimits sets the limits on running the VM, and those loaded from now on.
+// Steps over the limits fail.
+func (s *Server) SetLimits(l chip8.Limits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limits = l
+	s.vm.SetLimits(l)
+}
+
+// setVM replaces the VM, counting its instructions.
+func (s *Server) setVM(vm *chip8.VM) {
+	vm.OnInstruction(s.metrics.instruction)
+	vm.SetLimits(s.limits)
+	s.vm = vm
+}
+
+// method returns a handler calling h for requests using method, with the VM
+// locked.
+func (s *Server) method(method string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method {
+			w.Header().Set("Allow", method)
+			s.error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		h(w, r)
+	}
+}
+
+// loadROM replaces the VM with a new one running the ROM in the request body.
+// The variant is detected from the ROM unless given.
+func (s *Server) loadROM(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxROM))
+	if err != nil {
+		s.error(w, fmt.Sprintf("read ROM: %s", err), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if data, _, err = romfmt.Decode("ROM", data); err != nil {
+		s.error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vr, _ := chip8.Detect(data)
+	if name := r.URL.Query().Get("variant"); name != "" && name != "auto" {
+		if vr, err = chip8.ParseVariant(name); err != nil {
+			s.error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	vm := chip8.NewVariant(vr)
+	if err = vm.Load(bytes.NewReader(data)); err != nil {
+		s.error(w, fmt.Sprintf("load ROM: %s", err), http.StatusBadRequest)
+		return
+	}
+	s.setVM(vm)
+
+	writeJSON(w, map[string]string{"variant": vm.Variant().String()})
+}
+
+// setKeys holds the keys in the request body.
+func (s *Server) setKeys(w http.ResponseWriter, r *http.Request) {
+	var keys [16]bool
+	if err := json.NewDecoder(r.Body).Decode(&keys); err != nil {
+		s.error(w, fmt.Sprintf("decode keys: %s", err), http.StatusBadRequest)
+		return
+	}
+	s.vm.SetKeys(keys)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// step steps the VM the number of frames given, 1 by default.
+func (s *Server) step(w http.ResponseWriter, r *http.Request) {
+	frames := 1
+	if f := r.URL.Query().Get("frames"); f != "" {
+		var err error
+		if frames, err = strconv.Atoi(f); err != nil || frames < 0 || frames > maxFrames {
+			s.error(w, fmt.Sprintf("invalid frames %q, expected 0 to %d", f, maxFrames), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for f := 0; f < frames; f++ {
+		draws := s.vm.Draws()
+		if err := s.vm.StepFrame(); err != nil {
+			s.error(w, fmt.Sprintf("frame %d: %s", f, err), http.StatusUnprocessableEntity)
+			return
+		}
+
+		disp := s.vm.Display()
+		s.metrics.frame(disp.Dirty(), s.vm.Draws()-draws)
+		disp.MarkClean()
+	}
+
+	writeJSON(w, map[string]uint64{"cycles": s.vm.Cycles()})
+}
+
+// frame writes the display as a PNG.
+func (s *Server) frame(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, s.vm.Image()); err != nil {
+		s.error(w, fmt.Sprintf("encode frame: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(buf.Bytes())
+}
+
+// state writes the state of the VM as JSON, or restores it from the request
+// body.
+func (s *Server) state(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.method(http.MethodGet, s.saveState)(w, r)
+	case http.MethodPut:
+		s.method(http.MethodPut, s.loadState)(w, r)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		s.error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// saveState writes the state of the VM as JSON.
+func (s *Server) saveState(w http.ResponseWriter, r *http.Request) {
+	var buf bytes.Buffer
+	if err := s.vm.SaveState(&buf); err != nil {
+		s.error(w, fmt.Sprintf("save state: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf.Bytes())
+}
+
+// loadState restores the state of the VM from the request body.
+func (s *Server) loadState(w http.ResponseWriter, r *http.Request) {
+	if err := s.vm.LoadState(r.Body); err != nil {
+		s.error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeJSON writes v to w as JSON.
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,150 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func do(t *testing.T, s *Server, method, target string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, target, bytes.NewReader(body)))
+
+	return w
+}
+
+func TestServer(t *testing.T) {
+	// Draw a pixel at (0, 0) once key 5 is pressed.
+	rom := []byte{
+		0xA2, 0x08, // I = sprite.
+		0xF0, 0x0A, // Wait for a key.
+		0xD1, 0x11, // Draw at (0, 0).
+		0x12, 0x06, // Halt.
+		0x80, // Sprite.
+	}
+
+	s := NewServer()
+	if w := do(t, s, http.MethodPost, "/rom", rom); w.Code != http.StatusOK {
+		t.Fatalf("load ROM: %d %s", w.Code, w.Body)
+	}
+
+	keys, _ := json.Marshal([16]bool{5: true})
+	if w := do(t, s, http.MethodPut, "/keys", keys); w.Code != http.StatusNoContent {
+		t.Fatalf("set keys: %d %s", w.Code, w.Body)
+	}
+	if w := do(t, s, http.MethodPost, "/step?frames=2", nil); w.Code != http.StatusOK {
+		t.Fatalf("step: %d %s", w.Code, w.Body)
+	}
+
+	w := do(t, s, http.MethodGet, "/frame", nil)
+	img, err := png.Decode(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := img.At(0, 0).RGBA(); r == 0 {
+		t.Fatal("expected pixel (0, 0) to be lit")
+	}
+
+	saved := do(t, s, http.MethodGet, "/state", nil).Body.Bytes()
+
+	var st struct{ PC uint16 }
+	if err = json.Unmarshal(saved, &st); err != nil {
+		t.Fatal(err)
+	}
+	if st.PC != 0x206 {
+		t.Fatalf("expected PC 0x206, got 0x%03X", st.PC)
+	}
+
+	if w := do(t, s, http.MethodPost, "/rom", rom); w.Code != http.StatusOK {
+		t.Fatalf("reload ROM: %d %s", w.Code, w.Body)
+	}
+	if w := do(t, s, http.MethodPut, "/state", saved); w.Code != http.StatusNoContent {
+		t.Fatalf("load state: %d %s", w.Code, w.Body)
+	}
+	if pc := s.vm.PC(); pc != 0x206 {
+		t.Fatalf("expected the state to restore PC 0x206, got 0x%03X", pc)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	s := NewServer()
+
+	tests := []struct {
+		method, target, body string
+		code                 int
+	}{
+		{http.MethodGet, "/rom", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/rom?variant=nope", "\x00\xE0", http.StatusBadRequest},
+		{http.MethodPut, "/keys", "nope", http.StatusBadRequest},
+		{http.MethodPost, "/step?frames=-1", "", http.StatusBadRequest},
+		{http.MethodPost, "/step?frames=2000000000", "", http.StatusBadRequest},
+		{http.MethodPost, "/rom", string(make([]byte, maxROM+1)), http.StatusRequestEntityTooLarge},
+		{http.MethodPut, "/state", "{}", http.StatusBadRequest},
+		{http.MethodPost, "/state", "", http.StatusMethodNotAllowed},
+	}
+	for _, tc := range tests {
+		w := do(t, s, tc.method, tc.target, []byte(tc.body))
+		if w.Code != tc.code {
+			t.Errorf("%s %s %q: expected %d, got %d %s", tc.method, tc.target, tc.body, tc.code, w.Code, strings.TrimSpace(w.Body.String()))
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := NewServer()
+	s.SetLimits(chip8.Limits{MaxCycles: 100})
+
+	// A ROM looping forever.
+	if w := do(t, s, http.MethodPost, "/rom", []byte{0x12, 0x00}); w.Code != http.StatusOK {
+		t.Fatalf("load ROM: %d %s", w.Code, w.Body)
+	}
+	w := do(t, s, http.MethodPost, "/step?frames=1000", nil)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "cycle limit") {
+		t.Fatalf("expected the cycle limit to stop the step, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	// Draw a pixel and erase it in the same frame, then halt.
+	rom := []byte{
+		0xA2, 0x08, // I = sprite.
+		0xD0, 0x01, // Draw at (0, 0).
+		0xD0, 0x01, // Erase it.
+		0x12, 0x06, // Halt.
+		0x80, // Sprite.
+	}
+
+	s := NewServer()
+	do(t, s, http.MethodPost, "/rom", rom)
+	do(t, s, http.MethodPost, "/step?frames=2", nil)
+	do(t, s, http.MethodPost, "/step?frames=x", nil)
+
+	w := do(t, s, http.MethodGet, "/metrics", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("metrics: %d %s", w.Code, w.Body)
+	}
+
+	for _, want := range []string{
+		"chip8_cycles_total 10\n",
+		`chip8_instructions_total{group="ANNN"} 1` + "\n",
+		`chip8_instructions_total{group="DNNN"} 2` + "\n",
+		`chip8_instructions_total{group="1NNN"} 7` + "\n",
+		"chip8_frames_total 2\n",
+		"chip8_draws_total 1\n",
+		"chip8_draws_dropped_total 1\n",
+		`chip8_errors_total{code="400"} 1` + "\n",
+		"chip8_halted 1\n",
+	} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, w.Body)
+		}
+	}
+}
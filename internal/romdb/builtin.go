@@ -0,0 +1,6 @@
+package romdb
+
+// builtin is the index built in. It only lists ROMs whose hashes have been
+// checked against a known good dump, so a ROM is never given the wrong
+// title or quirks; others belong in the user's index.
+var builtin = Index{}
@@ -0,0 +1,58 @@
+package romdb
+
+import "fmt"
+
+// Paddle is an input profile for paddle games, such as PONG, moving the
+// paddle toward the mouse by holding the keys that move it up and down:
+//
+//	"paddle": {"up": 1, "down": 4, "register": 1, "height": 6, "deadZone": 2}
+type Paddle struct {
+	// Up and Down are the keys moving the paddle.
+	Up   byte `json:"up"`
+	Down byte `json:"down"`
+
+	// Register, if set, is the V register the game keeps the paddle's top
+	// in, in display pixels, and Height the paddle's height, so the paddle
+	// is moved until its middle is level with the mouse. Without it the
+	// mouse works like a joystick, holding Up above the middle of the
+	// display and Down below.
+	Register *byte `json:"register,omitempty"`
+	Height   int   `json:"height,omitempty"`
+
+	// DeadZone is how far, in display pixels, the mouse may be from the
+	// paddle's middle without a key being held, so it doesn't jitter
+	// around the mouse.
+	DeadZone float64 `json:"deadZone,omitempty"`
+}
+
+// Key returns the key to hold with the mouse at y and the paddle's middle at
+// at, both in display pixels from the top, and false for none.
+func (p Paddle) Key(y, at float64) (byte, bool) {
+	switch d := y - at; {
+	case d < -p.DeadZone:
+		return p.Up, true
+	case d > p.DeadZone:
+		return p.Down, true
+	}
+	return 0, false
+}
+
+// Middle returns the paddle's middle, in display pixels from the top, with
+// the game's registers read by v, on a display h pixels tall.
+func (p Paddle) Middle(v func(x byte) byte, h int) float64 {
+	if p.Register == nil {
+		return float64(h) / 2
+	}
+	return float64(v(*p.Register)) + float64(p.Height)/2
+}
+
+// validate returns an error if the keys or register don't exist.
+func (p Paddle) validate() error {
+	if p.Up > 0xF || p.Down > 0xF {
+		return fmt.Errorf("paddle keys must be 0 to 15")
+	}
+	if p.Register != nil && *p.Register > 0xF {
+		return fmt.Errorf("paddle register must be 0 to 15")
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package romdb identifies ROMs by their hashes, giving them a title and the
+// variant and quirks they need. Entries come from an index built in and from
+// one the user keeps, roms.json in the chip8 config directory, keyed by the
+// SHA1 of the ROM or, for archives only listing those, its CRC32:
+//
+//	{
+//		"0123456789abcdef0123456789abcdef01234567": {
+//			"title": "Pong",
+//			"author": "Paul Vervalin",
+//			"year": 1990,
+//			"variant": "chip8",
+//			"quirks": {"shift": true, "loadStore": true}
+//		},
+//		"89abcdef": {"title": "Tetris"}
+//	}
+//
+// The variant and quirks are optional, left out the variant is detected from
+// the ROM and the quirks are the variant's.
+//
+// Metadata packs, shared collections of entries, are index files of the same
+// format kept in the packs directory beside roms.json. Their entries usually
+// add a description and cover art for the ROM browser:
+//
+//	{
+//		"0123456789abcdef0123456789abcdef01234567": {
+//			"title": "Pong",
+//			"description": "The classic, for two players.",
+//			"cover": "covers/pong.png"
+//		}
+//	}
+package romdb
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Entry describes a known ROM.
+type Entry struct {
+	Title  string `json:"title"`
+	Author string `json:"author,omitempty"`
+	Year   int    `json:"year,omitempty"`
+
+	// Variant is the variant the ROM was written for, empty if detecting it
+	// works.
+	Variant string `json:"variant,omitempty"`
+
+	// Quirks replace those of the variant, if set.
+	Quirks *chip8.Quirks `json:"quirks,omitempty"`
+
+	// Description is shown in the ROM browser.
+	Description string `json:"description,omitempty"`
+
+	// Cover is the path of a PNG image of the ROM shown in the ROM browser
+	// in place of its thumbnail. Relative paths are from the directory of
+	// the index file, and made absolute when it's loaded.
+	Cover string `json:"cover,omitempty"`
+
+	// Paddle, if set, moves the paddle of a paddle game with the mouse.
+	Paddle *Paddle `json:"paddle,omitempty"`
+}
+
+// String returns the title of the ROM, with its author and year if known.
+func (e Entry) String() string {
+	var by []string
+	if e.Author != "" {
+		by = append(by, e.Author)
+	}
+	if e.Year != 0 {
+		by = append(by, fmt.Sprint(e.Year))
+	}
+	if len(by) == 0 {
+		return e.Title
+	}
+	return fmt.Sprintf("%s (%s)", e.Title, strings.Join(by, ", "))
+}
+
+// ID identifies a ROM by its hashes.
+type ID struct {
+	// SHA1 is the hex encoded SHA1 hash of the ROM.
+	SHA1 string
+
+	// CRC32 is the IEEE CRC32 of the ROM.
+	CRC32 uint32
+}
+
+// Identify returns the ID of rom.
+func Identify(rom []byte) ID {
+	sum := sha1.Sum(rom)
+	return ID{SHA1: hex.EncodeToString(sum[:]), CRC32: crc32.ChecksumIEEE(rom)}
+}
+
+// Index maps the hashes of ROMs, as lower case hex, to their entries.
+type Index map[string]Entry
+
+// Builtin returns a copy of the index built in.
+func Builtin() Index {
+	ix := make(Index, len(builtin))
+	for k, e := range builtin {
+		ix[k] = e
+	}
+	return ix
+}
+
+// Lookup returns the entry of the ROM with the given ID, matching its SHA1
+// before its CRC32.
+func (ix Index) Lookup(id ID) (Entry, bool) {
+	if e, ok := ix[id.SHA1]; ok {
+		return e, true
+	}
+	e, ok := ix[fmt.Sprintf("%08x", id.CRC32)]
+	return e, ok
+}
+
+// Add adds the entries of other to the index, replacing any for the same
+// hashes.
+func (ix Index) Add(other Index) {
+	for k, e := range other {
+		ix[k] = e
+	}
+}
+
+// Path returns the path of the user's index, in their config directory.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "roms.json"), nil
+}
+
+// PacksDir returns the directory of the user's metadata packs, in their
+// config directory.
+func PacksDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "packs"), nil
+}
+
+// LoadPacks reads the metadata packs in dir, every .json file, in the order
+// of their names, the entries of later packs replacing those of earlier ones.
+// It returns nil and no error if there's no dir.
+func LoadPacks(dir string) (Index, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var ix Index
+	for _, p := range paths {
+		pack, err := Load(p)
+		if err != nil {
+			return nil, err
+		}
+		if ix == nil {
+			ix = Index{}
+		}
+		ix.Add(pack)
+	}
+	return ix, nil
+}
+
+// Load reads the index at path. It returns nil and no error if there isn't
+// one.
+func Load(path string) (Index, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var raw Index
+	if err = json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+
+	ix := make(Index, len(raw))
+	for k, e := range raw {
+		k = strings.ToLower(k)
+		if _, err := hex.DecodeString(k); err != nil || (len(k) != 40 && len(k) != 8) {
+			return nil, fmt.Errorf("%s: %q is neither a SHA1 nor a CRC32", path, k)
+		}
+		if e.Title == "" {
+			return nil, fmt.Errorf("%s: %s has no title", path, k)
+		}
+		if e.Variant != "" {
+			if _, err := chip8.ParseVariant(e.Variant); err != nil {
+				return nil, fmt.Errorf("%s: %s: %s", path, k, err)
+			}
+		}
+		if e.Paddle != nil {
+			if err := e.Paddle.validate(); err != nil {
+				return nil, fmt.Errorf("%s: %s: %s", path, k, err)
+			}
+		}
+		if e.Cover != "" && !filepath.IsAbs(e.Cover) {
+			e.Cover = filepath.Join(filepath.Dir(path), e.Cover)
+		}
+		ix[k] = e
+	}
+	return ix, nil
+}
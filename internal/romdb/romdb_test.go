@@ -0,0 +1,154 @@
+package romdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIdentify(t *testing.T) {
+	id := Identify([]byte("abc"))
+	if id.SHA1 != "a9993e364706816aba3e25717850c26c9cd0d89d" {
+		t.Fatalf("unexpected SHA1 %s", id.SHA1)
+	}
+	if id.CRC32 != 0x352441C2 {
+		t.Fatalf("unexpected CRC32 %08x", id.CRC32)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "romdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "roms.json")
+
+	if ix, err := Load(path); ix != nil || err != nil {
+		t.Fatalf("expected no index and no error for a missing file, got %v, %v", ix, err)
+	}
+
+	src := `{
+		"A9993E364706816ABA3E25717850C26C9CD0D89D": {"title": "ABC", "author": "Me", "year": 1990, "variant": "schip", "quirks": {"clip": true}},
+		"00000001": {"title": "One"}
+	}`
+	if err = ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ix, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, ok := ix.Lookup(Identify([]byte("abc")))
+	if !ok {
+		t.Fatal("expected the ROM to be found by its SHA1")
+	}
+	if e.String() != "ABC (Me, 1990)" || e.Variant != "schip" || e.Quirks == nil || !e.Quirks.Clip {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+
+	if e, ok = ix.Lookup(ID{CRC32: 1}); !ok || e.String() != "One" {
+		t.Fatalf("expected the ROM to be found by its CRC32, got %+v", e)
+	}
+	if _, ok = ix.Lookup(ID{SHA1: "nope", CRC32: 2}); ok {
+		t.Fatal("expected an unknown ROM not to be found")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "romdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "roms.json")
+
+	tests := map[string]string{
+		"hash":    `{"xyz": {"title": "A"}}`,
+		"title":   `{"00000001": {}}`,
+		"variant": `{"00000001": {"title": "A", "variant": "nope"}}`,
+		"json":    `{`,
+		"paddle":  `{"00000001": {"title": "A", "paddle": {"up": 16}}}`,
+	}
+	for name, src := range tests {
+		if err = ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Load(path); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoadPacks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "romdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if ix, err := LoadPacks(filepath.Join(dir, "none")); ix != nil || err != nil {
+		t.Fatalf("expected no index and no error for a missing directory, got %v, %v", ix, err)
+	}
+
+	packs := map[string]string{
+		"a.json": `{"00000001": {"title": "One", "cover": "covers/one.png"}, "00000002": {"title": "Two"}}`,
+		"b.json": `{"00000002": {"title": "Two", "description": "The second."}}`,
+		"c.txt":  `{`,
+	}
+	for name, src := range packs {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix, err := LoadPacks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := ix.Lookup(ID{CRC32: 1}); e.Cover != filepath.Join(dir, "covers", "one.png") {
+		t.Fatalf("expected the cover to be relative to the pack, got %q", e.Cover)
+	}
+	if e, _ := ix.Lookup(ID{CRC32: 2}); e.Description != "The second." {
+		t.Fatalf("expected the later pack's entry, got %+v", e)
+	}
+}
+
+func TestPaddle(t *testing.T) {
+	reg := byte(1)
+	p := Paddle{Up: 1, Down: 4, Register: &reg, Height: 6, DeadZone: 2}
+
+	v := func(x byte) byte {
+		if x != reg {
+			t.Fatalf("expected V%X to be read, got V%X", reg, x)
+		}
+		return 10
+	}
+	at := p.Middle(v, 32)
+	if at != 13 {
+		t.Fatalf("expected the paddle's middle at 13, got %v", at)
+	}
+
+	tests := []struct {
+		y    float64
+		key  byte
+		hold bool
+	}{
+		{y: 5, key: 1, hold: true},
+		{y: 11, hold: false},
+		{y: 15, hold: false},
+		{y: 20, key: 4, hold: true},
+	}
+	for _, tc := range tests {
+		if key, hold := p.Key(tc.y, at); key != tc.key || hold != tc.hold {
+			t.Fatalf("mouse at %v: expected %X %t, got %X %t", tc.y, tc.key, tc.hold, key, hold)
+		}
+	}
+
+	// Without a register the paddle is taken to be in the middle.
+	if at := (Paddle{}).Middle(v, 32); at != 16 {
+		t.Fatalf("expected the middle of the display, got %v", at)
+	}
+}
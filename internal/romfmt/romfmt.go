@@ -0,0 +1,307 @@
+// Package romfmt decodes the formats ROMs are shared in other than raw
+// binaries: Intel HEX, as written by EPROM programmers and some assemblers,
+// and base64, as pasted into forums and archives with a .b64 extension, such
+// as game.ch8.b64.
+package romfmt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Format is a format ROMs are stored in.
+type Format int
+
+const (
+	// Raw is a plain binary, the ROM as it's loaded into memory.
+	Raw Format = iota
+
+	// IntelHex is Intel HEX text.
+	IntelHex
+
+	// Base64 is base64 encoded text.
+	Base64
+)
+
+// formatNames are the names of the formats, indexed by format.
+var formatNames = []string{"raw", "Intel HEX", "base64"}
+
+func (f Format) String() string {
+	return formatNames[f]
+}
+
+// Exts are the file extensions of the formats other than raw binaries.
+var Exts = []string{".hex", ".ihex", ".b64"}
+
+// maxSize is the largest ROM decoded, enough to fill the 16MB MegaChip8 can
+// address, so a stray address in a HEX file can't allocate gigabytes.
+const maxSize = 1 << 24
+
+// programAddr is where ROMs are loaded, see chip8.ProgramAddr.
+const programAddr = 0x200
+
+// Decode returns the ROM in data, read from a file called name, and the
+// format it was in. Files with the .hex or .ihex extension, or that read as
+// Intel HEX, are decoded as Intel HEX, and files with the .b64 extension as
+// base64. Anything else is a raw binary, returned as is.
+func Decode(name string, data []byte) ([]byte, Format, error) {
+	return DecodeMax(name, data, maxSize)
+}
+
+// DecodeMax is Decode for ROMs run in at most max bytes of memory. Intel HEX
+// with data beyond that is refused before the ROM is laid out, so the memory
+// decoding takes is bounded by max too.
+func DecodeMax(name string, data []byte, max int) ([]byte, Format, error) {
+	rom, f, err := decode(name, data, max)
+	if err == nil && len(rom) > max {
+		err = fmt.Errorf("%s is %d bytes, more than the %d allowed", name, len(rom), max)
+	}
+	return rom, f, err
+}
+
+// decode decodes data as in Decode, refusing Intel HEX with data beyond max.
+func decode(name string, data []byte, max int) ([]byte, Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".hex", ".ihex":
+		rom, err := decodeHex(data, max)
+		if err != nil {
+			return nil, IntelHex, fmt.Errorf("%s is not valid Intel HEX: %s", name, err)
+		}
+		return rom, IntelHex, nil
+	case ".b64":
+		rom, err := decodeBase64(data)
+		if err != nil {
+			return nil, Base64, fmt.Errorf("%s is not valid base64: %s", name, err)
+		}
+		return rom, Base64, nil
+	}
+
+	if isHex(data) {
+		rom, err := decodeHex(data, max)
+		if err != nil {
+			return nil, IntelHex, fmt.Errorf("%s looks like Intel HEX but isn't valid: %s", name, err)
+		}
+		return rom, IntelHex, nil
+	}
+	return data, Raw, nil
+}
+
+// isHex returns true if every line of data is an Intel HEX record: a colon
+// followed by hex digits. No raw ROM is likely to be.
+func isHex(data []byte) bool {
+	n := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != ':' || len(line) < 11 {
+			return false
+		}
+		for _, c := range line[1:] {
+			if !strings.ContainsRune("0123456789abcdefABCDEF", rune(c)) {
+				return false
+			}
+		}
+		n++
+	}
+	return n > 0
+}
+
+// Intel HEX record types.
+const (
+	recData = iota
+	recEOF
+	recSegment
+	recStartSegment
+	recLinear
+	recStartLinear
+)
+
+// decodeHex decodes Intel HEX with no data beyond max. Data at or above
+// programAddr is taken to be at its address in memory, otherwise addresses are
+// offsets into the ROM. Gaps are filled with zeros.
+func decodeHex(data []byte, max int) ([]byte, error) {
+	var (
+		chunks  []chunk
+		base    int
+		lo, end = -1, 0
+	)
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != ':' {
+			return nil, fmt.Errorf("line %d doesn't start with ':'", i+1)
+		}
+
+		rec := make([]byte, hex.DecodedLen(len(line)-1))
+		if _, err := hex.Decode(rec, line[1:]); err != nil {
+			return nil, fmt.Errorf("line %d: %s", i+1, err)
+		}
+		if len(rec) < 5 {
+			return nil, fmt.Errorf("line %d is too short for a record", i+1)
+		}
+		if len(rec) != int(rec[0])+5 {
+			return nil, fmt.Errorf("line %d has %d bytes, expected %d for %d bytes of data", i+1, len(rec), int(rec[0])+5, rec[0])
+		}
+
+		var sum byte
+		for _, b := range rec {
+			sum += b
+		}
+		if sum != 0 {
+			return nil, fmt.Errorf("line %d has the checksum 0x%02X, expected 0x%02X", i+1, rec[len(rec)-1], rec[len(rec)-1]-sum)
+		}
+
+		addr, payload := int(rec[1])<<8|int(rec[2]), rec[4:len(rec)-1]
+		switch rec[3] {
+		case recData:
+			a := base + addr
+			if a+len(payload) > max {
+				return nil, fmt.Errorf("line %d has data at 0x%X, beyond the 0x%X bytes a ROM can fill", i+1, a, max)
+			}
+			chunks = append(chunks, chunk{a, payload})
+			if lo < 0 || a < lo {
+				lo = a
+			}
+			if a+len(payload) > end {
+				end = a + len(payload)
+			}
+		case recEOF:
+			return layout(chunks, lo, end), nil
+		case recSegment, recLinear:
+			if len(payload) != 2 {
+				return nil, fmt.Errorf("line %d has a %d byte address, expected 2", i+1, len(payload))
+			}
+			base = int(payload[0])<<8 | int(payload[1])
+			if rec[3] == recSegment {
+				base <<= 4
+			} else {
+				base <<= 16
+			}
+		case recStartSegment, recStartLinear:
+			// Start addresses are for x86 CPUs, not the VM.
+		default:
+			return nil, fmt.Errorf("line %d has the unknown record type %02X", i+1, rec[3])
+		}
+	}
+	return nil, fmt.Errorf("no end of file record")
+}
+
+// chunk is the data of an Intel HEX record and its address.
+type chunk struct {
+	addr int
+	b    []byte
+}
+
+// layout lays out the chunks of data, the lowest at lo and ending at end, as a
+// ROM.
+func layout(chunks []chunk, lo, end int) []byte {
+	if lo < 0 {
+		return nil
+	}
+
+	offset := 0
+	if lo >= programAddr {
+		offset = programAddr
+	}
+	rom := make([]byte, end-offset)
+	for _, c := range chunks {
+		copy(rom[c.addr-offset:], c.b)
+	}
+	return rom
+}
+
+// decodeBase64 decodes base64, ignoring line breaks and other whitespace.
+// Padding is optional.
+func decodeBase64(data []byte) ([]byte, error) {
+	s := strings.Join(strings.Fields(string(data)), "")
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// Encode returns rom in the format for a file called name: Intel HEX for the
+// .hex and .ihex extensions, base64 for .b64 and the ROM as is otherwise.
+func Encode(name string, rom []byte) []byte {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".hex", ".ihex":
+		return encodeHex(rom)
+	case ".b64":
+		s := base64.StdEncoding.EncodeToString(rom)
+		var b bytes.Buffer
+		for len(s) > 76 {
+			b.WriteString(s[:76] + "\n")
+			s = s[76:]
+		}
+		b.WriteString(s + "\n")
+		return b.Bytes()
+	}
+	return rom
+}
+
+// encodeHex encodes rom as Intel HEX, 16 bytes to a record, at its address in
+// memory.
+func encodeHex(rom []byte) []byte {
+	var b bytes.Buffer
+	record := func(typ byte, addr int, data []byte) {
+		rec := append([]byte{byte(len(data)), byte(addr >> 8), byte(addr), typ}, data...)
+		var sum byte
+		for _, c := range rec {
+			sum += c
+		}
+		rec = append(rec, -sum)
+		fmt.Fprintf(&b, ":%X\n", rec)
+	}
+
+	upper := 0
+	for i := 0; i < len(rom); i += 16 {
+		addr := programAddr + i
+		if addr>>16 != upper {
+			upper = addr >> 16
+			record(recLinear, 0, []byte{byte(upper >> 8), byte(upper)})
+		}
+		end := i + 16
+		if end > len(rom) {
+			end = len(rom)
+		}
+		record(recData, addr&0xFFFF, rom[i:end])
+	}
+	record(recEOF, 0, nil)
+	return b.Bytes()
+}
+
+// Padding returns the number of zeros rom ends with.
+func Padding(rom []byte) int {
+	n := 0
+	for n < len(rom) && rom[len(rom)-1-n] == 0 {
+		n++
+	}
+	return n
+}
+
+// Trim returns rom without the zeros it ends with.
+func Trim(rom []byte) []byte {
+	return rom[:len(rom)-Padding(rom)]
+}
+
+// Pad returns rom padded with zeros to at least size bytes, and then up to a
+// multiple of align bytes if align is above zero.
+func Pad(rom []byte, size, align int) []byte {
+	n := len(rom)
+	if n < size {
+		n = size
+	}
+	if align > 0 && n%align != 0 {
+		n += align - n%align
+	}
+
+	padded := make([]byte, n)
+	copy(padded, rom)
+	return padded
+}
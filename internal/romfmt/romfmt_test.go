@@ -0,0 +1,151 @@
+package romfmt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDecodeHex(t *testing.T) {
+	// Two records at 0x200 and 0x204 with a gap between them.
+	src := ":0202000000E01C\n:02020400120ADC\n:00000001FF\n"
+
+	for _, name := range []string{"game.hex", "game.ch8"} {
+		rom, f, err := Decode(name, []byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f != IntelHex {
+			t.Fatalf("%s: expected Intel HEX, got %s", name, f)
+		}
+		exp := []byte{0x00, 0xE0, 0x00, 0x00, 0x12, 0x0A}
+		if !bytes.Equal(rom, exp) {
+			t.Fatalf("%s: expected % X, got % X", name, exp, rom)
+		}
+	}
+}
+
+func TestDecodeHexOffsets(t *testing.T) {
+	// Addresses below 0x200 are offsets into the ROM, here after an
+	// extended linear address of 0.
+	rom, _, err := Decode("game.ihex", []byte(":020000040000FA\r\n:02000000A2203C\r\n:00000001FF\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []byte{0xA2, 0x20}; !bytes.Equal(rom, exp) {
+		t.Fatalf("expected % X, got % X", exp, rom)
+	}
+}
+
+func TestDecodeHexErrors(t *testing.T) {
+	tests := map[string]string{
+		"checksum": ":0202000000E01D\n:00000001FF\n",
+		"no EOF":   ":0202000000E01C\n",
+		"length":   ":0402000000E01C\n:00000001FF\n",
+		"not hex":  "PK\x03\x04",
+	}
+
+	for name, src := range tests {
+		_, _, err := Decode("game.hex", []byte(src))
+		if err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		if !strings.HasPrefix(err.Error(), "game.hex is not valid Intel HEX") {
+			t.Fatalf("%s: unexpected error %q", name, err)
+		}
+	}
+}
+
+func TestDecodeMax(t *testing.T) {
+	// Data at 0x1000, past the 4K of Chip8 memory, after an extended linear
+	// address of 0.
+	src := ":020000040000FA\n:02100000A2202C\n:00000001FF\n"
+	if _, _, err := DecodeMax("game.hex", []byte(src), 0x1000); err == nil || !strings.Contains(err.Error(), "beyond") {
+		t.Fatalf("expected data beyond 4K to fail, got %v", err)
+	}
+	if rom, _, err := DecodeMax("game.hex", []byte(src), 0x10000); err != nil || len(rom) != 0xE02 {
+		t.Fatalf("expected a ROM of 0xE02 bytes, got %d %v", len(rom), err)
+	}
+
+	if _, _, err := DecodeMax("game.ch8", make([]byte, 5), 4); err == nil {
+		t.Fatal("expected a raw ROM over the limit to fail")
+	}
+}
+
+func TestDecodeBase64(t *testing.T) {
+	rom, f, err := Decode("game.ch8.b64", []byte("AOAS\nAg==\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != Base64 {
+		t.Fatalf("expected base64, got %s", f)
+	}
+	if exp := []byte{0x00, 0xE0, 0x12, 0x02}; !bytes.Equal(rom, exp) {
+		t.Fatalf("expected % X, got % X", exp, rom)
+	}
+
+	if _, _, err = Decode("game.ch8.b64", []byte("AO!S")); err == nil {
+		t.Fatal("expected invalid base64 to fail")
+	}
+}
+
+func TestDecodeRaw(t *testing.T) {
+	// A raw ROM that happens to start with a colon, 3A42 skipping if VA is
+	// 0x42.
+	data := []byte{0x3A, 0x42, 0x12, 0x00}
+	rom, f, err := Decode("game.ch8", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != Raw || !bytes.Equal(rom, data) {
+		t.Fatalf("expected the raw ROM back, got %s % X", f, rom)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	rom := make([]byte, 40)
+	for i := range rom {
+		rom[i] = byte(i)
+	}
+
+	for _, name := range []string{"game.hex", "game.ch8.b64", "game.ch8"} {
+		data := Encode(name, rom)
+		got, _, err := Decode(name, data)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if !bytes.Equal(got, rom) {
+			t.Fatalf("%s: expected % X back, got % X", name, rom, got)
+		}
+	}
+
+	exp := ":10020000000102030405060708090A0B0C0D0E0F76\n"
+	if got := string(Encode("game.hex", rom)); !strings.HasPrefix(got, exp) {
+		t.Fatalf("expected the HEX to start %q, got %q", exp, got)
+	}
+}
+
+func TestTrimPad(t *testing.T) {
+	rom := []byte{0x12, 0x00, 0x00, 0x00}
+	if n := Padding(rom); n != 3 {
+		t.Fatalf("expected 3 bytes of padding, got %d", n)
+	}
+	if got := Trim(rom); !bytes.Equal(got, []byte{0x12}) {
+		t.Fatalf("expected 12, got % X", got)
+	}
+
+	tests := []struct {
+		size, align, exp int
+	}{
+		{0, 0, 4},
+		{6, 0, 6},
+		{0, 3, 6},
+		{5, 4, 8},
+		{2, 2, 4},
+	}
+	for _, tt := range tests {
+		if got := len(Pad(rom, tt.size, tt.align)); got != tt.exp {
+			t.Fatalf("Pad(%d, %d): expected %d bytes, got %d", tt.size, tt.align, tt.exp, got)
+		}
+	}
+}
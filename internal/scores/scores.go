@@ -0,0 +1,139 @@
+// Package scores keeps the high scores of a ROM in a sidecar file beside it.
+// The sidecar says where in memory the game keeps its score, which is read
+// when the game is closed and recorded if it's among the best:
+//
+//	{
+//		"score": {"addr": "0x3F0", "size": 3, "digits": true},
+//		"scores": [
+//			{"score": 120, "time": "2020-01-02T15:04:05Z"}
+//		]
+//	}
+//
+// The score is size bytes from addr, big-endian, or with digits set a decimal
+// digit per byte as FX33 stores them. Only the score location need be written
+// by hand, the scores are added by the emulator.
+package scores
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/verify"
+)
+
+// Ext is added to the path of a ROM to name its sidecar.
+const Ext = ".scores.json"
+
+// Max is the number of scores kept.
+const Max = 10
+
+// Sidecar is the score location and high scores of a ROM.
+type Sidecar struct {
+	Score  Location `json:"score"`
+	Scores []Entry  `json:"scores"`
+}
+
+// Location is where a game keeps its score in memory.
+type Location struct {
+	Addr verify.Number `json:"addr"`
+
+	// Size is the number of bytes, 1 by default.
+	Size int `json:"size"`
+
+	// Digits is set if each byte is a decimal digit.
+	Digits bool `json:"digits"`
+}
+
+// Entry is a high score.
+type Entry struct {
+	Score uint64    `json:"score"`
+	Time  time.Time `json:"time"`
+}
+
+// Path returns the path of the sidecar of the ROM at rom.
+func Path(rom string) string {
+	return rom + Ext
+}
+
+// Load reads the sidecar at path. It returns nil and no error if there isn't
+// one.
+func Load(path string) (*Sidecar, error) {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sidecar{}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if s.Score.Size < 0 || s.Score.Size > 8 {
+		return nil, fmt.Errorf("%s: score size must be 1 to 8 bytes", path)
+	}
+
+	return s, nil
+}
+
+// Save writes the sidecar to path.
+func (s *Sidecar) Save(path string) error {
+	b, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Read returns the score in the memory of vm.
+func (s *Sidecar) Read(vm *chip8.VM) uint64 {
+	size := s.Score.Size
+	if size == 0 {
+		size = 1
+	}
+
+	var score uint64
+	for i := 0; i < size; i++ {
+		b := uint64(vm.Peek(uint16(s.Score.Addr) + uint16(i)))
+		if s.Score.Digits {
+			score = score*10 + b%10
+		} else {
+			score = score<<8 | b
+		}
+	}
+	return score
+}
+
+// Record records score, made at t, if it's among the best. It returns true if
+// it was recorded.
+func (s *Sidecar) Record(score uint64, t time.Time) bool {
+	if score == 0 {
+		return false
+	}
+
+	s.Scores = append(s.Scores, Entry{Score: score, Time: t})
+	sort.SliceStable(s.Scores, func(i, j int) bool {
+		return s.Scores[i].Score > s.Scores[j].Score
+	})
+
+	if len(s.Scores) <= Max {
+		return true
+	}
+	recorded := s.Scores[Max].Time != t || s.Scores[Max].Score != score
+	s.Scores = s.Scores[:Max]
+	return recorded
+}
+
+// Best returns the best score, or false if none have been recorded.
+func (s *Sidecar) Best() (Entry, bool) {
+	if len(s.Scores) == 0 {
+		return Entry{}, false
+	}
+	return s.Scores[0], true
+}
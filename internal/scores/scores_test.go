@@ -0,0 +1,99 @@
+package scores
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestRead(t *testing.T) {
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader([]byte{0x01, 0x02, 0x03})); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		loc  Location
+		want uint64
+	}{
+		{Location{Addr: 0x200}, 1},
+		{Location{Addr: 0x201, Size: 2}, 0x0203},
+		{Location{Addr: 0x200, Size: 3, Digits: true}, 123},
+	}
+	for _, tc := range tests {
+		s := &Sidecar{Score: tc.loc}
+		if got := s.Read(vm); got != tc.want {
+			t.Errorf("%+v: expected %d, got %d", tc.loc, tc.want, got)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	s := &Sidecar{}
+	now := time.Now()
+
+	if s.Record(0, now) {
+		t.Fatal("expected a zero score not to be recorded")
+	}
+	for i := 1; i <= Max; i++ {
+		if !s.Record(uint64(i*10), now.Add(time.Duration(i))) {
+			t.Fatalf("expected score %d to be recorded", i*10)
+		}
+	}
+	if s.Record(5, now) {
+		t.Fatal("expected a score below the table not to be recorded")
+	}
+	if !s.Record(55, now) {
+		t.Fatal("expected a score within the table to be recorded")
+	}
+
+	if len(s.Scores) != Max {
+		t.Fatalf("expected %d scores, got %d", Max, len(s.Scores))
+	}
+	if best, ok := s.Best(); !ok || best.Score != 100 {
+		t.Fatalf("expected a best of 100, got %d", best.Score)
+	}
+	if last := s.Scores[Max-1].Score; last != 20 {
+		t.Fatalf("expected 10 to drop off the table, lowest is %d", last)
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scores")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := Path(filepath.Join(dir, "game.ch8"))
+
+	if s, err := Load(path); s != nil || err != nil {
+		t.Fatalf("expected no sidecar, got %v, %v", s, err)
+	}
+
+	if err = ioutil.WriteFile(path, []byte(`{"score": {"addr": "0x3F0", "size": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Score.Addr != 0x3F0 || s.Score.Size != 2 {
+		t.Fatalf("unexpected location %+v", s.Score)
+	}
+
+	s.Record(42, time.Now())
+	if err = s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if s, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if best, _ := s.Best(); best.Score != 42 {
+		t.Fatalf("expected the score to be saved, got %+v", s.Scores)
+	}
+}
@@ -0,0 +1,293 @@
+// Package script runs Lua scripts hooked into a running VM. Scripts can react
+// to every frame, every instruction or to specific addresses being executed;
+// reading and writing memory, registers and keys, and drawing overlay text.
+// This is enough for cheats, simple bots, automated testing and poking around
+// inside a ROM.
+//
+//	-- Infinite lives and a lives counter.
+//	on_frame(function()
+//		poke(0x3F0, 3)
+//		text(0, "lives " .. peek(0x3F0))
+//	end)
+//
+//	on_pc(0x23A, function()
+//		if reg("v0") > 9 then
+//			print("score overflow", reg("v0"))
+//		end
+//	end)
+//
+// Scripts are Lua 5.1, run by gopher-lua with its standard libraries, and
+// have these functions besides:
+//
+//	on_frame(f)          Call f at every 60Hz frame.
+//	on_instruction(f)    Call f(pc, opcode) before every instruction.
+//	on_pc(addr, f)       Call f before the instruction at addr.
+//	peek(addr)           Read the byte of memory at addr.
+//	poke(addr, value)    Write value to memory at addr.
+//	reg(name)            Read v0-vf, i, pc, dt or st.
+//	set(name, value)     Set v0-vf, i, pc, dt or st to value.
+//	press(key)           Mark the key as pressed.
+//	release(key)         Mark the key as released.
+//	print(...)           Log the arguments.
+//	text(line, s)        Set a line of overlay text, or clear it without s.
+//
+// The body of the script runs once as it's loaded, registering its hooks.
+package script
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/logging"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// Engine runs a script against a VM.
+type Engine struct {
+	vm *chip8.VM
+	l  *lua.LState
+
+	frame []*lua.LFunction
+	instr []*lua.LFunction
+	pcs   map[uint16][]*lua.LFunction
+
+	// Overlay text is set on the emulation goroutine and read by the
+	// renderer, so needs guarding.
+	mu    sync.Mutex
+	lines map[int]string
+}
+
+// Load runs the script at path and attaches it to vm.
+func Load(vm *chip8.VM, path string) (*Engine, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return newEngine(vm, f, path)
+}
+
+// New runs the script read from r and attaches it to vm.
+func New(vm *chip8.VM, r io.Reader) (*Engine, error) {
+	return newEngine(vm, r, "script")
+}
+
+// newEngine runs the script read from r, called name in errors, registering
+// its hooks with vm.
+func newEngine(vm *chip8.VM, r io.Reader, name string) (*Engine, error) {
+	e := &Engine{
+		vm:    vm,
+		l:     lua.NewState(),
+		pcs:   make(map[uint16][]*lua.LFunction),
+		lines: make(map[int]string),
+	}
+	for name, f := range map[string]lua.LGFunction{
+		"on_frame":       e.onFrame,
+		"on_instruction": e.onInstruction,
+		"on_pc":          e.onPC,
+		"peek":           e.peek,
+		"poke":           e.poke,
+		"reg":            e.reg,
+		"set":            e.set,
+		"press":          e.press,
+		"release":        e.release,
+		"print":          e.print,
+		"text":           e.text,
+	} {
+		e.l.SetGlobal(name, e.l.NewFunction(f))
+	}
+
+	fn, err := e.l.Load(r, name)
+	if err == nil {
+		e.l.Push(fn)
+		err = e.l.PCall(0, 0, nil)
+	}
+	if err != nil {
+		e.l.Close()
+		return nil, err
+	}
+
+	if len(e.frame) > 0 {
+		vm.OnFrame(func() {
+			e.call(e.frame)
+		})
+	}
+	if len(e.instr) > 0 || len(e.pcs) > 0 {
+		vm.OnInstruction(func(pc, opc uint16) {
+			e.call(e.instr, lua.LNumber(pc), lua.LNumber(opc))
+			if fns, ok := e.pcs[pc]; ok {
+				e.call(fns)
+			}
+		})
+	}
+
+	return e, nil
+}
+
+// Lines returns the overlay text set by the script, ordered by line number.
+func (e *Engine) Lines() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	nums := make([]int, 0, len(e.lines))
+	for n := range e.lines {
+		nums = append(nums, n)
+	}
+	sort.Ints(nums)
+
+	lines := make([]string, len(nums))
+	for i, n := range nums {
+		lines[i] = e.lines[n]
+	}
+	return lines
+}
+
+// call calls fns in order with args. Scripts are best effort, errors are
+// logged rather than stopping the emulator.
+func (e *Engine) call(fns []*lua.LFunction, args ...lua.LValue) {
+	for _, fn := range fns {
+		if err := e.l.CallByParam(lua.P{Fn: fn, Protect: true}, args...); err != nil {
+			logging.Errorf("Script error: %s", err)
+		}
+	}
+}
+
+// onFrame implements on_frame(f).
+func (e *Engine) onFrame(l *lua.LState) int {
+	e.frame = append(e.frame, l.CheckFunction(1))
+	return 0
+}
+
+// onInstruction implements on_instruction(f).
+func (e *Engine) onInstruction(l *lua.LState) int {
+	e.instr = append(e.instr, l.CheckFunction(1))
+	return 0
+}
+
+// onPC implements on_pc(addr, f).
+func (e *Engine) onPC(l *lua.LState) int {
+	addr := uint16(l.CheckInt(1))
+	e.pcs[addr] = append(e.pcs[addr], l.CheckFunction(2))
+	return 0
+}
+
+// peek implements peek(addr).
+func (e *Engine) peek(l *lua.LState) int {
+	l.Push(lua.LNumber(e.vm.Peek(uint16(l.CheckInt(1)))))
+	return 1
+}
+
+// poke implements poke(addr, value).
+func (e *Engine) poke(l *lua.LState) int {
+	e.vm.Poke(uint16(l.CheckInt(1)), byte(l.CheckInt(2)))
+	return 0
+}
+
+// reg implements reg(name).
+func (e *Engine) reg(l *lua.LState) int {
+	name := l.CheckString(1)
+	vm := e.vm
+	dt, st := vm.Timers()
+
+	var n int
+	switch name {
+	case "i":
+		n = int(vm.I())
+	case "pc":
+		n = int(vm.PC())
+	case "dt":
+		n = int(dt)
+	case "st":
+		n = int(st)
+	default:
+		x, ok := parseV(name)
+		if !ok {
+			l.ArgError(1, fmt.Sprintf("unknown register %q", name))
+		}
+		n = int(vm.V(x))
+	}
+	l.Push(lua.LNumber(n))
+	return 1
+}
+
+// set implements set(name, value).
+func (e *Engine) set(l *lua.LState) int {
+	name, n := l.CheckString(1), l.CheckInt(2)
+	vm := e.vm
+	dt, st := vm.Timers()
+
+	switch name {
+	case "i":
+		vm.SetI(uint32(n))
+	case "pc":
+		vm.SetPC(uint16(n))
+	case "dt":
+		vm.SetTimers(byte(n), st)
+	case "st":
+		vm.SetTimers(dt, byte(n))
+	default:
+		x, ok := parseV(name)
+		if !ok {
+			l.ArgError(1, fmt.Sprintf("unknown register %q", name))
+		}
+		vm.SetV(x, byte(n))
+	}
+	return 0
+}
+
+// parseV parses a general purpose register name, v0 to vf.
+func parseV(name string) (byte, bool) {
+	if len(name) != 2 || name[0] != 'v' {
+		return 0, false
+	}
+	x, err := strconv.ParseUint(name[1:], 16, 8)
+	if err != nil {
+		return 0, false
+	}
+	return byte(x), true
+}
+
+// press implements press(key).
+func (e *Engine) press(l *lua.LState) int {
+	e.vm.KeyDown(byte(l.CheckInt(1)))
+	return 0
+}
+
+// release implements release(key).
+func (e *Engine) release(l *lua.LState) int {
+	e.vm.KeyUp(byte(l.CheckInt(1)))
+	return 0
+}
+
+// print implements print(...), logging the arguments in place of writing
+// them to stdout.
+func (e *Engine) print(l *lua.LState) int {
+	args := make([]string, l.GetTop())
+	for i := range args {
+		args[i] = l.ToStringMeta(l.Get(i + 1)).String()
+	}
+	logging.Infof("%s", strings.Join(args, " "))
+	return 0
+}
+
+// text implements text(line, s).
+func (e *Engine) text(l *lua.LState) int {
+	line := l.CheckInt(1)
+	v := l.Get(2)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if v == lua.LNil {
+		delete(e.lines, line)
+		return 0
+	}
+	e.lines[line] = l.ToStringMeta(v).String()
+	return 0
+}
@@ -0,0 +1,100 @@
+package script
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestEngine(t *testing.T) {
+	vm := chip8.New()
+
+	// 6005: V0 = 5, 1202: jump to self.
+	if err := vm.Load(bytes.NewReader([]byte{0x60, 0x05, 0x12, 0x02})); err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := New(vm, strings.NewReader(`
+frames = 0
+on_frame(function() frames = frames + 1 end)
+
+on_pc(0x202, function()
+	if reg("v0") == 5 then set("v1", 7) end  -- only once V0 is set
+	poke(0x300, peek(0x201))
+	text(1, "v1 " .. reg("v1"))
+	text(2, "cleared")
+	text(2)
+end)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err = vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if vm.V(1) != 7 {
+		t.Errorf("expected V1 to be 7, got %d", vm.V(1))
+	}
+	if vm.Peek(0x300) != 0x05 {
+		t.Errorf("expected 0x300 to be 5, got %d", vm.Peek(0x300))
+	}
+	if l := e.Lines(); len(l) != 1 || l[0] != "v1 7" {
+		t.Errorf("unexpected overlay %q", l)
+	}
+}
+
+func TestInstructionHook(t *testing.T) {
+	vm := chip8.New()
+	rom := []byte{
+		0x60, 0x03, // V0 = 3
+		0xE0, 0x9E, // Skip if key 3 is pressed
+		0x12, 0x02, // Jump back to the skip
+		0x12, 0x06, // Halt
+	}
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Holding key 3 down from the hook of the first instruction, so the
+	// second skips the jump.
+	e, err := New(vm, strings.NewReader(`
+on_instruction(function(pc, opc)
+	text(pc, string.format("%03X %04X", pc, opc))
+	if opc == 0x6003 then press(3) end
+end)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err = vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if l := e.Lines(); len(l) != 3 || l[0] != "200 6003" || l[1] != "202 E09E" || l[2] != "206 1206" {
+		t.Errorf("unexpected overlay %q", l)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tests := []string{
+		"on_frame(",
+		"on_frame(1)",
+		"on_pc(0x200)",
+		`reg("vz")`,
+		`set("x", 1)`,
+		"error('stop')",
+	}
+	for _, src := range tests {
+		if _, err := New(chip8.New(), strings.NewReader(src)); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
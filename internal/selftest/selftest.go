@@ -0,0 +1,216 @@
+// Package selftest runs Timendus' CHIP-8 test suite headlessly and scores the
+// emulator against it, as a check of its correctness across the variants.
+//
+// The suite's ROMs aren't distributed with the emulator, they're read from a
+// directory holding the bin folder of a release of the suite, from
+// https://github.com/Timendus/chip8-test-suite. The tests draw their results,
+// so a run passes when its final display matches the one expected, kept in
+// selftest.json in the same directory. The expected displays are recorded
+// once they've been checked against the suite's documentation, see Save.
+package selftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/verify"
+)
+
+// ExpectedFile is the name of the file of expected displays in the suite's
+// directory.
+const ExpectedFile = "selftest.json"
+
+// Test is a run of one of the suite's ROMs.
+type Test struct {
+	// Name names the run, the ROM and the platform picked from its menu.
+	Name string
+
+	// ROM is the file name of the ROM in the suite.
+	ROM     string
+	Variant chip8.Variant
+
+	// Platform is written to 0x1FF before running, which the suite's ROMs
+	// with a menu take as the choice made, 0 for none.
+	Platform byte
+
+	// Frames is the most 60Hz frames to run for. Runs stop early once the
+	// program halts.
+	Frames int
+}
+
+// Suite are the runs of the suite's ROMs that need no one at the keypad. The
+// keypad and beep tests aren't included.
+var Suite = []Test{
+	{Name: "chip8-logo", ROM: "1-chip8-logo.ch8", Variant: chip8.Chip8, Frames: 60},
+	{Name: "ibm-logo", ROM: "2-ibm-logo.ch8", Variant: chip8.Chip8, Frames: 60},
+	{Name: "corax+", ROM: "3-corax+.ch8", Variant: chip8.Chip8, Frames: 120},
+	{Name: "flags", ROM: "4-flags.ch8", Variant: chip8.Chip8, Frames: 120},
+	{Name: "quirks/chip8", ROM: "5-quirks.ch8", Variant: chip8.Chip8, Platform: 1, Frames: 600},
+	{Name: "quirks/schip", ROM: "5-quirks.ch8", Variant: chip8.SChip, Platform: 2, Frames: 600},
+	{Name: "quirks/xochip", ROM: "5-quirks.ch8", Variant: chip8.XOChip, Platform: 3, Frames: 600},
+	{Name: "scrolling/schip-lores", ROM: "8-scrolling.ch8", Variant: chip8.SChip, Platform: 1, Frames: 120},
+	{Name: "scrolling/schip-hires", ROM: "8-scrolling.ch8", Variant: chip8.SChip, Platform: 2, Frames: 120},
+	{Name: "scrolling/xochip-lores", ROM: "8-scrolling.ch8", Variant: chip8.XOChip, Platform: 3, Frames: 120},
+	{Name: "scrolling/xochip-hires", ROM: "8-scrolling.ch8", Variant: chip8.XOChip, Platform: 4, Frames: 120},
+}
+
+// Status is how a run fared.
+type Status int
+
+// The statuses.
+const (
+	// Pass drew the display expected.
+	Pass Status = iota
+
+	// Fail drew another display.
+	Fail
+
+	// Unchecked has no display expected yet.
+	Unchecked
+
+	// Missing wasn't run as the ROM isn't in the suite's directory.
+	Missing
+
+	// Error stopped with an error.
+	Error
+)
+
+// String returns the status as shown in the scorecard.
+func (s Status) String() string {
+	switch s {
+	case Pass:
+		return "PASS"
+	case Fail:
+		return "FAIL"
+	case Unchecked:
+		return "UNCHECKED"
+	case Missing:
+		return "MISSING"
+	default:
+		return "ERROR"
+	}
+}
+
+// Result is how a run fared.
+type Result struct {
+	Test   Test
+	Status Status
+	Err    error
+
+	// Display is the hash of the final display, see verify.DisplayHash, and
+	// Screen the display drawn as text.
+	Display string
+	Screen  string
+}
+
+// Expected are the hashes of the displays expected at the end of each run,
+// by name.
+type Expected map[string]string
+
+// Load returns the displays expected by the suite in dir, none if it has no
+// ExpectedFile yet.
+func Load(dir string) (Expected, error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, ExpectedFile))
+	if os.IsNotExist(err) {
+		return Expected{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	exp := Expected{}
+	if err = json.Unmarshal(b, &exp); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// Save writes the expected displays to the suite in dir. Only record
+// displays checked to show every test passed.
+func (e Expected) Save(dir string) error {
+	b, err := json.MarshalIndent(e, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ExpectedFile), append(b, '\n'), 0644)
+}
+
+// Run runs the suite in dir, scoring each run against exp.
+func Run(dir string, exp Expected) []Result {
+	res := make([]Result, len(Suite))
+	for i, t := range Suite {
+		res[i] = run(dir, t, exp)
+	}
+	return res
+}
+
+// run runs t from the suite in dir.
+func run(dir string, t Test, exp Expected) Result {
+	r := Result{Test: t}
+
+	rom, err := ioutil.ReadFile(filepath.Join(dir, t.ROM))
+	if os.IsNotExist(err) {
+		r.Status = Missing
+		return r
+	}
+	if err != nil {
+		r.Status, r.Err = Error, err
+		return r
+	}
+
+	vm := chip8.NewVariant(t.Variant)
+	if err = vm.Load(bytes.NewReader(rom)); err != nil {
+		r.Status, r.Err = Error, err
+		return r
+	}
+	if t.Platform != 0 {
+		vm.Poke(0x1FF, t.Platform)
+	}
+	for f := 0; f < t.Frames && !vm.Halted(); f++ {
+		if err = vm.StepFrame(); err != nil {
+			r.Status, r.Err = Error, err
+			break
+		}
+	}
+
+	r.Display, r.Screen = verify.DisplayHash(vm), screen(vm.Display())
+	if r.Err != nil {
+		return r
+	}
+	switch want, ok := exp[t.Name]; {
+	case !ok:
+		r.Status = Unchecked
+	case want == r.Display:
+		r.Status = Pass
+	default:
+		r.Status = Fail
+	}
+	return r
+}
+
+// halfBlocks are the characters drawing a pair of pixels one above the other,
+// indexed by the top pixel in bit 0 and the bottom in bit 1.
+var halfBlocks = [4]string{" ", "▀", "▄", "█"}
+
+// screen returns d drawn as text, two rows of pixels to a line.
+func screen(d *chip8.Display) string {
+	var sb strings.Builder
+	for y := 0; y < d.Height(); y += 2 {
+		for x := 0; x < d.Width(); x++ {
+			i := 0
+			if d.Pixel(x, y) {
+				i |= 1
+			}
+			if y+1 < d.Height() && d.Pixel(x, y+1) {
+				i |= 2
+			}
+			sb.WriteString(halfBlocks[i])
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
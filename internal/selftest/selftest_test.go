@@ -0,0 +1,121 @@
+package selftest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeROM writes rom to the suite in dir as name.
+func writeROM(t *testing.T, dir, name string, rom []byte) {
+	t.Helper()
+	if err := ioutil.WriteFile(filepath.Join(dir, name), rom, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// find returns the result of the run called name.
+func find(t *testing.T, res []Result, name string) Result {
+	t.Helper()
+	for _, r := range res {
+		if r.Test.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("no result for %s", name)
+	return Result{}
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "selftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 00EE: return with an empty stack.
+	writeROM(t, dir, "1-chip8-logo.ch8", []byte{0x00, 0xEE})
+
+	// A206: I = 0x206; D015: draw; 1204: jump to itself.
+	writeROM(t, dir, "2-ibm-logo.ch8", []byte{0xA2, 0x06, 0xD0, 0x15, 0x12, 0x04, 0xF0})
+
+	// A1FF: I = 0x1FF; F065: V0 = the platform; F029: I = its digit;
+	// D015: draw it; 1208: jump to itself.
+	writeROM(t, dir, "5-quirks.ch8", []byte{0xA1, 0xFF, 0xF0, 0x65, 0xF0, 0x29, 0xD0, 0x15, 0x12, 0x08})
+
+	res := Run(dir, Expected{})
+	if r := find(t, res, "chip8-logo"); r.Status != Error || r.Err == nil {
+		t.Fatalf("expected chip8-logo to fail with an error, got %s", r.Status)
+	}
+	if r := find(t, res, "corax+"); r.Status != Missing {
+		t.Fatalf("expected corax+ to be missing, got %s", r.Status)
+	}
+	ibm := find(t, res, "ibm-logo")
+	if ibm.Status != Unchecked {
+		t.Fatalf("expected ibm-logo to be unchecked, got %s", ibm.Status)
+	}
+
+	chip8, schip := find(t, res, "quirks/chip8"), find(t, res, "quirks/schip")
+	if chip8.Display == schip.Display {
+		t.Fatal("expected the platform picked to change the display")
+	}
+
+	exp := Expected{"ibm-logo": ibm.Display, "quirks/chip8": schip.Display}
+	res = Run(dir, exp)
+	if r := find(t, res, "ibm-logo"); r.Status != Pass {
+		t.Fatalf("expected ibm-logo to pass, got %s", r.Status)
+	}
+	if r := find(t, res, "quirks/chip8"); r.Status != Fail {
+		t.Fatalf("expected quirks/chip8 to fail, got %s", r.Status)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "selftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	exp, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exp) != 0 {
+		t.Fatalf("expected nothing expected yet, got %v", exp)
+	}
+
+	exp["flags"] = "abc"
+	if err = exp.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	if exp, err = Load(dir); err != nil {
+		t.Fatal(err)
+	}
+	if exp["flags"] != "abc" {
+		t.Fatalf("expected the flags display to be kept, got %v", exp)
+	}
+}
+
+// TestSuite runs the suite in the directory named by CHIP8_TEST_SUITE, if
+// set, failing on any run not drawing the display expected.
+func TestSuite(t *testing.T) {
+	dir := os.Getenv("CHIP8_TEST_SUITE")
+	if dir == "" {
+		t.Skip("CHIP8_TEST_SUITE is not set")
+	}
+
+	exp, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range Run(dir, exp) {
+		switch r.Status {
+		case Fail:
+			t.Errorf("%s drew:\n%s", r.Test.Name, r.Screen)
+		case Error:
+			t.Errorf("%s: %s", r.Test.Name, r.Err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package showcase
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// errTooLarge is returned for animations over the most pixels.
+var errTooLarge = errors.New("animation too large")
+
+// frame is a display recorded, shown for delay frames.
+type frame struct {
+	img   *image.Paletted
+	delay int
+}
+
+// recorder records the displays shown each frame for an animation, merging
+// those that don't change.
+type recorder struct {
+	scale     int
+	maxPixels int
+
+	frames []frame
+	pixels int
+}
+
+// newRecorder returns a recorder for an animation scaled by scale, of at most
+// maxPixels.
+func newRecorder(scale, maxPixels int) *recorder {
+	return &recorder{scale: scale, maxPixels: maxPixels}
+}
+
+// frame records img as shown for a frame. It's copied, as VM.Image is a view
+// of the display.
+func (r *recorder) frame(img *image.Paletted) error {
+	if n := len(r.frames); n > 0 {
+		last := r.frames[n-1]
+		if last.img.Rect == img.Rect && bytes.Equal(last.img.Pix, img.Pix) && samePalette(last.img.Palette, img.Palette) {
+			r.frames[n-1].delay++
+			return nil
+		}
+	}
+
+	r.pixels += len(img.Pix) * r.scale * r.scale
+	if r.maxPixels > 0 && r.pixels > r.maxPixels {
+		return errTooLarge
+	}
+
+	cp := &image.Paletted{
+		Pix:     append([]byte(nil), img.Pix...),
+		Stride:  img.Stride,
+		Rect:    img.Rect,
+		Palette: append(color.Palette(nil), img.Palette...),
+	}
+	r.frames = append(r.frames, frame{img: cp, delay: 1})
+	return nil
+}
+
+// gif returns the animation. Displays smaller than the largest shown, as
+// before a SUPER-CHIP program switches to hi-res, are scaled up to fill it.
+func (r *recorder) gif() (*gif.GIF, error) {
+	if len(r.frames) == 0 {
+		return nil, errors.New("no frames run")
+	}
+
+	var w, h int
+	for _, f := range r.frames {
+		if f.img.Rect.Dx() > w {
+			w = f.img.Rect.Dx()
+		}
+		if f.img.Rect.Dy() > h {
+			h = f.img.Rect.Dy()
+		}
+	}
+	if r.maxPixels > 0 && len(r.frames)*w*h*r.scale*r.scale > r.maxPixels {
+		return nil, errTooLarge
+	}
+
+	g := &gif.GIF{
+		Config: image.Config{Width: w * r.scale, Height: h * r.scale},
+	}
+	// GIF delays are in hundredths of a second, so the frames are shown
+	// from the nearest hundredth to when they were at 60Hz.
+	var shown int
+	for _, f := range r.frames {
+		start := shown * 100 / chip8.FrameRate
+		shown += f.delay
+		end := shown * 100 / chip8.FrameRate
+
+		s := r.scale * w / f.img.Rect.Dx()
+		if sy := r.scale * h / f.img.Rect.Dy(); sy < s {
+			s = sy
+		}
+		g.Image = append(g.Image, scaleImage(f.img, s, g.Config.Width, g.Config.Height))
+		g.Delay = append(g.Delay, end-start)
+	}
+	return g, nil
+}
+
+// scaleImage returns img scaled by s, on a canvas of w by h.
+func scaleImage(img *image.Paletted, s, w, h int) *image.Paletted {
+	out := image.NewPaletted(image.Rect(0, 0, w, h), img.Palette)
+	for y := 0; y < img.Rect.Dy()*s && y < h; y++ {
+		row := img.Pix[(y/s)*img.Stride:]
+		o := out.Pix[y*out.Stride:]
+		for x := 0; x < img.Rect.Dx()*s && x < w; x++ {
+			o[x] = row[x/s]
+		}
+	}
+	return out
+}
+
+// samePalette returns whether a and b are the same colours.
+func samePalette(a, b color.Palette) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+// Package showcase serves an HTTP endpoint running uploaded ROMs headlessly
+// and returning an animated GIF of them, for ROM showcase sites.
+//
+// The endpoint is:
+//
+//	POST /gif?frames=300&scale=4&variant=auto  Run the ROM in the request body.
+//
+// Everything a request can ask for is bounded by the server's Config, so it's
+// safe to expose publicly: the size of the upload, the frames run, how long
+// each frame and the whole run may take, the instructions executed, the size
+// of the animation and the runs at once. Requests over the limits fail
+// rather than queueing or running on.
+package showcase
+
+import (
+	"bytes"
+	"fmt"
+	"image/gif"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/romfmt"
+)
+
+// Config bounds the runs a server does.
+type Config struct {
+	// MaxROM is the largest upload accepted, in bytes.
+	MaxROM int64
+
+	// Frames is the number of 60Hz frames run when a request doesn't say,
+	// and MaxFrames the most a request may ask for.
+	Frames    int
+	MaxFrames int
+
+	// Scale is the size in pixels of each display pixel when a request
+	// doesn't say, and MaxScale the most a request may ask for.
+	Scale    int
+	MaxScale int
+
+	// MaxPixels is the most pixels, summed over its frames, an animation may
+	// have. It bounds the memory each run takes.
+	MaxPixels int
+
+	// Limits are set on each VM, see chip8.VM.SetLimits.
+	Limits chip8.Limits
+
+	// Timeout is the longest a run may take, all frames together.
+	Timeout time.Duration
+
+	// Runs is the most requests run at once. Those beyond are refused.
+	Runs int
+
+	// MegaChip allows MegaChip ROMs, which take 16MB of memory each.
+	MegaChip bool
+}
+
+// DefaultConfig returns limits suitable for a public server.
+func DefaultConfig() Config {
+	return Config{
+		MaxROM:    64 << 10,
+		Frames:    300,
+		MaxFrames: 1800,
+		Scale:     4,
+		MaxScale:  8,
+		MaxPixels: 64 << 20,
+		Limits: chip8.Limits{
+			MaxCycles: 1800 * chip8.ClockSpeed / chip8.FrameRate,
+			Budget:    50 * time.Millisecond,
+		},
+		Timeout: 10 * time.Second,
+		Runs:    4,
+	}
+}
+
+// Server is an http.Handler running uploaded ROMs.
+type Server struct {
+	cfg Config
+	mux *http.ServeMux
+
+	// runs holds a token for each run in progress.
+	runs chan struct{}
+}
+
+// NewServer returns a server bounding runs by cfg.
+func NewServer(cfg Config) *Server {
+	if cfg.Runs < 1 {
+		cfg.Runs = 1
+	}
+	s := &Server{
+		cfg:  cfg,
+		mux:  http.NewServeMux(),
+		runs: make(chan struct{}, cfg.Runs),
+	}
+	s.mux.HandleFunc("/gif", s.gif)
+
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// gif runs the ROM in the request body and writes a GIF of it.
+func (s *Server) gif(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	frames, ok := intParam(w, q.Get("frames"), "frames", s.cfg.Frames, s.cfg.MaxFrames)
+	if !ok {
+		return
+	}
+	scale, ok := intParam(w, q.Get("scale"), "scale", s.cfg.Scale, s.cfg.MaxScale)
+	if !ok {
+		return
+	}
+
+	// The token is taken before the upload is decoded, which takes memory
+	// too.
+	select {
+	case s.runs <- struct{}{}:
+		defer func() { <-s.runs }()
+	default:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many runs, try again later", http.StatusServiceUnavailable)
+		return
+	}
+
+	data, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxROM))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("ROM over %d bytes", s.cfg.MaxROM), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	// A ROM larger than the memory of the variants allowed can't run, so
+	// isn't decoded.
+	limit := chip8.XOChip.MemSize()
+	if s.cfg.MegaChip {
+		limit = chip8.MegaChip.MemSize()
+	}
+	if data, _, err = romfmt.DecodeMax("ROM", data, limit); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vr, _ := chip8.Detect(data)
+	if name := q.Get("variant"); name != "" && name != "auto" {
+		if vr, err = chip8.ParseVariant(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if vr == chip8.MegaChip && !s.cfg.MegaChip {
+		http.Error(w, "MegaChip ROMs aren't allowed", http.StatusUnprocessableEntity)
+		return
+	}
+
+	vm := chip8.NewVariant(vr)
+	vm.SetLimits(s.cfg.Limits)
+	if err = vm.Load(bytes.NewReader(data)); err != nil {
+		http.Error(w, fmt.Sprintf("load ROM: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	deadline := time.Now().Add(s.cfg.Timeout)
+	rec := newRecorder(scale, s.cfg.MaxPixels)
+	for f := 0; f < frames && !vm.Halted(); f++ {
+		if err = r.Context().Err(); err != nil {
+			return
+		}
+		if s.cfg.Timeout > 0 && time.Now().After(deadline) {
+			http.Error(w, fmt.Sprintf("run over %s", s.cfg.Timeout), http.StatusUnprocessableEntity)
+			return
+		}
+		if err = vm.StepFrame(); err != nil {
+			http.Error(w, fmt.Sprintf("frame %d: %s", f, err), http.StatusUnprocessableEntity)
+			return
+		}
+		if err = rec.frame(vm.Image()); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	g, err := rec.gif()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	var buf bytes.Buffer
+	if err = gif.EncodeAll(&buf, g); err != nil {
+		http.Error(w, fmt.Sprintf("encode GIF: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.Write(buf.Bytes())
+}
+
+// intParam parses the query parameter v called name, writing an error and
+// returning false if it's out of the range 1 to max. It's def if empty.
+func intParam(w http.ResponseWriter, v, name string, def, max int) (int, bool) {
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > max {
+		http.Error(w, fmt.Sprintf("invalid %s %q, must be 1 to %d", name, v, max), http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
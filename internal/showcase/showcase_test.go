@@ -0,0 +1,131 @@
+package showcase
+
+import (
+	"bytes"
+	"image/gif"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// loop runs forever without drawing. 7001: V0++; 1200: loop.
+var loop = []byte{0x70, 0x01, 0x12, 0x00}
+
+func do(t *testing.T, s *Server, method, target string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, target, bytes.NewReader(body)))
+
+	return w
+}
+
+func TestGIF(t *testing.T) {
+	// Draw a pixel at (0, 0) after 10 frames.
+	rom := []byte{
+		0x60, 0x0A, // V0 = 10.
+		0xF0, 0x15, // DT = V0.
+		0xF1, 0x07, // V1 = DT.
+		0x31, 0x00, // Skip if V1 is 0.
+		0x12, 0x04, // Loop.
+		0xA2, 0x12, // I = sprite.
+		0xD2, 0x21, // Draw at (0, 0).
+		0x70, 0x01, // V0++.
+		0x12, 0x0E, // Loop.
+		0x80, // Sprite.
+	}
+
+	s := NewServer(DefaultConfig())
+	w := do(t, s, http.MethodPost, "/gif?frames=30&scale=2", rom)
+	if w.Code != http.StatusOK {
+		t.Fatalf("%d %s", w.Code, w.Body)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "image/gif" {
+		t.Fatalf("expected a GIF, got %s", ct)
+	}
+
+	g, err := gif.DecodeAll(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 2 {
+		t.Fatalf("expected the unchanged frames to be merged into 2, got %d", len(g.Image))
+	}
+	if b := g.Image[0].Bounds(); b.Dx() != 128 || b.Dy() != 64 {
+		t.Fatalf("expected frames of 128x64, got %v", b)
+	}
+	if d := g.Delay[0] + g.Delay[1]; d != 50 {
+		t.Fatalf("expected 30 frames to last 50 hundredths, got %d", d)
+	}
+	if r, _, _, _ := g.Image[1].At(1, 1).RGBA(); r == 0 {
+		t.Fatal("expected pixel (0, 0) to be lit in the last frame")
+	}
+	if r, _, _, _ := g.Image[0].At(1, 1).RGBA(); r != 0 {
+		t.Fatal("expected pixel (0, 0) to be unlit in the first frame")
+	}
+}
+
+func TestGIFLimits(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxROM = 64
+	cfg.MaxPixels = 10000
+	s := NewServer(cfg)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   []byte
+		code   int
+		msg    string
+	}{
+		{name: "method", method: http.MethodGet, target: "/gif", code: http.StatusMethodNotAllowed},
+		{name: "frames", method: http.MethodPost, target: "/gif?frames=100000", body: loop, code: http.StatusBadRequest, msg: "must be 1 to 1800"},
+		{name: "scale", method: http.MethodPost, target: "/gif?scale=0", body: loop, code: http.StatusBadRequest, msg: "must be 1 to 8"},
+		{name: "rom size", method: http.MethodPost, target: "/gif", body: make([]byte, 65), code: http.StatusRequestEntityTooLarge},
+		{name: "hex size", method: http.MethodPost, target: "/gif", body: []byte(":020000040001F9\n:02000000A2203C\n:00000001FF\n"), code: http.StatusBadRequest, msg: "beyond"},
+		{name: "megachip", method: http.MethodPost, target: "/gif?variant=megachip", body: loop, code: http.StatusUnprocessableEntity, msg: "MegaChip"},
+		{name: "pixels", method: http.MethodPost, target: "/gif?scale=8", body: loop, code: http.StatusUnprocessableEntity, msg: "too large"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := do(t, s, tc.method, tc.target, tc.body)
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d %s", tc.code, w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tc.msg) {
+				t.Fatalf("expected %q in %q", tc.msg, w.Body)
+			}
+		})
+	}
+}
+
+func TestGIFCycleLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Limits.MaxCycles = 100
+	s := NewServer(cfg)
+
+	w := do(t, s, http.MethodPost, "/gif", loop)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "cycle limit") {
+		t.Fatalf("expected the cycle limit to stop the run, got %d %s", w.Code, w.Body)
+	}
+}
+
+func TestGIFBusy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Runs = 1
+	s := NewServer(cfg)
+
+	s.runs <- struct{}{}
+	w := do(t, s, http.MethodPost, "/gif", loop)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected a busy server to refuse the run, got %d %s", w.Code, w.Body)
+	}
+	<-s.runs
+
+	if w = do(t, s, http.MethodPost, "/gif", loop); w.Code != http.StatusOK {
+		t.Fatalf("expected the run once free, got %d %s", w.Code, w.Body)
+	}
+}
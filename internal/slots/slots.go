@@ -0,0 +1,116 @@
+// Package slots keeps numbered save states of a ROM in a directory, each with
+// a thumbnail of the display and the time it was saved, for a menu to browse.
+//
+// Slot n is kept as n.json, written by VM.SaveState, and n.png, and the Auto
+// slot as auto.json and auto.png.
+package slots
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Count is the number of slots of each ROM.
+const Count = 10
+
+// Auto is the slot saved to when the emulator is stopped from outside, kept
+// apart from the numbered slots so it never replaces one saved by hand. List
+// leaves it out.
+const Auto = -1
+
+// Slot is a save state slot.
+type Slot struct {
+	N int
+
+	// Saved is when the slot was saved, zero if it's empty, and Thumb the
+	// display as it was, nil if it's empty or the thumbnail can't be read.
+	Saved time.Time
+	Thumb image.Image
+}
+
+// Empty returns true if nothing has been saved in the slot.
+func (s Slot) Empty() bool {
+	return s.Saved.IsZero()
+}
+
+// Store is the directory a ROM's slots are kept in, created as they're
+// saved.
+type Store string
+
+// path returns the path of the file of slot n with ext.
+func (s Store) path(n int, ext string) string {
+	if n == Auto {
+		return filepath.Join(string(s), "auto"+ext)
+	}
+	return filepath.Join(string(s), strconv.Itoa(n)+ext)
+}
+
+// Save saves the state of vm in slot n, replacing anything saved there.
+func (s Store) Save(n int, vm *chip8.VM) error {
+	if n != Auto && (n < 0 || n >= Count) {
+		return fmt.Errorf("no slot %d", n)
+	}
+
+	var state, thumb bytes.Buffer
+	if err := vm.SaveState(&state); err != nil {
+		return err
+	}
+	if err := png.Encode(&thumb, vm.Image()); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(string(s), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(s.path(n, ".png"), thumb.Bytes(), 0644); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path(n, ".json"), state.Bytes(), 0644)
+}
+
+// Load restores the state of vm from slot n.
+func (s Store) Load(n int, vm *chip8.VM) error {
+	b, err := ioutil.ReadFile(s.path(n, ".json"))
+	if os.IsNotExist(err) && n == Auto {
+		return fmt.Errorf("nothing has been saved automatically")
+	}
+	if os.IsNotExist(err) {
+		return fmt.Errorf("slot %d is empty", n)
+	}
+	if err != nil {
+		return err
+	}
+	return vm.LoadState(bytes.NewReader(b))
+}
+
+// List returns every slot, in order.
+func (s Store) List() []Slot {
+	list := make([]Slot, Count)
+	for n := range list {
+		list[n].N = n
+
+		info, err := os.Stat(s.path(n, ".json"))
+		if err != nil {
+			continue
+		}
+		list[n].Saved = info.ModTime()
+
+		// A missing thumbnail doesn't stop the state being loaded.
+		f, err := os.Open(s.path(n, ".png"))
+		if err != nil {
+			continue
+		}
+		list[n].Thumb, _ = png.Decode(f)
+		f.Close()
+	}
+	return list
+}
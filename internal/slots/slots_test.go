@@ -0,0 +1,69 @@
+package slots
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	s := Store(filepath.Join(dir, "rom"))
+
+	vm := chip8.New()
+	if err = vm.Load(bytes.NewReader([]byte{0x60, 0x2A, 0x12, 0x02})); err != nil {
+		t.Fatal(err)
+	}
+	if err = vm.Cycle(); err != nil {
+		t.Fatal(err)
+	}
+
+	if list := s.List(); len(list) != Count || !list[3].Empty() {
+		t.Fatalf("expected %d empty slots, got %+v", Count, list)
+	}
+	if err = s.Load(3, vm); err == nil {
+		t.Fatal("expected an error loading an empty slot")
+	}
+	if err = s.Save(Count, vm); err == nil {
+		t.Fatal("expected an error saving past the last slot")
+	}
+
+	if err = s.Save(3, vm); err != nil {
+		t.Fatal(err)
+	}
+	list := s.List()
+	if list[3].Empty() || list[3].Thumb == nil || list[3].N != 3 {
+		t.Fatalf("expected slot 3 to be saved with a thumbnail, got %+v", list[3])
+	}
+	if !list[2].Empty() {
+		t.Fatal("expected slot 2 to be empty")
+	}
+
+	vm.SetV(0, 0)
+	if err = s.Load(3, vm); err != nil {
+		t.Fatal(err)
+	}
+	if vm.V(0) != 0x2A {
+		t.Fatalf("expected V0 to be restored to 0x2A, got 0x%X", vm.V(0))
+	}
+
+	// The automatic slot is kept apart from the numbered ones.
+	if err = s.Save(Auto, vm); err != nil {
+		t.Fatal(err)
+	}
+	if list := s.List(); len(list) != Count || !list[0].Empty() {
+		t.Fatalf("expected the automatic slot to be left out, got %+v", list)
+	}
+	vm.SetV(0, 0)
+	if err = s.Load(Auto, vm); err != nil || vm.V(0) != 0x2A {
+		t.Fatalf("expected V0 to be restored from the automatic slot, got 0x%X, %v", vm.V(0), err)
+	}
+}
@@ -1,11 +0,0 @@
-// Code generated by github.com/gobuffalo/packr. DO NOT EDIT.
-
-package sound
-
-import "github.com/gobuffalo/packr"
-
-// You can use the "packr clean" command to clean up this,
-// and any other packr generated files.
-func init() {
-	packr.PackJSONBytes("./data", "beep.wav", "\"UklGRnwhAABXQVZFZm10IBAAAAABAAEARKwAAIhYAQACABAAZGF0YVghAABZAJ4APABh/+r/ewB6AcUATf77ALEKixGkEAIQERBOEJsQbxCfEBsQPxCAEKsQJxAWEHQSCRWeDaT6L+5h7g7yGvJj8SLx9+7P7mLuC+9C7zfutO5o7pLuAO/E7mbtq+3U7e3tXu4q7pDuR+4G74ztMu3D7ZTtP+5U7qXuHe8T8JfvT+/a72vxt/A871zwD/AC8O7t2+vB8+gFjhJWE3sRlRF7ETARhxFjEVgRLxGOEUURAhJ5ERYR0hHcEKERkxJoEjcRJBEdEXgQdBDWEL4R9xG0EVcR7BH6EZ0SORIeEvgRwxGqEoMRABLvEAMQihD1EP8SNxXHDWr5i+uu69Hu4u4I73LuF+7X7ZnuNvAW76ruH+4n7ubtBO4c7ivuNe6L7bPtwexj7Rnu9e6J7wPvmO7P7rzu3u2q7Wftw+7k7qDuHO6C7YntMe5E7wHv2u8W8PbumuxH9GkHVBRnFIQQwRACEZsQfxAcEDsQ4BEMEgcRSBGVEfMRgBE1EUEQpxBjEEEQ3hCHEB4SOxJzEcURwRHcEJsR7hHTECARkBFmEVsRqhDsD+IRixF6EGURohAmEKQRhxPBDGX62ex17IfvXe+K7pzvR+917hbvFO9p7/Dufu7j7YztwO4t7gLuMu8N74Du0e447ynvMe9n7i7uE+9/7mvuD++87sTu7e7J7hfvlvAG8V3vO+7v7nPvOu8y8D7uVOqT8fYE7BL1Ek0QrBAAEVoRbhE0EQsRsxEWErkRVRHOENcRxhEmESkSNBGxENEQ6w/MDx8QwxD+EDARNBF5EAAQ2A9hEP0P5Q/gEBkQRBAdEQIRBRLhEQMRahHvEAoSGBVfDr36Ae1i7bLvOu/R7kLvNO8Q7mvuXe697XfusO5Q7/PuPO547u7tP+777lbv4+/R71vvsu7G7oTvlu8k733urO43717u3u4f8ILvgu8T71/unu5O7vDuZ+4L7rXtyOq88YUFiBOkE1UQGhDuEMARnhGnEfARmRFqEY4QcBAAEfEQnhFxEbcQABELEYQQ5xDYEfMRLhGWEOsQhRGgEaARChGHERkS4RAPEegRqxHUEeAR8hGqEVARsRE/ESwSvxTdDV76A+wD7BPv2e6H7q3u4+2d7RPuXu7s7jHvfe7k7RXvRO9p7h/vM+/M7tvuie9o77nuWe9k7rLtTO5y7pPu7e2o7pfuze2Z7qzu0u647TbtWu6q7ZztCO/q7tXsaetF8SAE5hO9E4wRlRFXEc8RuRHaEpISAxIEEhURcxGYEWoRFxH/EKgR8xCaEJMQwRBtEbwQ+xDSESgRVhK6Ev8QBRH6EBcR2BHIETARDRF1EqYSfBEhEQYRHBGnEH0SdRXHDZ76t+xH68Pt3u4S7mvtfe3/7Qnvle5A7j7uxu3c7dvt3O3o7bLub++U7krvYu/z7c3u1+5k7iLvBO8376vuO+6f7pDtie0G7kbtRu1a7rTuLO6l7pbuw+5Y7zbt8upZ8qIFtRP5E3YR6RB7EfsRBBIvEkMRyRB9EVsR9xASEQURLxH4EMQQOxF+ETURpBBeEcERRBGzEagRMxGWEM0QBhG7EDwRSBHgEbURFhHgEOAQ1REuEdsQIBFVEMoRkhQBD8j7UOw56yju3+7w7UHuSe6r7W/uJu6O7STuDu+S76Tuve6I72zueu5/70fvwO4e7hfv/O7W7cnuuO627uPu+u1U7ofveu8e7ibuUO4k7lfux+4R7yLu3e6P76LtL+uj8MgD1RKlE9AQxRBMEY0QGBEdEQoRehFhEFsQRRCaEFIR4xCOEJcQSBEWEYQQkhBMEIcQ3BD9EOMQHxGDEW0RcREfETkRlxGAEQsSMxLkEeYRbBHmESsSPBEfEaESghWlD0H86exP7IHvTe+F7nru2O5k7r3uAO/c7ULvu+/j7nrvNO/Y7yrv+O2q7qDvDO/57e7uG+5P7XDuC+7E7Z7tBu4Z7rDtGe5M7s/uDe9B7//uxu4F7i3tjO5E7yDt8+kr8HcDMRKRE8IQSRDWEIgRdhGrEFQRsBE2ERARVRE0Eb4QZRCVEAES2hDMEH0SAxH5EF8RnxHCEisSYRFKEQYSWBKyEc4RIBI1EQMRKBKsEVgROBLEEsoS7RGpEcASMBW2D1b9++zn6kzvYu+07Xfthu1M7pbu6O7O7kjusO6o7ojtve3P7rvu+O4O77/uoO4/7u/tcu7h7hvu1e2e7dXtJ+5S7YLuwu6g7ZntFe5N7k3tNu5c7mbtA+7s7TTtDesU8b4ErhPFFKYRSBF9EeIR2hK+ETMRhREWEWsRXhGvEZASmhHdEWASbhG8EUcR1BDQESYS8RCWECcSvhHjECoRKRLSEmwRhhHQEYIRgBLREosSQxJEEv8Q6RB5EQsS+hTrDm/7W+x+67PuLO/t7qruke4i7rLuA+9O7mLuu+1e7eXszewc7truSu887xnv8+4c7yXvre8e8Mrut+7U7u3uWO8n7rfts+1c7Xvtfu7M7qbtju387Xzu8u7B7wLuf+ol8E0CbxJGEzcQMxH3EFgRrBHbEdkRnBG4Ed0RvBKKEY8RUhLqEGERRRGkEHkQ1w8XENcQRxH8EMgRphK7EQ4RXxFjETMRMxKFEcYQdBIrEpMRKBLqEfcRkBHkEH0SrBWiEPD98O0d7EjvQO8H7mbvCe9m7djtm+1/7lLuH+1S7qjul+4R7xrv0O7c7ljvru+V79/uRu8I70HuB+/37pDuo+6m7kbuRu5g7pvu3u4C7g/uiu397c3use0/7tbsyOk07+UBMhO1FGkRMhF2EUgRpxEvEmkRZhLbEXcRTxIZEXMRHxE5Ef8RexHJEbkQshA7EWMRoxEkEBQR9hEQEdARKxETERsSfhG9EZQRDBGqEVMRcxH+EX0R1BCzEEISUhUpEMD87uyq60zuKu/A7p7udO5k7Yntj+7M7jvuHu637ujuGu4r7tTuW+6a7ozue+/u73juMu/M7o3t8O0C7nTusu6P7mLur+4d7pntfu457oXt5e0z7nft8e137/7t2Orw79ICdRMzFbURFhGHEdQRmxIVEtsR6xJNEiURkBDHEGYQ/A9BEG8QXREPEfIQbhHtEPEQKRGtEawRCxKCEcsQDxKQEooS8REuEU4RihHfERgR/BBdETQRgxE5EfoR4BTnD/j8LOxv6lzuvO6D7brtfO017fHuju8s7z3vBu6g7pTvfe797qTumu457zzu2u4k79vuye6Z7rHup+6Z7+juL+4e7q3tIe7V7T/uwu027RHuRO7R7gzup+3h7kTuC+vJ73QCZBJeFWER2RCnEnsRJhLQEQsRIRHGEKMRsBD+EDMSmhFgEZYQmRDAEMkQ3xCVEIkQRhAREWYRERHQEcwSURKoENIQ9BFuEucRkRAxEAMRKBKTEiMSZhGLEGcRjxQ4EOP98uw96qztiu6o7QrutO5A7rjup+8874/uv+0G7kHvj+/h7gDvee9U73vvwO6s7sXuve0i763vVe9O7z3u3u5C72buAu547pfuCu4a7mXuZO/E7jHu+O4h7wruhep28G4CXBJ/FfgQKBFREfUQDBK7ERES0xD3EA8R/xDjEZcQqBEcEggRKhGTERASVBGBEfYQvhD+EEMQ0BBTEKEQRxIiEnQRJhKvEjsR1hBNEUERVxGSEXARThE9ETgR9xQNESr+me6g6xPuCO9h7ovuRO737VTuou7p7fXtFu/f76vvk+5i7qPtTO2G7sbuQe8G7+PuAvB179HuEO8w7ljuGe9y7kXuiu6N7rzugu7l7Rbuc+6f7TztBO4+71nudOut73QAsBEWFdwRThHFEdkRtBBmEXUS2hEyEigRxxCdEYQRMhHoEPcQ7RA0EugR3RD5ERUSHhFQEE0RjBH3EIER3hAuEVERWxFrEi8SZBJ7Ep4R1RDsEGgRgBGgEbQRkROrEDIAIe4j66XuNO6z7mfvUu/M7rztU+6x7aTt5e4h7yXvOe4h7cLti+9d7yPvvu+p7v7ua+6t7TLv++6/7hDvVe6Q7ljvxe6o7VPub+7T7R/vNe9t723vTu6670vuHOu38AAAiQ8sEzURNRHEEJUR5RFTEpwSqRFvEpISXRGSEK8RLxJEEZMRGxHxEXESsBAyEG8QvhBlEPwQ7xGRESkS9xG+EOAQmBFUEdkQ8RDrEF0RyRG9Eo4SZRFUESQRORE9El8U9w+u/trtK+ow7qbvhO697YXufu8f7tXtfu5X7unuLO8x7wjvUu8d75buIO+R7pXuUu/N7l7u3e5A7yjvK+9v7kru7O4w76Tvb+/H7m/uY+6N7pXuWu5H7r3uQO9n7gbsi+8jAOMR5BWHEUIRGhL5EK0RAhGzEGIRwRBcEfoQKBHqEskS6BEUEbERZRKpEewQShAQEQkSphFVEY0ShRLBEPQRxRJHEpYShhBZEGsRchFNEqIR3xBTEdAROBFWEAsTOxDT/vftwOuc7hfv6+4d7j3u9u6k7+Xuy+397iTveO617ifu9e1q7mbu0e0+7tbuzO5T7trt9u2Z7cHteu6F7nvun+607i7u/e0l7ibvX+8d7oXu3u747vruvO4H7uTqS+/yAOAQohPhEO4QMhEtEGEQVxLjEasQSRE4EY8RExFNEd4R9BBnETQRTRF/EW4RthFaEe4SWxMAEm4RcBHPEX0RVhEuERoRXBH/EbkSYhIDElIRixHGERYR4hCrEfEUaxE5AITu/er+7j/vm+4M70Tv0+4L77Punu1Y71Pve+7h7hfvzu+R7hfu4u0i7l7uJu2S7jjui+2q7Xzt5e5m7sXu0O6/7eruM+/s7rruve6N7nDu8u5G7oHuJO817abpEe7HANsSphWiEGQPdBC+EMkQpxBgEM4QYBG0EAYR7RDXEJwRFxGYEWgRwRGYEqkREBKBEQMRKRFxEGUR1xHwEMgPUBAmEekQDxEaEecRmhLMEcYQ8RCVEeMRsxESETsUvhG3/+HuHevS7QvvEe5V7mTvG+/V7mTvyO4L727v5u0I7gzvCe/87ant3O1s7XjuH+/17vXuF+8R72Tu6e6f7vjuRe8Y7irvx+777eDupe7X7iLuLe3I7fbuNO8j7nvrLe6B/5AR3RVvEv8PDRG0EAwQjRASEF4QChC1EF4SABNLE98RDBGoEMIQ+BGdEZQRfxE1Ea8RnBCDEAQRkRCQEZER4RDnEHwQzRGqEkoSzxJ1EpcRuxDvERUTqxAHEVUUSxGQAE/uBOvB7d3uFO+j7vbuae7t7hLvS+4s7v/sUO5k76rtz+1v7uLtp+4i74bueu5F7qDudO/p76/vau4N79Xu5O2E7iPuwu7P7mnuyu6J7h/vV+9j753uVu+7723rru6a/xYR1hStEFERTxJLEeUQtBAxET0RSxFbETARTBEFElsSwhH9EGkRARLnED0QZxB7Ed4RuxDxECwRThHSEU0SdRHjEFAS8BCyEEMRQxAqEaUQexEZEhgRUBHZEasUxBAJAMPuJOr17SLvOu9N78vuve467tPtiu4t7xvuqO0O7mfumO4w747vc+6D72PvmO3T7QXuPe8b77TtAu607tzuG++B7yfuKu6q7ivuo+5+7wLw/+4t7/vure5h7jzqTu2l/bsPDxWLEcQRkBFLEPgQ5BFEEkESvxEXELUQGhLJEeQQTRGeEoUR9xA5EaMR0hGzEA4RdxFLEvgSFhIiEU0QxRBFETMRDRBUEKcRPxHCERsROBEWElYRyxGiEUwUxxIOAnTvAOsH7m7uMe4j7j7u3e7J7jzvXe8N8Ozv4e5e7vjtye4W7oDtbe5T7sPu0O537oftSO3+7TTuTu8W7yzuG+4X71XvtO047vDu7O6w7uvtsu7P7pDuEu8W7wTsTu0Z/pUQpRR/ETgQWBBlEAARyBBvEFgQDBFHEtcRDBIeEmMRxRCdEH0SgxI+EToSpBJhEUcQwRDzEbMSZRK8EbQRvRGhEaMQ4xCzEbEQBBHZEUURxBDsENYR1xCQELAUYhJ/ATfwhesO7gbweO+c7qntk+3C7p/urO4p7rjtFe/O7rPuWu6u7X3uFO6u7obv2u5v7ortT+0Z7p7uIu/m7hHuye6x7q3t4+4h75nuBu9j75bvLu7e7Rjvle+W7ozrve3M/fsP5hQ6EoMQPhHMEd8QdxE7EccR4xG2EMgR4xCEENkQ0RCiEdYQaBIgE/gRRhJgEQgR7xDGENcRqBLzEU4REhK+EaYRxRHAETgR0hB+EoUREBGsEVgQuxDxEKQT+RF/AZLvkuqV7sjuXe3U7mTuiu5u7j3u6+5t7p3vZO927oPuUe157YDu3+2q7TXvOe+a7iLvTu8P7xPuRu6L7gLu4u7D7pTuj+417nPuR+5s7pvuA+8g7znvG/Bi717sKe51/gkR4BTfESYR/hA+EeMRchGmEHoQIRGBEeoQiBFyES8RUBLOEekRRRICEUoRfhGnEHAQbhBxEHQQVhH2EfgRkxLtEVsRlhH1EEQRWBFMEc8QqhBwEa0QphCUEe8T7BFBAQHvnOqa7dbuA+9O717vMe9M79bvVe4P7hzu2+w37mvuNu5O7vTtHu9w72Tv0+6W7ibvvu6N7v/tbe5l7lDt1+3p7YTtiO0G7nHuJ+4v73Hv0u787vrtAu9776br4e0h/YcP8hSaETkRzhAtEJEQEBGmEckRqxH2EN4RERIPEWgRFBGgEA0R5BGCEfEQyhBxED8RjRHXEQISmhJTEoERwBJgEWAQLRGaECYRwxDBEDoRaxFHEf4QaBH4EDQUwhOFA+3wUevS7dnubO517jbuvO3E7W/ugO4d7hzupu3X7eTume5h7o/u/u2V7rDuNe6+7oPude4F7z3uju3D7aDtle2O7pLvWO9w74PvLO767uruP+407/Pt1O727n/qzesX+0YPRhWLEQwRZxE/EQ4SPBIIEc8QBhG3EP8QohFgETQQPBHREUsR3RG9EUcSNBLyEQATQRKDEfkRWhGcERMSFhEpESoRkBD/Dx0QaRHNEDoQ/BB9ER8SaBFVEUgUuhLEAh/w7upO7rzvVu4p74bvX+5Z7rntYO4T7x3vDe8t7lHuhO7I7TDuge5n7cPtWO4d7tjtce0Z79Hulu0a7xPvDe4T7vDuX+/57j/ufe547qDt3O7m7kruZu9k7+/rTuwc/IUPSBUzEmYR+xGLEKwQxBBSEKsQvRHnEY8QFhKtEucR2BFPERISgBEPEbcRhhF4EcIRzRHXESISExFlEWMR4xCXEaAQzBCDEWgRzBDlEKIRhRCOEJ8QixDtEJUTyBP7Ayfwquo97rPv2O6v7vHtm+4C7xrvJO+J7oDvz+667fvtKO6D7QjtiO7g7mju8O3O7VDu8+0V7kfuZu7W7nbuOO7h7tHuZe5e7rvuaO+f7jXvDPBD703vZu9i71HsvOzc+2kPvBWNEVAQhxCkEFwRkRAuEZwQFhDEENIQexEKEWERUhF5EGYR7RGbESMRShG6EboR1hGUEWsRhRGREZERJhEQEfoQbBEAEkgQMRB9EbsQChE4EXYQehAdEe4TzRLlA6jwC+s/7iHvke4H7lzuhe6B7t/uZu8h75Xu4u4E7kDub+8G7+zulO6I7mjuXe4D7mjtEe4N7gXuQO2a7Bnuu+7q7YftHe6C7tjuu+4x7mju8O5V71bvHO9C7vPrHe1D/PEPExWSEpYRzhE6EnYRRhEHEZ0RZxI2EkwRYRCVEAMRNhJUEQgQBRHKEGERMRK6ER0S+hFnEdERDBKDEaQR8RGbESMR7xD4EBQRbREKERwR/BBmESoSVxB5EPYTDhOTBK7xl+uy7ePu6+037Rjue+767WvuW+607arveO9E7r/uHu4A707vnO7X7nXvT+/67Urure6q7lzuZ+5Z7i7tKu6S7obuK+8j77zuIe7v7iDuaO5n73Puce8f70Ps9eyf+s0NvRSHEuQQ7hFBEtMR4BEwEcURihE4EToSghFREFwQWBAQET0SixHqEOsQhxEFEuwQzhAQEYYRNhKMEX8RcBEUEvwSFBE0EWoS6xCPESsSMxG+EXUR2hAgEXUT7xKxBFbx4Orb7STvHu+S7XXtIe8X7jfvKu/Q7cju5e027uvudO7k7gvvbO5e7jfvZ+6Z7gzv+e7O7sHtle7m7XLtGO5m7c/uMe957kbuYO7H7n7us+7S7iLvL+/77hTs0esF+0wOmRSdEVgQDxE9EFgRKRI3EuMS7xH2EYIRjBGtEgQSvxG7EeARCxJqEewQEhE0EnISpxFEEU8RYREQEaoR+BFXERoRsxAREaoQCBBOEXURKBFHEhQS0RAJEVAUDhRcBSHyg+sh7v3vNe8J7yfvKO7+7WLu4u6m7ovune6K7q7ufu5/78Dua+1j7o3uG+797Ivtu+387D7u4u3l7XLuQe697tLuee8j8D/wJO917+LvLO8g8HHvuu+t79fr9+zh+rENExRcEXEQSBFEEhYR2BD6EIEQZhFvEdwQzhByEYYRThFiEcgRQhKwEbQRChJQEoQSsRF4EWoStxI7EiQSkRGNESAS0BBoEKEQ6g9cEOAQohDmEA4RLRDqD/MS6RN9BjHy/+oJ7vfviu9U7yXv+O6s7wTvau947/Dtte717bPte+4O7Urtte2I7TbuX+5L7n7t6u0h7hLtYu0j7ozuc+4M7n7uIe+N77Dve+9K7xDvi+/x7wbvOu/G74bsNOzy+fkM7hNaEdwPlxAEEdkQFhE6EVERJhG0EOEQpBAmEfQRcxFZEvwRbRBZEagRRxEUEYYRhRJpEVERuBG9EDwRPBGVEFsRnxHFEQ8SGhLREaUQZRDiELIQQxBvEPATHBWlB67zAewQ7tXttuyM7ePttu0U7ensu+3A7Wbucu+c7zzwV+/v7YfvKPDm7nzuWu5j7v3tGu6k7mDttu2q7gbt0O1/7yzvBe9D7RvsqO0X7t3tRe5B76nvwu6E7hTsOOt1+csN4xQ8El8QqQ/kDzwRmhEGEpkSexK7EkYSthCXECoRWxGVEYwRuhH/Ef4RnRKMEogSihInEX0R8RFDEc8RKxEyEbgRHRE6ESARYBGHEb0QuhCWENcQKhHXEMsSfxNKBwH1Xu4+8F3wF+/r7hTuC+1u7FrrEexU7NzrfOyG7Lntcu5372nxI/HC8BvxMfEb8EHvLu8+7sju8O4r7jHumu6l7rntpu1P7Zvte+6h7eTtou7E7sLuQO/77uvqmur/9+YLeRRgEpMRexF3EckRVhHwETkSzRCjD8cQERG0EOgQTxBTECMQixEEE18S4REZESISYBIUElUTmhKZEmIScRHAEbER1xEIEbkQERHiEHgR8hDzEDcRrQ+uD10TdxKwBd/yiepG7l3w0+5t7hXuB+9Q7efstu6s7+Lvse3U7SPv5u698HrxpfBv78jvXPLt8HrwivCV7xruoOwX7M7tcO7h7srs9exg797wjPBn8ejv9e/Y7yLvcO6d7yTviO4h+XoMxRTjEmERJRJbEbkPkRCSEZcS2RC8D5ARZRMBEkURURCVEc4RuBJKE+EUAxSSEh0QKhDCDsUN6Q5rEqASkhHqD/IPMxCqEeESzhINETkQHRDXDyYQKBBWEU8UIBRMB4D0NusK78fwQO957n3u7uuJ60vrN+267bLtIu3/7RHtM+5H7y7wU+8p7/Tu8+6y7Tru2O4u7irsQ+v967vsp+xD7RfuVe5y79XvoO9E7k3tUu1p78jvqvDZ8AfxRu9n7JXrofjJCugTdRJiElYSmBJwEpoTChKKEUYRExE8D88OLA2KDdQOOxBuELIRgxITEzISHhK0EnoSsxGjEM0OUA9XEewQoBAtECkQqBAtESERyRLDEUoRShGAEfgP3BCXEw0UcAbe89rpPOwf7gfui+0+7mbt/u6r7jPucO1y7dvtp+5r7ajt/u1T7iDuNu4H72Lvmu9O75fuSe8d8OruP+8J7oPtP+5F76nuB+9+7tTuD+3b7TfuRu5j7vfwePAS7dzqd/fMCn8U7xJyEacRoRIdEqERBBHaECsRvhH/EQgSwxJbEm8RNRApEIkQXhFTD+APGBAhEDcQLxBkEDYSQxDiD24PexDaEIURIREnEpQREBEhEIIQ6BAHEPYPwRD1ERwTgweh9dXuKvCE8SXxivCu8JjwgO677kXtGe3k7D/rMOzr7bzuHPBO8NvvZe597xXv/e5Y71vvse5/7vXuWu7w7TztkO2Z757vI+/G7g/vQe9R7xLvPe4D7nzufu4Y66Xr3vjuDMwVmBKMEHwQNRHcDywP+xAmEZgRExG6EWUR8RDNEpgRSRFfESYRFBNeE0cSHBJeEnwSMRPvE3ITLBLXEp4SABHGEM4PhhAeEM8P+BCbD1URlBFwEJAPzw28EZETUQkH9oHsdu8B8Lnu8+3b7XXu8O517f/sfO1U7RLuhu1X7ujume9D7xru+u1L7f3uRO+471Pvs+4o7yLvbO/h7mLvZO4M7jrtuO3g7jDtwe2D7UPt7+y+7SHveu6d66PqMfbkCH8SsBHpESUSNBOsFMwUNhUsEw0TDRIiEcMQRRBhEDgQPBHVD+0PkhApEfgRrhE2EbEQChLMERQRrBDdEJsQ7RCPEhUTvBL7EN8RsREDEq8SiRHKEokS2BLWFbwWQwnO9Kzr9usa7r/tE+7s7Rjuwu+x8CfxbPDj74bwKvGx71fvzO5Q7mzu5+0X7uHsae0z7oDuUu5Y7f3tmO6n7yTvAO9Q7+Duvu7X7v3uo+2o7XvvVvDG8IPwNe8h7eDstPdfCn0TTBIDD2IO8A/lD3UQhhHsEL4QjBGZETQQpxBgEogRnxEJEmwRJhHGEB8RlRAcEIEQCREIEQkQ5g9JEEcQ8Q+aEEURGRE0EeMQwRFxElwSfBIeEpURihAkEyEVQAnl9Zbr3ewn74Tue+7W7iTvQu6U7THuKO407oXucO7j7Wnuc+4s7vruU+/f73rvKO8T8Gvvfe5j7/jvXO7S7RHu0O1i7pXuhu7c7Yzt3+697ortQu0O7sbtqO2l6+XpKvZDChoUQRKWDwcRVhElEXsSORMYE5IRzhAKEhUSdBDBEIwRehFBEeoPNRA3EAIQcRBCEVcS0BA7EHQQBxFtEW4QGRD9EP8Q/A+RD5AQehIVEo4S1BFlEd4RnxHRE7QTUgnd9tLtLO8z8ujwze9678HtWu4W7Sfuie5W7zPwPO8174DuAO6g7XPuu+2t7ZbtJO6K7nntiu4q7o7t0O2m7uruPe4Y7l/uju167arupu8Y8GTvqu/M7y/vue/07cvsMPjrCssUcxQCEwATihJjEi8SNBLgEpUT6xLXEYQRRRGfEUoSJhERELAPVA/MDxQPng/EEOML2AIu/0YAvgHZAaMAbgADALUAsgC2ANL//P7F/18AIgHBAB4BDwGMAdcAGQGwAdYBNwMHAt4AV/8V/37+If51/lf/\"")
-}
@@ -0,0 +1,77 @@
+//go:build !nobeep
+// +build !nobeep
+
+package sound
+
+import (
+	"time"
+
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/speaker"
+)
+
+func init() {
+	Register("beep", func(buffer time.Duration) (Audio, error) {
+		return NewBeep(buffer)
+	})
+}
+
+// Beep is an audio backend using faiface/beep.
+type Beep struct {
+	gen    generator
+	buffer time.Duration
+}
+
+// NewBeep initialises the speaker, buffering the given length of audio, and
+// returns a beep backend.
+func NewBeep(buffer time.Duration) (*Beep, error) {
+	sr := beep.SampleRate(sampleRate)
+	if err := speaker.Init(sr, sr.N(buffer)); err != nil {
+		return nil, err
+	}
+
+	b := &Beep{buffer: buffer}
+	speaker.Play(beep.StreamerFunc(b.stream))
+
+	return b, nil
+}
+
+// stream fills samples from the tone generator. It never ends.
+func (b *Beep) stream(samples [][2]float64) (int, bool) {
+	buf := make([]float64, len(samples))
+	b.gen.fill(buf)
+	for i, s := range buf {
+		samples[i][0], samples[i][1] = s, s
+	}
+	return len(samples), true
+}
+
+// StartTone implements Audio.
+func (b *Beep) StartTone() error {
+	b.gen.start(defaultPattern)
+	return nil
+}
+
+// StopTone implements Audio.
+func (b *Beep) StopTone() error {
+	b.gen.stop()
+	return nil
+}
+
+// PlayPattern implements Audio.
+func (b *Beep) PlayPattern(pattern [16]byte) error {
+	b.gen.start(pattern)
+	return nil
+}
+
+// Latency implements Audio.
+func (b *Beep) Latency() time.Duration {
+	return b.gen.latency(b.buffer)
+}
+
+// Close implements Audio. This version of beep can't close the speaker, so
+// the tone is stopped and the stream left playing silence.
+func (b *Beep) Close() error {
+	b.gen.stop()
+	return nil
+}
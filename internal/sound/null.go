@@ -0,0 +1,28 @@
+package sound
+
+import "time"
+
+func init() {
+	Register("null", func(time.Duration) (Audio, error) {
+		return Null{}, nil
+	})
+}
+
+// Null is an audio backend that makes no sound, for headless runs or
+// machines without a sound device.
+type Null struct{}
+
+// StartTone implements Audio.
+func (Null) StartTone() error { return nil }
+
+// StopTone implements Audio.
+func (Null) StopTone() error { return nil }
+
+// PlayPattern implements Audio.
+func (Null) PlayPattern([16]byte) error { return nil }
+
+// Latency implements Audio.
+func (Null) Latency() time.Duration { return 0 }
+
+// Close implements Audio.
+func (Null) Close() error { return nil }
@@ -0,0 +1,106 @@
+//go:build !nooto
+// +build !nooto
+
+package sound
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/oto"
+)
+
+// otoChunk is the number of samples written to the player at a time.
+const otoChunk = sampleRate / 60
+
+func init() {
+	Register("oto", func(buffer time.Duration) (Audio, error) {
+		return NewOto(buffer)
+	})
+}
+
+// Oto is an audio backend using hajimehoshi/oto directly.
+type Oto struct {
+	gen    generator
+	player *oto.Player
+	buffer time.Duration
+	done   chan struct{}
+}
+
+// NewOto opens the audio device, buffering the given length of audio, and
+// returns an oto backend. The buffer holds at least one chunk.
+func NewOto(buffer time.Duration) (*Oto, error) {
+	n := int(buffer.Seconds() * sampleRate)
+	if n < otoChunk {
+		n = otoChunk
+	}
+
+	p, err := oto.NewPlayer(sampleRate, 1, 2, n*2)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Oto{
+		player: p,
+		buffer: time.Duration(n) * time.Second / sampleRate,
+		done:   make(chan struct{}),
+	}
+	go o.play()
+
+	return o, nil
+}
+
+// play continually writes samples to the player, which blocks once its buffer
+// is full and so paces the loop.
+func (o *Oto) play() {
+	var (
+		samples = make([]float64, otoChunk)
+		buf     = make([]byte, otoChunk*2)
+	)
+
+	for {
+		select {
+		case <-o.done:
+			return
+		default:
+		}
+
+		o.gen.fill(samples)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint16(buf[i*2:], uint16(int16(s*math.MaxInt16)))
+		}
+		if _, err := o.player.Write(buf); err != nil {
+			return
+		}
+	}
+}
+
+// StartTone implements Audio.
+func (o *Oto) StartTone() error {
+	o.gen.start(defaultPattern)
+	return nil
+}
+
+// StopTone implements Audio.
+func (o *Oto) StopTone() error {
+	o.gen.stop()
+	return nil
+}
+
+// PlayPattern implements Audio.
+func (o *Oto) PlayPattern(pattern [16]byte) error {
+	o.gen.start(pattern)
+	return nil
+}
+
+// Latency implements Audio.
+func (o *Oto) Latency() time.Duration {
+	return o.gen.latency(o.buffer)
+}
+
+// Close implements Audio.
+func (o *Oto) Close() error {
+	close(o.done)
+	return o.player.Close()
+}
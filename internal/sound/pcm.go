@@ -0,0 +1,59 @@
+package sound
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+// SampleRate is the sample rate of the audio written by PCM.
+const SampleRate = sampleRate
+
+// PCM is an audio backend that generates the tone on demand rather than
+// playing it, for rendering audio offline, faster than real time. Its
+// samples are written with Write.
+type PCM struct {
+	gen generator
+	buf []float64
+	out []byte
+}
+
+// StartTone implements Audio.
+func (p *PCM) StartTone() error {
+	p.gen.start(defaultPattern)
+	return nil
+}
+
+// StopTone implements Audio.
+func (p *PCM) StopTone() error {
+	p.gen.stop()
+	return nil
+}
+
+// PlayPattern implements Audio.
+func (p *PCM) PlayPattern(pattern [16]byte) error {
+	p.gen.start(pattern)
+	return nil
+}
+
+// Latency implements Audio. Audio generated offline has none.
+func (p *PCM) Latency() time.Duration { return 0 }
+
+// Close implements Audio.
+func (p *PCM) Close() error { return nil }
+
+// Write writes the next n samples of the tone to w as signed 16-bit little
+// endian mono PCM at SampleRate, the s16le format of ffmpeg.
+func (p *PCM) Write(w io.Writer, n int) error {
+	if cap(p.buf) < n {
+		p.buf, p.out = make([]float64, n), make([]byte, 2*n)
+	}
+	buf, out := p.buf[:n], p.out[:2*n]
+
+	p.gen.fill(buf)
+	for i, s := range buf {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(s*0x7FFF)))
+	}
+	_, err := w.Write(out)
+	return err
+}
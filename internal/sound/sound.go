@@ -1,42 +1,170 @@
+// Package sound implements the audio output of the emulator. The Chip8 has a
+// single tone which sounds while the sound timer is non-zero; backends play
+// it through the system audio or not at all.
 package sound
 
 import (
-	"bytes"
-	"io/ioutil"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
+)
+
+// Audio is an audio backend.
+type Audio interface {
+	// StartTone starts playing the tone. It carries on until StopTone is
+	// called.
+	StartTone() error
+
+	// StopTone stops playing the tone.
+	StopTone() error
+
+	// PlayPattern starts playing a 128 sample, 1-bit waveform on loop in
+	// place of the default tone, as used by XO-CHIP. It carries on until
+	// StopTone is called.
+	PlayPattern(pattern [16]byte) error
+
+	// Latency returns the delay between the tone last being started and it
+	// being heard, as measured by the backend, or zero if it hasn't been.
+	Latency() time.Duration
+
+	// Close releases the audio device.
+	Close() error
+}
+
+// Factory opens an audio backend, queueing buffer of audio ahead of the
+// device.
+type Factory func(buffer time.Duration) (Audio, error)
+
+// backends are the audio backends registered, by name.
+var backends = map[string]Factory{}
+
+// Register makes an audio backend available to New as name. Backends register
+// themselves from init, each in a file of this package, optionally behind a
+// build tag. It panics if name is already registered.
+func Register(name string, f Factory) {
+	if _, ok := backends[name]; ok {
+		panic(fmt.Sprintf("sound: backend %q registered twice", name))
+	}
+	backends[name] = f
+}
+
+// Backends returns the names of the registered audio backends, sorted.
+func Backends() []string {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultBuffer is the default length of audio queued ahead of the device.
+// Shorter buffers bring the tone closer to the display but may crackle on
+// slow machines.
+const DefaultBuffer = 33 * time.Millisecond
+
+// New returns the audio backend called name, queueing buffer of audio ahead
+// of the device.
+func New(name string, buffer time.Duration) (Audio, error) {
+	f, ok := backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown audio backend %q, expected one of %q", name, Backends())
+	}
+	return f(buffer)
+}
+
+const (
+	// sampleRate is the output sample rate of the backends.
+	sampleRate = 44100
+
+	// patternRate is the rate at which pattern bits are played back, the
+	// XO-CHIP default.
+	patternRate = 4000
 
-	"github.com/faiface/beep"
-	"github.com/faiface/beep/speaker"
-	"github.com/faiface/beep/wav"
-	"github.com/gobuffalo/packr"
+	// volume is the amplitude of the generated wave.
+	volume = 0.2
 )
 
-var box = packr.NewBox("./data")
+// defaultPattern is a 500Hz square wave when played at patternRate.
+var defaultPattern = [16]byte{
+	0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0,
+	0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0, 0xF0,
+}
+
+// generator produces the samples of the tone. It is shared between backends
+// and safe for concurrent use.
+type generator struct {
+	mu      sync.Mutex
+	on      bool
+	pattern [16]byte
+
+	// Position within the pattern, in bits.
+	phase float64
+
+	// When the tone was last started, until its first sample is generated,
+	// and the time it took to do so.
+	started time.Time
+	delay   time.Duration
+}
+
+// start starts the tone with the given pattern.
+func (g *generator) start(pattern [16]byte) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-// Beep makes a beep sound.
-func Beep() error {
-	b, err := box.Find("beep.wav")
-	if err != nil {
-		return err
+	if !g.on {
+		g.started = time.Now()
 	}
+	g.on, g.pattern = true, pattern
+}
+
+// stop silences the tone.
+func (g *generator) stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.on, g.phase = false, 0
+}
+
+// latency returns the time taken to generate the first samples of the tone
+// when last started, plus buffer, the audio queued ahead of the device.
+func (g *generator) latency(buffer time.Duration) time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	s, format, err := wav.Decode(ioutil.NopCloser(bytes.NewReader(b)))
-	if err != nil {
-		return err
+	if g.delay == 0 {
+		return 0
 	}
+	return g.delay + buffer
+}
+
+// fill fills buf with the next samples, each between -1 and 1.
+func (g *generator) fill(buf []float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
-	if err = speaker.Init(
-		format.SampleRate,
-		format.SampleRate.N(time.Second/10),
-	); err != nil {
-		return err
+	if g.on && !g.started.IsZero() {
+		g.delay = time.Since(g.started)
+		g.started = time.Time{}
 	}
 
-	done := make(chan struct{})
-	speaker.Play(beep.Seq(s, beep.Callback(func() {
-		close(done)
-	})))
-	<-done
+	for i := range buf {
+		if !g.on {
+			buf[i] = 0
+			continue
+		}
 
-	return nil
+		bit := int(g.phase) % 128
+		if g.pattern[bit/8]>>(7-uint(bit%8))&1 == 1 {
+			buf[i] = volume
+		} else {
+			buf[i] = -volume
+		}
+
+		g.phase += patternRate / float64(sampleRate)
+		if g.phase >= 128 {
+			g.phase -= 128
+		}
+	}
 }
@@ -0,0 +1,148 @@
+// Package sprites records the sprites a program draws and renders them, so a
+// game's graphics can be seen apart from the code around them.
+package sprites
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Sprite is a sprite in memory as DXYN draws it.
+type Sprite struct {
+	Addr uint16
+
+	// Width is 8, or 16 for the SUPER-CHIP's 16x16 sprites, and Height the
+	// number of rows.
+	Width, Height int
+
+	// Data is the sprite's bytes when first drawn, a byte a row or two for
+	// wide sprites.
+	Data []byte
+
+	// Draws is the number of times it was drawn.
+	Draws int
+}
+
+// Decode returns the sprite of the given width and height at addr in mem.
+// Bytes past the end of mem are read as zero.
+func Decode(mem []byte, addr uint16, width, height int) Sprite {
+	s := Sprite{Addr: addr, Width: width, Height: height}
+	s.Data = make([]byte, height*width/8)
+	for i := range s.Data {
+		if a := int(addr) + i; a < len(mem) {
+			s.Data[i] = mem[a]
+		}
+	}
+	return s
+}
+
+// Lit returns true if the pixel at x, y of the sprite is set.
+func (s Sprite) Lit(x, y int) bool {
+	b := s.Data[y*s.Width/8+x/8]
+	return b&(0x80>>uint(x%8)) != 0
+}
+
+// Image returns the sprite as an image, a pixel each, lit pixels in fg on bg.
+func (s Sprite) Image(fg, bg color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, s.Width, s.Height))
+	for y := 0; y < s.Height; y++ {
+		for x := 0; x < s.Width; x++ {
+			c := bg
+			if s.Lit(x, y) {
+				c = fg
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// key identifies a sprite drawn.
+type key struct {
+	addr          uint16
+	width, height int
+}
+
+// Recorder records the sprites drawn by a VM.
+type Recorder struct {
+	vm      *chip8.VM
+	sprites map[key]*Sprite
+}
+
+// Record returns a recorder of the sprites vm draws from now on.
+func Record(vm *chip8.VM) *Recorder {
+	r := &Recorder{vm: vm, sprites: make(map[key]*Sprite)}
+	vm.OnInstruction(r.instruction)
+	return r
+}
+
+// instruction records the sprite drawn if opc is DXYN.
+func (r *Recorder) instruction(pc, opc uint16) {
+	if opc&0xF000 != 0xD000 {
+		return
+	}
+
+	w, h := 8, int(opc&0xF)
+	if h == 0 {
+		vr := r.vm.Variant()
+		if vr != chip8.SChip && vr != chip8.XOChip {
+			return
+		}
+		w, h = 16, 16
+	}
+
+	k := key{uint16(r.vm.I()), w, h}
+	s, ok := r.sprites[k]
+	if !ok {
+		s = &Sprite{Addr: k.addr, Width: w, Height: h, Data: make([]byte, w*h/8)}
+		for i := range s.Data {
+			s.Data[i] = r.vm.Peek(k.addr + uint16(i))
+		}
+		r.sprites[k] = s
+	}
+	s.Draws++
+}
+
+// Sprites returns the sprites drawn, by address then size.
+func (r *Recorder) Sprites() []Sprite {
+	var all []Sprite
+	for _, s := range r.sprites {
+		all = append(all, *s)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Addr != all[j].Addr {
+			return all[i].Addr < all[j].Addr
+		}
+		return all[i].Width*all[i].Height < all[j].Width*all[j].Height
+	})
+	return all
+}
+
+// Sheet lays sprites out left to right in rows of cols, each drawn at scale
+// in a cell big enough for a 16x16 sprite, lit pixels in fg on bg.
+func Sheet(sprites []Sprite, cols, scale int, fg, bg color.Color) *image.RGBA {
+	const gap = 2
+	cell := (16 + gap) * scale
+	rows := (len(sprites) + cols - 1) / cols
+	if len(sprites) < cols {
+		cols = len(sprites)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cols*cell, rows*cell))
+	for i, s := range sprites {
+		ox, oy := i%cols*cell, i/cols*cell
+		for y := 0; y < s.Height*scale; y++ {
+			for x := 0; x < s.Width*scale; x++ {
+				c := bg
+				if s.Lit(x/scale, y/scale) {
+					c = fg
+				}
+				img.Set(ox+x, oy+y, c)
+			}
+		}
+	}
+	return img
+}
@@ -0,0 +1,77 @@
+package sprites
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestRecord(t *testing.T) {
+	rom := []byte{
+		0xA2, 0x0C, // I = 0x20C.
+		0xD0, 0x02, // Draw 2 rows.
+		0xD0, 0x02, // And again.
+		0xA2, 0x0E, // I = 0x20E.
+		0xD0, 0x01, // Draw 1 row.
+		0x12, 0x0A, // Halt.
+		0xF0, 0x81, // The sprites.
+		0x3C,
+	}
+
+	vm := chip8.New()
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	r := Record(vm)
+	for i := 0; i < 6; i++ {
+		if err := vm.Cycle(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := r.Sprites()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 sprites, got %+v", got)
+	}
+	if s := got[0]; s.Addr != 0x20C || s.Height != 2 || s.Draws != 2 || !bytes.Equal(s.Data, []byte{0xF0, 0x81}) {
+		t.Fatalf("unexpected first sprite %+v", s)
+	}
+	if s := got[1]; s.Addr != 0x20E || s.Height != 1 || s.Draws != 1 {
+		t.Fatalf("unexpected second sprite %+v", s)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	s := Decode([]byte{0x00, 0x80, 0x01}, 1, 8, 3)
+	if !s.Lit(0, 0) || s.Lit(1, 0) || !s.Lit(7, 1) {
+		t.Fatalf("unexpected pixels in %+v", s)
+	}
+	if s.Data[2] != 0 {
+		t.Fatal("expected bytes past the end of memory to be zero")
+	}
+
+	wide := Decode(make([]byte, 32), 0, 16, 16)
+	if len(wide.Data) != 32 {
+		t.Fatalf("expected 32 bytes for a 16x16 sprite, got %d", len(wide.Data))
+	}
+}
+
+func TestSheet(t *testing.T) {
+	s := Decode([]byte{0x80}, 0, 8, 1)
+	img := Sheet([]Sprite{s, s, s}, 2, 2, color.White, color.Black)
+
+	if b := img.Bounds(); b.Dx() != 2*36 || b.Dy() != 2*36 {
+		t.Fatalf("expected a 72x72 sheet, got %v", b)
+	}
+	if r, _, _, _ := img.At(1, 1).RGBA(); r == 0 {
+		t.Fatal("expected the lit pixel to be scaled")
+	}
+	if r, _, _, _ := img.At(36+2, 0).RGBA(); r != 0 {
+		t.Fatal("expected the unlit pixel to be the background")
+	}
+	if r, _, _, _ := img.At(1, 36+1).RGBA(); r == 0 {
+		t.Fatal("expected the third sprite on the second row")
+	}
+}
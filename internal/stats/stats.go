@@ -0,0 +1,140 @@
+// Package stats keeps statistics of the ROMs played, for the ROM browser to
+// show: how long each has been played, how often it's been launched, the
+// longest session and when it was last played. They're kept in a file in the
+// user's config directory and never leave the machine.
+//
+// Setting "disabled" in the file stops statistics being kept:
+//
+//	{"disabled": true}
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Stats are the statistics of the ROMs played.
+type Stats struct {
+	// Disabled is set by the user to stop statistics being kept.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// ROMs are the statistics of each ROM, by absolute path.
+	ROMs map[string]*ROM `json:"roms,omitempty"`
+}
+
+// ROM are the statistics of a ROM.
+type ROM struct {
+	Launches int `json:"launches"`
+
+	// Played is the time spent playing the ROM, and Longest the longest
+	// session, in seconds. Time paused isn't counted.
+	Played  int64 `json:"played"`
+	Longest int64 `json:"longest"`
+
+	LastPlayed time.Time `json:"lastPlayed"`
+}
+
+// Path returns the path of the statistics file, in the user's config
+// directory.
+func Path() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chip8", "stats.json"), nil
+}
+
+// Load reads the statistics at path, which are empty if there's no file.
+func Load(path string) (*Stats, error) {
+	s := &Stats{}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, s); err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the statistics to path.
+func (s *Stats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// key returns the key of the ROM at path, its absolute path.
+func key(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// ROM returns the statistics of the ROM at path, nil if it's never been
+// played.
+func (s *Stats) ROM(path string) *ROM {
+	return s.ROMs[key(path)]
+}
+
+// Record records a session of the ROM at path, played for the given time and
+// ending at end. Nothing is recorded if the statistics are disabled.
+func (s *Stats) Record(path string, played time.Duration, end time.Time) {
+	if s.Disabled {
+		return
+	}
+	if s.ROMs == nil {
+		s.ROMs = make(map[string]*ROM)
+	}
+	r := s.ROM(path)
+	if r == nil {
+		r = &ROM{}
+		s.ROMs[key(path)] = r
+	}
+
+	secs := int64(played / time.Second)
+	r.Launches++
+	r.Played += secs
+	if secs > r.Longest {
+		r.Longest = secs
+	}
+	r.LastPlayed = end
+}
+
+// MostPlayed returns the statistics of the ROM played for longest, nil if
+// none have been played.
+func (s *Stats) MostPlayed() *ROM {
+	var most *ROM
+	for _, r := range s.ROMs {
+		if most == nil || r.Played > most.Played || (r.Played == most.Played && r.LastPlayed.After(most.LastPlayed)) {
+			most = r
+		}
+	}
+	return most
+}
+
+// SortByLastPlayed sorts the paths of ROMs with the most recently played
+// first and those never played after them, keeping their order.
+func (s *Stats) SortByLastPlayed(paths []string) {
+	sort.SliceStable(paths, func(i, j int) bool {
+		a, b := s.ROM(paths[i]), s.ROM(paths[j])
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.LastPlayed.After(b.LastPlayed)
+	})
+}
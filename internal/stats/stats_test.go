@@ -0,0 +1,71 @@
+package stats
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecord(t *testing.T) {
+	s := &Stats{}
+	start := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	s.Record("/a.ch8", 90*time.Second, start)
+	s.Record("/a.ch8", 30*time.Second, start.Add(time.Hour))
+	s.Record("/b.ch8", 100*time.Second, start.Add(2*time.Hour))
+
+	a := s.ROMs["/a.ch8"]
+	exp := &ROM{Launches: 2, Played: 120, Longest: 90, LastPlayed: start.Add(time.Hour)}
+	if !reflect.DeepEqual(a, exp) {
+		t.Fatalf("expected %+v, got %+v", exp, a)
+	}
+
+	if most := s.MostPlayed(); most != s.ROM("/a.ch8") {
+		t.Fatalf("expected /a.ch8 to be the most played, got %+v", most)
+	}
+
+	paths := []string{"/c.ch8", "/a.ch8", "/d.ch8", "/b.ch8"}
+	s.SortByLastPlayed(paths)
+	if exp := []string{"/b.ch8", "/a.ch8", "/c.ch8", "/d.ch8"}; !reflect.DeepEqual(paths, exp) {
+		t.Fatalf("expected %q, got %q", exp, paths)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	s := &Stats{Disabled: true}
+	s.Record("/a.ch8", time.Minute, time.Now())
+	if len(s.ROMs) != 0 {
+		t.Fatal("expected nothing recorded while disabled")
+	}
+	if s.MostPlayed() != nil {
+		t.Fatal("expected no ROM to be the most played")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "chip8", "stats.json")
+
+	s, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Record("/a.ch8", time.Minute, time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC))
+	if err = s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, s) {
+		t.Fatalf("expected %+v, got %+v", s, got)
+	}
+}
@@ -0,0 +1,174 @@
+// Package supervisor hosts several independent, headless VMs in one process
+// and runs them concurrently, e.g. to verify a batch of ROMs or to train
+// against many environments at once.
+package supervisor
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Config configures an instance.
+type Config struct {
+	// The ROM to run and the variant to run it as.
+	ROM     []byte
+	Variant chip8.Variant
+
+	// Seed seeds the random numbers generated by the program.
+	Seed int64
+
+	// Speed is the number of frames the instance runs for each frame run by
+	// the supervisor, 1 if zero.
+	Speed int
+}
+
+// instance is a VM and its configuration. The VM is locked while a frame is
+// running.
+type instance struct {
+	mu    sync.Mutex
+	vm    *chip8.VM
+	speed int
+	err   error
+}
+
+// Supervisor runs instances.
+type Supervisor struct {
+	mu        sync.RWMutex
+	instances []*instance
+}
+
+// New returns a supervisor with no instances.
+func New() *Supervisor {
+	return &Supervisor{}
+}
+
+// Add adds an instance running cfg, returning its ID.
+func (s *Supervisor) Add(cfg Config) (id int, err error) {
+	vm := chip8.NewVariant(cfg.Variant)
+	vm.Seed(cfg.Seed)
+	if err = vm.Load(bytes.NewReader(cfg.ROM)); err != nil {
+		return 0, fmt.Errorf("load ROM: %s", err)
+	}
+
+	in := &instance{vm: vm, speed: cfg.Speed}
+	if in.speed < 1 {
+		in.speed = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.instances = append(s.instances, in)
+
+	return len(s.instances) - 1, nil
+}
+
+// Len returns the number of instances.
+func (s *Supervisor) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.instances)
+}
+
+// Run runs every instance for the given number of frames, each scaled by the
+// instance's speed, concurrently. Instances that fail stop running, Err
+// returns why. Run returns the first error, by instance ID.
+func (s *Supervisor) Run(frames int) error {
+	s.mu.RLock()
+	instances := s.instances
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, in := range instances {
+		wg.Add(1)
+		go func(in *instance) {
+			defer wg.Done()
+			in.run(frames * in.speed)
+		}(in)
+	}
+	wg.Wait()
+
+	for id := range instances {
+		if err := s.Err(id); err != nil {
+			return fmt.Errorf("instance %d: %s", id, err)
+		}
+	}
+
+	return nil
+}
+
+// run steps the instance the given number of frames, locking it for each so
+// the display can be read in between. Halted programs are left alone.
+func (in *instance) run(frames int) {
+	for f := 0; f < frames; f++ {
+		in.mu.Lock()
+		if in.err == nil {
+			in.err = in.vm.StepFrame()
+		}
+		stop := in.err != nil || in.vm.Halted()
+		in.mu.Unlock()
+
+		// There's nothing left to run once the program halts.
+		if stop {
+			return
+		}
+	}
+}
+
+// get returns the instance with the given ID.
+func (s *Supervisor) get(id int) (*instance, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id < 0 || id >= len(s.instances) {
+		return nil, fmt.Errorf("no instance %d", id)
+	}
+	return s.instances[id], nil
+}
+
+// Frame returns the display of instance id, as returned by VM.Frame, and its
+// dimensions. It may be called while the instances are running.
+func (s *Supervisor) Frame(id int) (frame []bool, w, h int, err error) {
+	in, err := s.get(id)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	d := in.vm.Display()
+	return in.vm.Frame(), d.Width(), d.Height(), nil
+}
+
+// SetKeys sets the keys held by instance id.
+func (s *Supervisor) SetKeys(id int, keys [16]bool) error {
+	in, err := s.get(id)
+	if err != nil {
+		return err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.vm.SetKeys(keys)
+
+	return nil
+}
+
+// Err returns the error that stopped instance id, if any.
+func (s *Supervisor) Err(id int) error {
+	in, err := s.get(id)
+	if err != nil {
+		return err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.err
+}
@@ -0,0 +1,83 @@
+package supervisor
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// random draws a pixel at a random position each frame.
+var random = []byte{
+	0xA2, 0x0C, // I = sprite.
+	0xC0, 0x3F, // V0 = rand & 63.
+	0xC1, 0x1F, // V1 = rand & 31.
+	0xD0, 0x11, // Draw at (V0, V1).
+	0x12, 0x02, // Loop.
+	0x00, 0x00,
+	0x80, // Sprite.
+}
+
+func TestSupervisor(t *testing.T) {
+	s := New()
+	for _, cfg := range []Config{
+		{ROM: random, Seed: 1},
+		{ROM: random, Seed: 1, Speed: 2},
+		{ROM: random, Seed: 2},
+		{ROM: []byte{0x1F, 0xFF}, Variant: chip8.Chip8}, // Runs off the end of memory.
+	} {
+		if _, err := s.Add(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.Len() != 4 {
+		t.Fatalf("expected 4 instances, got %d", s.Len())
+	}
+
+	if err := s.Run(5); err == nil {
+		t.Fatal("expected the error from instance 3")
+	}
+	for id := 0; id < 3; id++ {
+		if err := s.Err(id); err != nil {
+			t.Fatalf("instance %d: %s", id, err)
+		}
+	}
+
+	frame := func(id int) []bool {
+		f, w, h, err := s.Frame(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w != chip8.DisplayWidth || h != chip8.DisplayHeight {
+			t.Fatalf("expected a %dx%d frame, got %dx%d", chip8.DisplayWidth, chip8.DisplayHeight, w, h)
+		}
+		return f
+	}
+
+	// The same seed run for the same number of frames draws the same pixels,
+	// other seeds and speeds differ.
+	want := chip8.New()
+	want.Seed(1)
+	if err := want.Load(bytes.NewReader(random)); err != nil {
+		t.Fatal(err)
+	}
+	for f := 0; f < 5; f++ {
+		if err := want.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !reflect.DeepEqual(frame(0), want.Frame()) {
+		t.Fatal("expected instance 0 to match a VM run alone")
+	}
+	if reflect.DeepEqual(frame(0), frame(1)) {
+		t.Fatal("expected instance 1 to have run further")
+	}
+	if reflect.DeepEqual(frame(0), frame(2)) {
+		t.Fatal("expected instance 2 to differ with another seed")
+	}
+
+	if _, _, _, err := s.Frame(4); err == nil {
+		t.Fatal("expected an error for a missing instance")
+	}
+}
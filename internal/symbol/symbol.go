@@ -0,0 +1,250 @@
+// Package symbol implements the symbol file format shared by the chip8
+// tooling, so the assembler, disassembler, tracer and debugger all show the
+// same names for addresses and data blocks.
+//
+// A symbol file is plain text with one symbol per line:
+//
+//	<address> <name> [code|data [size]]
+//
+// Addresses and sizes are hexadecimal, with or without a 0x prefix. The kind
+// defaults to code. Blank lines and lines beginning with # are ignored.
+package symbol
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind describes what lives at a symbol's address.
+type Kind int
+
+const (
+	// Code marks an address that is executed, e.g. a label or subroutine.
+	Code Kind = iota
+
+	// Data marks the start of a block of bytes that are only ever read, e.g.
+	// a sprite or lookup table.
+	Data
+)
+
+// String returns the name of the kind as it appears in a symbol file.
+func (k Kind) String() string {
+	if k == Data {
+		return "data"
+	}
+	return "code"
+}
+
+// Symbol is a named address.
+type Symbol struct {
+	Addr uint16
+	Name string
+	Kind Kind
+
+	// Size is the length of a data block in bytes. Zero for code.
+	Size uint16
+}
+
+// contains returns true if addr falls within the symbol.
+func (s Symbol) contains(addr uint16) bool {
+	if addr == s.Addr {
+		return true
+	}
+	return s.Kind == Data && addr > s.Addr && uint32(addr) < uint32(s.Addr)+uint32(s.Size)
+}
+
+// Table is a set of symbols indexed by both address and name.
+type Table struct {
+	byAddr map[uint16]Symbol
+	byName map[string]Symbol
+}
+
+// NewTable returns an empty symbol table.
+func NewTable() *Table {
+	return &Table{
+		byAddr: make(map[uint16]Symbol),
+		byName: make(map[string]Symbol),
+	}
+}
+
+// Add adds s to the table. An error is returned if the name or address is
+// already in use.
+func (t *Table) Add(s Symbol) error {
+	if s.Name == "" || strings.ContainsAny(s.Name, " \t#") {
+		return fmt.Errorf("invalid symbol name %q", s.Name)
+	}
+	if _, ok := t.byName[s.Name]; ok {
+		return fmt.Errorf("duplicate symbol %q", s.Name)
+	}
+	if o, ok := t.byAddr[s.Addr]; ok {
+		return fmt.Errorf("symbol %q has the same address as %q: 0x%03X", s.Name, o.Name, s.Addr)
+	}
+
+	t.byAddr[s.Addr] = s
+	t.byName[s.Name] = s
+
+	return nil
+}
+
+// Lookup returns the symbol at exactly addr.
+func (t *Table) Lookup(addr uint16) (Symbol, bool) {
+	if t == nil {
+		return Symbol{}, false
+	}
+	s, ok := t.byAddr[addr]
+	return s, ok
+}
+
+// Addr returns the address of the symbol called name.
+func (t *Table) Addr(name string) (uint16, bool) {
+	if t == nil {
+		return 0, false
+	}
+	s, ok := t.byName[name]
+	return s.Addr, ok
+}
+
+// Describe returns a human readable name for addr. Addresses inside a data
+// block are described as an offset from its start, e.g. "sprites+3". An empty
+// string is returned if no symbol covers addr.
+func (t *Table) Describe(addr uint16) string {
+	if t == nil {
+		return ""
+	}
+	if s, ok := t.byAddr[addr]; ok {
+		return s.Name
+	}
+	for _, s := range t.byAddr {
+		if s.contains(addr) {
+			return fmt.Sprintf("%s+%d", s.Name, addr-s.Addr)
+		}
+	}
+	return ""
+}
+
+// Symbols returns all symbols in the table ordered by address.
+func (t *Table) Symbols() []Symbol {
+	if t == nil {
+		return nil
+	}
+	syms := make([]Symbol, 0, len(t.byAddr))
+	for _, s := range t.byAddr {
+		syms = append(syms, s)
+	}
+	sort.Slice(syms, func(i, j int) bool {
+		return syms[i].Addr < syms[j].Addr
+	})
+	return syms
+}
+
+// Len returns the number of symbols in the table.
+func (t *Table) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.byAddr)
+}
+
+// Write writes the table to w in the symbol file format.
+func (t *Table) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, s := range t.Symbols() {
+		var err error
+		switch s.Kind {
+		case Data:
+			_, err = fmt.Fprintf(bw, "0x%03X %s data 0x%X\n", s.Addr, s.Name, s.Size)
+		default:
+			_, err = fmt.Fprintf(bw, "0x%03X %s\n", s.Addr, s.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Parse reads a symbol table from r.
+func Parse(r io.Reader) (*Table, error) {
+	t := NewTable()
+
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		s, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+		if err = t.Add(s); err != nil {
+			return nil, fmt.Errorf("line %d: %s", n, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Load reads the symbol file at path.
+func Load(path string) (*Table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// parseLine parses a single non-empty symbol file line.
+func parseLine(line string) (Symbol, error) {
+	var s Symbol
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 4 {
+		return s, fmt.Errorf("expected <address> <name> [code|data [size]], got %q", line)
+	}
+
+	addr, err := parseHex(fields[0])
+	if err != nil {
+		return s, fmt.Errorf("invalid address %q", fields[0])
+	}
+	s.Addr, s.Name = addr, fields[1]
+
+	if len(fields) > 2 {
+		switch fields[2] {
+		case "code":
+			s.Kind = Code
+		case "data":
+			s.Kind = Data
+		default:
+			return s, fmt.Errorf("unknown symbol kind %q", fields[2])
+		}
+	}
+	if len(fields) > 3 {
+		if s.Kind != Data {
+			return s, fmt.Errorf("size is only valid for data symbols")
+		}
+		if s.Size, err = parseHex(fields[3]); err != nil {
+			return s, fmt.Errorf("invalid size %q", fields[3])
+		}
+	}
+
+	return s, nil
+}
+
+// parseHex parses a 16-bit hexadecimal number with an optional 0x prefix.
+func parseHex(s string) (uint16, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	n, err := strconv.ParseUint(s, 16, 16)
+	return uint16(n), err
+}
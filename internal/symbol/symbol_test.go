@@ -0,0 +1,84 @@
+package symbol
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	src := `
+# comment
+0x200 start
+2F0 draw code
+0x31A paddle data 0x6
+`
+	tbl, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tbl.Len() != 3 {
+		t.Fatalf("expected 3 symbols, got %d", tbl.Len())
+	}
+	if addr, ok := tbl.Addr("draw"); !ok || addr != 0x2F0 {
+		t.Fatalf("expected draw at 0x2F0, got 0x%X", addr)
+	}
+
+	tests := map[uint16]string{
+		0x200: "start",
+		0x31A: "paddle",
+		0x31D: "paddle+3",
+		0x320: "",
+		0x2F2: "",
+	}
+	for addr, exp := range tests {
+		if got := tbl.Describe(addr); got != exp {
+			t.Errorf("0x%X: expected %q, got %q", addr, exp, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"0x200",
+		"zzz start",
+		"0x200 start weird",
+		"0x200 start code 4",
+		"0x200 a\n0x202 a",
+		"0x200 a\n0x200 b",
+	}
+	for _, src := range tests {
+		if _, err := Parse(strings.NewReader(src)); err == nil {
+			t.Errorf("%q: expected error", src)
+		}
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	tbl := NewTable()
+	for _, s := range []Symbol{
+		{Addr: 0x31A, Name: "paddle", Kind: Data, Size: 6},
+		{Addr: 0x200, Name: "start"},
+	} {
+		if err := tbl.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := tbl.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if exp := "0x200 start\n0x31A paddle data 0x6\n"; buf.String() != exp {
+		t.Fatalf("expected %q, got %q", exp, buf.String())
+	}
+
+	got, err := Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Describe(0x31B) != "paddle+1" {
+		t.Fatalf("round trip lost data block size")
+	}
+}
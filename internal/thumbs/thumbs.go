@@ -0,0 +1,156 @@
+// Package thumbs renders thumbnails of ROMs for the ROM browser, each the
+// display after a few seconds of running without input.
+//
+// Each ROM runs headlessly on a VM of its own seeded the same every time, so
+// ROMs render in parallel and a ROM always renders the same thumbnail.
+// Thumbnails are kept in a Store as PNGs named by the SHA1 of the ROM, so
+// they follow ROMs that are renamed or moved.
+package thumbs
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// Store is the directory thumbnails are kept in, created as they're saved.
+type Store string
+
+// DefaultStore returns the store in the user's config directory.
+func DefaultStore() (Store, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return Store(filepath.Join(dir, "chip8", "thumbs")), nil
+}
+
+// Path returns the path of the thumbnail of rom.
+func (s Store) Path(rom []byte) string {
+	sum := sha1.Sum(rom)
+	return s.PathOf(hex.EncodeToString(sum[:]))
+}
+
+// PathOf returns the path of the thumbnail of the ROM with the hex encoded
+// SHA1 hash, for callers that have hashed it already.
+func (s Store) PathOf(sha1 string) string {
+	return filepath.Join(string(s), sha1+".png")
+}
+
+// Has returns whether there's a thumbnail of rom.
+func (s Store) Has(rom []byte) bool {
+	_, err := os.Stat(s.Path(rom))
+	return err == nil
+}
+
+// Save saves img as the thumbnail of rom, replacing any there.
+func (s Store) Save(rom []byte, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(string(s), 0755); err != nil {
+		return err
+	}
+	return writeFile(s.Path(rom), buf.Bytes())
+}
+
+// Load returns the thumbnail of rom. The error satisfies os.IsNotExist if
+// there isn't one.
+func (s Store) Load(rom []byte) (image.Image, error) {
+	f, err := os.Open(s.Path(rom))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return png.Decode(f)
+}
+
+// writeFile writes data to path through a temporary file, so that browsers
+// reading thumbnails while they're rendered never see part of one.
+func writeFile(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Render returns the display after running rom for frames 60Hz frames, or
+// until it halts, on the variant it's detected as. The display is returned
+// with the error if the ROM stops with one.
+func Render(rom []byte, frames int) (*image.Paletted, error) {
+	vr, _ := chip8.Detect(rom)
+	vm := chip8.NewVariant(vr)
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		return nil, err
+	}
+	vm.Seed(0)
+
+	var err error
+	for f := 0; f < frames && !vm.Halted() && err == nil; f++ {
+		err = vm.StepFrame()
+	}
+
+	// VM.Image is a view of the display, so the pixels are copied.
+	img := vm.Image()
+	img.Pix = append([]byte(nil), img.Pix...)
+	return img, err
+}
+
+// Job is a ROM to render.
+type Job struct {
+	// Name names the ROM in its Result, such as its path.
+	Name string
+	ROM  []byte
+}
+
+// Result is the thumbnail of a ROM, or the error rendering it.
+type Result struct {
+	Job   Job
+	Image *image.Paletted
+	Err   error
+}
+
+// RenderAll renders jobs for frames each on workers goroutines, returning the
+// results in the order of jobs. done, if set, is called with each result as
+// it's rendered, from the worker rendering it.
+func RenderAll(jobs []Job, frames, workers int, done func(Result)) []Result {
+	if workers < 1 {
+		workers = 1
+	}
+
+	res := make([]Result, len(jobs))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				r := Result{Job: jobs[i]}
+				r.Image, r.Err = Render(jobs[i].ROM, frames)
+				res[i] = r
+				if done != nil {
+					done(r)
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	return res
+}
@@ -0,0 +1,94 @@
+package thumbs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+)
+
+// random draws a random pattern at (0, 0) each frame, so its thumbnail
+// depends on the VM's seed.
+var random = []byte{
+	0x00, 0xE0, // Clear.
+	0xC0, 0xFF, // V0 = random.
+	0xA2, 0x0E, // I = pattern.
+	0xF0, 0x55, // Store V0 at I.
+	0xD1, 0x11, // Draw at (0, 0).
+	0xF1, 0x0A, // Wait for a key.
+	0x12, 0x0A, // Loop.
+	0x00, // Pattern.
+}
+
+func TestRenderAll(t *testing.T) {
+	var jobs []Job
+	for i := 0; i < 8; i++ {
+		jobs = append(jobs, Job{Name: string(rune('a' + i)), ROM: random})
+	}
+	jobs[3].ROM = []byte{0x00, 0xEE}
+
+	var mu sync.Mutex
+	called := 0
+	res := RenderAll(jobs, 10, 4, func(Result) {
+		mu.Lock()
+		called++
+		mu.Unlock()
+	})
+	if called != len(jobs) {
+		t.Fatalf("expected done to be called for each of %d jobs, got %d", len(jobs), called)
+	}
+
+	for i, r := range res {
+		if r.Job.Name != jobs[i].Name {
+			t.Fatalf("expected result %d to be %s, got %s", i, jobs[i].Name, r.Job.Name)
+		}
+		if i == 3 {
+			if r.Err == nil {
+				t.Fatal("expected returning with an empty stack to fail")
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Fatalf("%s: %s", r.Job.Name, r.Err)
+		}
+		if !bytes.Equal(r.Image.Pix, res[0].Image.Pix) {
+			t.Fatalf("expected %s to render the same thumbnail as a", r.Job.Name)
+		}
+	}
+}
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "thumbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := Store(dir)
+	if s.Has(random) {
+		t.Fatal("expected no thumbnail yet")
+	}
+	if _, err = s.Load(random); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+
+	img, err := Render(random, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Save(random, img); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Has(random) {
+		t.Fatal("expected the thumbnail to be saved")
+	}
+
+	got, err := s.Load(random)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Bounds() != img.Bounds() {
+		t.Fatalf("expected a thumbnail of %v, got %v", img.Bounds(), got.Bounds())
+	}
+}
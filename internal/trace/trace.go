@@ -0,0 +1,135 @@
+// Package trace records the instructions, frames, draws and timer ticks of a
+// VM as a Chrome trace, which Perfetto (ui.perfetto.dev) and chrome://tracing
+// show on a timeline, for diagnosing pacing problems such as the timers
+// drifting relative to the instructions executed.
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/disasm"
+)
+
+// The threads events are shown on.
+const (
+	framesTID = iota + 1
+	instructionsTID
+	drawsTID
+)
+
+// Event is an event in the Chrome trace event format.
+type Event struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	TS    float64                `json:"ts"`
+	Dur   float64                `json:"dur,omitempty"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// Recorder records the events of a VM. Its hooks run on the emulation
+// goroutine, Write must be called there too or once the VM has stopped.
+type Recorder struct {
+	vm    *chip8.VM
+	start time.Time
+	now   func() time.Time
+
+	events []Event
+
+	// When the current frame started, in microseconds, and the instructions
+	// executed in it.
+	frameStart float64
+	frameInstr int
+	frames     int
+}
+
+// New returns a recorder of the events of vm from now on.
+func New(vm *chip8.VM) *Recorder {
+	return newRecorder(vm, time.Now)
+}
+
+// newRecorder returns a recorder timing events with now.
+func newRecorder(vm *chip8.VM, now func() time.Time) *Recorder {
+	r := &Recorder{vm: vm, start: now(), now: now}
+	for i, name := range []string{"frames", "instructions", "draws"} {
+		tid := framesTID + i
+		r.events = append(r.events, Event{
+			Name: "thread_name", Phase: "M", PID: 1, TID: tid,
+			Args: map[string]interface{}{"name": name},
+		})
+	}
+
+	vm.OnInstruction(r.instruction)
+	vm.OnFrame(r.frame)
+	return r
+}
+
+// ts returns the time since recording started in microseconds.
+func (r *Recorder) ts() float64 {
+	return float64(r.now().Sub(r.start)) / float64(time.Microsecond)
+}
+
+// instruction records the instruction opc at pc, and a draw if it is one.
+func (r *Recorder) instruction(pc, opc uint16) {
+	ts := r.ts()
+	r.frameInstr++
+	r.events = append(r.events, Event{
+		Name: disasm.Instruction(opc, r.vm.Symbols), Cat: "instruction", Phase: "i",
+		TS: ts, PID: 1, TID: instructionsTID, Scope: "t",
+		Args: map[string]interface{}{"pc": pc, "opcode": opc},
+	})
+
+	if opc&0xF000 == 0xD000 {
+		r.events = append(r.events, Event{
+			Name: "draw", Cat: "display", Phase: "i",
+			TS: ts, PID: 1, TID: drawsTID, Scope: "t",
+			Args: map[string]interface{}{"pc": pc},
+		})
+	}
+}
+
+// frame records the frame ending, with the instructions executed in it and
+// the timers after they tick.
+func (r *Recorder) frame() {
+	ts := r.ts()
+	dt, st := r.vm.Timers()
+
+	r.events = append(r.events,
+		Event{
+			Name: "frame", Cat: "frame", Phase: "X",
+			TS: r.frameStart, Dur: ts - r.frameStart, PID: 1, TID: framesTID,
+			Args: map[string]interface{}{"frame": r.frames, "instructions": r.frameInstr},
+		},
+		Event{
+			Name: "timers", Phase: "C", TS: ts, PID: 1,
+			Args: map[string]interface{}{"delay": dt, "sound": st},
+		},
+		Event{
+			Name: "instructions per frame", Phase: "C", TS: ts, PID: 1,
+			Args: map[string]interface{}{"instructions": r.frameInstr},
+		},
+	)
+
+	r.frameStart = ts
+	r.frameInstr = 0
+	r.frames++
+}
+
+// Events returns the events recorded so far.
+func (r *Recorder) Events() []Event {
+	return r.events
+}
+
+// Write writes the events recorded as a Chrome trace JSON object.
+func (r *Recorder) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(struct {
+		TraceEvents     []Event `json:"traceEvents"`
+		DisplayTimeUnit string  `json:"displayTimeUnit"`
+	}{r.events, "ms"})
+}
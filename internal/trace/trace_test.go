@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+func TestRecorder(t *testing.T) {
+	vm := chip8.New()
+	// A200: I = 0x200; D015: draw; 1202: jump to the draw.
+	if err := vm.Load(bytes.NewReader([]byte{0xA2, 0x00, 0xD0, 0x15, 0x12, 0x02})); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each reading of the clock is a millisecond later.
+	clock := time.Unix(0, 0)
+	r := newRecorder(vm, func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := vm.StepFrame(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts := make(map[string]int)
+	var frames []Event
+	for _, e := range r.Events() {
+		counts[e.Phase+" "+e.Cat]++
+		if e.Name == "frame" {
+			frames = append(frames, e)
+		}
+	}
+
+	if counts["M "] != 3 {
+		t.Fatalf("expected 3 thread names, got %d", counts["M "])
+	}
+	// Two frames of 5 instructions, 5 of them draws.
+	if counts["i instruction"] != 10 || counts["i display"] != 5 {
+		t.Fatalf("expected 10 instructions and 5 draws, got %v", counts)
+	}
+	if len(frames) != 2 || counts["C "] != 4 {
+		t.Fatalf("expected 2 frames with their counters, got %v", counts)
+	}
+	if frames[1].TS != frames[0].TS+frames[0].Dur || frames[1].Args["instructions"] != 5 {
+		t.Fatalf("expected the second frame to follow the first, got %+v", frames)
+	}
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		TraceEvents []Event `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.TraceEvents) != len(r.Events()) {
+		t.Fatalf("expected %d events written, got %d", len(r.Events()), len(out.TraceEvents))
+	}
+}
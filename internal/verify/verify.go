@@ -0,0 +1,359 @@
+// Package verify runs ROMs headlessly and checks the resulting machine state
+// against a test spec, letting ROM developers use the emulator as a test
+// runner in CI.
+//
+// Specs are YAML, or JSON if the file ends in .json:
+//
+//	rom: pong.ch8
+//	variant: schip
+//	cycles: 3000
+//	inputs:
+//	  - {cycle: 600, key: 0x1, hold: 30}
+//	display: 2f1d1c...
+//	memory:
+//	  - {addr: 0x3F0, value: 3}
+//	registers: {v0: 5, i: 0x2EA}
+//
+// The same spec as JSON:
+//
+//	{
+//		"rom": "pong.ch8",
+//		"variant": "schip",
+//		"cycles": 3000,
+//		"inputs": [
+//			{"cycle": 600, "key": "0x1", "hold": 30}
+//		],
+//		"display": "2f1d1c...",
+//		"memory": [
+//			{"addr": "0x3F0", "value": 3}
+//		],
+//		"registers": {"v0": 5, "i": "0x2EA"}
+//	}
+//
+// Numbers may be written in hexadecimal with a 0x prefix, quoted in JSON. The
+// ROM path is relative to the spec file. The variant defaults to the one
+// detected from the ROM, see chip8.Detect. Every check is optional.
+package verify
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+	"github.com/danmrichards/chip8/internal/coverage"
+	"github.com/danmrichards/chip8/internal/romfmt"
+	"gopkg.in/yaml.v2"
+)
+
+// Spec describes how to run a ROM and what state to expect afterwards.
+type Spec struct {
+	// ROM is the path to the ROM, relative to the spec file.
+	ROM string `json:"rom" yaml:"rom"`
+
+	// Variant is the name of the variant to run the ROM as, detected from
+	// the ROM if empty or auto.
+	Variant string `json:"variant" yaml:"variant"`
+
+	// Cycles is the number of instructions to execute.
+	Cycles uint64 `json:"cycles" yaml:"cycles"`
+
+	// Inputs are key presses to make while running.
+	Inputs []Input `json:"inputs" yaml:"inputs"`
+
+	// Display is the expected hex encoded display hash, see DisplayHash.
+	Display string `json:"display" yaml:"display"`
+
+	// Memory are expected bytes of memory.
+	Memory []Memory `json:"memory" yaml:"memory"`
+
+	// Registers are the expected values of v0-vf, i and pc.
+	Registers map[string]Number `json:"registers" yaml:"registers"`
+
+	// path is where the spec was loaded from.
+	path string
+}
+
+// Input is a key press made at a given cycle.
+type Input struct {
+	Cycle uint64 `json:"cycle" yaml:"cycle"`
+	Key   Number `json:"key" yaml:"key"`
+
+	// Hold is the number of cycles to hold the key for, defaulting to a
+	// single frame.
+	Hold uint64 `json:"hold" yaml:"hold"`
+}
+
+// Memory is an expected byte of memory.
+type Memory struct {
+	Addr  Number `json:"addr" yaml:"addr"`
+	Value Number `json:"value" yaml:"value"`
+}
+
+// Number is a number that can also be written as a string, allowing
+// hexadecimal values such as "0x200".
+type Number uint16
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *Number) UnmarshalJSON(b []byte) error {
+	return n.parse(strings.Trim(string(b), `"`))
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (n *Number) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return n.parse(s)
+}
+
+// parse sets n to the number in s, in decimal or with a 0x prefix.
+func (n *Number) parse(s string) error {
+	v, err := strconv.ParseUint(s, 0, 16)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", s)
+	}
+	*n = Number(v)
+	return nil
+}
+
+// Result is the outcome of running a spec.
+type Result struct {
+	// Failures describes each check that did not hold. An empty list means
+	// the spec passed.
+	Failures []string
+
+	// DisplayHash is the hash of the display once the ROM finished running.
+	DisplayHash string
+
+	// Variant is the name of the variant the ROM actually ran as, which
+	// loading may have switched from the one asked for.
+	Variant string
+
+	// Cycles is the number of instructions executed, fewer than the spec's
+	// if the ROM halted first.
+	Cycles uint64
+
+	// Coverage records the bytes of the ROM the run executed and read.
+	Coverage *coverage.Map
+
+	// Output holds what the ROM printed with the debug routines, see
+	// chip8.EnableDebug.
+	Output []string
+}
+
+// Passed returns true if every check in the spec held.
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// LoadSpec reads the spec at path.
+func LoadSpec(path string) (*Spec, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Spec{path: path}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(s)
+	} else {
+		err = yaml.UnmarshalStrict(b, s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", path, err)
+	}
+	if s.Cycles == 0 {
+		return nil, fmt.Errorf("%s: cycles must be set", path)
+	}
+	if s.Variant != "" && s.Variant != "auto" {
+		if _, err = chip8.ParseVariant(s.Variant); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return s, nil
+}
+
+// ROMPath returns the path of the ROM relative to the working directory.
+func (s *Spec) ROMPath() string {
+	if s.ROM == "" || filepath.IsAbs(s.ROM) {
+		return s.ROM
+	}
+	return filepath.Join(filepath.Dir(s.path), s.ROM)
+}
+
+// Run runs the spec against the ROM at path, for the spec's cycles or until
+// the ROM halts. Emulation errors, such as unsupported opcodes, are returned
+// as errors rather than failures.
+func (s *Spec) Run(rom string) (*Result, error) {
+	data, err := ioutil.ReadFile(rom)
+	if err != nil {
+		return nil, err
+	}
+	if data, _, err = romfmt.Decode(rom, data); err != nil {
+		return nil, err
+	}
+
+	return s.RunReader(bytes.NewReader(data))
+}
+
+// RunReader runs the spec against the ROM read from rom.
+func (s *Spec) RunReader(rom io.Reader) (*Result, error) {
+	data, err := ioutil.ReadAll(rom)
+	if err != nil {
+		return nil, err
+	}
+
+	vr, _ := chip8.Detect(data)
+	if s.Variant != "" && s.Variant != "auto" {
+		if vr, err = chip8.ParseVariant(s.Variant); err != nil {
+			return nil, err
+		}
+	}
+
+	vm := chip8.NewVariant(vr)
+	if err = vm.Load(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	cov := coverage.New(vm, len(data))
+
+	// A failed assertion stops the run as a failure rather than an error.
+	var output []string
+	vm.EnableDebug(func(format string, args ...interface{}) {
+		output = append(output, fmt.Sprintf(format, args...))
+	})
+	failed := ""
+	vm.OnSys(chip8.DebugFail, func() error {
+		failed = fmt.Sprintf("assertion failed at 0x%03X", vm.PC()-2)
+		return errors.New(failed)
+	})
+
+	inputs := make([]Input, len(s.Inputs))
+	copy(inputs, s.Inputs)
+	sort.Slice(inputs, func(i, j int) bool {
+		return inputs[i].Cycle < inputs[j].Cycle
+	})
+
+	// Stop early once the program halts, nothing changes after that.
+	var c uint64
+	for ; c < s.Cycles && !vm.Halted(); c++ {
+		for _, in := range inputs {
+			hold := in.Hold
+			if hold == 0 {
+				hold = chip8.ClockSpeed / chip8.FrameRate
+			}
+
+			switch c {
+			case in.Cycle:
+				vm.KeyDown(byte(in.Key) & 0xF)
+			case in.Cycle + hold:
+				vm.KeyUp(byte(in.Key) & 0xF)
+			}
+		}
+
+		if err := vm.Cycle(); err != nil {
+			if failed != "" {
+				break
+			}
+			return nil, fmt.Errorf("cycle %d: %s", c, err)
+		}
+	}
+
+	r := s.check(vm)
+	if failed != "" {
+		r.Failures = append([]string{fmt.Sprintf("cycle %d: %s", c, failed)}, r.Failures...)
+	}
+	r.Cycles = c
+	r.Coverage = cov
+	r.Output = output
+	return r, nil
+}
+
+// check compares the state of vm to the spec.
+func (s *Spec) check(vm *chip8.VM) *Result {
+	r := &Result{
+		DisplayHash: DisplayHash(vm),
+		Variant:     vm.Variant().String(),
+	}
+
+	if s.Display != "" && !strings.EqualFold(s.Display, r.DisplayHash) {
+		r.Failures = append(r.Failures, fmt.Sprintf(
+			"display: expected %s, got %s", s.Display, r.DisplayHash,
+		))
+	}
+
+	for _, m := range s.Memory {
+		if got := vm.Peek(uint16(m.Addr)); got != byte(m.Value) {
+			r.Failures = append(r.Failures, fmt.Sprintf(
+				"memory 0x%03X: expected 0x%02X, got 0x%02X", m.Addr, m.Value, got,
+			))
+		}
+	}
+
+	regs := make([]string, 0, len(s.Registers))
+	for name := range s.Registers {
+		regs = append(regs, name)
+	}
+	sort.Strings(regs)
+
+	for _, name := range regs {
+		exp := uint16(s.Registers[name])
+		got, err := register(vm, name)
+		if err != nil {
+			r.Failures = append(r.Failures, err.Error())
+			continue
+		}
+		if got != exp {
+			r.Failures = append(r.Failures, fmt.Sprintf(
+				"register %s: expected 0x%X, got 0x%X", name, exp, got,
+			))
+		}
+	}
+
+	return r
+}
+
+// register returns the value of the named register.
+func register(vm *chip8.VM, name string) (uint16, error) {
+	switch name = strings.ToLower(name); name {
+	case "i":
+		return uint16(vm.I()), nil
+	case "pc":
+		return vm.PC(), nil
+	}
+
+	if len(name) == 2 && name[0] == 'v' {
+		if x, err := strconv.ParseUint(name[1:], 16, 8); err == nil {
+			return uint16(vm.V(byte(x))), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown register %q", name)
+}
+
+// DisplayHash returns the hex encoded SHA-1 hash of the display, one byte per
+// pixel in row order.
+func DisplayHash(vm *chip8.VM) string {
+	disp := vm.Display()
+	px := make([]byte, disp.Width()*disp.Height())
+	for i := range px {
+		if vm.PixelSet(i) {
+			px[i] = 1
+		}
+	}
+
+	sum := sha1.Sum(px)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	// 6005: V0 = 5
+	// A300: I = 0x300
+	// F10A: wait for a key press, storing it in V1
+	// 1206: jump to self
+	rom := []byte{0x60, 0x05, 0xA3, 0x00, 0xF1, 0x0A, 0x12, 0x06}
+
+	s := &Spec{
+		Cycles: 20,
+		Inputs: []Input{{Cycle: 10, Key: 0x1}},
+		Registers: map[string]Number{
+			"v0": 5,
+			"i":  0x300,
+			"pc": 0x206,
+		},
+		Memory: []Memory{{Addr: 0x200, Value: 0x60}},
+	}
+
+	res, err := s.RunReader(bytes.NewReader(rom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Passed() {
+		t.Fatalf("unexpected failures: %q", res.Failures)
+	}
+	if res.Cycles >= s.Cycles {
+		t.Fatalf("expected to stop once halted, ran %d cycles", res.Cycles)
+	}
+
+	s.Display = "nope"
+	s.Registers["v0"] = 6
+	if res, err = s.RunReader(bytes.NewReader(rom)); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Failures) != 2 {
+		t.Fatalf("expected 2 failures, got %q", res.Failures)
+	}
+}
+
+func TestRunVariant(t *testing.T) {
+	// 6003: V0 = 3
+	// 6110: V1 = 0x10
+	// 8016: shift right, V0 = V0 >> 1, or V1 >> 1 on XO-CHIP
+	// 1206: jump to self
+	rom := []byte{0x60, 0x03, 0x61, 0x10, 0x80, 0x16, 0x12, 0x06}
+
+	tests := map[string]Number{
+		"":       0x01,
+		"chip8":  0x01,
+		"xochip": 0x08,
+	}
+	for variant, v0 := range tests {
+		s := &Spec{
+			Variant:   variant,
+			Cycles:    10,
+			Registers: map[string]Number{"v0": v0},
+		}
+		res, err := s.RunReader(bytes.NewReader(rom))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Passed() {
+			t.Errorf("%q: unexpected failures: %q", variant, res.Failures)
+		}
+
+		exp := variant
+		if exp == "" {
+			exp = "chip8"
+		}
+		if res.Variant != exp {
+			t.Errorf("%q: expected to run as %s, got %s", variant, exp, res.Variant)
+		}
+	}
+}
+
+func TestLoadSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	specs := map[string]string{
+		"spec.yaml": `
+rom: pong.ch8
+variant: schip
+cycles: 3000
+inputs:
+  - {cycle: 600, key: 0x1, hold: 30}
+memory:
+  - {addr: 0x3F0, value: 3}
+registers: {v0: 5, i: "0x2EA"}
+`,
+		"spec.json": `{
+	"rom": "pong.ch8",
+	"variant": "schip",
+	"cycles": 3000,
+	"inputs": [{"cycle": 600, "key": "0x1", "hold": 30}],
+	"memory": [{"addr": "0x3F0", "value": 3}],
+	"registers": {"v0": 5, "i": "0x2EA"}
+}`,
+	}
+	for name, src := range specs {
+		path := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := LoadSpec(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.ROMPath() != filepath.Join(dir, "pong.ch8") || s.Variant != "schip" || s.Cycles != 3000 {
+			t.Errorf("%s: unexpected spec %+v", name, s)
+		}
+		if len(s.Inputs) != 1 || s.Inputs[0].Key != 1 || s.Inputs[0].Hold != 30 {
+			t.Errorf("%s: unexpected inputs %+v", name, s.Inputs)
+		}
+		if len(s.Memory) != 1 || s.Memory[0].Addr != 0x3F0 || s.Memory[0].Value != 3 {
+			t.Errorf("%s: unexpected memory %+v", name, s.Memory)
+		}
+		if s.Registers["v0"] != 5 || s.Registers["i"] != 0x2EA {
+			t.Errorf("%s: unexpected registers %+v", name, s.Registers)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.yml")
+	if err = ioutil.WriteFile(bad, []byte("cycles: 10\nspeed: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadSpec(bad); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+
+	if err = ioutil.WriteFile(bad, []byte("cycles: 10\nvariant: nes\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadSpec(bad); err == nil {
+		t.Error("expected an error for an unknown variant")
+	}
+}
+
+func TestRunDebug(t *testing.T) {
+	// 6005: V0 = 5
+	// 0FF0: print V0
+	// 4005: skip unless V0 == 5, so the assertion fails
+	// 0FD1: fail
+	rom := []byte{0x60, 0x05, 0x0F, 0xF0, 0x40, 0x05, 0x0F, 0xD1}
+
+	s := &Spec{Cycles: 20}
+	res, err := s.RunReader(bytes.NewReader(rom))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Output) != 1 || res.Output[0] != "0x202: v0 = 0x05 (5)" {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	if len(res.Failures) != 1 || res.Failures[0] != "cycle 3: assertion failed at 0x206" {
+		t.Fatalf("expected the assertion to fail, got %q", res.Failures)
+	}
+}
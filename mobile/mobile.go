@@ -0,0 +1,232 @@
+// Package mobile runs the emulator in Android and iOS apps. It is bound to
+// Java and Objective-C with gomobile:
+//
+//	make android
+//	make ios
+//
+// The app picks a ROM with the platform's file chooser and passes its bytes to
+// Load. On each screen refresh it calls Step and draws the pixels returned by
+// Pixels, with the 4x4 keypad laid out by Key below or beside them. Touches on
+// the keypad are passed to Touch and Lift.
+package mobile
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/danmrichards/chip8/internal/chip8"
+)
+
+// keypad is the layout of the Chip8 hex keypad.
+var keypad = [4][4]byte{
+	{0x1, 0x2, 0x3, 0xC},
+	{0x4, 0x5, 0x6, 0xD},
+	{0x7, 0x8, 0x9, 0xE},
+	{0xA, 0x0, 0xB, 0xF},
+}
+
+// Emulator is a VM driven by the app, with its on-screen keypad.
+type Emulator struct {
+	vm   *chip8.VM
+	rgba []byte
+
+	// The area of the screen the keypad is drawn in, in the app's own units.
+	padX, padY, padW, padH float64
+
+	// Touches, by pointer ID, and the key each is on, if any. Touches come
+	// from the UI thread, so are guarded by mu.
+	mu      sync.Mutex
+	touches map[int]int
+	down    [16]bool
+}
+
+// NewEmulator returns an emulator with no ROM loaded.
+func NewEmulator() *Emulator {
+	return &Emulator{touches: make(map[int]int)}
+}
+
+// Load loads rom, detecting the variant it was written for.
+func (e *Emulator) Load(rom []byte) error {
+	vr, _ := chip8.Detect(rom)
+	vm := chip8.NewVariant(vr)
+	if err := vm.Load(bytes.NewReader(rom)); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.vm = vm
+	e.touches = make(map[int]int)
+	e.down = [16]bool{}
+	e.mu.Unlock()
+
+	return nil
+}
+
+// Loaded returns true once a ROM has been loaded.
+func (e *Emulator) Loaded() bool {
+	return e.vm != nil
+}
+
+// Variant returns the name of the variant the ROM is run as.
+func (e *Emulator) Variant() string {
+	if e.vm == nil {
+		return ""
+	}
+	return e.vm.Variant().String()
+}
+
+// Step runs the VM for a frame. Apps call it 60 times a second, from their
+// display link or choreographer.
+func (e *Emulator) Step() error {
+	if e.vm == nil {
+		return nil
+	}
+	return e.vm.StepFrame()
+}
+
+// Reset restarts the ROM.
+func (e *Emulator) Reset() error {
+	if e.vm == nil {
+		return nil
+	}
+	return e.vm.Reset()
+}
+
+// Sounding returns true while the tone should sound.
+func (e *Emulator) Sounding() bool {
+	if e.vm == nil {
+		return false
+	}
+	_, sound := e.vm.Timers()
+	return sound > 0
+}
+
+// Width returns the width of the display in pixels. It changes as programs
+// switch resolution.
+func (e *Emulator) Width() int {
+	if e.vm == nil {
+		return chip8.DisplayWidth
+	}
+	return e.vm.Display().Width()
+}
+
+// Height returns the height of the display in pixels.
+func (e *Emulator) Height() int {
+	if e.vm == nil {
+		return chip8.DisplayHeight
+	}
+	return e.vm.Display().Height()
+}
+
+// Pixels returns the display as RGBA, four bytes a pixel in row order, Width
+// by Height. The slice is reused by the next call.
+func (e *Emulator) Pixels() []byte {
+	if e.vm == nil {
+		return nil
+	}
+
+	img := e.vm.Image()
+	if n := 4 * len(img.Pix); len(e.rgba) != n {
+		e.rgba = make([]byte, n)
+	}
+
+	pal := make([][4]byte, len(img.Palette))
+	for i, c := range img.Palette {
+		r, g, b, a := c.RGBA()
+		pal[i] = [4]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8), byte(a >> 8)}
+	}
+	for i, p := range img.Pix {
+		copy(e.rgba[4*i:], pal[p][:])
+	}
+
+	return e.rgba
+}
+
+// Key returns the key at row and col of the keypad, for drawing it, or -1 if
+// either is out of range. Keys are laid out as on the COSMAC VIP:
+//
+//	1 2 3 C
+//	4 5 6 D
+//	7 8 9 E
+//	A 0 B F
+func Key(row, col int) int {
+	if row < 0 || col < 0 || row >= 4 || col >= 4 {
+		return -1
+	}
+	return int(keypad[row][col])
+}
+
+// SetKeypad sets the area of the screen the keypad is drawn in, in the same
+// units as the touches passed to Touch.
+func (e *Emulator) SetKeypad(x, y, w, h float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.padX, e.padY, e.padW, e.padH = x, y, w, h
+}
+
+// KeyAt returns the key drawn at (x, y), or -1 if the point is outside the
+// keypad.
+func (e *Emulator) KeyAt(x, y float64) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.keyAt(x, y)
+}
+
+// keyAt is KeyAt, with mu held.
+func (e *Emulator) keyAt(x, y float64) int {
+	if e.padW <= 0 || e.padH <= 0 {
+		return -1
+	}
+	x, y = (x-e.padX)/e.padW, (y-e.padY)/e.padH
+	if x < 0 || y < 0 || x >= 1 || y >= 1 {
+		return -1
+	}
+	return Key(int(y*4), int(x*4))
+}
+
+// Touch presses the key under the touch id at (x, y). Apps call it as the
+// touch starts and each time it moves, so sliding a finger between keys
+// releases one and presses the other. Several touches may be down at once.
+func (e *Emulator) Touch(id int, x, y float64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.touches[id] = e.keyAt(x, y)
+	e.update()
+}
+
+// Lift releases the key under the touch id, as the touch ends or is
+// cancelled.
+func (e *Emulator) Lift(id int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.touches, id)
+	e.update()
+}
+
+// update presses the keys now touched and releases those no longer, with mu
+// held. A key stays down while any touch is on it.
+func (e *Emulator) update() {
+	var down [16]bool
+	for _, key := range e.touches {
+		if key >= 0 {
+			down[key] = true
+		}
+	}
+
+	for key := range down {
+		if down[key] == e.down[key] {
+			continue
+		}
+		if e.vm != nil {
+			if down[key] {
+				e.vm.Press(byte(key))
+			} else {
+				e.vm.Release(byte(key))
+			}
+		}
+	}
+	e.down = down
+}
@@ -0,0 +1,96 @@
+package mobile
+
+import "testing"
+
+func TestKeyAt(t *testing.T) {
+	e := NewEmulator()
+	if k := e.KeyAt(10, 10); k != -1 {
+		t.Fatalf("expected no key before the keypad is set, got %d", k)
+	}
+
+	e.SetKeypad(100, 200, 400, 400)
+	cases := []struct {
+		x, y float64
+		key  int
+	}{
+		{110, 210, 0x1},
+		{490, 210, 0xC},
+		{250, 350, 0x5},
+		{110, 590, 0xA},
+		{490, 590, 0xF},
+		{99, 210, -1},
+		{500, 210, -1},
+		{110, 600, -1},
+	}
+	for _, c := range cases {
+		if k := e.KeyAt(c.x, c.y); k != c.key {
+			t.Errorf("(%v, %v): expected key %d, got %d", c.x, c.y, c.key, k)
+		}
+	}
+}
+
+func TestTouch(t *testing.T) {
+	e := NewEmulator()
+
+	// Waits for a key and stores it in V0 at 0x300, then halts.
+	rom := []byte{
+		0xF0, 0x0A, // V0 = key
+		0xA3, 0x00, // I = 0x300
+		0xF0, 0x55, // Store V0
+		0x12, 0x06, // Halt
+	}
+	if err := e.Load(rom); err != nil {
+		t.Fatal(err)
+	}
+	e.SetKeypad(0, 0, 4, 4)
+
+	// Two fingers on 5, it stays down until both lift.
+	e.Touch(1, 1.5, 1.5)
+	e.Touch(2, 1.2, 1.8)
+	e.Lift(1)
+	if !e.down[0x5] {
+		t.Fatal("expected 5 to be down while touched")
+	}
+	for f := 0; f < 3; f++ {
+		if err := e.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := e.vm.Peek(0x300); got != 0x5 {
+		t.Fatalf("expected key 5, got %X", got)
+	}
+
+	// Sliding off the keypad releases it.
+	e.Touch(2, 8, 8)
+	if e.down[0x5] {
+		t.Fatal("expected 5 to be released")
+	}
+}
+
+func TestPixels(t *testing.T) {
+	e := NewEmulator()
+	if e.Pixels() != nil {
+		t.Fatal("expected no pixels before a ROM is loaded")
+	}
+
+	// Draws the top of the font sprite for 0, 0xF0, at (0, 0).
+	if err := e.Load([]byte{0x60, 0x00, 0xF0, 0x29, 0xD0, 0x01}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Step(); err != nil {
+		t.Fatal(err)
+	}
+
+	px := e.Pixels()
+	if len(px) != 4*e.Width()*e.Height() {
+		t.Fatalf("expected %d bytes, got %d", 4*e.Width()*e.Height(), len(px))
+	}
+	for x, lit := range []bool{true, true, true, true, false} {
+		if got := px[4*x] == 0xFF; got != lit {
+			t.Fatalf("pixel %d: expected lit %v", x, lit)
+		}
+		if px[4*x+3] != 0xFF {
+			t.Fatalf("pixel %d: expected to be opaque", x)
+		}
+	}
+}